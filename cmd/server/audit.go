@@ -0,0 +1,58 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"slices"
+
+	portfolio "github.com/fpatron/portfolio"
+	"github.com/fpatron/portfolio/internal/a11y"
+	"github.com/fpatron/portfolio/internal/handler"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+// auditA11y implements the audit-a11y command, which renders every template
+// with the data validate uses and reports the accessibility problems of each
+// by the file defining it. A problem is reported once, for the smallest
+// template showing it, which is the one defining it rather than those
+// calling it.
+func auditA11y(cfg portfolio.Config, args []string) error {
+	flags := flag.NewFlagSet("audit-a11y", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: portfolio audit-a11y")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	h, err := handler.New(site, handler.Options{BaseURL: cfg.BaseURL})
+	if err != nil {
+		return err
+	}
+	outputs, err := h.RenderTemplates()
+	if err != nil {
+		return fmt.Errorf("render templates: %w", err)
+	}
+	slices.SortStableFunc(outputs, func(a, b render.Output) int { return cmp.Compare(len(a.HTML), len(b.HTML)) })
+	seen := make(map[string]bool)
+	n := 0
+	for _, o := range outputs {
+		problems, err := a11y.Audit(o.HTML, o.Page)
+		if err != nil {
+			return fmt.Errorf("%s: %w", o.File, err)
+		}
+		for _, p := range problems {
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+			n++
+			fmt.Printf("%s (%s): %s\n", o.File, o.Name, p)
+		}
+	}
+	if n > 0 {
+		return fmt.Errorf("audit-a11y: %d problems found", n)
+	}
+	fmt.Println("no accessibility problems found")
+	return nil
+}
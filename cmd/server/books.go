@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	portfolio "github.com/fpatron/portfolio"
+	"github.com/fpatron/portfolio/internal/books"
+	"github.com/fpatron/portfolio/internal/db"
+)
+
+// importBooks implements the import-books command, which fills the
+// bookshelf from a Goodreads export or an Open Library reading log.
+func importBooks(cfg portfolio.Config, args []string) error {
+	fs := flag.NewFlagSet("import-books", flag.ExitOnError)
+	goodreads := fs.String("goodreads", "", "Goodreads library export CSV to import")
+	openLibrary := fs.String("openlibrary", "", "Open Library username whose reading log to import")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: portfolio import-books -goodreads export.csv | -openlibrary user")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if (*goodreads == "") == (*openLibrary == "") {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if cfg.DatabasePath == "" {
+		return errors.New("import-books: DATABASE_PATH is not set")
+	}
+
+	ctx := context.Background()
+	database, err := db.Open(cfg.DatabasePath)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	store, err := books.NewStore(ctx, database)
+	if err != nil {
+		return err
+	}
+
+	var list []books.Book
+	source := "openlibrary"
+	if *goodreads != "" {
+		source = "goodreads"
+		f, err := os.Open(*goodreads)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		list, err = books.ParseGoodreads(f)
+		if err != nil {
+			return err
+		}
+	} else if list, err = books.NewOpenLibrary(*openLibrary).Books(ctx); err != nil {
+		return err
+	}
+	if err := store.Replace(ctx, source, list); err != nil {
+		return err
+	}
+	log.Printf("imported %d books from %s", len(list), source)
+	return nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io/fs"
+	"maps"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	portfolio "github.com/fpatron/portfolio"
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/handler"
+)
+
+// externalLink matches absolute links in rendered HTML.
+var externalLink = regexp.MustCompile(`(?:href|src)="((?:https?:)?//[^"]+)"`)
+
+// checkLinks implements the check-links command, which renders every page
+// the site links to, the way export does, and reports the links that lead
+// nowhere: internal paths that do not resolve and, with -external, remote
+// URLs that fail. The URLs in the data files are checked along with the
+// ones in the pages.
+func checkLinks(cfg portfolio.Config, args []string) error {
+	flags := flag.NewFlagSet("check-links", flag.ExitOnError)
+	external := flags.Bool("external", false, "also request the external links")
+	concurrency := flags.Int("concurrency", 8, "external links requested at once")
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout of each external request")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: portfolio check-links [-external] [-concurrency 8] [-timeout 10s]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	h, err := handler.New(site, handler.Options{BaseURL: cfg.BaseURL})
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	h.PublicRoutes(mux)
+
+	// Without BASE_URL, pages link to the host of the requests they were
+	// rendered for.
+	l := newLinkSet(cmp.Or(cfg.BaseURL, "http://example.com"))
+	l.crawl(mux)
+	l.addData(h.Data())
+
+	dead := l.checkInternal(mux)
+	if *external {
+		client := &http.Client{Timeout: *timeout}
+		dead = append(dead, l.checkExternal(context.Background(), client, max(*concurrency, 1))...)
+	}
+	if len(dead) > 0 {
+		for _, d := range dead {
+			fmt.Println(d)
+		}
+		return fmt.Errorf("check-links: %d dead links", len(dead))
+	}
+	fmt.Printf("%d internal and %d external links are fine\n", len(l.internal), len(l.external))
+	if !*external && len(l.external) > 0 {
+		fmt.Println("run with -external to check the external ones")
+	}
+	return nil
+}
+
+// linkSet collects the links found on the site with where they were found.
+type linkSet struct {
+	base     string
+	internal map[string][]string // path -> pages linking to it
+	external map[string][]string // URL -> pages or data files linking to it
+}
+
+func newLinkSet(baseURL string) *linkSet {
+	return &linkSet{
+		base:     strings.TrimSuffix(baseURL, "/"),
+		internal: make(map[string][]string),
+		external: make(map[string][]string),
+	}
+}
+
+// add records that from links to ref. Links to the site's own origin are
+// internal.
+func (l *linkSet) add(from, ref string) {
+	ref, _, _ = strings.Cut(html.UnescapeString(ref), "#")
+	switch {
+	case ref == "" || exportSkip[ref]:
+	case strings.HasPrefix(ref, "//"):
+		l.add(from, "https:"+ref)
+	case strings.HasPrefix(ref, "/"):
+		if !slices.Contains(l.internal[ref], from) {
+			l.internal[ref] = append(l.internal[ref], from)
+		}
+	case l.base != "" && (ref == l.base || strings.HasPrefix(ref, l.base+"/")):
+		l.add(from, "/"+strings.TrimPrefix(strings.TrimPrefix(ref, l.base), "/"))
+	case strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://"):
+		if !slices.Contains(l.external[ref], from) {
+			l.external[ref] = append(l.external[ref], from)
+		}
+	}
+}
+
+// crawl renders the export seeds and every page they lead to, collecting
+// the links of each.
+func (l *linkSet) crawl(mux http.Handler) {
+	queue := append([]string(nil), exportSeeds...)
+	seen := make(map[string]bool)
+	for _, p := range queue {
+		seen[p] = true
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, p, nil))
+		if mediaType, _, _ := mime.ParseMediaType(rec.Header().Get("Content-Type")); rec.Code != http.StatusOK || mediaType != "text/html" {
+			continue
+		}
+		body := rec.Body.Bytes()
+		for _, m := range localLink.FindAllSubmatch(body, -1) {
+			l.add(p, string(m[1]))
+		}
+		for _, m := range externalLink.FindAllSubmatch(body, -1) {
+			l.add(p, string(m[1]))
+		}
+		for next := range l.internal {
+			if !seen[next] && !strings.HasPrefix(next, "/static/") {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+}
+
+// addData records the URLs of the data files, including those no page
+// shows.
+func (l *linkSet) addData(data content.PageData) {
+	for _, ref := range []string{data.About.GitHub, data.About.LinkedIn, data.About.X, data.About.ProfilePhoto} {
+		l.add("data/about.json", ref)
+	}
+	for _, p := range data.Projects {
+		for _, ref := range []string{p.Link, p.Image, p.PackageURL} {
+			l.add("data/projects.json", ref)
+		}
+	}
+	for _, e := range data.Experience {
+		for _, ref := range []string{e.CompanyURL, e.Logo} {
+			l.add("data/experience.json", ref)
+		}
+	}
+}
+
+// checkInternal requests each internal link from mux, or looks it up among
+// the static files, and describes the ones that do not resolve.
+func (l *linkSet) checkInternal(mux *http.ServeMux) []string {
+	var dead []string
+	for _, ref := range slices.Sorted(maps.Keys(l.internal)) {
+		if name, ok := strings.CutPrefix(ref, "/static/"); ok {
+			p, _, _ := strings.Cut(name, "?")
+			if _, err := fs.Stat(site, "static/"+p); err != nil {
+				dead = append(dead, deadLink(ref, "not found", l.internal[ref]))
+			}
+			continue
+		}
+		req := httptest.NewRequest(http.MethodGet, ref, nil)
+		// The 404 page catches every path no other route does.
+		if _, pattern := mux.Handler(req); pattern == "GET /" {
+			dead = append(dead, deadLink(ref, "no route", l.internal[ref]))
+			continue
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code >= 400 {
+			dead = append(dead, deadLink(ref, http.StatusText(rec.Code), l.internal[ref]))
+		}
+	}
+	return dead
+}
+
+// checkExternal requests each external link, at most concurrency at a time,
+// and describes the ones that fail.
+func (l *linkSet) checkExternal(ctx context.Context, client *http.Client, concurrency int) []string {
+	refs := slices.Sorted(maps.Keys(l.external))
+	problems := make([]string, len(refs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, ref := range refs {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := checkURL(ctx, client, ref); err != nil {
+				problems[i] = deadLink(ref, err.Error(), l.external[ref])
+			}
+		})
+	}
+	wg.Wait()
+	return slices.DeleteFunc(problems, func(p string) bool { return p == "" })
+}
+
+// checkURL requests ref with HEAD, falling back to GET for servers that do
+// not allow it, and fails on errors and error statuses.
+func checkURL(ctx context.Context, client *http.Client, ref string) error {
+	var status int
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		req, err := http.NewRequestWithContext(ctx, method, ref, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("User-Agent", "portfolio-check-links")
+		resp, err := client.Do(req)
+		if err != nil {
+			// The URL is already in the report.
+			var uerr *url.Error
+			if errors.As(err, &uerr) {
+				return uerr.Err
+			}
+			return err
+		}
+		resp.Body.Close()
+		status = resp.StatusCode
+		if status != http.StatusMethodNotAllowed && status != http.StatusForbidden && status != http.StatusNotImplemented {
+			break
+		}
+	}
+	if status >= 400 {
+		return fmt.Errorf("%d %s", status, http.StatusText(status))
+	}
+	return nil
+}
+
+// deadLink describes a dead link and where it was found.
+func deadLink(ref, problem string, from []string) string {
+	return fmt.Sprintf("%s: %s (linked from %s)", ref, problem, strings.Join(from, ", "))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	portfolio "github.com/fpatron/portfolio"
+	"github.com/fpatron/portfolio/internal/blog"
+)
+
+// crossPost implements the crosspost command, which publishes the blog's
+// posts to dev.to and Medium and records the copies in their front matter.
+func crossPost(cfg portfolio.Config, args []string) error {
+	fs := flag.NewFlagSet("crosspost", flag.ExitOnError)
+	dir := fs.String("dir", filepath.Join(siteDir, blog.Dir), "directory holding the posts")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: portfolio crosspost [-dir content/posts]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	published, err := portfolio.CrossPost(ctx, cfg, *dir)
+	for _, p := range published {
+		log.Printf("published %s", p)
+	}
+	if len(published) == 0 && err == nil {
+		log.Print("crosspost: nothing to publish; set DEVTO_API_KEY or MEDIUM_TOKEN, or every post already has its copies")
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	portfolio "github.com/fpatron/portfolio"
+	"github.com/fpatron/portfolio/internal/deploy"
+)
+
+// deploySite implements the deploy command, which exports the site and
+// publishes it to the target named by DEPLOY_TARGET.
+func deploySite(cfg portfolio.Config, args []string) error {
+	flags := flag.NewFlagSet("deploy", flag.ExitOnError)
+	target := flags.String("target", cfg.Getenv("DEPLOY_TARGET"), "where to publish: s3, netlify or github-pages")
+	dir := flags.String("o", "", "directory to export to; a temporary one by default")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: portfolio deploy [-target s3|netlify|github-pages] [-o dir]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	t, err := deployTarget(*target, cfg.Getenv)
+	if err != nil {
+		return err
+	}
+	if *dir == "" {
+		tmp, err := os.MkdirTemp("", "portfolio-export-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		*dir = tmp
+	}
+	n, err := exportTo(cfg, *dir)
+	if err != nil {
+		return err
+	}
+	log.Printf("exported %d files to %s", n, *dir)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := t.Deploy(ctx, *dir); err != nil {
+		return fmt.Errorf("deploy: %w", err)
+	}
+	return nil
+}
+
+// deployTarget builds the named target from the settings.
+func deployTarget(name string, getenv func(string) string) (deploy.Target, error) {
+	switch name {
+	case "s3":
+		bucket := getenv("DEPLOY_S3_BUCKET")
+		creds := deploy.Credentials{
+			AccessKey:    getenv("AWS_ACCESS_KEY_ID"),
+			SecretKey:    getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken: getenv("AWS_SESSION_TOKEN"),
+		}
+		if bucket == "" || creds.AccessKey == "" || creds.SecretKey == "" {
+			return nil, errors.New("deploy: s3 requires DEPLOY_S3_BUCKET, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		}
+		region := cmp.Or(getenv("DEPLOY_S3_REGION"), getenv("AWS_REGION"), "us-east-1")
+		t := deploy.NewS3(bucket, region, creds)
+		t.Prefix = getenv("DEPLOY_S3_PREFIX")
+		t.Endpoint = getenv("DEPLOY_S3_ENDPOINT")
+		t.Distribution = getenv("DEPLOY_CLOUDFRONT_DISTRIBUTION")
+		return t, nil
+	case "netlify":
+		site, token := getenv("NETLIFY_SITE_ID"), getenv("NETLIFY_AUTH_TOKEN")
+		if site == "" || token == "" {
+			return nil, errors.New("deploy: netlify requires NETLIFY_SITE_ID and NETLIFY_AUTH_TOKEN")
+		}
+		return deploy.NewNetlify(site, token), nil
+	case "github-pages":
+		t := deploy.NewGitHubPages(cmp.Or(getenv("DEPLOY_GIT_REMOTE"), "origin"), cmp.Or(getenv("DEPLOY_GIT_BRANCH"), "gh-pages"))
+		t.CNAME = getenv("DEPLOY_CNAME")
+		return t, nil
+	case "":
+		return nil, errors.New("deploy: set DEPLOY_TARGET or -target")
+	default:
+		return nil, fmt.Errorf("deploy: unknown target %q, want s3, netlify or github-pages", name)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"archive/zip"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	portfolio "github.com/fpatron/portfolio"
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/handler"
+	"github.com/fpatron/portfolio/internal/importer"
+)
+
+// importExperience implements the import-experience command, which writes
+// data/experience.json and data/skills.json from a LinkedIn data export or
+// from structured CSV files.
+func importExperience(_ portfolio.Config, args []string) error {
+	fs := flag.NewFlagSet("import-experience", flag.ExitOnError)
+	linkedIn := fs.String("linkedin", "", "LinkedIn data export, as the zip archive or its extracted directory")
+	experienceCSV := fs.String("experience", "", "CSV with one experience entry per line")
+	skillsCSV := fs.String("skills", "", "CSV with category and skill columns")
+	dir := fs.String("o", filepath.Join(siteDir, "data"), "directory to write experience.json and skills.json to")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: portfolio import-experience -linkedin export.zip | -experience file.csv [-skills file.csv] [-o data]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if (*linkedIn == "") == (*experienceCSV == "" && *skillsCSV == "") {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var (
+		experience []content.Experience
+		skills     []content.SkillCategory
+		err        error
+	)
+	if *linkedIn != "" {
+		experience, skills, err = readLinkedIn(*linkedIn)
+	} else {
+		if *experienceCSV != "" {
+			err = readFile(*experienceCSV, func(f *os.File) (err error) {
+				experience, err = importer.ParseExperienceCSV(f)
+				return err
+			})
+		}
+		if err == nil && *skillsCSV != "" {
+			err = readFile(*skillsCSV, func(f *os.File) (err error) {
+				skills, err = importer.ParseSkillsCSV(f)
+				return err
+			})
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if experience != nil {
+		keepCompanyDetails(experience, filepath.Join(*dir, "experience.json"))
+		if err := handler.ValidateExperience(experience); err != nil {
+			return fmt.Errorf("invalid experience:\n%w", err)
+		}
+		if err := writeJSON(filepath.Join(*dir, "experience.json"), experience); err != nil {
+			return err
+		}
+		log.Printf("wrote %d experience entries", len(experience))
+	}
+	if skills != nil {
+		if err := handler.ValidateSkills(skills); err != nil {
+			return fmt.Errorf("invalid skills:\n%w", err)
+		}
+		if err := writeJSON(filepath.Join(*dir, "skills.json"), skills); err != nil {
+			return err
+		}
+		log.Printf("wrote %d skill categories", len(skills))
+	}
+	return nil
+}
+
+func readLinkedIn(path string) ([]content.Experience, []content.SkillCategory, error) {
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer zr.Close()
+		return importer.ParseLinkedIn(zr)
+	}
+	return importer.ParseLinkedIn(os.DirFS(path))
+}
+
+func readFile(path string, read func(*os.File) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return read(f)
+}
+
+// keepCompanyDetails fills in the company URL and logo of imported entries
+// from the existing file, since exports do not carry them.
+func keepCompanyDetails(list []content.Experience, path string) {
+	var existing []content.Experience
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err == nil {
+		err = json.Unmarshal(b, &existing)
+	}
+	if err != nil {
+		log.Printf("import-experience: keeping company details: %v", err)
+		return
+	}
+	known := make(map[string]content.Experience, len(existing))
+	for _, e := range existing {
+		known[strings.ToLower(e.Company)] = e
+	}
+	for i, e := range list {
+		k := known[strings.ToLower(e.Company)]
+		list[i].CompanyURL = cmp.Or(e.CompanyURL, k.CompanyURL)
+		list[i].Logo = cmp.Or(e.Logo, k.Logo)
+	}
+}
+
+func writeJSON(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
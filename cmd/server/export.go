@@ -0,0 +1,179 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"html"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	portfolio "github.com/fpatron/portfolio"
+	"github.com/fpatron/portfolio/internal/handler"
+)
+
+// exportSeeds are exported even when no page links to them.
+var exportSeeds = []string{"/", "/sitemap.xml", "/feed.xml", "/atom.xml", "/resume.pdf", "/resume.json", "/contact.vcf", "/api/projects", "/api/experience", "/api/posts"}
+
+// exportSkip lists linked paths that only make sense on the server, such
+// as the analytics honeypot.
+var exportSkip = map[string]bool{"/trap": true}
+
+// localLink matches the links in rendered HTML, and the URLs of meta tags
+// such as og:image.
+var localLink = regexp.MustCompile(`(?:href|src|hx-get|content)="((?:https?://[^/"]+)?/[^"]*)"`)
+
+// exportSite implements the export command, which writes a static mirror of
+// the public pages, partials and API responses, plus the static assets, so
+// the site can be hosted without the server.
+func exportSite(cfg portfolio.Config, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	dir := fs.String("o", "dist", "directory to write the site to")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: portfolio export [-o dist]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	n, err := exportTo(cfg, *dir)
+	if err != nil {
+		return err
+	}
+	log.Printf("exported %d files to %s", n, *dir)
+	return nil
+}
+
+// exportTo writes the static site to dir and returns how many files it
+// wrote.
+func exportTo(cfg portfolio.Config, dir string) (int, error) {
+	h, err := handler.New(site, handler.Options{BaseURL: cfg.BaseURL})
+	if err != nil {
+		return 0, err
+	}
+	mux := http.NewServeMux()
+	h.PublicRoutes(mux)
+	// Absolute links to the site, such as its social cards, are exported
+	// like site-relative ones. Without BASE_URL they have the host of the
+	// export's requests.
+	base := strings.TrimSuffix(cmp.Or(cfg.BaseURL, "http://example.com"), "/")
+
+	n, err := copyStatic(dir)
+	if err != nil {
+		return 0, err
+	}
+	queue := append([]string(nil), exportSeeds...)
+	seen := make(map[string]bool)
+	for _, p := range queue {
+		seen[p] = true
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		links, err := exportRoute(mux, dir, base, p)
+		if err != nil {
+			return 0, err
+		}
+		if links == nil {
+			continue
+		}
+		n++
+		for _, l := range links {
+			if !seen[l] {
+				seen[l] = true
+				queue = append(queue, l)
+			}
+		}
+	}
+	return n, nil
+}
+
+// exportRoute renders p and writes it below dir. It returns the local links
+// found in the response, those to base included, or nil if p was skipped.
+func exportRoute(mux http.Handler, dir, base, p string) ([]string, error) {
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, p, nil))
+	body := rec.Body.Bytes()
+	contentType := rec.Header().Get("Content-Type")
+	switch code := rec.Code; {
+	case code == http.StatusNoContent:
+		// Sections that have nothing to show yet stay empty.
+	case code >= 300 && code < 400:
+		// Static hosts cannot redirect, so outbound links get a page that
+		// does it in the browser.
+		to := html.EscapeString(rec.Header().Get("Location"))
+		body = fmt.Appendf(nil, "<!DOCTYPE html>\n<meta charset=\"utf-8\">\n<title>Redirecting…</title>\n<link rel=\"canonical\" href=\"%s\">\n<meta http-equiv=\"refresh\" content=\"0; url=%s\">\n", to, to)
+		contentType = "text/html; charset=utf-8"
+	case code != http.StatusOK:
+		log.Printf("export: skipping %s: %d %s", p, code, http.StatusText(code))
+		return nil, nil
+	}
+
+	name := strings.TrimPrefix(p, "/")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	isHTML := mediaType == "text/html" || contentType == ""
+	if path.Ext(p) == "" && isHTML {
+		// Served as a directory index, so the path works without an
+		// extension.
+		name = path.Join(name, "index.html")
+	}
+	file := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(file, body, 0o644); err != nil {
+		return nil, err
+	}
+
+	links := []string{}
+	if mediaType == "text/html" {
+		for _, m := range localLink.FindAllSubmatch(body, -1) {
+			l, _, _ := strings.Cut(html.UnescapeString(string(m[1])), "#")
+			if !strings.HasPrefix(l, "/") {
+				var ok bool
+				if l, ok = strings.CutPrefix(l, base); !ok || !strings.HasPrefix(l, "/") {
+					continue
+				}
+			}
+			// Query strings are lost on a static host; static files are
+			// copied separately.
+			if l == "" || strings.HasPrefix(l, "//") || strings.Contains(l, "?") || strings.HasPrefix(l, "/static/") || exportSkip[l] {
+				continue
+			}
+			links = append(links, l)
+		}
+	}
+	return links, nil
+}
+
+// copyStatic writes the site's static assets to dir/static and returns
+// how many files it wrote.
+func copyStatic(dir string) (int, error) {
+	n := 0
+	err := fs.WalkDir(site, "static", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := fs.ReadFile(site, p)
+		if err != nil {
+			return err
+		}
+		file := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return err
+		}
+		n++
+		return os.WriteFile(file, b, 0o644)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("copy static files: %w", err)
+	}
+	return n, nil
+}
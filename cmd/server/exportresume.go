@@ -0,0 +1,49 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	portfolio "github.com/fpatron/portfolio"
+	"github.com/fpatron/portfolio/internal/handler"
+	"github.com/fpatron/portfolio/internal/handler/about"
+)
+
+// exportResume implements the export-resume command, which writes the
+// resume served at /resume.pdf, or its JSON Resume or vCard, to a file.
+func exportResume(cfg portfolio.Config, args []string) error {
+	flags := flag.NewFlagSet("export-resume", flag.ExitOnError)
+	format := flags.String("format", "pdf", "pdf, json (JSON Resume) or vcard")
+	out := flags.String("o", "", "file to write, - for standard output (default the name /resume.pdf downloads as)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: portfolio export-resume [-format pdf|json|vcard] [-o file]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	h, err := handler.New(site, handler.Options{BaseURL: cfg.BaseURL})
+	if err != nil {
+		return err
+	}
+	base := cmp.Or(cfg.BaseURL, "http://localhost:"+cfg.Port)
+	if cfg.BaseURL == "" && *format != "pdf" {
+		log.Printf("BASE_URL is not set; links point at %s", base)
+	}
+	name, body, err := about.ResumeFile(h.Data(), base, *format)
+	if err != nil {
+		return fmt.Errorf("export-resume: %w", err)
+	}
+	if *out == "-" {
+		_, err = os.Stdout.Write(body)
+		return err
+	}
+	name = cmp.Or(*out, name)
+	if err := os.WriteFile(name, body, 0o644); err != nil {
+		return fmt.Errorf("write resume: %w", err)
+	}
+	log.Printf("wrote %s", name)
+	return nil
+}
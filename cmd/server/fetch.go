@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	portfolio "github.com/fpatron/portfolio"
+)
+
+// fetchData implements the fetch command, which writes repositories, books
+// and talks from their external sources to the data directory, so they are
+// embedded in the next build.
+func fetchData(cfg portfolio.Config, args []string) error {
+	fs := flag.NewFlagSet("fetch", flag.ExitOnError)
+	dir := fs.String("o", filepath.Join(siteDir, "data"), "directory to write repos.json, books.json and talks.json to")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: portfolio fetch [-o data]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	written, err := portfolio.Fetch(ctx, cfg, *dir)
+	if len(written) > 0 {
+		log.Printf("wrote %s to %s", strings.Join(written, ", "), *dir)
+	} else if err == nil {
+		log.Print("fetch: nothing to fetch; set GITHUB_USER, REPO_ACCOUNTS, OPENLIBRARY_USER or TALKS_SHEET")
+	}
+	return err
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	portfolio "github.com/fpatron/portfolio"
+	"github.com/fpatron/portfolio/internal/handler"
+	"github.com/fpatron/portfolio/internal/images"
+)
+
+// lintImages implements the lint-images command, which reports the static
+// images that are too large or in the wrong format for their use, and
+// those served at full size without a scaled variant.
+func lintImages(cfg portfolio.Config, args []string) error {
+	flags := flag.NewFlagSet("lint-images", flag.ExitOnError)
+	l := images.DefaultLimits
+	flags.Int64Var(&l.Bytes, "max-bytes", l.Bytes, "largest image file, in bytes")
+	flags.IntVar(&l.Width, "max-width", l.Width, "largest width or height of a photo or other image, in pixels")
+	flags.IntVar(&l.IconWidth, "max-icon-width", l.IconWidth, "largest width or height of a logo or icon, in pixels")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: portfolio lint-images [-max-bytes 204800] [-max-width 1600] [-max-icon-width 256]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	h, err := handler.New(site, handler.Options{BaseURL: cfg.BaseURL})
+	if err != nil {
+		return err
+	}
+	problems, err := h.LintImages(l)
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("lint-images: %d problems found", len(problems))
+	}
+	fmt.Println("no image problems found")
+	return nil
+}
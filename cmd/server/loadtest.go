@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	portfolio "github.com/fpatron/portfolio"
+	"github.com/fpatron/portfolio/internal/handler"
+)
+
+// loadTest implements the loadtest command, which sends requests to a
+// running site at a fixed rate, cycling through its main routes, and
+// reports the latency percentiles and error rate of each.
+func loadTest(cfg portfolio.Config, args []string) error {
+	flags := flag.NewFlagSet("loadtest", flag.ExitOnError)
+	target := flags.String("target", "", "URL of the site to load, e.g. https://example.com")
+	rate := flags.Int("rate", 200, "requests per second")
+	duration := flags.Duration("duration", 30*time.Second, "how long to send requests")
+	paths := flags.String("paths", "", "comma-separated paths to request in turn (default: the home page, its partials, the project pages and the API)")
+	concurrency := flags.Int("concurrency", 100, "requests in flight at most; requests due while all are busy are dropped")
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout of each request")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: portfolio loadtest -target URL [-rate 200] [-duration 30s] [-paths /,/api/projects]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if *target == "" {
+		flags.Usage()
+		return errors.New("loadtest: -target is required")
+	}
+	if *rate < 1 || *concurrency < 1 {
+		return errors.New("loadtest: -rate and -concurrency must be positive")
+	}
+
+	routes, err := loadRoutes(cfg, *paths)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+
+	l := &loader{
+		base:        strings.TrimSuffix(*target, "/"),
+		client:      &http.Client{Timeout: *timeout, Transport: &http.Transport{MaxIdleConnsPerHost: *concurrency}},
+		concurrency: *concurrency,
+		stats:       make(map[string]*routeStats),
+	}
+	fmt.Printf("sending %d requests/s to %s for %s over %d routes\n", *rate, l.base, *duration, len(routes))
+	elapsed := l.run(ctx, routes, *rate)
+	l.report(os.Stdout, routes, elapsed)
+	return nil
+}
+
+// loadRoutes returns the paths of -paths or, when it is empty, the site's
+// main routes.
+func loadRoutes(cfg portfolio.Config, paths string) ([]string, error) {
+	if paths != "" {
+		var routes []string
+		for p := range strings.SplitSeq(paths, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				routes = append(routes, "/"+strings.TrimPrefix(p, "/"))
+			}
+		}
+		return routes, nil
+	}
+	h, err := handler.New(site, handler.Options{BaseURL: cfg.BaseURL})
+	if err != nil {
+		return nil, err
+	}
+	routes := []string{"/"}
+	for _, s := range h.Sections() {
+		if s.Partial != "" {
+			routes = append(routes, s.Partial)
+		}
+	}
+	for _, p := range h.Data().Projects {
+		routes = append(routes, "/projects/"+p.Slug)
+	}
+	return append(routes, "/api/projects", "/api/experience", "/sitemap.xml"), nil
+}
+
+// loader sends the requests and collects their results.
+type loader struct {
+	base        string
+	client      *http.Client
+	concurrency int
+
+	mu      sync.Mutex
+	stats   map[string]*routeStats
+	dropped int
+}
+
+// routeStats are the results of a route's requests.
+type routeStats struct {
+	latencies []time.Duration // of the requests that got a response
+	errors    int             // failed requests and error statuses
+	requests  int
+}
+
+// run sends rate requests per second, spread evenly and cycling through
+// routes, until ctx is done, and returns how long it ran.
+func (l *loader) run(ctx context.Context, routes []string, rate int) time.Duration {
+	start := time.Now()
+	tick := time.NewTicker(time.Second / time.Duration(rate))
+	defer tick.Stop()
+	sem := make(chan struct{}, l.concurrency)
+	var wg sync.WaitGroup
+	for i := 0; ; i++ {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return time.Since(start)
+		case <-tick.C:
+		}
+		select {
+		case sem <- struct{}{}:
+		default:
+			l.mu.Lock()
+			l.dropped++
+			l.mu.Unlock()
+			continue
+		}
+		path := routes[i%len(routes)]
+		wg.Go(func() {
+			defer func() { <-sem }()
+			l.request(path)
+		})
+	}
+}
+
+// request gets path and records the outcome. The body is read, so the
+// latency covers the whole response.
+func (l *loader) request(path string) {
+	start := time.Now()
+	resp, err := l.client.Get(l.base + path)
+	failed := err != nil
+	if err == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		failed = err != nil || resp.StatusCode >= 400
+	}
+	d := time.Since(start)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s := l.stats[path]
+	if s == nil {
+		s = new(routeStats)
+		l.stats[path] = s
+	}
+	s.requests++
+	if failed {
+		s.errors++
+	}
+	if resp != nil {
+		s.latencies = append(s.latencies, d)
+	}
+}
+
+// report prints a table of the results per route and in total.
+func (l *loader) report(w io.Writer, routes []string, elapsed time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "route\trequests\terrors\tp50\tp90\tp99\tmax\t")
+	var total routeStats
+	for _, r := range routes {
+		s := l.stats[r]
+		if s == nil {
+			continue
+		}
+		printStats(tw, r, s)
+		total.requests += s.requests
+		total.errors += s.errors
+		total.latencies = append(total.latencies, s.latencies...)
+	}
+	printStats(tw, "total", &total)
+	tw.Flush()
+	fmt.Fprintf(w, "%.1f requests/s over %s", float64(total.requests)/elapsed.Seconds(), elapsed.Round(time.Millisecond))
+	if l.dropped > 0 {
+		fmt.Fprintf(w, ", %d requests dropped with %d in flight", l.dropped, l.concurrency)
+	}
+	fmt.Fprintln(w)
+}
+
+func printStats(w io.Writer, route string, s *routeStats) {
+	slices.Sort(s.latencies)
+	errRate := 100 * float64(s.errors) / float64(max(s.requests, 1))
+	fmt.Fprintf(w, "%s\t%d\t%d (%.1f%%)\t%s\t%s\t%s\t%s\t\n", route, s.requests, s.errors, errRate,
+		percentile(s.latencies, 0.5), percentile(s.latencies, 0.9), percentile(s.latencies, 0.99), percentile(s.latencies, 1))
+}
+
+// percentile returns the p-th percentile of the sorted latencies.
+func percentile(sorted []time.Duration, p float64) string {
+	if len(sorted) == 0 {
+		return "-"
+	}
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)].Round(100 * time.Microsecond).String()
+}
@@ -1,89 +1,142 @@
+// Command portfolio serves the portfolio site and provides tools to
+// export, check and import its content.
 package main
 
 import (
-	"context"
+	"flag"
+	"fmt"
 	"io/fs"
 	"log"
-	"net/http"
+	"maps"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"slices"
 
 	portfolio "github.com/fpatron/portfolio"
-	"github.com/fpatron/portfolio/internal/handler"
+	"github.com/fpatron/portfolio/internal/config"
 )
 
-type responseWriter struct {
-	http.ResponseWriter
-	status int
+// command is a portfolio subcommand.
+type command struct {
+	name    string
+	summary string
+	run     func(cfg portfolio.Config, args []string) error
 }
 
-func (rw *responseWriter) WriteHeader(code int) {
-	rw.status = code
-	rw.ResponseWriter.WriteHeader(code)
+// site holds the templates, static files and data files the commands
+// use: the built-in ones, or those of siteDir when -root is set.
+var (
+	site    fs.FS = portfolio.FS
+	siteDir string
+)
+
+var commands = []command{
+	{"serve", "run the web server, and the gRPC server when GRPC_PORT is set (the default)", serve},
+	{"export", "write a static copy of the site", exportSite},
+	{"export-resume", "write the resume as PDF, JSON Resume or vCard", exportResume},
+	{"deploy", "export the site and publish it to S3, Netlify or GitHub Pages", deploySite},
+	{"validate", "check the templates and data files", validate},
+	{"validate-data", "check the data files only", validateData},
+	{"check-links", "report the dead links of the pages and data files", checkLinks},
+	{"audit-a11y", "report accessibility problems of the templates", auditA11y},
+	{"perf-budget", "report the bytes and requests of each page and fail over budget", perfBudget},
+	{"lint-images", "report static images that are too large, in the wrong format or without a scaled variant", lintImages},
+	{"loadtest", "send requests to a running site and report their latency", loadTest},
+	{"new", "add a project or a draft blog post", newContent},
+	{"crosspost", "publish the blog posts to dev.to and Medium", crossPost},
+	{"fetch", "write repositories, books and talks from their sources to the data files", fetchData},
+	{"import-books", "fill the bookshelf from Goodreads or Open Library", importBooks},
+	{"import-experience", "write experience and skills from LinkedIn or CSV", importExperience},
+	{"version", "print the version", printVersion},
 }
 
-func loggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
-		next.ServeHTTP(rw, r)
-		log.Printf("%s %s %d %s", r.Method, r.URL.Path, rw.status, time.Since(start))
-	})
+// aliases maps other names of commands to the commands they run.
+var aliases = map[string]string{
+	"generate": "export",
 }
 
-func main() {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "usage: portfolio [-config file] [-env file] [-set KEY=VALUE] [-root dir] <command> [flags]")
+	fmt.Fprintln(out, "\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %-18s %s\n", c.name, c.summary)
 	}
-
-	h, err := handler.New(portfolio.FS)
-	if err != nil {
-		log.Fatalf("failed to initialize handler: %v", err)
+	fmt.Fprintln(out, "\naliases:")
+	for _, alias := range slices.Sorted(maps.Keys(aliases)) {
+		fmt.Fprintf(out, "  %-18s %s\n", alias, aliases[alias])
 	}
+	fmt.Fprintln(out, "\nRun \"portfolio <command> -h\" for the flags of a command.")
+	fmt.Fprintln(out, "\nflags:")
+	flag.PrintDefaults()
+}
 
-	staticFS, err := fs.Sub(portfolio.FS, "static")
-	if err != nil {
-		log.Fatalf("failed to create static sub-FS: %v", err)
+// openRoot returns the site files of dir, which must have the layout of
+// the built-in ones.
+func openRoot(dir string) (fs.FS, error) {
+	fsys := os.DirFS(dir)
+	for _, sub := range []string{"templates", "static", "data"} {
+		info, err := fs.Stat(fsys, sub)
+		if err != nil {
+			return nil, fmt.Errorf("root %s: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("root %s: %s is not a directory", dir, sub)
+		}
 	}
+	return fsys, nil
+}
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("GET /", h.Index)
-	mux.HandleFunc("GET /partials/about", h.About)
-	mux.HandleFunc("GET /partials/projects", h.Projects)
-	mux.HandleFunc("GET /partials/interests", h.Interests)
-	mux.HandleFunc("POST /contact", h.Contact)
-	mux.HandleFunc("GET /health", h.Health)
-	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(staticFS)))
-
-	srv := &http.Server{
-		Addr:         ":" + port,
-		Handler:      loggingMiddleware(mux),
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  60 * time.Second,
+func main() {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML file to read settings from; the environment and flags take precedence")
+	envFile := flag.String("env", "", "file of KEY=VALUE lines to read settings from; the environment takes precedence")
+	overrides := config.Settings{}
+	flag.Var(overrides, "set", "`KEY=VALUE` setting, taking precedence over the environment and -config; repeatable")
+	flag.Func("port", "HTTP `port`, as PORT", func(v string) error { return overrides.Set("PORT=" + v) })
+	flag.Func("base-url", "public origin of the site, as BASE_URL, e.g. https://example.com", func(v string) error { return overrides.Set("BASE_URL=" + v) })
+	root := flag.String("root", "", "directory holding templates/, static/ and data/ to use instead of the built-in files")
+	flag.Usage = usage
+	flag.Parse()
+	if *envFile != "" {
+		if err := loadEnvFile(*envFile); err != nil {
+			log.Fatalf("failed to load settings: %v", err)
+		}
 	}
-
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
-
-	go func() {
-		log.Printf("server listening on :%s", port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("server error: %v", err)
+	var file config.Settings
+	if *configFile != "" {
+		var err error
+		if file, err = config.Load(*configFile); err != nil {
+			log.Fatalf("failed to load settings: %v", err)
 		}
-	}()
-
-	<-stop
-	log.Println("shutting down...")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	}
+	cfg := portfolio.ConfigFrom(config.Getenv(file, overrides))
+	if *root != "" {
+		fsys, err := openRoot(*root)
+		if err != nil {
+			log.Fatal(err)
+		}
+		site, siteDir = fsys, *root
+	}
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("shutdown error: %v", err)
+	name, args := "serve", flag.Args()
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		usage()
+		return
+	}
+	if cmd, ok := aliases[name]; ok {
+		name = cmd
+	}
+	for _, c := range commands {
+		if c.name == name {
+			if err := c.run(cfg, args); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
 	}
-	log.Println("server stopped")
+	fmt.Fprintf(os.Stderr, "portfolio: unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
 }
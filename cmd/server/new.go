@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	portfolio "github.com/fpatron/portfolio"
+	"github.com/fpatron/portfolio/internal/blog"
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/handler"
+)
+
+// newContent implements the new command, which scaffolds content files.
+func newContent(_ portfolio.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: portfolio new project|post [flags] \"Title\"")
+	}
+	switch args[0] {
+	case "project":
+		return newProject(args[1:])
+	case "post":
+		return newPost(args[1:])
+	default:
+		return fmt.Errorf("new: unknown content type %q, want project or post", args[0])
+	}
+}
+
+// projectEntry is a projects.json entry with every curated field present,
+// so the new entry shows what can be filled in.
+type projectEntry struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Link        string   `json:"link"`
+	Image       string   `json:"image"`
+	Package     string   `json:"package"`
+}
+
+// newProject appends a project to data/projects.json after checking that
+// the file stays valid.
+func newProject(args []string) error {
+	fs := flag.NewFlagSet("new project", flag.ExitOnError)
+	description := fs.String("description", "", "one-paragraph description")
+	tags := fs.String("tags", "", "comma-separated tags")
+	link := fs.String("link", "", "project or repository URL")
+	image := fs.String("image", "", "site path or remote URL of an image")
+	pkg := fs.String("package", "", "published package, go:<module> or npm:<name>")
+	dir := fs.String("data", filepath.Join(siteDir, "data"), "directory holding projects.json")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: portfolio new project [flags] \"Title\"")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	entry := projectEntry{
+		Title:       strings.TrimSpace(fs.Arg(0)),
+		Description: *description,
+		Tags:        []string{},
+		Link:        *link,
+		Image:       *image,
+		Package:     *pkg,
+	}
+	for _, t := range strings.Split(*tags, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			entry.Tags = append(entry.Tags, t)
+		}
+	}
+	if entry.Description == "" {
+		entry.Description = "TODO: describe " + entry.Title + "."
+	}
+
+	path := filepath.Join(*dir, "projects.json")
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var projects []content.Project
+	if err := json.Unmarshal(b, &projects); err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	projects = append(projects, content.Project{
+		Title:       entry.Title,
+		Description: entry.Description,
+		Tags:        entry.Tags,
+		Link:        entry.Link,
+		Image:       entry.Image,
+		Package:     entry.Package,
+	})
+	if err := handler.ValidateProjects(projects); err != nil {
+		return fmt.Errorf("invalid project:\n%w", err)
+	}
+
+	out, err := appendJSON(b, entry)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		return err
+	}
+	log.Printf("added %q to %s", entry.Title, path)
+	return nil
+}
+
+// newPost writes a draft post to content/posts, named after its title.
+func newPost(args []string) error {
+	fs := flag.NewFlagSet("new post", flag.ExitOnError)
+	tags := fs.String("tags", "", "comma-separated tags")
+	dir := fs.String("dir", filepath.Join(siteDir, blog.Dir), "directory holding the posts")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: portfolio new post [flags] \"Title\"")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	title := strings.TrimSpace(fs.Arg(0))
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+	var list []string
+	for _, t := range strings.Split(*tags, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			list = append(list, t)
+		}
+	}
+	src := fmt.Sprintf("---\ntitle: %s\ndate: %s\ntags: [%s]\ndraft: true\n---\n\nTODO: write the post.\n",
+		title, time.Now().Format(time.DateOnly), strings.Join(list, ", "))
+	name := strings.Join(words, "-")
+	if _, err := blog.Parse(name, []byte(src)); err != nil {
+		return fmt.Errorf("invalid post: %w", err)
+	}
+
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(*dir, name+".md")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(src); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Printf("added %q to %s as a draft", title, path)
+	return nil
+}
+
+// stringArray matches an indented JSON array of strings.
+var stringArray = regexp.MustCompile(`\[\s*"(?:[^"\\]|\\.)*"(?:,\s*"(?:[^"\\]|\\.)*")*\s*\]`)
+
+// appendJSON adds v to the JSON array in b, leaving the formatting of the
+// existing entries alone. String arrays stay on one line, like the
+// hand-written entries.
+func appendJSON(b []byte, v any) ([]byte, error) {
+	entry, err := json.MarshalIndent(v, "  ", "  ")
+	if err != nil {
+		return nil, err
+	}
+	entry = stringArray.ReplaceAllFunc(entry, func(m []byte) []byte {
+		var list []string
+		if json.Unmarshal(m, &list) != nil {
+			return m
+		}
+		items := make([]string, len(list))
+		for i, s := range list {
+			q, _ := json.Marshal(s)
+			items[i] = string(q)
+		}
+		return []byte("[" + strings.Join(items, ", ") + "]")
+	})
+	body := bytes.TrimRight(b, " \t\r\n")
+	if !bytes.HasSuffix(body, []byte("]")) {
+		return nil, errors.New("not a JSON array")
+	}
+	body = bytes.TrimRight(body[:len(body)-1], " \t\r\n")
+	var out bytes.Buffer
+	out.Write(body)
+	if !bytes.HasSuffix(body, []byte("[")) {
+		out.WriteByte(',')
+	}
+	out.WriteString("\n  ")
+	out.Write(entry)
+	out.WriteString("\n]\n")
+	return out.Bytes(), nil
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/net/html"
+
+	portfolio "github.com/fpatron/portfolio"
+	"github.com/fpatron/portfolio/internal/handler"
+)
+
+// assetKinds are the kinds of bytes a page is charged for, in report order.
+var assetKinds = []string{"html", "css", "js", "images"}
+
+// perfBudget implements the perf-budget command, which renders every page
+// with what it loads, sums the bytes of each kind and the requests, and
+// fails when a page is over budget.
+func perfBudget(cfg portfolio.Config, args []string) error {
+	flags := flag.NewFlagSet("perf-budget", flag.ExitOnError)
+	budget := map[string]*int{
+		"html":   flags.Int("html", 100, "budget of HTML, the page and its partials, in KB"),
+		"css":    flags.Int("css", 50, "budget of CSS, in KB"),
+		"js":     flags.Int("js", 100, "budget of JavaScript, in KB"),
+		"images": flags.Int("images", 300, "budget of images, in KB"),
+		"total":  flags.Int("total", 500, "budget of all bytes, in KB"),
+	}
+	maxRequests := flags.Int("requests", 25, "budget of requests")
+	download := flags.Bool("external", false, "download the external assets, such as scripts from a CDN, to count their bytes")
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout of each external request")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: portfolio perf-budget [-html 100] [-css 50] [-js 100] [-images 300] [-total 500] [-requests 25] [-external]")
+		fmt.Fprintln(flags.Output(), "Budgets are per page; 0 turns one off.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	h, err := handler.New(site, handler.Options{BaseURL: cfg.BaseURL})
+	if err != nil {
+		return err
+	}
+	staticFS, err := fs.Sub(site, "static")
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	h.PublicRoutes(mux)
+	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(staticFS)))
+
+	m := &pageMeter{
+		mux:  mux,
+		base: strings.TrimSuffix(cmp.Or(cfg.BaseURL, "http://example.com"), "/"),
+	}
+	if *download {
+		m.client = &http.Client{Timeout: *timeout}
+	}
+	routes := []string{"/"}
+	for _, p := range h.Data().Projects {
+		routes = append(routes, "/projects/"+p.Slug)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "route\trequests\thtml\tcss\tjs\timages\ttotal\tover budget")
+	over := 0
+	var notes []string
+	external := false
+	for _, route := range routes {
+		c := m.measure(route)
+		var problems []string
+		for _, kind := range append(assetKinds, "total") {
+			if limit := int64(*budget[kind]) << 10; limit > 0 && c.bytes[kind] > limit {
+				problems = append(problems, kind)
+			}
+		}
+		if *maxRequests > 0 && c.requests > *maxRequests {
+			problems = append(problems, "requests")
+		}
+		if len(problems) > 0 {
+			over++
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n", route, c.requests,
+			kb(c.bytes["html"]), kb(c.bytes["css"]), kb(c.bytes["js"]), kb(c.bytes["images"]), kb(c.bytes["total"]),
+			strings.Join(problems, ", "))
+		for _, u := range c.unmeasured {
+			notes = append(notes, fmt.Sprintf("%s: %s not counted", route, u))
+		}
+		external = external || c.external > 0
+	}
+	tw.Flush()
+	for _, n := range notes {
+		fmt.Println(n)
+	}
+	if external {
+		fmt.Println("external assets are counted as requests but not bytes; run with -external to download them")
+	}
+	if over > 0 {
+		return fmt.Errorf("perf-budget: %d of %d pages over budget", over, len(routes))
+	}
+	fmt.Printf("%d pages within budget\n", len(routes))
+	return nil
+}
+
+func kb(n int64) string {
+	return fmt.Sprintf("%.1f KB", float64(n)/1024)
+}
+
+// pageMeter measures what loading a page costs.
+type pageMeter struct {
+	mux    http.Handler
+	base   string
+	client *http.Client // for external assets; nil to leave them out
+}
+
+// pageCost is what a page and everything it loads weigh.
+type pageCost struct {
+	requests   int
+	bytes      map[string]int64 // by kind, and "total"
+	unmeasured []string         // assets that failed to load
+	external   int              // external assets not downloaded
+}
+
+// asset is a resource a page loads.
+type asset struct {
+	ref  string // site path or external URL
+	kind string
+}
+
+// measure loads route like a browser that scrolls through the page: the
+// HTML, its stylesheets, scripts and images, and the partials HTMX loads
+// on load or when they are revealed, with what those load in turn.
+func (m *pageMeter) measure(route string) pageCost {
+	c := pageCost{bytes: make(map[string]int64)}
+	queue := []asset{{route, "html"}}
+	seen := map[string]bool{route: true}
+	for len(queue) > 0 {
+		a := queue[0]
+		queue = queue[1:]
+		c.requests++
+		if !strings.HasPrefix(a.ref, "/") && m.client == nil {
+			c.external++
+			continue
+		}
+		body, err := m.fetch(a, a.ref != route)
+		if err != nil {
+			c.unmeasured = append(c.unmeasured, fmt.Sprintf("%s (%v)", a.ref, err))
+			continue
+		}
+		c.bytes[a.kind] += int64(len(body))
+		c.bytes["total"] += int64(len(body))
+		if a.kind != "html" {
+			continue
+		}
+		for _, ref := range pageAssets(body) {
+			ref.ref = m.local(ref.ref)
+			if ref.ref != "" && !seen[ref.ref] {
+				seen[ref.ref] = true
+				queue = append(queue, ref)
+			}
+		}
+	}
+	return c
+}
+
+// local returns ref as a site path when it points to the site, as an
+// absolute URL when it is external, and "" when it is neither.
+func (m *pageMeter) local(ref string) string {
+	switch {
+	case strings.HasPrefix(ref, "//"):
+		ref = "https:" + ref
+	case strings.HasPrefix(ref, "/"):
+		return ref
+	}
+	if rest, ok := strings.CutPrefix(ref, m.base); ok && (rest == "" || rest[0] == '/') {
+		return cmp.Or(rest, "/")
+	}
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		return ref
+	}
+	return ""
+}
+
+// fetch returns the body of a, from the site or, for external assets, over
+// the network.
+func (m *pageMeter) fetch(a asset, partial bool) ([]byte, error) {
+	if strings.HasPrefix(a.ref, "/") {
+		req := httptest.NewRequest(http.MethodGet, a.ref, nil)
+		if partial && a.kind == "html" {
+			req.Header.Set("HX-Request", "true")
+		}
+		rec := httptest.NewRecorder()
+		m.mux.ServeHTTP(rec, req)
+		if rec.Code >= 400 {
+			return nil, errors.New(strings.ToLower(http.StatusText(rec.Code)))
+		}
+		return rec.Body.Bytes(), nil
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, a.ref, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, errors.New(resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// pageAssets returns the stylesheets, scripts, images and eagerly loaded
+// partials an HTML document refers to.
+func pageAssets(b []byte) []asset {
+	var assets []asset
+	z := html.NewTokenizer(bytes.NewReader(b))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return assets
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		tok := z.Token()
+		attrs := make(map[string]string, len(tok.Attr))
+		for _, a := range tok.Attr {
+			attrs[a.Key] = a.Val
+		}
+		rel := " " + strings.ToLower(attrs["rel"]) + " "
+		switch {
+		case tok.Data == "link" && strings.Contains(rel, " stylesheet "):
+			assets = append(assets, asset{attrs["href"], "css"})
+		case tok.Data == "link" && strings.Contains(rel, " icon "):
+			assets = append(assets, asset{attrs["href"], "images"})
+		case tok.Data == "script" && attrs["src"] != "":
+			assets = append(assets, asset{attrs["src"], "js"})
+		case tok.Data == "img" && attrs["src"] != "":
+			assets = append(assets, asset{attrs["src"], "images"})
+		}
+		if get := attrs["hx-get"]; get != "" && eagerTrigger(attrs["hx-trigger"]) {
+			assets = append(assets, asset{get, "html"})
+		}
+	}
+}
+
+// eagerTrigger reports whether an hx-trigger loads its partial without the
+// visitor doing anything but scroll.
+func eagerTrigger(trigger string) bool {
+	for t := range strings.SplitSeq(trigger, ",") {
+		switch name, _, _ := strings.Cut(strings.TrimSpace(t), " "); name {
+		case "load", "revealed", "intersect":
+			return true
+		}
+	}
+	return false
+}
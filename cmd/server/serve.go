@@ -0,0 +1,73 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata" // BOOKING_TIMEZONE on images without zoneinfo
+
+	portfolio "github.com/fpatron/portfolio"
+)
+
+// serve implements the serve command, which runs the web server, and the
+// gRPC server when GRPC_PORT is set, until it receives SIGINT or SIGTERM.
+func serve(cfg portfolio.Config, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	dev := flags.Bool("dev", false, "serve templates, static files and data from -dir, and reload open pages when they change")
+	dir := flags.String("dir", cmp.Or(siteDir, "."), "site directory used with -dev")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: portfolio serve [-dev [-dir .]]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	opts := []portfolio.Option{portfolio.WithConfig(cfg), portfolio.WithFS(site)}
+	if *dev {
+		opts = append(opts, portfolio.WithDev(*dir))
+	}
+	srv, err := portfolio.New(opts...)
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			if err := srv.Reload(); err != nil {
+				log.Printf("reload failed: %v", err)
+			}
+		}
+	}()
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Start(context.Background()) }()
+
+	var serveErr error
+	select {
+	case <-stop:
+	case serveErr = <-errc:
+	}
+	log.Println("shutting down...")
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	if serveErr != nil {
+		return serveErr
+	}
+	log.Println("server stopped")
+	return nil
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+
+	portfolio "github.com/fpatron/portfolio"
+	"github.com/fpatron/portfolio/internal/books"
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/handler"
+	"github.com/fpatron/portfolio/internal/i18n"
+	"github.com/fpatron/portfolio/internal/jsonresume"
+	"github.com/fpatron/portfolio/internal/repos"
+	"github.com/fpatron/portfolio/internal/shortlinks"
+	"github.com/fpatron/portfolio/internal/sitefs"
+	"github.com/fpatron/portfolio/internal/talks"
+	"github.com/fpatron/portfolio/schemas"
+)
+
+// validate implements the validate command, which checks the templates and
+// data files the way the server would load them and reports every problem
+// found. It fails when there is at least one.
+func validate(_ portfolio.Config, args []string) error {
+	return checkSite("validate", args, true)
+}
+
+// validateData implements the validate-data command, which checks the data
+// files like validate without rendering the pages and templates.
+func validateData(_ portfolio.Config, args []string) error {
+	return checkSite("validate-data", args, false)
+}
+
+// checkSite runs the checks of the command name, those of the templates
+// too when templates is set.
+func checkSite(name string, args []string, templates bool) error {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	dataDir := flags.String("data-dir", "", "read the data files from this directory instead of the site's")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: portfolio %s [-data-dir data]\n", name)
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	fsys := site
+	if *dataDir != "" {
+		fsys = sitefs.FS{Base: site, Data: os.DirFS(*dataDir)}
+	}
+
+	var r report
+	// With a JSON Resume, the files it stands in for are optional.
+	_, err := fs.Stat(fsys, jsonresume.File)
+	hasResume := err == nil
+	if hasResume {
+		r.check(jsonresume.File, decodeResume(fsys, jsonresume.File))
+	}
+	for _, name := range slices.Sorted(maps.Keys(dataFiles)) {
+		err := validateFile(fsys, name, dataFiles[name]())
+		if hasResume && errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		r.check(name, err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(fetchedFiles)) {
+		if err := validateFile(fsys, name, fetchedFiles[name]()); !errors.Is(err, fs.ErrNotExist) {
+			r.check(name, err)
+		}
+	}
+	h, err := handler.New(fsys, handler.Options{})
+	if err != nil {
+		r.check("load", err)
+	} else {
+		data := h.Data()
+		r.check("data/projects.json", handler.ValidateProjects(data.Projects))
+		r.check("data/experience.json", handler.ValidateExperience(data.Experience))
+		r.check("data/skills.json", handler.ValidateSkills(data.Skills))
+		r.check("data/links.json", handler.ValidateShortLinks(data.ShortLinks))
+		r.check("assets", handler.ValidateAssets(data, fsys))
+		if templates {
+			r.check("render", renderPages(h))
+			r.check("templates", h.CheckTemplates())
+		}
+	}
+	if !templates {
+		// Without a handler, the languages' pages are not rendered.
+		h = nil
+	}
+	r.checkLanguages(fsys, h)
+
+	if len(r) > 0 {
+		for _, line := range r {
+			fmt.Println(line)
+		}
+		return fmt.Errorf("%s: %d problems found", name, len(r))
+	}
+	if templates {
+		fmt.Println("templates and data are valid")
+	} else {
+		fmt.Println("data is valid")
+	}
+	return nil
+}
+
+// report collects problems, one line each.
+type report []string
+
+// check adds each error joined in err, prefixed with where.
+func (r *report) check(where string, err error) {
+	if err == nil {
+		return
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			r.check(where, e)
+		}
+		return
+	}
+	*r = append(*r, where+": "+err.Error())
+}
+
+// checkLanguages checks the data files of each language under data/ and,
+// when the site loaded, renders its pages in that language.
+func (r *report) checkLanguages(fsys fs.FS, h *handler.Handler) {
+	entries, _ := fs.ReadDir(fsys, "data")
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		lang, dir := e.Name(), "data/"+e.Name()
+		lfs := i18n.FS(fsys, lang)
+		files := maps.Clone(dataFiles)
+		maps.Copy(files, fetchedFiles)
+		for _, name := range slices.Sorted(maps.Keys(files)) {
+			local := dir + "/" + strings.TrimPrefix(name, "data/")
+			if _, err := fs.Stat(fsys, local); err == nil {
+				r.check(local, validateFile(lfs, name, files[name]()))
+			}
+		}
+		if _, err := fs.Stat(fsys, dir+"/strings.json"); err == nil {
+			r.check(dir+"/strings.json", decodeStrict(fsys, dir+"/strings.json", new(i18n.Strings)))
+		}
+		if h == nil {
+			continue
+		}
+		hl, err := h.Locale(lfs, lang)
+		if err != nil {
+			r.check(dir, err)
+			continue
+		}
+		r.check(dir+" render", renderPages(hl))
+	}
+}
+
+// dataFiles maps each data file to the type it is loaded into.
+var dataFiles = map[string]func() any{
+	"data/about.json":      func() any { return new(content.About) },
+	"data/projects.json":   func() any { return new([]content.Project) },
+	"data/interests.json":  func() any { return new([]content.Interest) },
+	"data/skills.json":     func() any { return new([]content.SkillCategory) },
+	"data/experience.json": func() any { return new([]content.Experience) },
+}
+
+// fetchedFiles maps the optional data files, most of them written by the
+// fetch command, which are checked when present, to their types.
+var fetchedFiles = map[string]func() any{
+	"data/repos.json": func() any { return new([]repos.Repo) },
+	"data/books.json": func() any { return new([]books.Book) },
+	"data/talks.json": func() any { return new([]talks.Talk) },
+	"data/links.json": func() any { return new([]shortlinks.Link) },
+}
+
+// validateFile checks the data file name against its schema and, when it
+// conforms, decodes it into v with decodeStrict, which catches what the
+// schema does not describe.
+func validateFile(fsys fs.FS, name string, v any) error {
+	if err := schemas.Validate(fsys, name); err != nil {
+		return err
+	}
+	return decodeStrict(fsys, name, v)
+}
+
+// decodeResume checks that the file name is a JSON Resume. Unlike the
+// other files, it may have sections the site does not read.
+func decodeResume(fsys fs.FS, name string) error {
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	var res jsonresume.Resume
+	if err := json.Unmarshal(b, &res); err != nil {
+		return err
+	}
+	if res.Basics.Name == "" {
+		return errors.New("basics.name is empty")
+	}
+	return nil
+}
+
+// decodeStrict decodes the file name into v, rejecting fields the site does
+// not know, which are usually misspelled ones, and trailing content.
+func decodeStrict(fsys fs.FS, name string, v any) error {
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected content after the JSON value")
+	}
+	return nil
+}
+
+// renderPages renders the home page, its sections and every project page,
+// which catches template errors that only show with the actual data.
+func renderPages(h *handler.Handler) error {
+	mux := http.NewServeMux()
+	h.PublicRoutes(mux)
+	paths := []string{"/"}
+	for _, s := range h.Sections() {
+		if s.Partial != "" {
+			paths = append(paths, s.Partial)
+		}
+	}
+	for _, p := range h.Data().Projects {
+		paths = append(paths, "/projects/"+p.Slug)
+	}
+	var errs []error
+	for _, p := range paths {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, p, nil))
+		// Sections fed by background jobs have no content here.
+		if rec.Code != http.StatusOK && rec.Code != http.StatusNoContent {
+			errs = append(errs, fmt.Errorf("GET %s: %d %s", p, rec.Code, strings.TrimSpace(rec.Body.String())))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"runtime/debug"
+
+	portfolio "github.com/fpatron/portfolio"
+)
+
+// version is set at build time with -ldflags "-X main.version=...". When
+// empty, the version comes from the build information.
+var version string
+
+// buildVersion describes the running binary: the release version or the
+// VCS revision it was built from.
+func buildVersion() string {
+	if version != "" {
+		return version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+	var revision, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+	if revision == "" {
+		return "devel"
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if modified == "true" {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// printVersion implements the version command.
+func printVersion(_ portfolio.Config, args []string) error {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	fs.Parse(args)
+	info, _ := debug.ReadBuildInfo()
+	goVersion := "unknown"
+	if info != nil {
+		goVersion = info.GoVersion
+	}
+	fmt.Printf("portfolio %s (%s)\n", buildVersion(), goVersion)
+	return nil
+}
@@ -0,0 +1,80 @@
+package portfolio
+
+import (
+	"cmp"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds the server's settings.
+type Config struct {
+	// Port is the HTTP port. When empty, Start does not listen and the
+	// site is only reachable through Handler.
+	Port string
+	// GRPCPort is the gRPC port. When empty, the gRPC server is not
+	// started.
+	GRPCPort     string
+	BaseURL      string
+	DatabasePath string
+	// Getenv looks up the settings of the optional integrations, such as
+	// GITHUB_USER or SPOTIFY_CLIENT_ID, by their environment variable
+	// names. It defaults to os.Getenv.
+	Getenv func(key string) string
+}
+
+// ConfigFromEnv reads the settings from the environment.
+func ConfigFromEnv() Config {
+	return ConfigFrom(os.Getenv)
+}
+
+// ConfigFrom reads the settings from getenv, which then also serves the
+// settings of the integrations.
+func ConfigFrom(getenv func(key string) string) Config {
+	return Config{
+		Port:         cmp.Or(getenv("PORT"), "8080"),
+		GRPCPort:     getenv("GRPC_PORT"),
+		BaseURL:      getenv("BASE_URL"),
+		DatabasePath: getenv("DATABASE_PATH"),
+		Getenv:       getenv,
+	}
+}
+
+// getenv returns the setting named key.
+func (c Config) getenv(key string) string {
+	if c.Getenv == nil {
+		return os.Getenv(key)
+	}
+	return c.Getenv(key)
+}
+
+// envInt returns the integer value of the setting key, or def when it is
+// unset or invalid.
+func (c Config) envInt(key string, def int) int {
+	v := c.getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("invalid %s %q, using %d", key, v, def)
+		return def
+	}
+	return n
+}
+
+// envDuration returns the duration value of the setting key, or def when
+// it is unset or invalid.
+func (c Config) envDuration(key string, def time.Duration) time.Duration {
+	v := c.getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("invalid %s %q, using %s", key, v, def)
+		return def
+	}
+	return d
+}
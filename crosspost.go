@@ -0,0 +1,91 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/blog"
+	"github.com/fpatron/portfolio/internal/crosspost"
+)
+
+// relativeLink matches site-relative Markdown and HTML links and images.
+var relativeLink = regexp.MustCompile(`(\]\(|(?:href|src)=")/([^/])`)
+
+// CrossPost publishes the posts in dir, the posts directory, to dev.to with
+// DEVTO_API_KEY and to Medium with MEDIUM_TOKEN, naming their page under
+// BASE_URL as the canonical URL. The URL of each copy is written back to
+// the post's front matter, as devto or medium, so drafts and posts that
+// already have a copy on a site are skipped. It returns what it published,
+// as slug: site entries.
+func CrossPost(ctx context.Context, c Config, dir string) ([]string, error) {
+	var targets []crosspost.Target
+	if key := c.getenv("DEVTO_API_KEY"); key != "" {
+		targets = append(targets, crosspost.NewDevTo(key))
+	}
+	if token := c.getenv("MEDIUM_TOKEN"); token != "" {
+		targets = append(targets, crosspost.NewMedium(token))
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	if c.BaseURL == "" {
+		return nil, errors.New("crosspost: BASE_URL is needed for the canonical URLs")
+	}
+	base := strings.TrimSuffix(c.BaseURL, "/")
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	var published []string
+	var errs []error
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return published, err
+		}
+		p, err := blog.Parse(strings.TrimSuffix(filepath.Base(file), ".md"), src)
+		if err != nil {
+			return published, fmt.Errorf("%s: %w", file, err)
+		}
+		if p.Draft {
+			continue
+		}
+		body, err := blog.Body(src)
+		if err != nil {
+			return published, fmt.Errorf("%s: %w", file, err)
+		}
+		article := crosspost.Article{
+			Title:        p.Title,
+			Summary:      p.Summary,
+			Tags:         p.Tags,
+			Markdown:     relativeLink.ReplaceAllString(string(body), "${1}"+base+"/${2}"),
+			CanonicalURL: base + "/blog/" + p.Slug,
+		}
+		copies := map[string]string{"devto": p.DevTo, "medium": p.Medium}
+		for _, t := range targets {
+			if copies[t.Name()] != "" {
+				continue
+			}
+			u, err := t.Publish(ctx, article)
+			if err == nil {
+				// Record the copy at once, so a later failure doesn't
+				// publish it twice on the next run.
+				if src, err = blog.SetField(src, t.Name(), u); err == nil {
+					err = os.WriteFile(file, src, 0o644)
+				}
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("crosspost %s to %s: %w", p.Slug, t.Name(), err))
+				continue
+			}
+			published = append(published, p.Slug+": "+t.Name())
+		}
+	}
+	return published, errors.Join(errs...)
+}
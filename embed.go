@@ -2,5 +2,8 @@ package portfolio
 
 import "embed"
 
-//go:embed templates static data
+// Refresh the fetched data files before a release with "go generate".
+//go:generate go run ./cmd/server fetch -o data
+
+//go:embed templates static data content
 var FS embed.FS
@@ -0,0 +1,91 @@
+package portfolio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/books"
+	"github.com/fpatron/portfolio/internal/github"
+	"github.com/fpatron/portfolio/internal/repos"
+	"github.com/fpatron/portfolio/internal/talks"
+)
+
+// Fetch writes the external content configured in c to dir, the data
+// directory, so it can be embedded at build time instead of synced at
+// runtime: the repositories of GITHUB_USER and REPO_ACCOUNTS to
+// repos.json, the Open Library reading log of OPENLIBRARY_USER to
+// books.json and the talks in the TALKS_SHEET spreadsheet to talks.json.
+// A source that fails leaves its file as it was; the others are still
+// written. It returns the names of the files written, none when no source
+// is configured.
+func Fetch(ctx context.Context, c Config, dir string) ([]string, error) {
+	var sources []fetchSource
+	var gh *github.Client
+	if user := c.getenv("GITHUB_USER"); user != "" {
+		gh = github.NewClient(user, c.getenv("GITHUB_TOKEN"))
+	}
+	providers, err := repoProviders(c, c.getenv("REPO_ACCOUNTS"), gh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REPO_ACCOUNTS: %w", err)
+	}
+	if len(providers) > 0 {
+		syncer := repos.NewSyncer(c.envInt("REPO_MIN_STARS", 1), providers...)
+		sources = append(sources, fetchSource{"repos.json", func(ctx context.Context) (any, error) {
+			// Unlike the runtime sync, a partial result would drop the
+			// failed provider's repositories from the file.
+			return syncer.Sync(ctx)
+		}})
+	}
+	if user := c.getenv("OPENLIBRARY_USER"); user != "" {
+		sources = append(sources, fetchSource{"books.json", func(ctx context.Context) (any, error) {
+			return books.NewOpenLibrary(user).Books(ctx)
+		}})
+	}
+	if sheet := c.getenv("TALKS_SHEET"); sheet != "" {
+		client := &http.Client{Timeout: 30 * time.Second}
+		sources = append(sources, fetchSource{"talks.json", func(ctx context.Context) (any, error) {
+			return talks.Fetch(ctx, client, sheet)
+		}})
+	}
+
+	var written []string
+	var errs []error
+	for _, s := range sources {
+		v, err := s.fetch(ctx)
+		if err == nil {
+			err = writeDataFile(filepath.Join(dir, s.file), v)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fetch %s: %w", s.file, err))
+			continue
+		}
+		written = append(written, s.file)
+	}
+	return written, errors.Join(errs...)
+}
+
+// fetchSource produces the contents of one data file.
+type fetchSource struct {
+	file  string
+	fetch func(context.Context) (any, error)
+}
+
+// writeDataFile writes v as indented JSON, replacing path only once the
+// new contents are complete.
+func writeDataFile(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
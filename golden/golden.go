@@ -0,0 +1,185 @@
+// Package golden compares the pages of the site with golden files, so a
+// change to the templates shows up as a diff of their HTML in review. Call
+// Check from a test, with the site's templates over fixed data files so the
+// golden files only change with the templates, and run the test with
+// -update to write the golden files after an intended change:
+//
+//	func TestPages(t *testing.T) {
+//		golden.Check(t, golden.Site(portfolio.FS, "testdata/site"), "testdata/golden")
+//	}
+package golden
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/handler"
+	"github.com/fpatron/portfolio/internal/workhours"
+)
+
+var update = flag.Bool("update", false, "write the golden files instead of comparing with them")
+
+// BaseURL is the origin of the rendered pages.
+const BaseURL = "http://example.com"
+
+// Now is the time the pages are rendered at, a Wednesday morning within
+// Hours, so the local time of the contact section is the same on every run.
+var Now = time.Date(2026, time.January, 14, 9, 30, 0, 0, time.UTC)
+
+// Hours are the working hours the local time is shown against: 9:00 to
+// 17:00, Monday to Friday, an hour ahead of UTC.
+var Hours = &workhours.Hours{
+	Location: time.FixedZone("CET", 60*60),
+	Start:    9 * time.Hour,
+	End:      17 * time.Hour,
+	Days:     [7]bool{time.Monday: true, time.Tuesday: true, time.Wednesday: true, time.Thursday: true, time.Friday: true},
+	Response: "a few hours",
+}
+
+// options are the handler options the pages are rendered with.
+func options() handler.Options {
+	return handler.Options{BaseURL: BaseURL, Hours: Hours, Now: func() time.Time { return Now }}
+}
+
+// Site returns the templates and static files of base with the data files
+// and blog posts of dir, its data/ and content/ directories, so golden
+// files don't change with the site's content.
+func Site(base fs.FS, dir string) fs.FS {
+	return site{base: base, fixed: os.DirFS(dir)}
+}
+
+type site struct {
+	base, fixed fs.FS
+}
+
+func (s site) Open(name string) (fs.File, error) {
+	if s.isFixed(name) {
+		return s.fixed.Open(name)
+	}
+	return s.base.Open(name)
+}
+
+func (s site) isFixed(name string) bool {
+	for _, dir := range []string{"data", "content"} {
+		if name == dir || strings.HasPrefix(name, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Routes returns the paths Check renders for fsys: the home page, the
+// partials of its sections, the local time and every project and blog post
+// page.
+func Routes(fsys fs.FS) ([]string, error) {
+	h, err := handler.New(fsys, options())
+	if err != nil {
+		return nil, err
+	}
+	return routes(h), nil
+}
+
+func routes(h *handler.Handler) []string {
+	paths := []string{"/"}
+	for _, s := range h.Sections() {
+		if s.Partial != "" {
+			paths = append(paths, s.Partial)
+		}
+		if s.Name == "contact" {
+			paths = append(paths, "/partials/localtime")
+		}
+	}
+	for _, p := range h.Data().Projects {
+		paths = append(paths, "/projects/"+p.Slug)
+	}
+	for _, p := range h.Data().Posts {
+		paths = append(paths, "/blog/"+p.Slug)
+	}
+	return paths
+}
+
+// Check renders the site of fsys, its templates and data files, at Now, and
+// compares each route with its golden file in dir, in a subtest per route.
+// Routes with nothing to show, such as sections fed by integrations, are
+// skipped.
+func Check(t *testing.T, fsys fs.FS, dir string) {
+	t.Helper()
+	h, err := handler.New(fsys, options())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	h.PublicRoutes(mux)
+	for _, p := range routes(h) {
+		t.Run(p, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, p, nil))
+			switch rec.Code {
+			case http.StatusNoContent:
+				t.Skip("no content")
+			case http.StatusOK:
+			default:
+				t.Fatalf("GET %s: %d %s", p, rec.Code, strings.TrimSpace(rec.Body.String()))
+			}
+			Compare(t, filepath.Join(dir, File(p)), rec.Body.Bytes())
+		})
+	}
+}
+
+// File returns the name of the golden file of path.
+func File(path string) string {
+	name := strings.Trim(path, "/")
+	if name == "" {
+		name = "index"
+	}
+	return strings.ReplaceAll(name, "/", "_") + ".html"
+}
+
+// Compare fails t when got differs from the golden file, showing the first
+// line that differs. With -update, it writes got to the file instead.
+func Compare(t testing.TB, file string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (run with -update to accept it):\n%s", file, firstDiff(want, got))
+	}
+}
+
+// firstDiff describes the first line that differs between want and got.
+func firstDiff(want, got []byte) string {
+	w, g := strings.Split(string(want), "\n"), strings.Split(string(got), "\n")
+	for i := range max(len(w), len(g)) {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl || i >= len(w) || i >= len(g) {
+			return fmt.Sprintf("line %d:\n-%s\n+%s", i+1, wl, gl)
+		}
+	}
+	return "line endings differ"
+}
@@ -0,0 +1,14 @@
+package golden_test
+
+import (
+	"testing"
+
+	portfolio "github.com/fpatron/portfolio"
+	"github.com/fpatron/portfolio/golden"
+)
+
+// TestPages compares the pages of the built-in templates, rendered with
+// the data files and posts of testdata/site, with testdata/golden.
+func TestPages(t *testing.T) {
+	golden.Check(t, golden.Site(portfolio.FS, "testdata/site"), "testdata/golden")
+}
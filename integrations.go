@@ -0,0 +1,262 @@
+package portfolio
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/activitypub"
+	"github.com/fpatron/portfolio/internal/blog"
+	"github.com/fpatron/portfolio/internal/booking"
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/github"
+	"github.com/fpatron/portfolio/internal/handler"
+	"github.com/fpatron/portfolio/internal/newsletter"
+	"github.com/fpatron/portfolio/internal/notify"
+	"github.com/fpatron/portfolio/internal/nowplaying"
+	"github.com/fpatron/portfolio/internal/repos"
+	"github.com/fpatron/portfolio/internal/sse"
+	"github.com/fpatron/portfolio/internal/uptime"
+	"github.com/fpatron/portfolio/internal/webmention"
+)
+
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rw *responseWriter) WriteHeader(code int) {
+	rw.status = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer's Flush.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// loggingMiddleware logs each request to logger.
+func loggingMiddleware(logger *log.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+		logger.Printf("%s %s %d %s", r.Method, r.URL.Path, rw.status, time.Since(start))
+	})
+}
+
+// alertMiddleware alerts the site owner about responses with a server error
+// status.
+func alertMiddleware(alerts *notify.Alerts, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+		if rw.status >= 500 {
+			alerts.Alert(r.Method+" "+r.URL.Path, fmt.Sprintf("%s %s returned %d", r.Method, r.URL.Path, rw.status))
+		}
+	})
+}
+
+// publishAvailability pushes the rendered availability badge to the
+// "availability" SSE topic.
+func publishAvailability(h *handler.Handler, events *sse.Broker) {
+	frag, err := h.Fragment("availability", h.Data().About)
+	if err != nil {
+		log.Printf("availability event: %v", err)
+		return
+	}
+	events.Publish("availability", frag)
+}
+
+// publishViewers pushes the live viewer count to the viewers SSE topic
+// whenever a page opens or closes its stream.
+func publishViewers(h *handler.Handler, events *sse.Broker) {
+	events.Watch = func(topic string, n int) {
+		if topic != handler.ViewersTopic {
+			return
+		}
+		frag, err := h.Fragment("viewers", n)
+		if err != nil {
+			log.Printf("viewers event: %v", err)
+			return
+		}
+		events.Publish(handler.ViewersTopic, frag)
+	}
+}
+
+// nowPlayingProvider returns the configured music provider, preferring
+// Spotify over Last.fm, or nil when neither is configured.
+func nowPlayingProvider(c Config) nowplaying.Provider {
+	id, secret, refresh := c.getenv("SPOTIFY_CLIENT_ID"), c.getenv("SPOTIFY_CLIENT_SECRET"), c.getenv("SPOTIFY_REFRESH_TOKEN")
+	if id != "" && secret != "" && refresh != "" {
+		return nowplaying.NewSpotify(id, secret, refresh)
+	}
+	if user, key := c.getenv("LASTFM_USER"), c.getenv("LASTFM_API_KEY"); user != "" && key != "" {
+		return nowplaying.NewLastFM(user, key)
+	}
+	return nil
+}
+
+// bookingProvider returns the Cal.com or Calendly provider configured in
+// the settings, or nil.
+func bookingProvider(c Config) booking.Provider {
+	if user, event := c.getenv("CALCOM_USERNAME"), c.getenv("CALCOM_EVENT"); user != "" && event != "" {
+		return booking.NewCalCom(user, event)
+	}
+	if token, event := c.getenv("CALENDLY_TOKEN"), c.getenv("CALENDLY_EVENT_TYPE"); token != "" && event != "" {
+		return booking.NewCalendly(token, event)
+	}
+	return nil
+}
+
+// uptimeMonitor returns the UptimeRobot or healthchecks.io monitor
+// configured in the settings, or nil.
+func uptimeMonitor(c Config) uptime.Monitor {
+	if key := c.getenv("UPTIMEROBOT_API_KEY"); key != "" {
+		return uptime.NewUptimeRobot(key, c.getenv("UPTIMEROBOT_MONITOR_ID"))
+	}
+	if key, check := c.getenv("HEALTHCHECKS_API_KEY"), c.getenv("HEALTHCHECKS_CHECK"); key != "" && check != "" {
+		return uptime.NewHealthchecks(key, check, c.getenv("HEALTHCHECKS_URL"))
+	}
+	return nil
+}
+
+// newsletterProvider returns the provider selected by NEWSLETTER_PROVIDER,
+// or nil when none is.
+func newsletterProvider(c Config) (newsletter.Provider, error) {
+	key, list := c.getenv("NEWSLETTER_API_KEY"), c.getenv("NEWSLETTER_LIST")
+	switch provider := c.getenv("NEWSLETTER_PROVIDER"); provider {
+	case "":
+		return nil, nil
+	case "buttondown":
+		return newsletter.NewButtondown(key), nil
+	case "mailchimp":
+		return newsletter.NewMailchimp(key, list)
+	case "listmonk":
+		return newsletter.NewListmonk(c.getenv("NEWSLETTER_URL"), c.getenv("NEWSLETTER_API_USER"), key, list)
+	default:
+		return nil, fmt.Errorf("unknown newsletter provider %q", provider)
+	}
+}
+
+// repoProviders builds the repository sync providers from spec, a
+// comma-separated list of provider:user[@origin] accounts such as
+// "gitlab:fpatron,codeberg:fpatron,gitea:fpatron@git.example.com". The
+// GITHUB_USER client, when configured, is always included.
+func repoProviders(c Config, spec string, gh *github.Client) ([]repos.Provider, error) {
+	var providers []repos.Provider
+	if gh != nil {
+		providers = append(providers, gh)
+	}
+	for _, account := range strings.Split(spec, ",") {
+		account = strings.TrimSpace(account)
+		if account == "" {
+			continue
+		}
+		kind, user, ok := strings.Cut(account, ":")
+		if !ok || user == "" {
+			return nil, fmt.Errorf("account %q: want provider:user", account)
+		}
+		user, origin, _ := strings.Cut(user, "@")
+		if origin != "" && !strings.Contains(origin, "://") {
+			origin = "https://" + origin
+		}
+		origin = strings.TrimSuffix(origin, "/")
+		switch kind {
+		case "github":
+			if gh != nil && strings.EqualFold(user, gh.User) {
+				continue
+			}
+			c := github.NewClient(user, c.getenv("GITHUB_TOKEN"))
+			if origin != "" {
+				c.API = origin
+			}
+			providers = append(providers, c)
+		case "gitlab":
+			providers = append(providers, repos.NewGitLab(user, c.getenv("GITLAB_TOKEN"), cmp.Or(origin, "https://gitlab.com")))
+		case "codeberg":
+			providers = append(providers, repos.NewGitea("codeberg", user, c.getenv("GITEA_TOKEN"), cmp.Or(origin, repos.CodebergAPI)))
+		case "gitea":
+			if origin == "" {
+				return nil, fmt.Errorf("account %q: gitea needs @origin", account)
+			}
+			providers = append(providers, repos.NewGitea("gitea", user, c.getenv("GITEA_TOKEN"), origin))
+		default:
+			return nil, fmt.Errorf("account %q: unknown provider %q", account, kind)
+		}
+	}
+	return providers, nil
+}
+
+// articles lists the blog posts and the curated projects for the
+// ActivityPub outbox. Synced repositories are left out so a sync doesn't
+// flood followers.
+func articles(data content.PageData, base string) []activitypub.Article {
+	var list []activitypub.Article
+	for _, p := range posts(data) {
+		list = append(list, activitypub.Article{
+			ID:      base + "/blog/" + p.Slug,
+			Title:   p.Title,
+			Summary: p.Summary,
+		})
+	}
+	for _, p := range data.Projects {
+		if p.Synced {
+			continue
+		}
+		list = append(list, activitypub.Article{
+			ID:      base + "/projects/" + p.Slug,
+			Title:   p.Title,
+			Summary: p.Description,
+		})
+	}
+	return list
+}
+
+// mentionPages lists the blog posts and the curated project pages with
+// their outbound links, for sending webmentions.
+func mentionPages(data content.PageData, base string) []webmention.Page {
+	var pages []webmention.Page
+	for _, p := range posts(data) {
+		u := base + "/blog/" + p.Slug
+		links := webmention.Links(u, string(p.HTML))
+		if len(links) == 0 {
+			continue
+		}
+		pages = append(pages, webmention.Page{
+			URL:     u,
+			Content: p.Title + "\n" + string(p.HTML),
+			Links:   links,
+		})
+	}
+	for _, p := range data.Projects {
+		if p.Synced || p.Link == "" {
+			continue
+		}
+		pages = append(pages, webmention.Page{
+			URL:     base + "/projects/" + p.Slug,
+			Content: p.Title + "\n" + p.Description,
+			Links:   []string{p.Link},
+		})
+	}
+	return pages
+}
+
+// posts returns the published posts, or none when the blog is disabled.
+func posts(data content.PageData) []blog.Post {
+	if !data.HasSection("blog") {
+		return nil
+	}
+	return data.Posts
+}
+
+// absoluteURL resolves a site-relative path against base.
+func absoluteURL(base, path string) string {
+	if path == "" || strings.Contains(path, "://") {
+		return path
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(path, "/")
+}
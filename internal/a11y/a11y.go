@@ -0,0 +1,179 @@
+// Package a11y checks rendered HTML for the accessibility mistakes that
+// are easy to make in templates: images without alt text, form controls
+// without labels, skipped heading levels and links or buttons whose text
+// says nothing about where they lead.
+package a11y
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// vagueText is link text that only makes sense next to what surrounds it,
+// which screen reader users listing the links of a page do not hear.
+var vagueText = []string{"click here", "here", "read more", "more", "learn more", "link", "this", "details", "view", "go"}
+
+// Audit parses b, a full page when page is set or else a fragment of one,
+// and describes each problem found in it.
+func Audit(b []byte, page bool) ([]string, error) {
+	doc, err := html.Parse(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("parse html: %w", err)
+	}
+	a := auditor{labeled: make(map[string]bool)}
+	for n := range doc.Descendants() {
+		if n.DataAtom == atom.Label {
+			if id := attr(n, "for"); id != "" {
+				a.labeled[id] = true
+			}
+		}
+	}
+	for n := range doc.Descendants() {
+		a.node(n)
+	}
+	if page && a.h1 == 0 {
+		a.report("the page has no <h1>")
+	}
+	return a.problems, nil
+}
+
+type auditor struct {
+	labeled  map[string]bool // ids of the controls with a <label for>
+	level    int             // of the last heading
+	h1       int
+	problems []string
+}
+
+func (a *auditor) report(format string, args ...any) {
+	a.problems = append(a.problems, fmt.Sprintf(format, args...))
+}
+
+func (a *auditor) node(n *html.Node) {
+	if n.Type != html.ElementNode {
+		return
+	}
+	switch n.DataAtom {
+	case atom.Img:
+		if _, ok := lookup(n, "alt"); !ok && attr(n, "role") != "presentation" && attr(n, "aria-hidden") != "true" {
+			a.report("%s has no alt text; use alt=\"\" for a decorative image", describe(n))
+		}
+	case atom.Input, atom.Select, atom.Textarea:
+		switch attr(n, "type") {
+		case "hidden", "submit", "button", "reset":
+			return
+		case "image":
+			if attr(n, "alt") == "" {
+				a.report("%s has no alt text", describe(n))
+			}
+			return
+		}
+		if !a.hasLabel(n) {
+			a.report("%s has no label", describe(n))
+		}
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		if level == 1 {
+			if a.h1++; a.h1 == 2 {
+				a.report("more than one <h1>")
+			}
+		}
+		if a.level > 0 && level > a.level+1 {
+			a.report("<%s> %q follows an <h%d>, skipping a level", n.Data, text(n), a.level)
+		}
+		a.level = level
+	case atom.A, atom.Button:
+		if n.DataAtom == atom.A && attr(n, "href") == "" {
+			return
+		}
+		name := accessibleName(n)
+		switch {
+		case name == "":
+			a.report("%s has no text; add some or an aria-label", describe(n))
+		case slices.Contains(vagueText, strings.ToLower(strings.Trim(name, " .…→"))):
+			a.report("%s says %q, which does not tell where it leads", describe(n), name)
+		}
+	}
+}
+
+// hasLabel reports whether a form control has a label: a <label> around it
+// or pointing at it, an aria-label or aria-labelledby, or a title.
+func (a *auditor) hasLabel(n *html.Node) bool {
+	if id := attr(n, "id"); id != "" && a.labeled[id] {
+		return true
+	}
+	for _, k := range []string{"aria-label", "aria-labelledby", "title"} {
+		if strings.TrimSpace(attr(n, k)) != "" {
+			return true
+		}
+	}
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.DataAtom == atom.Label {
+			return true
+		}
+	}
+	return false
+}
+
+// accessibleName approximates the name assistive technology announces for
+// a link or button: its aria-label, or else its text and the alt text of
+// its images.
+func accessibleName(n *html.Node) string {
+	if l := strings.TrimSpace(attr(n, "aria-label")); l != "" {
+		return l
+	}
+	var sb strings.Builder
+	for d := range n.Descendants() {
+		switch {
+		case d.Type == html.TextNode:
+			sb.WriteString(d.Data)
+		case d.DataAtom == atom.Img:
+			sb.WriteString(" " + attr(d, "alt") + " ")
+		}
+	}
+	if name := strings.Join(strings.Fields(sb.String()), " "); name != "" {
+		return name
+	}
+	return strings.TrimSpace(attr(n, "title"))
+}
+
+// text returns the text under n with its spaces collapsed.
+func text(n *html.Node) string {
+	var sb strings.Builder
+	for d := range n.Descendants() {
+		if d.Type == html.TextNode {
+			sb.WriteString(d.Data)
+		}
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// describe identifies an element in a report by its tag and the attributes
+// that best tell it apart.
+func describe(n *html.Node) string {
+	s := "<" + n.Data
+	for _, k := range []string{"id", "name", "class", "href", "src"} {
+		if v, ok := lookup(n, k); ok {
+			return fmt.Sprintf("%s %s=%q>", s, k, v)
+		}
+	}
+	return s + ">"
+}
+
+func lookup(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func attr(n *html.Node, key string) string {
+	v, _ := lookup(n, key)
+	return v
+}
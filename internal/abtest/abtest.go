@@ -0,0 +1,93 @@
+// Package abtest splits visitors between the site and a variant of it, so
+// a new layout or wording can be compared in the analytics.
+package abtest
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+)
+
+// Cookie holds the variant a visitor was assigned.
+const Cookie = "variant"
+
+// The variants. A is the site as usual.
+const (
+	A = "a"
+	B = "b"
+)
+
+type variantKey struct{}
+
+// Variant returns the variant assigned to the request with ctx, or "" when
+// no test is running.
+func Variant(ctx context.Context) string {
+	v, _ := ctx.Value(variantKey{}).(string)
+	return v
+}
+
+// Split sends a share of the visitors to variant B.
+type Split struct {
+	percent int
+	b       *http.ServeMux
+}
+
+// New returns a Split that assigns percent of new visitors to B, whose
+// routes are served by b. Routes b does not have always use the site.
+func New(b *http.ServeMux, percent int) *Split {
+	return &Split{percent: min(max(percent, 0), 100), b: b}
+}
+
+// Assign is middleware that gives each visitor a variant, kept in a cookie
+// so it sticks across visits, and adds it to the request's context. A
+// ?variant=a or ?variant=b parameter switches the visitor's variant, to
+// review both.
+func (s *Split) Assign(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := r.URL.Query().Get(Cookie)
+		if v != A && v != B {
+			v = ""
+			if c, err := r.Cookie(Cookie); err == nil && (c.Value == A || c.Value == B) {
+				v = c.Value
+			}
+		}
+		if v == "" || r.URL.Query().Has(Cookie) {
+			if v == "" {
+				v = A
+				if rand.IntN(100) < s.percent {
+					v = B
+				}
+			}
+			http.SetCookie(w, &http.Cookie{
+				Name:     Cookie,
+				Value:    v,
+				Path:     "/",
+				MaxAge:   90 * 24 * 60 * 60,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), variantKey{}, v)))
+	})
+}
+
+// Route serves B's routes from the variant for visitors assigned to it,
+// and everything else from a. Responses that depend on the variant vary by
+// cookie, so shared caches keep them apart.
+func (s *Split) Route(a http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pattern := s.b.Handler(r)
+		// "GET /" is the 404 page of the paths it doesn't serve, which
+		// the site handles.
+		if pattern == "" || pattern == "GET /" {
+			a.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Cookie")
+		if Variant(r.Context()) == B {
+			s.b.ServeHTTP(w, r)
+			return
+		}
+		a.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,606 @@
+// Package activitypub makes the site a minimal ActivityPub actor that
+// fediverse users can follow: WebFinger discovery, an actor document, an
+// outbox of published articles, and an inbox that accepts follows. New
+// articles are delivered to followers' inboxes with signed requests.
+package activitypub
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"database/sql"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/db"
+	"github.com/fpatron/portfolio/internal/webmention"
+	"github.com/fpatron/portfolio/internal/worker"
+)
+
+// Routes served by Register, relative to the site root.
+const (
+	ActorPath     = "/ap/actor"
+	InboxPath     = "/ap/inbox"
+	OutboxPath    = "/ap/outbox"
+	FollowersPath = "/ap/followers"
+)
+
+const (
+	contentType = "application/activity+json"
+	public      = "https://www.w3.org/ns/activitystreams#Public"
+	asContext   = "https://www.w3.org/ns/activitystreams"
+	maxBody     = 1 << 20
+)
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS activitypub_keys (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		private_pem TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS activitypub_followers (
+		actor TEXT PRIMARY KEY,
+		inbox TEXT NOT NULL,
+		ts INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS activitypub_published (
+		id TEXT PRIMARY KEY,
+		ts INTEGER NOT NULL
+	)`,
+}
+
+// Article is an item published to the outbox.
+type Article struct {
+	ID      string // absolute URL of the article's page
+	Title   string
+	Summary string
+}
+
+// Config describes the actor.
+type Config struct {
+	// BaseURL is the site's absolute origin; it determines every ID and
+	// the WebFinger domain.
+	BaseURL string
+	// Username is the handle part of @username@domain.
+	Username string
+	Name     string
+	Summary  string
+	Icon     string // absolute URL of the avatar
+}
+
+// Server implements the actor's endpoints.
+type Server struct {
+	cfg    Config
+	domain string
+	db     *sql.DB
+	key    *rsa.PrivateKey
+	pubPEM string
+	pool   *worker.Pool
+	// HTTP fetches remote actors and delivers activities. The default
+	// client refuses to connect to loopback and private addresses, since
+	// actor and key URLs come from unauthenticated inbox requests.
+	HTTP *http.Client
+
+	mu       sync.RWMutex
+	articles []published
+
+	keysMu sync.Mutex
+	keys   map[string]signer
+}
+
+// signer is a verified remote key and the actor that owns it.
+type signer struct {
+	key   *rsa.PublicKey
+	owner string
+}
+
+type published struct {
+	Article
+	at time.Time
+}
+
+// New creates the tables if needed and loads, or on first use generates,
+// the actor's signing key. Activities are delivered on pool's workers.
+func New(ctx context.Context, database *sql.DB, cfg Config, pool *worker.Pool) (*Server, error) {
+	u, err := url.Parse(cfg.BaseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("activitypub: BaseURL must be absolute, got %q", cfg.BaseURL)
+	}
+	if cfg.Username == "" {
+		return nil, errors.New("activitypub: missing username")
+	}
+	if err := db.Migrate(ctx, database, schema...); err != nil {
+		return nil, fmt.Errorf("activitypub: %w", err)
+	}
+	key, err := loadKey(ctx, database)
+	if err != nil {
+		return nil, fmt.Errorf("activitypub: %w", err)
+	}
+	pubPEM, err := encodePublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("activitypub: %w", err)
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	return &Server{
+		cfg:    cfg,
+		domain: u.Host,
+		db:     database,
+		key:    key,
+		pubPEM: pubPEM,
+		pool:   pool,
+		HTTP:   webmention.PublicClient(),
+		keys:   make(map[string]signer),
+	}, nil
+}
+
+func loadKey(ctx context.Context, database *sql.DB) (*rsa.PrivateKey, error) {
+	var p string
+	err := database.QueryRowContext(ctx, `SELECT private_pem FROM activitypub_keys WHERE id = 1`).Scan(&p)
+	if errors.Is(err, sql.ErrNoRows) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		p = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+		if _, err := database.ExecContext(ctx, `INSERT INTO activitypub_keys (id, private_pem) VALUES (1, ?)`, p); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode([]byte(p))
+	if block == nil {
+		return nil, errors.New("stored key is not PEM")
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+func (s *Server) actorID() string     { return s.cfg.BaseURL + ActorPath }
+func (s *Server) followersID() string { return s.cfg.BaseURL + FollowersPath }
+func (s *Server) keyID() string       { return s.actorID() + "#main-key" }
+
+// Handle returns the actor's fediverse handle, e.g. "@me@example.com".
+func (s *Server) Handle() string {
+	return "@" + s.cfg.Username + "@" + s.domain
+}
+
+// Register mounts the WebFinger and actor routes on mux.
+func (s *Server) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /.well-known/webfinger", s.webfinger)
+	mux.HandleFunc("GET "+ActorPath, s.actor)
+	mux.HandleFunc("GET "+OutboxPath, s.outbox)
+	mux.HandleFunc("GET "+FollowersPath, s.followers)
+	mux.HandleFunc("POST "+InboxPath, s.inbox)
+}
+
+func writeActivity(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", contentType)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("activitypub: encode: %v", err)
+	}
+}
+
+func (s *Server) webfinger(w http.ResponseWriter, r *http.Request) {
+	resource := r.URL.Query().Get("resource")
+	if !strings.EqualFold(resource, "acct:"+s.cfg.Username+"@"+s.domain) && resource != s.actorID() {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/jrd+json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"subject": "acct:" + s.cfg.Username + "@" + s.domain,
+		"aliases": []string{s.actorID(), s.cfg.BaseURL + "/"},
+		"links": []map[string]string{
+			{"rel": "self", "type": contentType, "href": s.actorID()},
+			{"rel": "http://webfinger.net/rel/profile-page", "type": "text/html", "href": s.cfg.BaseURL + "/"},
+		},
+	})
+}
+
+func (s *Server) actor(w http.ResponseWriter, r *http.Request) {
+	doc := map[string]any{
+		"@context":          []string{asContext, "https://w3id.org/security/v1"},
+		"id":                s.actorID(),
+		"type":              "Person",
+		"preferredUsername": s.cfg.Username,
+		"name":              s.cfg.Name,
+		"summary":           s.cfg.Summary,
+		"url":               s.cfg.BaseURL + "/",
+		"inbox":             s.cfg.BaseURL + InboxPath,
+		"outbox":            s.cfg.BaseURL + OutboxPath,
+		"followers":         s.followersID(),
+		"discoverable":      true,
+		"publicKey": map[string]string{
+			"id":           s.keyID(),
+			"owner":        s.actorID(),
+			"publicKeyPem": s.pubPEM,
+		},
+	}
+	if s.cfg.Icon != "" {
+		doc["icon"] = map[string]string{"type": "Image", "url": s.cfg.Icon}
+	}
+	writeActivity(w, doc)
+}
+
+// create wraps a in a Create activity addressed to the public and to
+// followers.
+func (s *Server) create(a published) map[string]any {
+	at := a.at.UTC().Format(time.RFC3339)
+	content := "<p>" + html.EscapeString(a.Summary) + `</p><p><a href="` + html.EscapeString(a.ID) + `">` + html.EscapeString(a.ID) + "</a></p>"
+	return map[string]any{
+		"@context":  asContext,
+		"id":        a.ID + "#create",
+		"type":      "Create",
+		"actor":     s.actorID(),
+		"published": at,
+		"to":        []string{public},
+		"cc":        []string{s.followersID()},
+		"object": map[string]any{
+			"id":           a.ID,
+			"type":         "Article",
+			"name":         a.Title,
+			"summary":      a.Summary,
+			"content":      content,
+			"url":          a.ID,
+			"attributedTo": s.actorID(),
+			"published":    at,
+			"to":           []string{public},
+			"cc":           []string{s.followersID()},
+		},
+	}
+}
+
+func (s *Server) outbox(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	items := make([]any, 0, len(s.articles))
+	for _, a := range s.articles {
+		items = append(items, s.create(a))
+	}
+	s.mu.RUnlock()
+	writeActivity(w, map[string]any{
+		"@context":     asContext,
+		"id":           s.cfg.BaseURL + OutboxPath,
+		"type":         "OrderedCollection",
+		"totalItems":   len(items),
+		"orderedItems": items,
+	})
+}
+
+func (s *Server) followers(w http.ResponseWriter, r *http.Request) {
+	var n int
+	if err := s.db.QueryRowContext(r.Context(), `SELECT count(*) FROM activitypub_followers`).Scan(&n); err != nil {
+		log.Printf("activitypub: count followers: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	writeActivity(w, map[string]any{
+		"@context":   asContext,
+		"id":         s.followersID(),
+		"type":       "OrderedCollection",
+		"totalItems": n,
+	})
+}
+
+// Publish sets the articles listed in the outbox, newest first, and
+// delivers a Create activity to every follower for each article it hasn't
+// seen before. Delivery happens in the background.
+func (s *Server) Publish(ctx context.Context, articles []Article) error {
+	now := time.Now()
+	var fresh []published
+	list := make([]published, 0, len(articles))
+	for _, a := range articles {
+		var ts int64
+		err := s.db.QueryRowContext(ctx, `SELECT ts FROM activitypub_published WHERE id = ?`, a.ID).Scan(&ts)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			if _, err := s.db.ExecContext(ctx, `INSERT INTO activitypub_published (id, ts) VALUES (?, ?)`, a.ID, now.Unix()); err != nil {
+				return fmt.Errorf("activitypub: publish: %w", err)
+			}
+			p := published{a, now}
+			fresh = append(fresh, p)
+			list = append(list, p)
+		case err != nil:
+			return fmt.Errorf("activitypub: publish: %w", err)
+		default:
+			list = append(list, published{a, time.Unix(ts, 0)})
+		}
+	}
+	slices.SortStableFunc(list, func(a, b published) int { return b.at.Compare(a.at) })
+	s.mu.Lock()
+	s.articles = list
+	s.mu.Unlock()
+
+	if len(fresh) == 0 {
+		return nil
+	}
+	inboxes, err := s.inboxes(ctx)
+	if err != nil {
+		return err
+	}
+	err = s.pool.Go("activitypub delivery", func(ctx context.Context) error {
+		for _, a := range fresh {
+			s.deliverAll(ctx, inboxes, s.create(a))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("activitypub: deliver: %w", err)
+	}
+	return nil
+}
+
+// inboxes returns the distinct inboxes of all followers, so a shared inbox
+// receives each activity once.
+func (s *Server) inboxes(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT inbox FROM activitypub_followers`)
+	if err != nil {
+		return nil, fmt.Errorf("activitypub: followers: %w", err)
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var inbox string
+		if err := rows.Scan(&inbox); err != nil {
+			return nil, err
+		}
+		out = append(out, inbox)
+	}
+	return out, rows.Err()
+}
+
+func (s *Server) deliverAll(ctx context.Context, inboxes []string, activity any) {
+	for _, inbox := range inboxes {
+		if err := s.deliver(ctx, inbox, activity); err != nil {
+			log.Printf("activitypub: deliver to %s: %v", inbox, err)
+		}
+	}
+}
+
+// deliver POSTs a signed activity to inbox.
+func (s *Server) deliver(ctx context.Context, inbox string, activity any) error {
+	body, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, inbox, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if err := sign(req, body, s.key, s.keyID()); err != nil {
+		return err
+	}
+	resp, err := s.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxBody))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return nil
+}
+
+// remoteActor is the part of a remote actor document the server uses.
+type remoteActor struct {
+	ID        string `json:"id"`
+	Inbox     string `json:"inbox"`
+	Endpoints struct {
+		SharedInbox string `json:"sharedInbox"`
+	} `json:"endpoints"`
+	PublicKey struct {
+		ID           string `json:"id"`
+		Owner        string `json:"owner"`
+		PublicKeyPem string `json:"publicKeyPem"`
+	} `json:"publicKey"`
+}
+
+// fetchActor GETs a remote actor document with a signed request, which
+// servers in authorized-fetch mode require.
+func (s *Server) fetchActor(ctx context.Context, id string) (remoteActor, error) {
+	var a remoteActor
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, id, nil)
+	if err != nil {
+		return a, err
+	}
+	req.Header.Set("Accept", contentType)
+	if err := sign(req, nil, s.key, s.keyID()); err != nil {
+		return a, err
+	}
+	resp, err := s.HTTP.Do(req)
+	if err != nil {
+		return a, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return a, fmt.Errorf("GET %s: %s", id, resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxBody)).Decode(&a); err != nil {
+		return a, fmt.Errorf("decode actor %s: %w", id, err)
+	}
+	return a, nil
+}
+
+// publicKey resolves a signature keyId to its key and owner. The key must
+// be published by the actor that owns it, on the keyId's host, so a server
+// cannot sign activities in the name of another server's actors.
+func (s *Server) publicKey(ctx context.Context, keyID string) (*rsa.PublicKey, string, error) {
+	s.keysMu.Lock()
+	k, ok := s.keys[keyID]
+	s.keysMu.Unlock()
+	if ok {
+		return k.key, k.owner, nil
+	}
+	actorURL, _, _ := strings.Cut(keyID, "#")
+	a, err := s.fetchActor(ctx, actorURL)
+	if err != nil {
+		return nil, "", err
+	}
+	if a.PublicKey.ID != keyID {
+		return nil, "", fmt.Errorf("actor %s has no key %s", actorURL, keyID)
+	}
+	if a.ID == "" || a.PublicKey.Owner != a.ID {
+		return nil, "", fmt.Errorf("key %s is owned by %q, not by actor %q", keyID, a.PublicKey.Owner, a.ID)
+	}
+	if !sameHost(keyID, a.ID) {
+		return nil, "", fmt.Errorf("key %s is not on the host of actor %s", keyID, a.ID)
+	}
+	key, err := decodePublicKey(a.PublicKey.PublicKeyPem)
+	if err != nil {
+		return nil, "", err
+	}
+	s.keysMu.Lock()
+	s.keys[keyID] = signer{key: key, owner: a.ID}
+	s.keysMu.Unlock()
+	return key, a.ID, nil
+}
+
+// sameHost reports whether the URLs a and b have the same host.
+func sameHost(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil || ua.Host == "" {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(ua.Host, ub.Host)
+}
+
+// activity is an incoming activity. Object is either an ID or an embedded
+// object.
+type activity struct {
+	ID     string          `json:"id"`
+	Type   string          `json:"type"`
+	Actor  string          `json:"actor"`
+	Object json.RawMessage `json:"object"`
+}
+
+// objectID returns the ID of a's object, whether embedded or referenced.
+func (a activity) objectID() string {
+	var id string
+	if json.Unmarshal(a.Object, &id) == nil {
+		return id
+	}
+	var obj struct{ ID string }
+	json.Unmarshal(a.Object, &obj)
+	return obj.ID
+}
+
+func (s *Server) inbox(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBody))
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	var act activity
+	if err := json.Unmarshal(body, &act); err != nil || act.Actor == "" {
+		http.Error(w, "invalid activity", http.StatusBadRequest)
+		return
+	}
+
+	var owner string
+	_, err = verify(r, body, func(keyID string) (*rsa.PublicKey, error) {
+		key, o, err := s.publicKey(r.Context(), keyID)
+		owner = o
+		return key, err
+	})
+	if err != nil {
+		if act.Type == "Delete" {
+			// Deleted accounts announce themselves once their key is
+			// already gone; there is nothing to verify or do.
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		log.Printf("activitypub: inbox: rejected %s from %s: %v", act.Type, act.Actor, err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	if owner != act.Actor {
+		http.Error(w, "signer is not the actor", http.StatusUnauthorized)
+		return
+	}
+
+	switch act.Type {
+	case "Follow":
+		if act.objectID() != s.actorID() {
+			http.Error(w, "unknown object", http.StatusBadRequest)
+			return
+		}
+		if err := s.follow(r.Context(), act, body); err != nil {
+			log.Printf("activitypub: follow from %s: %v", act.Actor, err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+	case "Undo":
+		var inner activity
+		if json.Unmarshal(act.Object, &inner) == nil && inner.Type == "Follow" {
+			s.unfollow(r.Context(), act.Actor)
+		}
+	case "Delete":
+		if act.objectID() == act.Actor {
+			s.unfollow(r.Context(), act.Actor)
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// follow stores the follower and sends back an Accept.
+func (s *Server) follow(ctx context.Context, act activity, raw json.RawMessage) error {
+	a, err := s.fetchActor(ctx, act.Actor)
+	if err != nil {
+		return err
+	}
+	inbox := a.Endpoints.SharedInbox
+	if inbox == "" {
+		inbox = a.Inbox
+	}
+	if inbox == "" {
+		return errors.New("actor has no inbox")
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT OR REPLACE INTO activitypub_followers (actor, inbox, ts) VALUES (?, ?, ?)`,
+		act.Actor, inbox, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	accept := map[string]any{
+		"@context": asContext,
+		"id":       s.actorID() + "#accepts/" + url.PathEscape(act.ID),
+		"type":     "Accept",
+		"actor":    s.actorID(),
+		"object":   raw,
+	}
+	err = s.pool.Go("activitypub delivery", func(ctx context.Context) error {
+		if err := s.deliver(ctx, a.Inbox, accept); err != nil {
+			return fmt.Errorf("activitypub: accept follow from %s: %w", act.Actor, err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("activitypub: accept follow from %s: %v", act.Actor, err)
+	}
+	log.Printf("activitypub: new follower %s", act.Actor)
+	return nil
+}
+
+func (s *Server) unfollow(ctx context.Context, actor string) {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM activitypub_followers WHERE actor = ?`, actor); err != nil {
+		log.Printf("activitypub: unfollow %s: %v", actor, err)
+	}
+}
@@ -0,0 +1,181 @@
+package activitypub
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// signedHeaders are the headers covered by outgoing signatures, in the
+// draft-cavage HTTP Signatures scheme Mastodon and most of the fediverse
+// use.
+var signedHeaders = []string{"(request-target)", "host", "date", "digest"}
+
+// maxClockSkew bounds how old a signed request's Date may be.
+const maxClockSkew = 12 * time.Hour
+
+// digest returns the Digest header value for body.
+func digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// signingString builds the string covered by a signature over headers.
+func signingString(r *http.Request, headers []string) (string, error) {
+	lines := make([]string, len(headers))
+	for i, h := range headers {
+		switch h {
+		case "(request-target)":
+			lines[i] = h + ": " + strings.ToLower(r.Method) + " " + r.URL.RequestURI()
+		case "host":
+			host := r.Host
+			if host == "" {
+				host = r.URL.Host
+			}
+			lines[i] = "host: " + host
+		default:
+			v := r.Header.Get(h)
+			if v == "" {
+				return "", fmt.Errorf("signed header %q missing", h)
+			}
+			lines[i] = h + ": " + v
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// sign adds Date, Digest and Signature headers to r, an outgoing request
+// with the given body, using key identified by keyID.
+func sign(r *http.Request, body []byte, key *rsa.PrivateKey, keyID string) error {
+	r.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	headers := signedHeaders
+	if r.Method == http.MethodGet {
+		headers = headers[:3]
+	} else {
+		r.Header.Set("Digest", digest(body))
+	}
+	s, err := signingString(r, headers)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256([]byte(s))
+	sig, err := rsa.SignPKCS1v15(nil, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Signature", fmt.Sprintf(`keyId="%s",algorithm="rsa-sha256",headers="%s",signature="%s"`,
+		keyID, strings.Join(headers, " "), base64.StdEncoding.EncodeToString(sig)))
+	return nil
+}
+
+// signature is a parsed Signature header.
+type signature struct {
+	keyID   string
+	headers []string
+	sig     []byte
+}
+
+func parseSignature(v string) (signature, error) {
+	var s signature
+	params := make(map[string]string)
+	for _, part := range strings.Split(v, ",") {
+		k, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		params[k] = strings.Trim(val, `"`)
+	}
+	s.keyID = params["keyId"]
+	if s.keyID == "" || params["signature"] == "" {
+		return s, errors.New("incomplete signature header")
+	}
+	if alg := params["algorithm"]; alg != "" && alg != "rsa-sha256" && alg != "hs2019" {
+		return s, fmt.Errorf("unsupported signature algorithm %q", alg)
+	}
+	s.headers = strings.Fields(params["headers"])
+	if len(s.headers) == 0 {
+		s.headers = []string{"date"}
+	}
+	var err error
+	if s.sig, err = base64.StdEncoding.DecodeString(params["signature"]); err != nil {
+		return s, fmt.Errorf("decode signature: %w", err)
+	}
+	return s, nil
+}
+
+// verify checks r's signature against the public key returned by
+// lookupKey for its keyId, and that body matches the signed digest. It
+// returns the keyId.
+func verify(r *http.Request, body []byte, lookupKey func(keyID string) (*rsa.PublicKey, error)) (string, error) {
+	s, err := parseSignature(r.Header.Get("Signature"))
+	if err != nil {
+		return "", err
+	}
+	covered := strings.Join(s.headers, " ")
+	if !strings.Contains(covered, "(request-target)") || !strings.Contains(covered, "date") {
+		return "", errors.New("signature must cover (request-target) and date")
+	}
+	if r.Method == http.MethodPost {
+		if !strings.Contains(covered, "digest") {
+			return "", errors.New("signature must cover digest")
+		}
+		if r.Header.Get("Digest") != digest(body) {
+			return "", errors.New("digest mismatch")
+		}
+	}
+	date, err := http.ParseTime(r.Header.Get("Date"))
+	if err != nil {
+		return "", fmt.Errorf("invalid date: %w", err)
+	}
+	if d := time.Since(date); d > maxClockSkew || d < -maxClockSkew {
+		return "", errors.New("date outside allowed skew")
+	}
+	str, err := signingString(r, s.headers)
+	if err != nil {
+		return "", err
+	}
+	key, err := lookupKey(s.keyID)
+	if err != nil {
+		return "", fmt.Errorf("fetch key %s: %w", s.keyID, err)
+	}
+	sum := sha256.Sum256([]byte(str))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], s.sig); err != nil {
+		return "", errors.New("bad signature")
+	}
+	return s.keyID, nil
+}
+
+func encodePublicKey(key *rsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+func decodePublicKey(s string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		if k, err2 := x509.ParsePKCS1PublicKey(block.Bytes); err2 == nil {
+			return k, nil
+		}
+		return nil, err
+	}
+	k, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not an RSA key")
+	}
+	return k, nil
+}
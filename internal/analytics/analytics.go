@@ -0,0 +1,483 @@
+// Package analytics records privacy-friendly page views in SQLite. No cookies
+// are set and no raw IP addresses or user agents are stored: visitors are
+// identified by a hash of their truncated IP and user agent, salted with a
+// random value that rotates daily, so they can't be tracked across days. When
+// a country resolver is configured, only the resulting country code is kept.
+package analytics
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/abtest"
+	"github.com/fpatron/portfolio/internal/clientip"
+	"github.com/fpatron/portfolio/internal/db"
+)
+
+// rawRetention is how long individual page views are kept after they have
+// been rolled up into the daily tables.
+const rawRetention = 30 * 24 * time.Hour
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS pageviews (
+		id INTEGER PRIMARY KEY,
+		ts INTEGER NOT NULL,
+		path TEXT NOT NULL,
+		referrer TEXT NOT NULL DEFAULT '',
+		visitor TEXT NOT NULL,
+		ua_class TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS pageviews_ts ON pageviews(ts)`,
+	`CREATE TABLE IF NOT EXISTS daily_pageviews (
+		day TEXT NOT NULL,
+		path TEXT NOT NULL,
+		views INTEGER NOT NULL,
+		visitors INTEGER NOT NULL,
+		PRIMARY KEY (day, path)
+	)`,
+	`CREATE TABLE IF NOT EXISTS outbound_clicks (
+		id INTEGER PRIMARY KEY,
+		ts INTEGER NOT NULL,
+		target TEXT NOT NULL,
+		visitor TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS outbound_clicks_ts ON outbound_clicks(ts)`,
+	`CREATE TABLE IF NOT EXISTS goals (
+		id INTEGER PRIMARY KEY,
+		ts INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		visitor TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS goals_ts ON goals(ts)`,
+	`CREATE TABLE IF NOT EXISTS campaign_landings (
+		id INTEGER PRIMARY KEY,
+		ts INTEGER NOT NULL,
+		visitor TEXT NOT NULL,
+		source TEXT NOT NULL,
+		medium TEXT NOT NULL,
+		campaign TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS campaign_landings_visitor ON campaign_landings(visitor, ts)`,
+	`CREATE TABLE IF NOT EXISTS daily_referrers (
+		day TEXT NOT NULL,
+		referrer TEXT NOT NULL,
+		views INTEGER NOT NULL,
+		PRIMARY KEY (day, referrer)
+	)`,
+	`CREATE TABLE IF NOT EXISTS asset_visitors (
+		visitor TEXT PRIMARY KEY,
+		ts INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS suspect_visitors (
+		visitor TEXT PRIMARY KEY,
+		ts INTEGER NOT NULL,
+		reason TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS daily_bots (
+		day TEXT PRIMARY KEY,
+		views INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS server_errors (
+		id INTEGER PRIMARY KEY,
+		ts INTEGER NOT NULL,
+		path TEXT NOT NULL,
+		status INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS server_errors_ts ON server_errors(ts)`,
+	`CREATE TABLE IF NOT EXISTS daily_countries (
+		day TEXT NOT NULL,
+		country TEXT NOT NULL,
+		views INTEGER NOT NULL,
+		visitors INTEGER NOT NULL,
+		PRIMARY KEY (day, country)
+	)`,
+	`CREATE TABLE IF NOT EXISTS daily_variants (
+		day TEXT NOT NULL,
+		variant TEXT NOT NULL,
+		views INTEGER NOT NULL,
+		visitors INTEGER NOT NULL,
+		PRIMARY KEY (day, variant)
+	)`,
+}
+
+// human is the condition a page view must meet to count as human traffic:
+// a browser-like user agent, a client that fetched the page's assets or ran
+// its scripts, and no visit to the honeypot link. Everything else is counted
+// as bot traffic.
+const human = `ua_class != 'bot'
+	AND visitor IN (SELECT visitor FROM asset_visitors)
+	AND visitor NOT IN (SELECT visitor FROM suspect_visitors)`
+
+// PageView is a single recorded page view.
+type PageView struct {
+	Time     time.Time
+	Path     string
+	Referrer string // host only; empty for direct and internal traffic
+	Visitor  string
+	UAClass  string
+	Country  string // ISO code; empty when unknown or geo lookup is disabled
+	Variant  string // A/B test variant; empty when no test is running
+}
+
+// CountryResolver maps a client address to an ISO country code, or "" when
+// unknown. *geoip.DB implements it.
+type CountryResolver interface {
+	Country(addr netip.Addr) string
+}
+
+// write is a queued INSERT.
+type write struct {
+	query string
+	args  []any
+}
+
+// Recorder writes page views and clicks to the database in the background so
+// request latency is unaffected. Writes are dropped, not queued unboundedly,
+// if the database falls behind.
+type Recorder struct {
+	// Countries, if set, resolves each page view's country at record time.
+	// It must be set before the recorder is used.
+	Countries CountryResolver
+	// Now, if set, replaces time.Now as the recorder's clock. It must be
+	// set before the recorder is used.
+	Now func() time.Time
+
+	db     *sql.DB
+	writes chan write
+	done   chan struct{}
+
+	// closeMu guards sending on writes against Close closing it: writes
+	// recorded by requests that outlive the server's shutdown are dropped.
+	closeMu sync.RWMutex
+	closed  bool
+
+	saltMu  sync.Mutex
+	saltDay string
+	salt    []byte
+
+	// assetsSeen holds today's visitors already recorded in asset_visitors,
+	// so each one is written once. It is reset with the salt.
+	assetsSeen map[string]bool
+}
+
+// New creates the analytics tables if needed and starts the writer.
+func New(ctx context.Context, database *sql.DB) (*Recorder, error) {
+	if err := db.Migrate(ctx, database, schema...); err != nil {
+		return nil, fmt.Errorf("analytics: %w", err)
+	}
+	if err := db.AddColumn(ctx, database, "pageviews", "country", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return nil, fmt.Errorf("analytics: %w", err)
+	}
+	for _, table := range []string{"pageviews", "goals"} {
+		if err := db.AddColumn(ctx, database, table, "variant", "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return nil, fmt.Errorf("analytics: %w", err)
+		}
+	}
+	r := &Recorder{
+		db:     database,
+		writes: make(chan write, 256),
+		done:   make(chan struct{}),
+	}
+	go r.run()
+	return r, nil
+}
+
+func (r *Recorder) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// Close stops accepting writes and waits for queued ones to complete.
+// Writes recorded after it are dropped.
+func (r *Recorder) Close() {
+	r.closeMu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.writes)
+	}
+	r.closeMu.Unlock()
+	<-r.done
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+	for w := range r.writes {
+		if _, err := r.db.Exec(w.query, w.args...); err != nil {
+			log.Printf("analytics: write: %v", err)
+		}
+	}
+}
+
+func (r *Recorder) enqueue(w write) {
+	r.closeMu.RLock()
+	defer r.closeMu.RUnlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.writes <- w:
+	default:
+		log.Printf("analytics: queue full, dropping write")
+	}
+}
+
+// Record queues v for writing.
+func (r *Recorder) Record(v PageView) {
+	r.enqueue(write{
+		`INSERT INTO pageviews (ts, path, referrer, visitor, ua_class, country, variant) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		[]any{v.Time.Unix(), v.Path, v.Referrer, v.Visitor, v.UAClass, v.Country, v.Variant},
+	})
+}
+
+// RecordClick queues an outbound click on target (e.g. a project slug).
+// Clicks from bots are ignored.
+func (r *Recorder) RecordClick(req *http.Request, target string) {
+	if ClassifyUserAgent(req.UserAgent()) == ClassBot {
+		return
+	}
+	now := r.now()
+	r.enqueue(write{
+		`INSERT INTO outbound_clicks (ts, target, visitor) VALUES (?, ?, ?)`,
+		[]any{now.Unix(), target, r.visitorID(req, now)},
+	})
+}
+
+// visitorID returns today's anonymous identifier for the request's client.
+func (r *Recorder) visitorID(req *http.Request, now time.Time) string {
+	day := now.UTC().Format(time.DateOnly)
+	r.saltMu.Lock()
+	if r.saltDay != day {
+		r.salt = make([]byte, 16)
+		rand.Read(r.salt)
+		r.saltDay = day
+		r.assetsSeen = make(map[string]bool)
+	}
+	salt := r.salt
+	r.saltMu.Unlock()
+
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(clientip.Truncate(clientip.FromRequest(req)).String()))
+	h.Write([]byte(req.UserAgent()))
+	return hex.EncodeToString(h.Sum(nil)[:12])
+}
+
+// referrerHost returns the host of the request's Referer, or "" when it is
+// missing, unparsable or the site itself.
+func referrerHost(req *http.Request) string {
+	ref := req.Referer()
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil || u.Host == "" || strings.EqualFold(u.Host, req.Host) {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// markAssets records that the request's client fetched a subresource, which
+// crawlers that only want the HTML rarely do.
+func (r *Recorder) markAssets(req *http.Request) {
+	now := r.now()
+	visitor := r.visitorID(req, now)
+	r.saltMu.Lock()
+	seen := r.assetsSeen[visitor]
+	r.assetsSeen[visitor] = true
+	r.saltMu.Unlock()
+	if !seen {
+		r.enqueue(write{
+			`INSERT OR IGNORE INTO asset_visitors (visitor, ts) VALUES (?, ?)`,
+			[]any{visitor, now.Unix()},
+		})
+	}
+}
+
+// Honeypot serves the link hidden from humans in the page layout. Any client
+// following it is flagged, and its views for the day are counted as bots.
+func (r *Recorder) Honeypot(w http.ResponseWriter, req *http.Request) {
+	now := r.now()
+	r.enqueue(write{
+		`INSERT OR IGNORE INTO suspect_visitors (visitor, ts, reason) VALUES (?, ?, 'honeypot')`,
+		[]any{r.visitorID(req, now), now.Unix()},
+	})
+	http.NotFound(w, req)
+}
+
+// isSubresource reports whether a request is one a real browser makes while
+// rendering a page: a static asset or an HTMX request.
+func isSubresource(req *http.Request) bool {
+	return strings.HasPrefix(req.URL.Path, "/static/") || req.Header.Get("HX-Request") != ""
+}
+
+// isPage reports whether a request is a page load worth counting, as opposed
+// to an asset, API call, HTMX partial or stream.
+func isPage(req *http.Request) bool {
+	if req.Method != http.MethodGet || req.Header.Get("HX-Request") != "" {
+		return false
+	}
+	for _, prefix := range []string{"/static/", "/partials/", "/api/", "/v1/", "/events", "/health", "/badge/", "/oembed", "/admin", "/stats/"} {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Campaign identifies a marketing campaign by its UTM parameters.
+type Campaign struct {
+	Source string
+	Medium string
+	Name   string
+}
+
+// campaignFromQuery extracts UTM parameters. A landing needs at least
+// utm_source or utm_campaign to count.
+func campaignFromQuery(q url.Values) (Campaign, bool) {
+	c := Campaign{
+		Source: truncate(q.Get("utm_source"), 100),
+		Medium: truncate(q.Get("utm_medium"), 100),
+		Name:   truncate(q.Get("utm_campaign"), 100),
+	}
+	return c, c.Source != "" || c.Name != ""
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// Goal names recorded with RecordGoal. GoalContact is the conversion; the
+// contact_* goals track the steps of the contact funnel.
+const (
+	GoalContact          = "contact"
+	GoalContactViewed    = "contact_viewed"
+	GoalContactValidated = "contact_validated"
+	GoalContactDelivered = "contact_delivered"
+)
+
+// RecordGoal queues a conversion such as a contact form submission.
+func (r *Recorder) RecordGoal(req *http.Request, name string) {
+	now := r.now()
+	r.enqueue(write{
+		`INSERT INTO goals (ts, name, visitor, variant) VALUES (?, ?, ?, ?)`,
+		[]any{now.Unix(), name, r.visitorID(req, now), abtest.Variant(req.Context())},
+	})
+}
+
+// Middleware records successful page loads and server errors served by next.
+func (r *Recorder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		page := isPage(req)
+		if !page && isSubresource(req) {
+			r.markAssets(req)
+		}
+		sr := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sr, req)
+		if sr.status >= http.StatusInternalServerError {
+			r.enqueue(write{
+				`INSERT INTO server_errors (ts, path, status) VALUES (?, ?, ?)`,
+				[]any{r.now().Unix(), truncate(req.URL.Path, 200), sr.status},
+			})
+		}
+		if !page || sr.status != http.StatusOK {
+			return
+		}
+		now := r.now()
+		v := PageView{
+			Time:     now,
+			Path:     req.URL.Path,
+			Referrer: referrerHost(req),
+			Visitor:  r.visitorID(req, now),
+			UAClass:  ClassifyUserAgent(req.UserAgent()),
+			Variant:  abtest.Variant(req.Context()),
+		}
+		if r.Countries != nil {
+			v.Country = r.Countries.Country(clientip.FromRequest(req))
+		}
+		r.Record(v)
+		if c, ok := campaignFromQuery(req.URL.Query()); ok && v.UAClass != ClassBot {
+			r.enqueue(write{
+				`INSERT INTO campaign_landings (ts, visitor, source, medium, campaign) VALUES (?, ?, ?, ?, ?)`,
+				[]any{now.Unix(), v.Visitor, c.Source, c.Medium, c.Name},
+			})
+		}
+	})
+}
+
+// Aggregate rolls raw page views from before today into the daily tables and
+// prunes raw views past the retention window. Bots are excluded from the
+// daily counts and tallied in daily_bots instead. It is safe to run
+// repeatedly.
+func (r *Recorder) Aggregate(ctx context.Context) error {
+	now := r.now().UTC()
+	today := now.Truncate(24 * time.Hour).Unix()
+	cutoff := now.Add(-rawRetention).Unix()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmts := []struct {
+		query string
+		args  []any
+	}{
+		{`INSERT OR REPLACE INTO daily_pageviews (day, path, views, visitors)
+			SELECT date(ts, 'unixepoch'), path, count(*), count(DISTINCT visitor)
+			FROM pageviews WHERE ts < ? AND ` + human + ` GROUP BY 1, 2`, []any{today}},
+		{`INSERT OR REPLACE INTO daily_referrers (day, referrer, views)
+			SELECT date(ts, 'unixepoch'), referrer, count(*)
+			FROM pageviews WHERE ts < ? AND referrer != '' AND ` + human + ` GROUP BY 1, 2`, []any{today}},
+		{`INSERT OR REPLACE INTO daily_bots (day, views)
+			SELECT date(ts, 'unixepoch'), count(*)
+			FROM pageviews WHERE ts < ? AND NOT (` + human + `) GROUP BY 1`, []any{today}},
+		{`INSERT OR REPLACE INTO daily_countries (day, country, views, visitors)
+			SELECT date(ts, 'unixepoch'), country, count(*), count(DISTINCT visitor)
+			FROM pageviews WHERE ts < ? AND country != '' AND ` + human + ` GROUP BY 1, 2`, []any{today}},
+		{`INSERT OR REPLACE INTO daily_variants (day, variant, views, visitors)
+			SELECT date(ts, 'unixepoch'), variant, count(*), count(DISTINCT visitor)
+			FROM pageviews WHERE ts < ? AND variant != '' AND ` + human + ` GROUP BY 1, 2`, []any{today}},
+		{`DELETE FROM pageviews WHERE ts < ?`, []any{cutoff}},
+		{`DELETE FROM outbound_clicks WHERE ts < ?`, []any{cutoff}},
+		{`DELETE FROM campaign_landings WHERE ts < ?`, []any{cutoff}},
+		{`DELETE FROM asset_visitors WHERE ts < ?`, []any{cutoff}},
+		{`DELETE FROM suspect_visitors WHERE ts < ?`, []any{cutoff}},
+		{`DELETE FROM server_errors WHERE ts < ?`, []any{cutoff}},
+	}
+	for _, s := range stmts {
+		if _, err := tx.ExecContext(ctx, s.query, s.args...); err != nil {
+			return fmt.Errorf("analytics: aggregate: %w", err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,43 @@
+package analytics
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/db"
+)
+
+// TestRecordAfterClose records while and after the recorder closes, as
+// requests outliving the server's shutdown do: the writes are dropped
+// instead of sent on the closed queue.
+func TestRecordAfterClose(t *testing.T) {
+	database, err := db.Open(filepath.Join(t.TempDir(), "analytics.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	r, err := New(context.Background(), database)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				r.Record(PageView{Time: time.Now(), Path: "/"})
+			}
+		}()
+	}
+	r.Close()
+	wg.Wait()
+	r.Record(PageView{Time: time.Now(), Path: "/"})
+	r.RecordClick(httptest.NewRequest("GET", "/out/github", nil), "https://github.com")
+	r.Close()
+}
@@ -0,0 +1,323 @@
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Count is a labeled total.
+type Count struct {
+	Label string
+	Views int
+}
+
+// Day is one point of the daily series.
+type Day struct {
+	Date     time.Time
+	Views    int
+	Visitors int
+}
+
+// Report summarizes human traffic over a period.
+type Report struct {
+	From, To     time.Time
+	Views        int
+	Visitors     int
+	Bots         int // page views filtered out as bot traffic
+	Daily        []Day
+	TopPages     []Count
+	TopReferrers []Count
+	TopCountries []Count
+	TopClicks    []Count // outbound clicks by target: project slugs and short links
+	Contacts     int
+	Errors       int     // 5xx responses
+	TopErrors    []Count // 5xx responses by path
+	Campaigns    []CampaignStats
+	Funnel       []FunnelStep
+	Variants     []VariantStats // A/B test variants, when a test ran
+}
+
+// FunnelStep is one step of the contact funnel with the share of the
+// previous step that reached it.
+type FunnelStep struct {
+	Name    string
+	Count   int
+	Percent float64
+}
+
+// CampaignStats reports how a UTM campaign performed. Contacts counts
+// visitors who submitted the contact form after landing from the campaign,
+// attributed through the same daily visitor hash that identifies a session.
+type CampaignStats struct {
+	Campaign
+	Visits   int
+	Contacts int
+}
+
+// VariantStats reports the traffic and contact submissions of an A/B test
+// variant.
+type VariantStats struct {
+	Variant  string
+	Views    int
+	Visitors int
+	Contacts int
+}
+
+// ConversionRate returns contact submissions per visitor, as a percentage.
+func (v VariantStats) ConversionRate() float64 {
+	if v.Visitors == 0 {
+		return 0
+	}
+	return 100 * float64(v.Contacts) / float64(v.Visitors)
+}
+
+// ConversionRate returns contact submissions per visitor, as a percentage.
+func (r Report) ConversionRate() float64 {
+	if r.Visitors == 0 {
+		return 0
+	}
+	return 100 * float64(r.Contacts) / float64(r.Visitors)
+}
+
+// dailyUnion selects (day, path, views, visitors) rows from
+// the daily roll-up for past days and from raw views for today, which has
+// not been aggregated yet.
+const dailyUnion = `
+	SELECT day, path, views, visitors FROM daily_pageviews WHERE day >= ? AND day < ?
+	UNION ALL
+	SELECT date(ts, 'unixepoch'), path, count(*), count(DISTINCT visitor)
+	FROM pageviews WHERE ts >= ? AND ` + human + ` GROUP BY 1, 2`
+
+const referrerUnion = `
+	SELECT referrer, views FROM daily_referrers WHERE day >= ? AND day < ?
+	UNION ALL
+	SELECT referrer, count(*) FROM pageviews
+	WHERE ts >= ? AND referrer != '' AND ` + human + ` GROUP BY 1`
+
+const variantUnion = `
+	SELECT variant, views, visitors FROM daily_variants WHERE day >= ? AND day < ?
+	UNION ALL
+	SELECT variant, count(*), count(DISTINCT visitor) FROM pageviews
+	WHERE ts >= ? AND variant != '' AND ` + human + ` GROUP BY 1`
+
+const countryUnion = `
+	SELECT country, views FROM daily_countries WHERE day >= ? AND day < ?
+	UNION ALL
+	SELECT country, count(*) FROM pageviews
+	WHERE ts >= ? AND country != '' AND ` + human + ` GROUP BY 1`
+
+// Report builds a summary of the last days days, today included.
+func (r *Recorder) Report(ctx context.Context, days int, limit int) (Report, error) {
+	now := r.now().UTC()
+	today := now.Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -(days - 1))
+	rep := Report{From: from, To: now}
+	args := []any{from.Format(time.DateOnly), today.Format(time.DateOnly), today.Unix()}
+
+	byDay := make(map[string]*Day)
+	for d := from; !d.After(today); d = d.AddDate(0, 0, 1) {
+		rep.Daily = append(rep.Daily, Day{Date: d})
+	}
+	for i := range rep.Daily {
+		byDay[rep.Daily[i].Date.Format(time.DateOnly)] = &rep.Daily[i]
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT day, sum(views), sum(visitors) FROM (`+dailyUnion+`) GROUP BY day`, args...)
+	if err != nil {
+		return rep, fmt.Errorf("analytics: daily series: %w", err)
+	}
+	err = scanRows(rows, func() error {
+		var day string
+		var views, visitors int
+		if err := rows.Scan(&day, &views, &visitors); err != nil {
+			return err
+		}
+		if d, ok := byDay[day]; ok {
+			d.Views, d.Visitors = views, visitors
+		}
+		rep.Views += views
+		// Daily visitor hashes rotate, so summing days is the best available
+		// approximation of visitors over the period.
+		rep.Visitors += visitors
+		return nil
+	})
+	if err != nil {
+		return rep, fmt.Errorf("analytics: daily series: %w", err)
+	}
+
+	if rep.TopPages, err = r.topCounts(ctx, `SELECT path, sum(views) AS v FROM (`+dailyUnion+`) GROUP BY path ORDER BY v DESC LIMIT ?`, append(args, limit)...); err != nil {
+		return rep, fmt.Errorf("analytics: top pages: %w", err)
+	}
+	if rep.TopReferrers, err = r.topCounts(ctx, `SELECT referrer, sum(views) AS v FROM (`+referrerUnion+`) GROUP BY referrer ORDER BY v DESC LIMIT ?`, append(args, limit)...); err != nil {
+		return rep, fmt.Errorf("analytics: top referrers: %w", err)
+	}
+	if rep.TopCountries, err = r.topCounts(ctx, `SELECT country, sum(views) AS v FROM (`+countryUnion+`) GROUP BY country ORDER BY v DESC LIMIT ?`, append(args, limit)...); err != nil {
+		return rep, fmt.Errorf("analytics: top countries: %w", err)
+	}
+
+	if rep.TopClicks, err = r.topCounts(ctx, `SELECT target, count(*) AS n FROM outbound_clicks WHERE ts >= ? GROUP BY target ORDER BY n DESC LIMIT ?`, from.Unix(), limit); err != nil {
+		return rep, fmt.Errorf("analytics: top clicks: %w", err)
+	}
+
+	err = r.db.QueryRowContext(ctx, `SELECT coalesce(sum(views), 0) FROM (
+		SELECT views FROM daily_bots WHERE day >= ? AND day < ?
+		UNION ALL
+		SELECT count(*) FROM pageviews WHERE ts >= ? AND NOT (`+human+`))`, args...).Scan(&rep.Bots)
+	if err != nil {
+		return rep, fmt.Errorf("analytics: bots: %w", err)
+	}
+
+	err = r.db.QueryRowContext(ctx, `SELECT count(*) FROM goals WHERE name = ? AND ts >= ?`, GoalContact, from.Unix()).Scan(&rep.Contacts)
+	if err != nil {
+		return rep, fmt.Errorf("analytics: contacts: %w", err)
+	}
+
+	if err := r.db.QueryRowContext(ctx, `SELECT count(*) FROM server_errors WHERE ts >= ?`, from.Unix()).Scan(&rep.Errors); err != nil {
+		return rep, fmt.Errorf("analytics: errors: %w", err)
+	}
+	if rep.TopErrors, err = r.topCounts(ctx, `SELECT path, count(*) AS n FROM server_errors WHERE ts >= ? GROUP BY path ORDER BY n DESC LIMIT ?`, from.Unix(), limit); err != nil {
+		return rep, fmt.Errorf("analytics: errors: %w", err)
+	}
+
+	if rep.Campaigns, err = r.campaigns(ctx, from, limit); err != nil {
+		return rep, fmt.Errorf("analytics: campaigns: %w", err)
+	}
+	if rep.Funnel, err = r.funnel(ctx, from); err != nil {
+		return rep, fmt.Errorf("analytics: funnel: %w", err)
+	}
+	if rep.Variants, err = r.variants(ctx, from, args); err != nil {
+		return rep, fmt.Errorf("analytics: variants: %w", err)
+	}
+	return rep, nil
+}
+
+func (r *Recorder) variants(ctx context.Context, from time.Time, args []any) ([]VariantStats, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT v.variant, v.views, v.visitors, coalesce(g.n, 0)
+		FROM (SELECT variant, sum(views) AS views, sum(visitors) AS visitors FROM (`+variantUnion+`) GROUP BY variant) v
+		LEFT JOIN (SELECT variant, count(*) AS n FROM goals WHERE name = ? AND ts >= ? GROUP BY variant) g USING (variant)
+		ORDER BY v.variant`, append(args, GoalContact, from.Unix())...)
+	if err != nil {
+		return nil, err
+	}
+	var out []VariantStats
+	err = scanRows(rows, func() error {
+		var v VariantStats
+		if err := rows.Scan(&v.Variant, &v.Views, &v.Visitors, &v.Contacts); err != nil {
+			return err
+		}
+		out = append(out, v)
+		return nil
+	})
+	return out, err
+}
+
+// funnelGoals maps each funnel step to the goal recording it.
+var funnelGoals = []struct{ step, goal string }{
+	{"Form viewed", GoalContactViewed},
+	{"Submitted", GoalContact},
+	{"Validated", GoalContactValidated},
+	{"Delivered", GoalContactDelivered},
+}
+
+func (r *Recorder) funnel(ctx context.Context, from time.Time) ([]FunnelStep, error) {
+	counts := make(map[string]int)
+	rows, err := r.db.QueryContext(ctx, `SELECT name, count(*) FROM goals WHERE ts >= ? GROUP BY name`, from.Unix())
+	if err != nil {
+		return nil, err
+	}
+	err = scanRows(rows, func() error {
+		var name string
+		var n int
+		if err := rows.Scan(&name, &n); err != nil {
+			return err
+		}
+		counts[name] = n
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]FunnelStep, len(funnelGoals))
+	for i, g := range funnelGoals {
+		steps[i] = FunnelStep{Name: g.step, Count: counts[g.goal], Percent: 100}
+		if i > 0 {
+			if prev := steps[i-1].Count; prev > 0 {
+				steps[i].Percent = 100 * float64(steps[i].Count) / float64(prev)
+			} else {
+				steps[i].Percent = 0
+			}
+		}
+	}
+	return steps, nil
+}
+
+func (r *Recorder) campaigns(ctx context.Context, from time.Time, limit int) ([]CampaignStats, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT l.source, l.medium, l.campaign, count(DISTINCT l.visitor),
+			count(DISTINCT CASE WHEN g.id IS NOT NULL THEN l.visitor END)
+		FROM campaign_landings l
+		LEFT JOIN goals g ON g.visitor = l.visitor AND g.name = ? AND g.ts >= l.ts
+		WHERE l.ts >= ?
+		GROUP BY 1, 2, 3
+		ORDER BY 4 DESC
+		LIMIT ?`, GoalContact, from.Unix(), limit)
+	if err != nil {
+		return nil, err
+	}
+	var out []CampaignStats
+	err = scanRows(rows, func() error {
+		var c CampaignStats
+		if err := rows.Scan(&c.Source, &c.Medium, &c.Name, &c.Visits, &c.Contacts); err != nil {
+			return err
+		}
+		out = append(out, c)
+		return nil
+	})
+	return out, err
+}
+
+// Clicks returns the outbound clicks of the last days days, today
+// included, by target. Clicks are kept for as long as raw page views.
+func (r *Recorder) Clicks(ctx context.Context, days int) (map[string]int, error) {
+	from := r.now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	counts, err := r.topCounts(ctx, `SELECT target, count(*) FROM outbound_clicks WHERE ts >= ? GROUP BY target`, from.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("analytics: clicks: %w", err)
+	}
+	m := make(map[string]int, len(counts))
+	for _, c := range counts {
+		m[c.Label] = c.Views
+	}
+	return m, nil
+}
+
+func (r *Recorder) topCounts(ctx context.Context, query string, args ...any) ([]Count, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var out []Count
+	err = scanRows(rows, func() error {
+		var c Count
+		if err := rows.Scan(&c.Label, &c.Views); err != nil {
+			return err
+		}
+		out = append(out, c)
+		return nil
+	})
+	return out, err
+}
+
+// scanRows calls scan for each row and closes rows.
+func scanRows(rows *sql.Rows, scan func() error) error {
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,40 @@
+package analytics
+
+import "strings"
+
+// User agent classes recorded with each page view.
+const (
+	ClassDesktop = "desktop"
+	ClassMobile  = "mobile"
+	ClassTablet  = "tablet"
+	ClassBot     = "bot"
+	ClassUnknown = "unknown"
+)
+
+var botMarkers = []string{
+	"bot", "crawl", "spider", "slurp", "curl", "wget", "python-requests",
+	"go-http-client", "headless", "httpclient", "facebookexternalhit", "preview",
+}
+
+// ClassifyUserAgent buckets a User-Agent header into a coarse device class.
+// The raw header is never stored.
+func ClassifyUserAgent(ua string) string {
+	if ua == "" {
+		return ClassUnknown
+	}
+	l := strings.ToLower(ua)
+	for _, m := range botMarkers {
+		if strings.Contains(l, m) {
+			return ClassBot
+		}
+	}
+	switch {
+	case strings.Contains(l, "ipad") || strings.Contains(l, "tablet"):
+		return ClassTablet
+	case strings.Contains(l, "mobi") || strings.Contains(l, "iphone") || strings.Contains(l, "android"):
+		return ClassMobile
+	case strings.Contains(l, "mozilla"):
+		return ClassDesktop
+	}
+	return ClassUnknown
+}
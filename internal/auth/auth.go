@@ -0,0 +1,33 @@
+// Package auth guards administrative routes.
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+)
+
+// Basic wraps next with HTTP Basic authentication against a single
+// user/password pair. When either is empty the admin area is considered
+// disabled and every request gets a 404, so an unconfigured deployment never
+// exposes it.
+func Basic(user, password string, next http.Handler) http.Handler {
+	if user == "" || password == "" {
+		return http.HandlerFunc(http.NotFound)
+	}
+	wantUser := sha256.Sum256([]byte(user))
+	wantPass := sha256.Sum256([]byte(password))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, p, ok := r.BasicAuth()
+		gotUser := sha256.Sum256([]byte(u))
+		gotPass := sha256.Sum256([]byte(p))
+		userOK := subtle.ConstantTimeCompare(gotUser[:], wantUser[:])
+		passOK := subtle.ConstantTimeCompare(gotPass[:], wantPass[:])
+		if !ok || userOK&passOK != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="admin", charset="UTF-8"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
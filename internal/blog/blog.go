@@ -0,0 +1,243 @@
+// Package blog loads the blog's posts: Markdown files with front matter,
+// rendered to HTML once when they are loaded.
+package blog
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// Dir is the directory of the posts in the site's files.
+const Dir = "content/posts"
+
+// Post is a blog post. Its slug is the name of its file without the .md
+// extension.
+type Post struct {
+	Slug    string    `json:"slug"`
+	Title   string    `json:"title"`
+	Date    time.Time `json:"date"`
+	Tags    []string  `json:"tags,omitempty"`
+	Draft   bool      `json:"draft,omitempty"`
+	Summary string    `json:"summary"`
+	// DevTo and Medium are the URLs of the post's copies on those sites,
+	// recorded by the crosspost command.
+	DevTo  string `json:"devto,omitempty"`
+	Medium string `json:"medium,omitempty"`
+	// ReadingTime is the time it takes to read the post, in minutes.
+	ReadingTime int `json:"reading_time"`
+	// HTML is the rendered body. Posts are the site owner's, so raw HTML
+	// in them is kept.
+	HTML template.HTML `json:"html,omitempty"`
+}
+
+// HasTag reports whether the post is tagged tag, ignoring case.
+func (p Post) HasTag(tag string) bool {
+	return slices.ContainsFunc(p.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM, extension.Footnote, extension.Typographer),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Load reads the posts in dir of fsys, newest first. A missing directory
+// has no posts.
+func Load(fsys fs.FS, dir string) ([]Post, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	var posts []Post
+	for _, f := range files {
+		b, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			return nil, err
+		}
+		p, err := Parse(strings.TrimSuffix(path.Base(f), ".md"), b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		posts = append(posts, p)
+	}
+	slices.SortStableFunc(posts, func(a, b Post) int {
+		if c := b.Date.Compare(a.Date); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Slug, b.Slug)
+	})
+	return posts, nil
+}
+
+// Parse reads a post from its front matter, between "---" lines, and its
+// Markdown body. The front matter has a title, a date (2006-01-02), and
+// optionally tags, a list such as [go, htmx], draft and a summary; without
+// one, the summary is the post's first paragraph. devto and medium hold
+// the URLs of the post's copies on those sites.
+func Parse(slug string, src []byte) (Post, error) {
+	p := Post{Slug: slug}
+	if !slugPattern.MatchString(slug) {
+		return p, fmt.Errorf("name %q: use lowercase letters, digits and dashes", slug)
+	}
+	front, body, err := split(src)
+	if err != nil {
+		return p, err
+	}
+	for i, line := range strings.Split(string(front), "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return p, fmt.Errorf("front matter line %d: want key: value", i+2)
+		}
+		value = unquote(strings.TrimSpace(value))
+		switch key = strings.TrimSpace(key); key {
+		case "title":
+			p.Title = value
+		case "date":
+			d, err := time.Parse(time.DateOnly, value)
+			if err != nil {
+				return p, fmt.Errorf("date %q: want 2006-01-02", value)
+			}
+			p.Date = d
+		case "tags":
+			for _, t := range strings.Split(strings.Trim(value, "[]"), ",") {
+				if t = unquote(strings.TrimSpace(t)); t != "" {
+					p.Tags = append(p.Tags, t)
+				}
+			}
+		case "draft":
+			d, err := strconv.ParseBool(value)
+			if err != nil {
+				return p, fmt.Errorf("draft %q: want true or false", value)
+			}
+			p.Draft = d
+		case "summary":
+			p.Summary = value
+		case "devto":
+			p.DevTo = value
+		case "medium":
+			p.Medium = value
+		default:
+			return p, fmt.Errorf("front matter line %d: unknown key %q", i+2, key)
+		}
+	}
+	if p.Title == "" {
+		return p, fmt.Errorf("no title")
+	}
+	if p.Date.IsZero() {
+		return p, fmt.Errorf("no date")
+	}
+
+	doc := markdown.Parser().Parse(text.NewReader(body))
+	var buf bytes.Buffer
+	if err := markdown.Renderer().Render(&buf, body, doc); err != nil {
+		return p, fmt.Errorf("render: %w", err)
+	}
+	p.HTML = template.HTML(buf.String())
+	if p.Summary == "" {
+		p.Summary = firstParagraph(doc, body)
+	}
+	p.ReadingTime = max((len(strings.Fields(string(body)))+100)/200, 1)
+	return p, nil
+}
+
+// split returns the front matter and the Markdown body of src.
+func split(src []byte) (front, body []byte, err error) {
+	src = bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n"))
+	rest, ok := bytes.CutPrefix(src, []byte("---\n"))
+	if !ok {
+		return nil, nil, fmt.Errorf("no front matter: start the file with a --- line")
+	}
+	front, body, ok = bytes.Cut(rest, []byte("\n---\n"))
+	if !ok {
+		return nil, nil, fmt.Errorf("front matter is not closed by a --- line")
+	}
+	return front, body, nil
+}
+
+// Body returns the Markdown body of a post's source, without its front
+// matter.
+func Body(src []byte) ([]byte, error) {
+	_, body, err := split(src)
+	return body, err
+}
+
+// SetField returns src with the front matter key set to value, replacing
+// the key's line if there is one and adding it last otherwise.
+func SetField(src []byte, key, value string) ([]byte, error) {
+	front, body, err := split(src)
+	if err != nil {
+		return nil, err
+	}
+	line := key + ": " + value
+	lines := strings.Split(string(front), "\n")
+	i := slices.IndexFunc(lines, func(l string) bool {
+		k, _, ok := strings.Cut(l, ":")
+		return ok && strings.TrimSpace(k) == key
+	})
+	if i >= 0 {
+		lines[i] = line
+	} else {
+		lines = append(lines, line)
+	}
+	return []byte("---\n" + strings.Join(lines, "\n") + "\n---\n" + string(body)), nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// firstParagraph returns the text of the first paragraph of doc.
+func firstParagraph(doc ast.Node, src []byte) string {
+	var sb strings.Builder
+	inside := false
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if _, ok := n.(*ast.Paragraph); ok {
+			if !entering {
+				return ast.WalkStop, nil
+			}
+			inside = true
+		}
+		if t, ok := n.(*ast.Text); ok && entering && inside {
+			sb.Write(t.Segment.Value(src))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				sb.WriteByte(' ')
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(sb.String())
+}
+
+// Published returns the posts that are not drafts.
+func Published(posts []Post) []Post {
+	var published []Post
+	for _, p := range posts {
+		if !p.Draft {
+			published = append(published, p)
+		}
+	}
+	return published
+}
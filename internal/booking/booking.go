@@ -0,0 +1,64 @@
+// Package booking fetches open meeting slots from Cal.com or Calendly so
+// visitors can pick a time to talk without leaving the page to look for one.
+// Bookings themselves, including the confirmation email, are completed on
+// the provider's booking page.
+package booking
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Slot is an open start time and the page that books it.
+type Slot struct {
+	Start time.Time `json:"start"`
+	URL   string    `json:"url"`
+}
+
+// Provider lists open slots starting between from and to.
+type Provider interface {
+	Slots(ctx context.Context, from, to time.Time) ([]Slot, error)
+}
+
+// Day groups the slots starting on one calendar day.
+type Day struct {
+	Date  time.Time `json:"date"`
+	Slots []Slot    `json:"slots"`
+}
+
+// ByDay groups slots, in order, by their calendar day in loc, keeping at
+// most perDay slots for each day.
+func ByDay(slots []Slot, loc *time.Location, perDay int) []Day {
+	var days []Day
+	for _, s := range slots {
+		s.Start = s.Start.In(loc)
+		y, m, d := s.Start.Date()
+		date := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		if len(days) == 0 || !days[len(days)-1].Date.Equal(date) {
+			days = append(days, Day{Date: date})
+		}
+		if day := &days[len(days)-1]; len(day.Slots) < perDay {
+			day.Slots = append(day.Slots, s)
+		}
+	}
+	return days
+}
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// getJSON sends req and decodes a 200 JSON response into v.
+func getJSON(req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", req.URL.Path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
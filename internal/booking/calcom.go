@@ -0,0 +1,67 @@
+package booking
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// CalCom reads the open slots of a public Cal.com event type.
+type CalCom struct {
+	username string
+	event    string
+	apiURL   string
+	siteURL  string
+}
+
+// NewCalCom returns a provider for the event type with the given slug on
+// a Cal.com user's public page.
+func NewCalCom(username, event string) *CalCom {
+	return &CalCom{
+		username: username,
+		event:    event,
+		apiURL:   "https://api.cal.com",
+		siteURL:  "https://cal.com",
+	}
+}
+
+// Slots lists the open slots, each linking to the booking page with the
+// slot preselected.
+func (c *CalCom) Slots(ctx context.Context, from, to time.Time) ([]Slot, error) {
+	q := url.Values{
+		"username":      {c.username},
+		"eventTypeSlug": {c.event},
+		"start":         {from.UTC().Format(time.RFC3339)},
+		"end":           {to.UTC().Format(time.RFC3339)},
+		"timeZone":      {"UTC"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+"/v2/slots?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("cal-api-version", "2024-09-04")
+	var resp struct {
+		Data map[string][]struct {
+			Start time.Time `json:"start"`
+		} `json:"data"`
+	}
+	if err := getJSON(req, &resp); err != nil {
+		return nil, err
+	}
+
+	page := c.siteURL + "/" + url.PathEscape(c.username) + "/" + url.PathEscape(c.event)
+	var slots []Slot
+	for _, day := range resp.Data {
+		for _, s := range day {
+			q := url.Values{
+				"date": {s.Start.UTC().Format(time.DateOnly)},
+				"slot": {s.Start.UTC().Format("2006-01-02T15:04:05.000Z")},
+			}
+			slots = append(slots, Slot{Start: s.Start, URL: page + "?" + q.Encode()})
+		}
+	}
+	slices.SortFunc(slots, func(a, b Slot) int { return a.Start.Compare(b.Start) })
+	return slots, nil
+}
@@ -0,0 +1,64 @@
+package booking
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// calendlyMaxRange is the longest range the available times endpoint
+// accepts in one request.
+const calendlyMaxRange = 7 * 24 * time.Hour
+
+// Calendly reads the open slots of a Calendly event type.
+type Calendly struct {
+	token     string
+	eventType string
+	apiURL    string
+}
+
+// NewCalendly returns a provider for the event type with the given URI,
+// e.g. "https://api.calendly.com/event_types/AAAA", using a personal access
+// token.
+func NewCalendly(token, eventType string) *Calendly {
+	return &Calendly{token: token, eventType: eventType, apiURL: "https://api.calendly.com"}
+}
+
+// Slots lists the open slots, each linking to its Calendly scheduling page.
+// Ranges longer than a week are fetched a week at a time.
+func (c *Calendly) Slots(ctx context.Context, from, to time.Time) ([]Slot, error) {
+	var slots []Slot
+	for start := from; start.Before(to); start = start.Add(calendlyMaxRange) {
+		end := start.Add(calendlyMaxRange)
+		if end.After(to) {
+			end = to
+		}
+		q := url.Values{
+			"event_type": {c.eventType},
+			"start_time": {start.UTC().Format(time.RFC3339)},
+			"end_time":   {end.UTC().Format(time.RFC3339)},
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+"/event_type_available_times?"+q.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		var resp struct {
+			Collection []struct {
+				Status        string    `json:"status"`
+				StartTime     time.Time `json:"start_time"`
+				SchedulingURL string    `json:"scheduling_url"`
+			} `json:"collection"`
+		}
+		if err := getJSON(req, &resp); err != nil {
+			return nil, err
+		}
+		for _, s := range resp.Collection {
+			if s.Status == "available" {
+				slots = append(slots, Slot{Start: s.StartTime, URL: s.SchedulingURL})
+			}
+		}
+	}
+	return slots, nil
+}
@@ -0,0 +1,110 @@
+// Package books keeps the reading list shown in the bookshelf section. It
+// is filled from a Goodreads CSV export or an Open Library reading log.
+package books
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/db"
+)
+
+// Shelves that are synced. Other shelves, such as want-to-read, are
+// ignored.
+const (
+	ShelfReading = "currently-reading"
+	ShelfRead    = "read"
+)
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS books (
+		source TEXT NOT NULL,
+		id TEXT NOT NULL,
+		shelf TEXT NOT NULL,
+		title TEXT NOT NULL,
+		author TEXT NOT NULL,
+		cover TEXT NOT NULL,
+		url TEXT NOT NULL,
+		rating INTEGER NOT NULL,
+		finished INTEGER NOT NULL,
+		PRIMARY KEY (source, id)
+	)`,
+}
+
+// Book is an entry on one of the synced shelves.
+type Book struct {
+	ID     string `json:"id"`
+	Shelf  string `json:"shelf"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	Cover  string `json:"cover,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Rating int    `json:"rating,omitempty"` // 1-5, 0 when unrated
+	// Finished is when a read book was finished, or zero if unknown.
+	Finished time.Time `json:"finished,omitzero"`
+}
+
+// Store persists the bookshelf in SQLite.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates the table if needed.
+func NewStore(ctx context.Context, database *sql.DB) (*Store, error) {
+	if err := db.Migrate(ctx, database, schema...); err != nil {
+		return nil, fmt.Errorf("books: %w", err)
+	}
+	return &Store{db: database}, nil
+}
+
+// Replace swaps every book previously imported from source for list, so
+// books removed from the shelves disappear too.
+func (s *Store) Replace(ctx context.Context, source string, list []Book) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("replace books: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM books WHERE source = ?`, source); err != nil {
+		return fmt.Errorf("replace books: %w", err)
+	}
+	for _, b := range list {
+		var finished int64
+		if !b.Finished.IsZero() {
+			finished = b.Finished.Unix()
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT OR REPLACE INTO books (source, id, shelf, title, author, cover, url, rating, finished)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			source, b.ID, b.Shelf, b.Title, b.Author, b.Cover, b.URL, b.Rating, finished); err != nil {
+			return fmt.Errorf("replace books: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Shelf returns the books on shelf, most recently finished first.
+func (s *Store) Shelf(ctx context.Context, shelf string, limit int) ([]Book, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, shelf, title, author, cover, url, rating, finished FROM books
+		WHERE shelf = ? ORDER BY finished DESC, title LIMIT ?`, shelf, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query books: %w", err)
+	}
+	defer rows.Close()
+	var list []Book
+	for rows.Next() {
+		var b Book
+		var finished int64
+		if err := rows.Scan(&b.ID, &b.Shelf, &b.Title, &b.Author, &b.Cover, &b.URL, &b.Rating, &finished); err != nil {
+			return nil, fmt.Errorf("query books: %w", err)
+		}
+		if finished > 0 {
+			b.Finished = time.Unix(finished, 0).UTC()
+		}
+		list = append(list, b)
+	}
+	return list, rows.Err()
+}
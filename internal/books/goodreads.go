@@ -0,0 +1,80 @@
+package books
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseGoodreads reads the CSV from Goodreads' "Export Library" and returns
+// the books on the currently-reading and read shelves.
+func ParseGoodreads(r io.Reader) ([]Book, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read goodreads export: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, h := range header {
+		col[strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))] = i
+	}
+	for _, name := range []string{"Book Id", "Title", "Author", "Exclusive Shelf"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("read goodreads export: missing column %q", name)
+		}
+	}
+
+	var list []Book
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read goodreads export: %w", err)
+		}
+		get := func(name string) string {
+			if i, ok := col[name]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		shelf := get("Exclusive Shelf")
+		if shelf != ShelfReading && shelf != ShelfRead {
+			continue
+		}
+		b := Book{
+			ID:     get("Book Id"),
+			Shelf:  shelf,
+			Title:  get("Title"),
+			Author: get("Author"),
+			URL:    "https://www.goodreads.com/book/show/" + get("Book Id"),
+		}
+		if b.ID == "" || b.Title == "" {
+			return nil, fmt.Errorf("read goodreads export: line %d: missing book id or title", line)
+		}
+		b.Rating, _ = strconv.Atoi(get("My Rating"))
+		// The export has no covers; Open Library serves them by ISBN.
+		if n := unwrapISBN(get("ISBN13")); n != "" {
+			b.Cover = coverURL("isbn", n)
+		} else if n := unwrapISBN(get("ISBN")); n != "" {
+			b.Cover = coverURL("isbn", n)
+		}
+		if t, err := time.Parse("2006/01/02", get("Date Read")); err == nil {
+			b.Finished = t
+		}
+		list = append(list, b)
+	}
+	return list, nil
+}
+
+// unwrapISBN unwraps the spreadsheet-safe ="0123456789" form used by the
+// export.
+func unwrapISBN(s string) string {
+	return strings.Trim(s, `="`)
+}
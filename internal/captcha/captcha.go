@@ -0,0 +1,130 @@
+// Package captcha verifies the CAPTCHA widgets of Cloudflare Turnstile and
+// hCaptcha, whose tokens are checked with the provider before a form is
+// accepted.
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrFailed is returned when the provider doesn't accept the token.
+var ErrFailed = errors.New("captcha: verification failed")
+
+// provider describes a CAPTCHA service.
+type provider struct {
+	verifyURL string
+	widget    Widget
+}
+
+var providers = map[string]provider{
+	"turnstile": {
+		verifyURL: "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+		widget: Widget{
+			Script: "https://challenges.cloudflare.com/turnstile/v0/api.js",
+			Class:  "cf-turnstile",
+			Global: "turnstile",
+			Field:  "cf-turnstile-response",
+		},
+	},
+	"hcaptcha": {
+		verifyURL: "https://api.hcaptcha.com/siteverify",
+		widget: Widget{
+			Script: "https://js.hcaptcha.com/1/api.js",
+			Class:  "h-captcha",
+			Global: "hcaptcha",
+			Field:  "h-captcha-response",
+		},
+	},
+}
+
+// Widget is what a page needs to show the CAPTCHA: the provider's script,
+// the class of the element it renders into, the script's global object,
+// the form field it fills in, and the site key. The zero Widget means no
+// CAPTCHA.
+type Widget struct {
+	Script  string
+	Class   string
+	Global  string
+	Field   string
+	SiteKey string
+}
+
+// Verifier checks CAPTCHA tokens with a provider.
+type Verifier struct {
+	widget    Widget
+	verifyURL string
+	secret    string
+	client    *http.Client
+}
+
+// New returns a Verifier for the named provider, "turnstile" or
+// "hcaptcha", with the site's keys.
+func New(name, siteKey, secret string) (*Verifier, error) {
+	p, ok := providers[name]
+	if !ok {
+		names := make([]string, 0, len(providers))
+		for n := range providers {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("captcha: unknown provider %q (want %s)", name, strings.Join(names, " or "))
+	}
+	if siteKey == "" || secret == "" {
+		return nil, fmt.Errorf("captcha: %s needs a site key and a secret key", name)
+	}
+	w := p.widget
+	w.SiteKey = siteKey
+	return &Verifier{widget: w, verifyURL: p.verifyURL, secret: secret, client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+// Widget returns the widget to show, or the zero Widget for a nil
+// Verifier.
+func (v *Verifier) Widget() Widget {
+	if v == nil {
+		return Widget{}
+	}
+	return v.widget
+}
+
+// Verify checks the token the widget added to form, sent from remoteIP.
+// It returns ErrFailed, wrapped with the provider's error codes, when the
+// token is missing or rejected.
+func (v *Verifier) Verify(ctx context.Context, form url.Values, remoteIP string) error {
+	token := form.Get(v.widget.Field)
+	if token == "" {
+		return fmt.Errorf("%w: missing token", ErrFailed)
+	}
+	vals := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		vals.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(vals.Encode()))
+	if err != nil {
+		return fmt.Errorf("captcha: verify: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("captcha: verify: %w", err)
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("captcha: verify: %s", resp.Status)
+	}
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrFailed, strings.Join(result.ErrorCodes, ", "))
+	}
+	return nil
+}
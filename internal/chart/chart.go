@@ -0,0 +1,49 @@
+// Package chart renders small server-side SVG charts for the admin pages.
+package chart
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+)
+
+// Point is one bar of a chart.
+type Point struct {
+	Label string
+	Value int
+	// Secondary is drawn as a darker inner bar, e.g. visitors within views.
+	Secondary int
+}
+
+// Bars renders points as a vertical bar chart width×height pixels in size.
+// Each bar carries a <title> so hovering shows its label and values.
+func Bars(points []Point, width, height int) template.HTML {
+	if len(points) == 0 {
+		return ""
+	}
+	const axis = 16 // room for the first/last labels
+	maxV := 1
+	for _, p := range points {
+		maxV = max(maxV, p.Value, p.Secondary)
+	}
+	plotH := float64(height - axis)
+	slot := float64(width) / float64(len(points))
+	barW := max(slot*0.8, 1)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg class="chart" xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="100%%" role="img">`, width, height)
+	for i, p := range points {
+		x := float64(i)*slot + (slot-barW)/2
+		h := plotH * float64(p.Value) / float64(maxV)
+		h2 := plotH * float64(p.Secondary) / float64(maxV)
+		fmt.Fprintf(&sb, `<g><title>%s: %d / %d</title>`, html.EscapeString(p.Label), p.Value, p.Secondary)
+		fmt.Fprintf(&sb, `<rect class="chart-bar" x="%.1f" y="%.1f" width="%.1f" height="%.1f"/>`, x, plotH-h, barW, h)
+		fmt.Fprintf(&sb, `<rect class="chart-bar-secondary" x="%.1f" y="%.1f" width="%.1f" height="%.1f"/>`, x, plotH-h2, barW, h2)
+		sb.WriteString(`</g>`)
+	}
+	fmt.Fprintf(&sb, `<text class="chart-label" x="0" y="%d">%s</text>`, height-2, html.EscapeString(points[0].Label))
+	fmt.Fprintf(&sb, `<text class="chart-label" x="%d" y="%d" text-anchor="end">%s</text>`, width, height-2, html.EscapeString(points[len(points)-1].Label))
+	sb.WriteString(`</svg>`)
+	return template.HTML(sb.String())
+}
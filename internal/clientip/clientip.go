@@ -0,0 +1,66 @@
+// Package clientip determines the address of the client behind a request.
+package clientip
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// FromRequest returns the client address of r. Forwarding headers
+// (X-Forwarded-For, then X-Real-IP) are only honored when the direct peer is
+// a loopback or private address, i.e. a reverse proxy on the same host or
+// network; otherwise they could be spoofed by the client.
+func FromRequest(r *http.Request) netip.Addr {
+	peer := remoteAddr(r)
+	if !peer.IsValid() || !(peer.IsLoopback() || peer.IsPrivate()) {
+		return peer
+	}
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		// The right-most address not belonging to our own proxies is the
+		// one the outermost proxy saw.
+		parts := strings.Split(xff, ",")
+		for i := len(parts) - 1; i >= 0; i-- {
+			a, err := netip.ParseAddr(strings.TrimSpace(parts[i]))
+			if err != nil {
+				break
+			}
+			a = a.Unmap()
+			if !(a.IsLoopback() || a.IsPrivate()) || i == 0 {
+				return a
+			}
+		}
+	}
+	if a, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return a.Unmap()
+	}
+	return peer
+}
+
+func remoteAddr(r *http.Request) netip.Addr {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	a, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return a.Unmap()
+}
+
+// Truncate zeroes the host part of a: IPv4 addresses keep their /24 and
+// IPv6 addresses their /48, which is enough for rough aggregation without
+// identifying a single device.
+func Truncate(a netip.Addr) netip.Addr {
+	bits := 48
+	if a.Is4() {
+		bits = 24
+	}
+	p, err := a.Prefix(bits)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return p.Addr()
+}
@@ -0,0 +1,124 @@
+// Package config loads the site's settings from a YAML file, the
+// environment and command-line flags. Settings are named by their
+// environment variables, such as PORT or SMTP_HOST, so each source can set
+// any of them.
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Settings are setting values by environment variable name.
+type Settings map[string]string
+
+// Load reads the YAML file at path. Keys are matched case-insensitively to
+// the environment variables, with dashes as underscores, and nested maps
+// join their keys: smtp: {host: x} sets SMTP_HOST. Lists become
+// comma-separated values.
+func Load(path string) (Settings, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	s := make(Settings)
+	if err := s.flatten("", doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s Settings) flatten(prefix string, m map[string]any) error {
+	for k, v := range m {
+		key := Key(k)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			if err := s.flatten(key, v); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				str, err := scalar(item)
+				if err != nil {
+					return fmt.Errorf("%s: %w", key, err)
+				}
+				items[i] = str
+			}
+			s[key] = strings.Join(items, ",")
+		default:
+			str, err := scalar(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			s[key] = str
+		}
+	}
+	return nil
+}
+
+func scalar(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("unsupported value %v", v)
+}
+
+// Key returns the environment variable name of a file key or flag name,
+// such as BASE_URL for base-url.
+func Key(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// String lists the settings as KEY=VALUE, sorted, for flag.Value.
+func (s Settings) String() string {
+	var kv []string
+	for k, v := range s {
+		kv = append(kv, k+"="+v)
+	}
+	slices.Sort(kv)
+	return strings.Join(kv, ",")
+}
+
+// Set parses a KEY=VALUE flag, so Settings can collect repeated -set flags.
+func (s Settings) Set(kv string) error {
+	k, v, ok := strings.Cut(kv, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("want KEY=VALUE, got %q", kv)
+	}
+	s[Key(k)] = v
+	return nil
+}
+
+// Getenv returns a lookup of the settings that takes flags over the
+// environment over file. Either may be nil.
+func Getenv(file, flags Settings) func(key string) string {
+	return func(key string) string {
+		if v, ok := flags[key]; ok {
+			return v
+		}
+		if v, ok := os.LookupEnv(key); ok {
+			return v
+		}
+		return file[key]
+	}
+}
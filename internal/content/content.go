@@ -0,0 +1,373 @@
+// Package content defines the site's content: the records of the data
+// files and the page data the templates render.
+package content
+
+import (
+	"html/template"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fpatron/portfolio/internal/blog"
+	"github.com/fpatron/portfolio/internal/books"
+	"github.com/fpatron/portfolio/internal/captcha"
+	"github.com/fpatron/portfolio/internal/pkgstats"
+	"github.com/fpatron/portfolio/internal/shortlinks"
+	"github.com/fpatron/portfolio/internal/talks"
+)
+
+// Project represents a portfolio project loaded from data/projects.json.
+type Project struct {
+	Slug        string   `json:"slug"` // derived from Title when empty
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Link        string   `json:"link"`
+	Image       string   `json:"image"` // site path or remote URL
+	// Date is when the project was released or last updated, written like
+	// the dates of data/experience.json: "Jul 2023" or "2018".
+	Date string `json:"date,omitempty"`
+	// Package is the published package, "go:<module>" or "npm:<name>".
+	Package string `json:"package,omitempty"`
+
+	// Set from the repository sync when Link is a synced repository.
+	// Source is the code host, e.g. "github" or "codeberg".
+	Source   string    `json:"source,omitempty"`
+	Stars    int       `json:"stars,omitempty"`
+	Language string    `json:"language,omitempty"`
+	PushedAt time.Time `json:"pushed_at,omitzero"`
+	// Synced is true for projects that come from the repository sync
+	// rather than projects.json.
+	Synced bool `json:"synced,omitempty"`
+
+	// Set from the package statistics when Package is set.
+	Version    string `json:"version,omitempty"`
+	Downloads  int    `json:"weekly_downloads,omitempty"`
+	PackageURL string `json:"package_url,omitempty"`
+
+	// Set from the like counts when likes are enabled.
+	Likes *Likes `json:"likes,omitempty"`
+}
+
+// Likes is how many visitors liked a project, and whether the visitor a
+// response is for is one of them.
+type Likes struct {
+	Count int  `json:"count"`
+	Liked bool `json:"liked"`
+}
+
+// SortDate returns the later of the project's Date and its last push, used
+// to order projects chronologically. Undated projects sort last.
+func (p Project) SortDate() time.Time {
+	t, _ := ParseLooseDate(p.Date)
+	if p.PushedAt.After(t) {
+		return p.PushedAt
+	}
+	return t
+}
+
+// PackageRegistry returns the registry part of p.Package, "go" or "npm".
+func (p Project) PackageRegistry() string {
+	registry, _, _ := pkgstats.Parse(p.Package)
+	return registry
+}
+
+// Interest represents a personal interest loaded from data/interests.json.
+type Interest struct {
+	Emoji       string `json:"emoji"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+}
+
+// SkillCategory represents a labeled group of skills loaded from data/skills.json.
+type SkillCategory struct {
+	Category string   `json:"category"`
+	Skills   []string `json:"skills"`
+}
+
+// Experience represents a single entry in data/experience.json.
+type Experience struct {
+	Role        string   `json:"role"`
+	Company     string   `json:"company"`
+	CompanyURL  string   `json:"company_url"`
+	Logo        string   `json:"logo"`
+	StartDate   string   `json:"start_date"`
+	EndDate     string   `json:"end_date"`
+	Dates       []string `json:"dates,omitempty"`
+	Location    string   `json:"location"`
+	Description []string `json:"description"`
+	Type        string   `json:"type"` // "work" or "education"
+}
+
+// DateRange formats the entry's dates as the timeline shows them.
+func (e Experience) DateRange() string {
+	if len(e.Dates) > 0 {
+		return strings.Join(e.Dates, ", ")
+	}
+	if e.EndDate == "" {
+		return e.StartDate
+	}
+	return e.StartDate + " – " + e.EndDate
+}
+
+// SortDate returns the most recent date mentioned by the entry, used to order
+// experience chronologically. Unparseable dates sort last.
+func (e Experience) SortDate() time.Time {
+	candidates := append([]string{e.EndDate, e.StartDate}, e.Dates...)
+	var latest time.Time
+	for _, s := range candidates {
+		if t, ok := ParseLooseDate(s); ok && t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+var seasonMonths = map[string]time.Month{
+	"winter": time.January,
+	"spring": time.April,
+	"summer": time.July,
+	"fall":   time.October,
+	"autumn": time.October,
+}
+
+// ParseLooseDate understands the free-form dates used in data/experience.json:
+// "Jul 2023", "July 2023", "Summer 2021", "2018" and "Present".
+func ParseLooseDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "present") {
+		return time.Now(), true
+	}
+	for _, layout := range []string{"Jan 2006", "January 2006", "2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	if season, year, ok := strings.Cut(s, " "); ok {
+		if m, ok := seasonMonths[strings.ToLower(season)]; ok {
+			if y, err := strconv.Atoi(year); err == nil {
+				return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// About holds profile data loaded from data/about.json.
+type About struct {
+	Name              string `json:"name"`
+	Tagline           string `json:"tagline"`
+	Bio               string `json:"bio"`
+	Location          string `json:"location"`
+	Availability      bool   `json:"availability"`
+	YearsOfExperience int    `json:"years_of_experience"`
+	Email             string `json:"email"`
+	GitHub            string `json:"github"`
+	LinkedIn          string `json:"linkedin"`
+	X                 string `json:"x"`
+	ProfilePhoto      string `json:"profile_photo"`
+}
+
+// VCard returns a vCard 4.0 of the site's owner, whose site is at url:
+// their name, title, email, location, photo and the URLs of the site and
+// their profiles.
+func (a About) VCard(url string) string {
+	esc := strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\n", `\n`).Replace
+	given, family := a.Name, ""
+	if i := strings.LastIndex(a.Name, " "); i >= 0 {
+		given, family = a.Name[:i], a.Name[i+1:]
+	}
+	lines := []string{
+		"BEGIN:VCARD",
+		"VERSION:4.0",
+		"KIND:individual",
+		"N:" + esc(family) + ";" + esc(given) + ";;;",
+		"FN:" + esc(a.Name),
+	}
+	if a.Tagline != "" {
+		lines = append(lines, "TITLE:"+esc(a.Tagline))
+	}
+	if a.Email != "" {
+		lines = append(lines, "EMAIL:"+a.Email)
+	}
+	if a.ProfilePhoto != "" {
+		photo := a.ProfilePhoto
+		if strings.HasPrefix(photo, "/") {
+			photo = strings.TrimSuffix(url, "/") + photo
+		}
+		lines = append(lines, "PHOTO:"+photo)
+	}
+	lines = append(lines, "URL:"+url)
+	for _, u := range []string{a.GitHub, a.LinkedIn, a.X} {
+		if u != "" {
+			lines = append(lines, "URL:"+u)
+		}
+	}
+	if a.Location != "" {
+		lines = append(lines, "ADR:;;;"+esc(a.Location)+";;;")
+	}
+	lines = append(lines, "END:VCARD")
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(foldLine(l))
+		b.WriteString("\r\n")
+	}
+	return b.String()
+}
+
+// foldLine folds a vCard content line into lines of at most 75 octets,
+// each continued with a space, without splitting a UTF-8 character.
+func foldLine(l string) string {
+	var b strings.Builder
+	limit := 75
+	for len(l) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(l[i]) {
+			i--
+		}
+		b.WriteString(l[:i])
+		b.WriteString("\r\n ")
+		l = l[i:]
+		limit = 74 // after the leading space
+	}
+	b.WriteString(l)
+	return b.String()
+}
+
+// Section is a part of the home page, as the menu and the page render it.
+type Section struct {
+	// Name is the id of the section's element on the home page.
+	Name string
+	// Nav is the section's link in the menu; none when its label is empty.
+	Nav NavLink
+	// Partial, when set, is the path the home page loads the section from
+	// once it scrolls into view. Loading shows an indicator until then;
+	// sections that may be empty go without.
+	Partial string
+	Loading bool
+}
+
+// NavLink is an entry of the menu, linking to a section of the home page.
+type NavLink struct {
+	Label string
+	Href  string
+	// Button styles the link as a call to action.
+	Button bool
+}
+
+// PageData is passed to all templates.
+type PageData struct {
+	About      About           `json:"about"`
+	Projects   []Project       `json:"projects"`
+	Interests  []Interest      `json:"interests"`
+	Skills     []SkillCategory `json:"skills"`
+	Experience []Experience    `json:"experience"`
+	// Talks and Books come from data/talks.json and data/books.json, which
+	// "portfolio fetch" writes.
+	Talks []talks.Talk `json:"talks,omitempty"`
+	Books []books.Book `json:"books,omitempty"`
+	// ShortLinks come from the optional data/links.json. More can be added
+	// from the admin page.
+	ShortLinks []shortlinks.Link `json:"-"`
+	// Posts are the blog posts of content/posts, newest first.
+	Posts []blog.Post `json:"-"`
+
+	// Project is set when rendering a single project's page.
+	Project *Project `json:"project,omitempty"`
+	// Post is set when rendering a blog post's page.
+	Post *blog.Post `json:"post,omitempty"`
+	// BaseURL is the site origin and URL the absolute URL of the page
+	// being rendered.
+	BaseURL string `json:"-"`
+	URL     string `json:"-"`
+	// Meta describes the page to search engines and link previews; see
+	// WithMeta.
+	Meta Meta `json:"-"`
+
+	// AnalyticsScript is injected into the page head when set.
+	AnalyticsScript template.HTML `json:"-"`
+	// IndieAuth adds the IndieAuth discovery links to the page head.
+	IndieAuth bool `json:"-"`
+	// Sections are the enabled sections, which make up the menu and the
+	// home page.
+	Sections []Section `json:"-"`
+	// DisabledRoutes are the paths that answer with the 404 page, and
+	// those under them; see Serves.
+	DisabledRoutes []string `json:"-"`
+	// Lang is the language of the page and Languages those the site is
+	// served in, the default first.
+	Lang      string   `json:"-"`
+	Languages []string `json:"-"`
+	// Theme is the color theme the visitor picked, "dark" or "light", or
+	// "" to follow their browser.
+	Theme string `json:"-"`
+	// ContactForm is the contact form as rendered on the page, empty
+	// unless it is shown again after a rejected submission.
+	ContactForm ContactForm `json:"-"`
+}
+
+// ContactForm is the state of the contact form.
+type ContactForm struct {
+	Name    string
+	Email   string
+	Message string
+	// Token is set when the form is rendered with its token rather than
+	// fetching one when it comes into view.
+	Token string
+	// Captcha is the CAPTCHA widget to show, if any.
+	Captcha captcha.Widget
+	// Error is about the whole form and Errors about each field.
+	Error  string
+	Errors ContactFormErrors
+}
+
+// ContactFormErrors are the messages of the contact form's invalid fields.
+type ContactFormErrors struct {
+	Name    string
+	Email   string
+	Message string
+}
+
+// Serves reports whether the site serves the page a link to path, such as
+// "/blog" or "/#contact", leads to, rather than the page being one of
+// DisabledRoutes or under one.
+func (d PageData) Serves(path string) bool {
+	path, _, _ = strings.Cut(path, "#")
+	path, _, _ = strings.Cut(path, "?")
+	return !RouteDisabled(d.DisabledRoutes, path)
+}
+
+// RouteDisabled reports whether path is one of disabled or lies under one.
+// "/" only disables the home page.
+func RouteDisabled(disabled []string, path string) bool {
+	return slices.ContainsFunc(disabled, func(prefix string) bool {
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	})
+}
+
+// HasSection reports whether the section called name is enabled.
+func (d PageData) HasSection(name string) bool {
+	return slices.ContainsFunc(d.Sections, func(s Section) bool { return s.Name == name })
+}
+
+// FindProject returns the project with the given slug.
+func (d PageData) FindProject(slug string) (Project, bool) {
+	for _, p := range d.Projects {
+		if p.Slug == slug {
+			return p, true
+		}
+	}
+	return Project{}, false
+}
+
+// FindPost returns the post with the given slug.
+func (d PageData) FindPost(slug string) (blog.Post, bool) {
+	for _, p := range d.Posts {
+		if p.Slug == slug {
+			return p, true
+		}
+	}
+	return blog.Post{}, false
+}
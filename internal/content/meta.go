@@ -0,0 +1,176 @@
+package content
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Meta is what search engines and link previews read from a page's head:
+// its Open Graph and Twitter card tags and its JSON-LD.
+type Meta struct {
+	Title       string
+	Description string
+	URL         string // canonical
+	// Image is the absolute URL of the preview image: the page's social
+	// card from /og/, or a project's own image.
+	Image string
+	// Type is the og:type: "profile", "website" or "article".
+	Type string
+	// TwitterHandle is the X account of the site's owner, with its @.
+	TwitterHandle string
+	// StructuredData are the JSON-LD blocks of the page.
+	StructuredData []any
+}
+
+// WithMeta returns d with its Meta set for the page it renders: the post,
+// the project or else the home page at d.URL. BaseURL and URL must be set.
+func (d PageData) WithMeta() PageData {
+	a := d.About
+	m := Meta{
+		Title:         a.Name,
+		Description:   a.Tagline,
+		URL:           d.URL,
+		Image:         d.BaseURL + "/og/home.png",
+		Type:          "profile",
+		TwitterHandle: xHandle(a.X),
+	}
+	if a.Tagline != "" {
+		m.Title += " — " + a.Tagline
+	}
+	if a.Bio != "" {
+		m.Description = a.Bio
+	}
+	switch {
+	case d.Post != nil:
+		p := d.Post
+		m.Title, m.Description, m.Type = p.Title, p.Summary, "article"
+		m.Image = d.BaseURL + "/og/blog/" + p.Slug + ".png"
+		m.StructuredData = []any{ldCreativeWork{
+			Context:       schemaOrg,
+			Type:          "BlogPosting",
+			Name:          p.Title,
+			Headline:      p.Title,
+			Description:   p.Summary,
+			URL:           d.URL,
+			Keywords:      strings.Join(p.Tags, ", "),
+			DatePublished: ldDate(p.Date),
+			Author:        &ldPerson{Type: "Person", Name: a.Name, URL: d.BaseURL + "/"},
+		}}
+	case d.Project != nil:
+		p := d.Project
+		m.Title, m.Description, m.Type = p.Title, p.Description, "website"
+		m.Image = d.BaseURL + "/og/projects/" + p.Slug + ".png"
+		if p.Image != "" {
+			m.Image = d.absolute(p.Image)
+		}
+		m.StructuredData = []any{d.creativeWork(*p)}
+	default:
+		m.StructuredData = []any{d.person()}
+		for _, p := range d.Projects {
+			m.StructuredData = append(m.StructuredData, d.creativeWork(p))
+		}
+	}
+	d.Meta = m
+	return d
+}
+
+const schemaOrg = "https://schema.org"
+
+// ldPerson is a schema.org Person.
+type ldPerson struct {
+	Context     string   `json:"@context,omitempty"`
+	Type        string   `json:"@type"`
+	Name        string   `json:"name"`
+	JobTitle    string   `json:"jobTitle,omitempty"`
+	Description string   `json:"description,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Image       string   `json:"image,omitempty"`
+	Email       string   `json:"email,omitempty"`
+	Address     string   `json:"address,omitempty"`
+	SameAs      []string `json:"sameAs,omitempty"`
+}
+
+// ldCreativeWork is a schema.org CreativeWork, or one of its kinds such as
+// BlogPosting.
+type ldCreativeWork struct {
+	Context       string    `json:"@context,omitempty"`
+	Type          string    `json:"@type"`
+	Name          string    `json:"name"`
+	Headline      string    `json:"headline,omitempty"`
+	Description   string    `json:"description,omitempty"`
+	URL           string    `json:"url"`
+	Image         string    `json:"image,omitempty"`
+	Keywords      string    `json:"keywords,omitempty"`
+	SameAs        string    `json:"sameAs,omitempty"`
+	DatePublished string    `json:"datePublished,omitempty"`
+	DateModified  string    `json:"dateModified,omitempty"`
+	Author        *ldPerson `json:"author,omitempty"`
+}
+
+// person describes the site's owner from data/about.json.
+func (d PageData) person() ldPerson {
+	a := d.About
+	p := ldPerson{
+		Context:     schemaOrg,
+		Type:        "Person",
+		Name:        a.Name,
+		JobTitle:    a.Tagline,
+		Description: a.Bio,
+		URL:         d.BaseURL + "/",
+		Image:       d.absolute(a.ProfilePhoto),
+		Address:     a.Location,
+	}
+	if a.Email != "" {
+		p.Email = "mailto:" + a.Email
+	}
+	for _, u := range []string{a.GitHub, a.LinkedIn, a.X} {
+		if u != "" {
+			p.SameAs = append(p.SameAs, u)
+		}
+	}
+	return p
+}
+
+// creativeWork describes a project from data/projects.json.
+func (d PageData) creativeWork(p Project) ldCreativeWork {
+	w := ldCreativeWork{
+		Context:      schemaOrg,
+		Type:         "CreativeWork",
+		Name:         p.Title,
+		Description:  p.Description,
+		URL:          d.BaseURL + "/projects/" + p.Slug,
+		Keywords:     strings.Join(p.Tags, ", "),
+		SameAs:       p.Link,
+		DateModified: ldDate(p.PushedAt),
+		Author:       &ldPerson{Type: "Person", Name: d.About.Name, URL: d.BaseURL + "/"},
+	}
+	if p.Image != "" {
+		w.Image = d.absolute(p.Image)
+	}
+	return w
+}
+
+// absolute resolves a site path against BaseURL, leaving URLs alone.
+func (d PageData) absolute(path string) string {
+	if strings.HasPrefix(path, "/") {
+		return d.BaseURL + path
+	}
+	return path
+}
+
+func ldDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// xHandle returns "@name" for a profile URL such as https://x.com/name.
+func xHandle(profile string) string {
+	u, err := url.Parse(profile)
+	if err != nil || u.Path == "" || u.Path == "/" {
+		return ""
+	}
+	return "@" + strings.TrimPrefix(strings.Trim(u.Path, "/"), "@")
+}
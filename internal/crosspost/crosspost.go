@@ -0,0 +1,71 @@
+// Package crosspost publishes copies of the blog's posts to dev.to and
+// Medium. The copies name the site's post as their canonical URL, so search
+// engines credit the original.
+package crosspost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Article is a post to publish.
+type Article struct {
+	Title   string
+	Summary string
+	Tags    []string
+	// Markdown is the body, with site-relative links made absolute.
+	Markdown string
+	// CanonicalURL is the address of the post on the site.
+	CanonicalURL string
+}
+
+// Target is a blogging platform.
+type Target interface {
+	// Name is the platform's front matter key, such as "devto".
+	Name() string
+	// Publish publishes a and returns the URL of the copy.
+	Publish(ctx context.Context, a Article) (string, error)
+}
+
+// firstTags returns at most n of tags, never nil so it encodes as a list.
+func firstTags(tags []string, n int) []string {
+	return append([]string{}, tags[:min(len(tags), n)]...)
+}
+
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// call sends a JSON request and decodes the response into out.
+func call(ctx context.Context, method, url string, auth func(*http.Request), in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	auth(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", method, req.URL.Path, resp.Status, bytes.TrimSpace(msg))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,44 @@
+package crosspost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// DevTo publishes articles through the dev.to (Forem) API.
+type DevTo struct {
+	key     string
+	baseURL string
+}
+
+// NewDevTo returns a DevTo target using an API key.
+func NewDevTo(key string) *DevTo {
+	return &DevTo{key: key, baseURL: "https://dev.to"}
+}
+
+// Name returns "devto".
+func (d *DevTo) Name() string { return "devto" }
+
+// Publish publishes a with at most four tags, the most dev.to accepts.
+func (d *DevTo) Publish(ctx context.Context, a Article) (string, error) {
+	article := map[string]any{
+		"title":         a.Title,
+		"body_markdown": a.Markdown,
+		"published":     true,
+		"canonical_url": a.CanonicalURL,
+		"description":   a.Summary,
+		"tags":          firstTags(a.Tags, 4),
+	}
+	var created struct {
+		URL string `json:"url"`
+	}
+	auth := func(req *http.Request) { req.Header.Set("api-key", d.key) }
+	if err := call(ctx, http.MethodPost, d.baseURL+"/api/articles", auth, map[string]any{"article": article}, &created); err != nil {
+		return "", err
+	}
+	if created.URL == "" {
+		return "", errors.New("dev.to returned no article URL")
+	}
+	return created.URL, nil
+}
@@ -0,0 +1,58 @@
+package crosspost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Medium publishes posts through the Medium API with an integration token.
+type Medium struct {
+	token   string
+	baseURL string
+}
+
+// NewMedium returns a Medium target using an integration token.
+func NewMedium(token string) *Medium {
+	return &Medium{token: token, baseURL: "https://api.medium.com"}
+}
+
+// Name returns "medium".
+func (m *Medium) Name() string { return "medium" }
+
+func (m *Medium) auth(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+m.token)
+}
+
+// Publish publishes a as the token's user, with at most three tags, the
+// most Medium accepts.
+func (m *Medium) Publish(ctx context.Context, a Article) (string, error) {
+	var me struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := call(ctx, http.MethodGet, m.baseURL+"/v1/me", m.auth, nil, &me); err != nil {
+		return "", err
+	}
+	post := map[string]any{
+		"title":         a.Title,
+		"contentFormat": "markdown",
+		"content":       "# " + a.Title + "\n\n" + a.Markdown,
+		"canonicalUrl":  a.CanonicalURL,
+		"tags":          firstTags(a.Tags, 3),
+		"publishStatus": "public",
+	}
+	var created struct {
+		Data struct {
+			URL string `json:"url"`
+		} `json:"data"`
+	}
+	if err := call(ctx, http.MethodPost, m.baseURL+"/v1/users/"+me.Data.ID+"/posts", m.auth, post, &created); err != nil {
+		return "", err
+	}
+	if created.Data.URL == "" {
+		return "", errors.New("medium returned no post URL")
+	}
+	return created.Data.URL, nil
+}
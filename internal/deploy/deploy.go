@@ -0,0 +1,31 @@
+// Package deploy publishes an exported static site to a host: an S3
+// bucket behind CloudFront, Netlify, or a GitHub Pages branch.
+package deploy
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+)
+
+// Target publishes the site exported to a directory.
+type Target interface {
+	Deploy(ctx context.Context, dir string) error
+}
+
+// files lists the files below dir as slash-separated paths relative to it.
+func files(dir string) ([]string, error) {
+	var list []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		list = append(list, filepath.ToSlash(rel))
+		return nil
+	})
+	return list, err
+}
@@ -0,0 +1,89 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitHubPages commits the site as the only commit of a branch, usually
+// gh-pages, and force-pushes it. It runs the git command. GitHub Pages
+// refreshes its CDN when the branch is published.
+type GitHubPages struct {
+	// CNAME, when set, is written to the CNAME file for a custom domain.
+	CNAME string
+
+	remote string
+	branch string
+}
+
+// NewGitHubPages returns a target pushing to branch of remote, a remote
+// name or URL.
+func NewGitHubPages(remote, branch string) *GitHubPages {
+	return &GitHubPages{remote: remote, branch: branch}
+}
+
+// Deploy replaces the branch with the contents of dir. The repository is
+// kept outside dir, so the export stays clean.
+func (g *GitHubPages) Deploy(ctx context.Context, dir string) error {
+	// Without .nojekyll, Pages runs Jekyll, which drops files starting
+	// with an underscore.
+	if err := os.WriteFile(filepath.Join(dir, ".nojekyll"), nil, 0o644); err != nil {
+		return err
+	}
+	if g.CNAME != "" {
+		if err := os.WriteFile(filepath.Join(dir, "CNAME"), []byte(g.CNAME+"\n"), 0o644); err != nil {
+			return err
+		}
+	}
+	remote := g.remote
+	if !strings.Contains(remote, ":") && !strings.Contains(remote, "/") {
+		// A remote name is resolved in the current repository, since the
+		// temporary one has no remotes.
+		url, err := git(ctx, "remote", "get-url", remote)
+		if err != nil {
+			return fmt.Errorf("github pages: git remote: %w", err)
+		}
+		remote = url
+	}
+	gitDir, err := os.MkdirTemp("", "portfolio-pages-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(gitDir)
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	repo := []string{"--git-dir", gitDir, "--work-tree", abs, "-c", "user.name=portfolio", "-c", "user.email=portfolio@localhost"}
+	steps := [][]string{
+		{"init", "--quiet", "--initial-branch", g.branch},
+		{"add", "--all"},
+		{"commit", "--quiet", "--message", "Deploy site"},
+		{"push", "--quiet", "--force", remote, "HEAD:refs/heads/" + g.branch},
+	}
+	for _, args := range steps {
+		if _, err := git(ctx, append(repo, args...)...); err != nil {
+			return fmt.Errorf("github pages: git %s: %w", args[0], err)
+		}
+	}
+	log.Printf("deploy: pushed %s to %s", g.branch, g.remote)
+	return nil
+}
+
+// git runs git with args and returns its trimmed output.
+func git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
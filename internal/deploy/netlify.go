@@ -0,0 +1,120 @@
+package deploy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// NetlifyAPI is the Netlify API origin.
+const NetlifyAPI = "https://api.netlify.com/api/v1"
+
+// Netlify uploads the site to a Netlify site as a zip deploy. Netlify
+// clears its CDN cache itself when the deploy goes live.
+type Netlify struct {
+	site   string
+	token  string
+	api    string
+	client *http.Client
+	// poll is how often the deploy's state is checked.
+	poll time.Duration
+}
+
+// NewNetlify returns a Netlify target for the site ID or name, using a
+// personal access token.
+func NewNetlify(site, token string) *Netlify {
+	return &Netlify{site: site, token: token, api: NetlifyAPI, client: &http.Client{Timeout: 2 * time.Minute}, poll: 2 * time.Second}
+}
+
+type netlifyDeploy struct {
+	ID           string `json:"id"`
+	State        string `json:"state"`
+	ErrorMessage string `json:"error_message"`
+	URL          string `json:"ssl_url"`
+}
+
+// Deploy zips dir, uploads it and waits until Netlify has published it.
+func (n *Netlify) Deploy(ctx context.Context, dir string) error {
+	archive, err := zipDir(dir)
+	if err != nil {
+		return fmt.Errorf("netlify: %w", err)
+	}
+	var d netlifyDeploy
+	if err := n.do(ctx, http.MethodPost, "/sites/"+url.PathEscape(n.site)+"/deploys", archive, &d); err != nil {
+		return fmt.Errorf("netlify: upload: %w", err)
+	}
+	for d.State != "ready" {
+		switch d.State {
+		case "error", "rejected":
+			return fmt.Errorf("netlify: deploy %s %s: %s", d.ID, d.State, d.ErrorMessage)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(n.poll):
+		}
+		if err := n.do(ctx, http.MethodGet, "/deploys/"+url.PathEscape(d.ID), nil, &d); err != nil {
+			return fmt.Errorf("netlify: deploy %s: %w", d.ID, err)
+		}
+	}
+	log.Printf("deploy: netlify deploy %s is live at %s", d.ID, d.URL)
+	return nil
+}
+
+func (n *Netlify) do(ctx context.Context, method, path string, body []byte, v any) error {
+	req, err := http.NewRequestWithContext(ctx, method, n.api+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+n.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/zip")
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// zipDir returns a zip archive of the files below dir.
+func zipDir(dir string) ([]byte, error) {
+	list, err := files(dir)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range list {
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		w, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(b); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,216 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CloudFrontAPI is the CloudFront API origin.
+const CloudFrontAPI = "https://cloudfront.amazonaws.com"
+
+// S3 uploads the site to a bucket, removes the objects the site no longer
+// has and, when Distribution is set, invalidates the CloudFront cache.
+type S3 struct {
+	// Prefix is prepended to every key, e.g. "www/".
+	Prefix string
+	// Distribution is the CloudFront distribution ID serving the bucket.
+	Distribution string
+	// Endpoint replaces the AWS origin, for S3-compatible storage. Objects
+	// are then addressed path-style, as Endpoint/bucket/key.
+	Endpoint string
+	// CloudFront is the CloudFront API origin.
+	CloudFront string
+
+	bucket string
+	region string
+	creds  Credentials
+	client *http.Client
+}
+
+// NewS3 returns an S3 target for bucket in region.
+func NewS3(bucket, region string, creds Credentials) *S3 {
+	return &S3{
+		CloudFront: CloudFrontAPI,
+		bucket:     bucket,
+		region:     region,
+		creds:      creds,
+		client:     &http.Client{Timeout: time.Minute},
+	}
+}
+
+// Deploy uploads the files of dir that differ from the bucket's and deletes
+// the objects below Prefix that dir does not have.
+func (s *S3) Deploy(ctx context.Context, dir string) error {
+	remote, err := s.list(ctx)
+	if err != nil {
+		return err
+	}
+	local, err := files(dir)
+	if err != nil {
+		return err
+	}
+	uploaded, unchanged := 0, 0
+	for _, name := range local {
+		body, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return err
+		}
+		key := s.Prefix + name
+		sum := md5.Sum(body)
+		etag, ok := remote[key]
+		delete(remote, key)
+		// Single-part uploads have the MD5 of their content as ETag.
+		if ok && etag == hex.EncodeToString(sum[:]) {
+			unchanged++
+			continue
+		}
+		if err := s.put(ctx, key, body); err != nil {
+			return err
+		}
+		uploaded++
+	}
+	for key := range remote {
+		if err := s.do(ctx, http.MethodDelete, s.objectURL(key, nil), nil, nil); err != nil {
+			return fmt.Errorf("s3: delete %s: %w", key, err)
+		}
+	}
+	log.Printf("deploy: s3://%s: %d uploaded, %d deleted, %d unchanged", s.bucket, uploaded, len(remote), unchanged)
+	if s.Distribution == "" || uploaded+len(remote) == 0 {
+		return nil
+	}
+	return s.invalidate(ctx)
+}
+
+// objectURL returns the URL of key, or of the bucket when key is empty.
+func (s *S3) objectURL(key string, q url.Values) *url.URL {
+	u := &url.URL{Scheme: "https", Host: s.bucket + ".s3." + s.region + ".amazonaws.com", Path: "/" + key}
+	if s.Endpoint != "" {
+		u, _ = url.Parse(strings.TrimSuffix(s.Endpoint, "/"))
+		u.Path += "/" + s.bucket + "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = q.Encode()
+	return u
+}
+
+// list returns the ETags of the objects below Prefix by key.
+func (s *S3) list(ctx context.Context) (map[string]string, error) {
+	etags := make(map[string]string)
+	q := url.Values{"list-type": {"2"}, "prefix": {s.Prefix}}
+	for {
+		var page struct {
+			Contents []struct {
+				Key  string
+				ETag string
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		var buf bytes.Buffer
+		if err := s.do(ctx, http.MethodGet, s.objectURL("", q), nil, &buf); err != nil {
+			return nil, fmt.Errorf("s3: list %s: %w", s.bucket, err)
+		}
+		if err := xml.Unmarshal(buf.Bytes(), &page); err != nil {
+			return nil, fmt.Errorf("s3: list %s: %w", s.bucket, err)
+		}
+		for _, o := range page.Contents {
+			etags[o.Key] = strings.Trim(o.ETag, `"`)
+		}
+		if !page.IsTruncated {
+			return etags, nil
+		}
+		q.Set("continuation-token", page.NextContinuationToken)
+	}
+}
+
+func (s *S3) put(ctx context.Context, key string, body []byte) error {
+	err := s.do(ctx, http.MethodPut, s.objectURL(key, nil), body, nil, func(h http.Header) {
+		h.Set("Content-Type", contentType(key, body))
+		// Assets are not fingerprinted, so caches must check back soon.
+		h.Set("Cache-Control", "public, max-age=300")
+	})
+	if err != nil {
+		return fmt.Errorf("s3: put %s: %w", key, err)
+	}
+	return nil
+}
+
+// invalidate clears the whole distribution. Pages are reached through
+// several URLs each (/p, /p/ and /p/index.html), so a wildcard is simpler
+// than listing them, and it counts as a single path.
+func (s *S3) invalidate(ctx context.Context) error {
+	batch := fmt.Sprintf(`<InvalidationBatch xmlns="http://cloudfront.amazonaws.com/doc/2020-05-31/"><Paths><Quantity>1</Quantity><Items><Path>/*</Path></Items></Paths><CallerReference>portfolio-%d</CallerReference></InvalidationBatch>`, time.Now().UnixNano())
+	u, err := url.Parse(strings.TrimSuffix(s.CloudFront, "/") + "/2020-05-31/distribution/" + url.PathEscape(s.Distribution) + "/invalidation")
+	if err != nil {
+		return err
+	}
+	// CloudFront is a global service, signed for us-east-1.
+	if err := s.send(ctx, http.MethodPost, u, []byte(batch), nil, "cloudfront", "us-east-1", func(h http.Header) {
+		h.Set("Content-Type", "text/xml")
+	}); err != nil {
+		return fmt.Errorf("cloudfront: invalidate %s: %w", s.Distribution, err)
+	}
+	log.Printf("deploy: invalidated cloudfront distribution %s", s.Distribution)
+	return nil
+}
+
+func (s *S3) do(ctx context.Context, method string, u *url.URL, body []byte, out io.Writer, headers ...func(http.Header)) error {
+	return s.send(ctx, method, u, body, out, "s3", s.region, headers...)
+}
+
+// send makes a signed request and copies a successful response to out.
+func (s *S3) send(ctx context.Context, method string, u *url.URL, body []byte, out io.Writer, service, region string, headers ...func(http.Header)) error {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for _, f := range headers {
+		f(req.Header)
+	}
+	hash := emptyHash
+	if len(body) > 0 {
+		hash = hexHash(body)
+	}
+	req.Header.Set("X-Amz-Content-Sha256", hash)
+	s.creds.sign(req, hash, service, region, time.Now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		_, err = io.Copy(out, resp.Body)
+	}
+	return err
+}
+
+// contentType returns the media type of the file name. The exported API
+// responses have no extension, so JSON is recognized by its content.
+func contentType(name string, body []byte) string {
+	if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+		return t
+	}
+	if json.Valid(body) {
+		return "application/json"
+	}
+	return http.DetectContentType(body)
+}
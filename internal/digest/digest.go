@@ -0,0 +1,82 @@
+// Package digest emails the site owner a weekly analytics summary.
+package digest
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/analytics"
+	"github.com/fpatron/portfolio/internal/mailer"
+)
+
+// Days is the period covered by a digest.
+const Days = 7
+
+// Digest renders the last week's report with the digest.html email template
+// and sends it to To.
+type Digest struct {
+	Analytics *analytics.Recorder
+	Mailer    *mailer.Mailer
+	Templates *template.Template
+	To        string
+	SiteName  string
+	BaseURL   string
+}
+
+// Data is passed to the digest.html template.
+type Data struct {
+	SiteName     string
+	DashboardURL string
+	Report       analytics.Report
+}
+
+// Send builds and emails the digest.
+func (d *Digest) Send(ctx context.Context) error {
+	rep, err := d.Analytics.Report(ctx, Days, 5)
+	if err != nil {
+		return fmt.Errorf("digest: %w", err)
+	}
+	data := Data{
+		SiteName:     d.SiteName,
+		DashboardURL: strings.TrimSuffix(d.BaseURL, "/") + "/admin/stats?days=7",
+		Report:       rep,
+	}
+	var html strings.Builder
+	if err := d.Templates.ExecuteTemplate(&html, "digest.html", data); err != nil {
+		return fmt.Errorf("digest: render: %w", err)
+	}
+	err = d.Mailer.Send(mailer.Message{
+		To:      []string{d.To},
+		Subject: fmt.Sprintf("[%s] Weekly stats: %d views, %d contacts", d.SiteName, rep.Views, rep.Contacts),
+		Text:    text(data),
+		HTML:    html.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("digest: %w", err)
+	}
+	return nil
+}
+
+// text is the plain-text alternative of the email.
+func text(data Data) string {
+	var b strings.Builder
+	r := data.Report
+	fmt.Fprintf(&b, "%s, %s – %s\n\n", data.SiteName, r.From.Format("Jan 2"), r.To.Format("Jan 2"))
+	fmt.Fprintf(&b, "Views: %d\nVisitors: %d\nContacts: %d\nServer errors: %d\n", r.Views, r.Visitors, r.Contacts, r.Errors)
+	for _, section := range []struct {
+		title  string
+		counts []analytics.Count
+	}{{"Top pages", r.TopPages}, {"Top referrers", r.TopReferrers}, {"Errors", r.TopErrors}} {
+		if len(section.counts) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s\n", section.title)
+		for _, c := range section.counts {
+			fmt.Fprintf(&b, "  %6d  %s\n", c.Views, c.Label)
+		}
+	}
+	fmt.Fprintf(&b, "\nDashboard: %s\n", data.DashboardURL)
+	return b.String()
+}
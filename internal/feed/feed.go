@@ -0,0 +1,180 @@
+// Package feed writes RSS 2.0 and Atom feeds.
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// Feed is a feed of Items, newest first.
+type Feed struct {
+	Title       string
+	Description string
+	// Link is the site's home page and Self the URL the feed is served at.
+	Link   string
+	Self   string
+	Author string
+	// Updated is when an item last changed.
+	Updated time.Time
+	Items   []Item
+}
+
+// Item is an entry of a feed. Its Link doubles as its ID.
+type Item struct {
+	Title      string
+	Link       string
+	Summary    string
+	Content    string // HTML, optional
+	Categories []string
+	// Published is zero for items without a date, which Atom dates at the
+	// feed's Updated.
+	Published time.Time
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      rssSelf   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssSelf struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        string   `xml:"guid"`
+	Description string   `xml:"description"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+}
+
+// RSS encodes f as an RSS 2.0 document.
+func RSS(f Feed) ([]byte, error) {
+	doc := rss{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			AtomLink:    rssSelf{Href: f.Self, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	if !f.Updated.IsZero() {
+		doc.Channel.LastBuildDate = f.Updated.Format(time.RFC1123Z)
+	}
+	for _, it := range f.Items {
+		item := rssItem{
+			Title:       it.Title,
+			Link:        it.Link,
+			GUID:        it.Link,
+			Description: it.Summary,
+			Categories:  it.Categories,
+		}
+		if !it.Published.IsZero() {
+			item.PubDate = it.Published.Format(time.RFC1123Z)
+		}
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+	return encode(doc)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published,omitempty"`
+	Updated    string         `xml:"updated"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary,omitempty"`
+	Content    *atomContent   `xml:"content"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// Atom encodes f as an Atom document.
+func Atom(f Feed) ([]byte, error) {
+	updated := f.Updated.UTC().Format(time.RFC3339)
+	doc := atomFeed{
+		Title:   f.Title,
+		ID:      f.Self,
+		Updated: updated,
+		Links:   []atomLink{{Href: f.Self, Rel: "self"}, {Href: f.Link, Rel: "alternate"}},
+		Author:  atomAuthor{Name: f.Author},
+	}
+	for _, it := range f.Items {
+		e := atomEntry{
+			Title:   it.Title,
+			ID:      it.Link,
+			Link:    atomLink{Href: it.Link, Rel: "alternate"},
+			Updated: updated,
+			Summary: it.Summary,
+		}
+		if !it.Published.IsZero() {
+			e.Published = it.Published.UTC().Format(time.RFC3339)
+			e.Updated = e.Published
+		}
+		for _, c := range it.Categories {
+			e.Categories = append(e.Categories, atomCategory{Term: c})
+		}
+		if it.Content != "" {
+			e.Content = &atomContent{Type: "html", Body: it.Content}
+		}
+		doc.Entries = append(doc.Entries, e)
+	}
+	return encode(doc)
+}
+
+func encode(doc any) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("encode feed: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
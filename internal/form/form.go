@@ -0,0 +1,192 @@
+// Package form parses the bodies of form submissions, urlencoded or
+// multipart, for the site's public POST handlers. Unlike
+// http.Request.ParseForm, it bounds what it reads, decodes the declared
+// charset and only returns valid UTF-8 text.
+package form
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// MaxBytes is the body size limit that suits the site's forms.
+const MaxBytes = 64 << 10
+
+// maxFields bounds the number of fields of a form.
+const maxFields = 100
+
+// Errors of Parse, which Status maps to response statuses.
+var (
+	ErrTooLarge  = errors.New("form: body too large")
+	ErrMediaType = errors.New("form: unsupported media type")
+	ErrCharset   = errors.New("form: unsupported charset")
+	ErrEncoding  = errors.New("form: invalid text")
+	ErrMalformed = errors.New("form: malformed body")
+)
+
+// Parse reads the form in the body of r, at most maxBytes of it. Query
+// parameters are not included.
+func Parse(r *http.Request, maxBytes int64) (url.Values, error) {
+	return ParseBody(r.Header.Get("Content-Type"), r.Body, maxBytes)
+}
+
+// ParseBody parses a form body of the given Content-Type, which must be
+// application/x-www-form-urlencoded or multipart/form-data. Text in
+// another charset than UTF-8, declared by the charset parameter, is
+// converted; values that are still not valid UTF-8 or hold NUL bytes are
+// rejected. The files of multipart forms are skipped.
+func ParseBody(contentType string, body io.Reader, maxBytes int64) (url.Values, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q", ErrMediaType, contentType)
+	}
+	if mediaType != "application/x-www-form-urlencoded" && mediaType != "multipart/form-data" {
+		return nil, fmt.Errorf("%w %q", ErrMediaType, mediaType)
+	}
+	dec, err := decoder(params["charset"])
+	if err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("form: read body: %w", err)
+	}
+	if int64(len(b)) > maxBytes {
+		return nil, ErrTooLarge
+	}
+
+	f := &fields{vals: make(url.Values)}
+	if mediaType == "multipart/form-data" {
+		err = f.addMultipart(b, params["boundary"], dec)
+	} else {
+		err = f.addURLEncoded(b, dec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f.vals, nil
+}
+
+// decoder returns the decoder of charset, or nil for UTF-8.
+func decoder(charset string) (*encoding.Decoder, error) {
+	if charset == "" {
+		return nil, nil
+	}
+	e, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q", ErrCharset, charset)
+	}
+	if name, _ := htmlindex.Name(e); name == "utf-8" {
+		return nil, nil
+	}
+	return e.NewDecoder(), nil
+}
+
+// fields collects the values of a form.
+type fields struct {
+	vals url.Values
+	n    int
+}
+
+// add decodes a name and value from dec, or checks them when they are
+// UTF-8 already, and adds them.
+func (f *fields) add(name, value string, dec *encoding.Decoder) error {
+	if f.n++; f.n > maxFields {
+		return fmt.Errorf("%w: more than %d fields", ErrMalformed, maxFields)
+	}
+	for _, s := range []*string{&name, &value} {
+		if dec != nil {
+			var err error
+			if *s, err = dec.String(*s); err != nil {
+				return fmt.Errorf("%w: %w", ErrEncoding, err)
+			}
+		}
+		if !utf8.ValidString(*s) || strings.IndexByte(*s, 0) >= 0 {
+			return fmt.Errorf("%w in field %q", ErrEncoding, strings.ToValidUTF8(name, "�"))
+		}
+	}
+	f.vals[name] = append(f.vals[name], value)
+	return nil
+}
+
+func (f *fields) addURLEncoded(b []byte, dec *encoding.Decoder) error {
+	for pair := range strings.SplitSeq(string(b), "&") {
+		if pair == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(pair, "=")
+		k, err := url.QueryUnescape(k)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformed, err)
+		}
+		if v, err = url.QueryUnescape(v); err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformed, err)
+		}
+		if err := f.add(k, v, dec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fields) addMultipart(b []byte, boundary string, dec *encoding.Decoder) error {
+	if boundary == "" {
+		return fmt.Errorf("%w: no multipart boundary", ErrMalformed)
+	}
+	mr := multipart.NewReader(bytes.NewReader(b), boundary)
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformed, err)
+		}
+		name := p.FormName()
+		if name == "" || p.FileName() != "" {
+			continue
+		}
+		v, err := io.ReadAll(p)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformed, err)
+		}
+		partDec := dec
+		if _, params, err := mime.ParseMediaType(p.Header.Get("Content-Type")); err == nil && params["charset"] != "" {
+			if partDec, err = decoder(params["charset"]); err != nil {
+				return err
+			}
+		}
+		if err := f.add(name, string(v), partDec); err != nil {
+			return err
+		}
+	}
+}
+
+// Status returns the response status for an error of Parse.
+func Status(err error) int {
+	switch {
+	case errors.Is(err, ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrMediaType), errors.Is(err, ErrCharset):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// Error replies to a request whose form Parse rejected with err.
+func Error(w http.ResponseWriter, err error) {
+	code := Status(err)
+	http.Error(w, strings.ToLower(http.StatusText(code)), code)
+}
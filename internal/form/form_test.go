@@ -0,0 +1,91 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+const limit = 1 << 10
+
+// FuzzParseBody checks that any body either parses into valid UTF-8 fields,
+// within the limits, or fails with one of the package's errors.
+func FuzzParseBody(f *testing.F) {
+	for _, seed := range []struct{ contentType, body string }{
+		{"application/x-www-form-urlencoded", "name=Ada&email=ada%40example.com&message=Hello+there"},
+		{"application/x-www-form-urlencoded; charset=iso-8859-1", "name=Ren%E9e"},
+		{"application/x-www-form-urlencoded; charset=shift_jis", "name=%82%A0"},
+		{"application/x-www-form-urlencoded", "a=%ff&b=%00&c=%zz&=&&"},
+		{"multipart/form-data; boundary=X", "--X\r\nContent-Disposition: form-data; name=\"name\"\r\n\r\nAda\r\n--X--\r\n"},
+		{"multipart/form-data; boundary=X", "--X\r\nContent-Disposition: form-data; name=\"f\"; filename=\"a.txt\"\r\n\r\ndata\r\n--X\r\nContent-Disposition: form-data; name=\"m\"\r\nContent-Type: text/plain; charset=windows-1252\r\n\r\n\x93hi\x94\r\n--X--\r\n"},
+		{"multipart/form-data; boundary=X", "--X\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\nunterminated"},
+		{"multipart/form-data", ""},
+		{"text/plain", "name=Ada"},
+		{"application/x-www-form-urlencoded; charset=nope", "a=b"},
+		{"", ""},
+	} {
+		f.Add(seed.contentType, seed.body)
+	}
+	f.Fuzz(func(t *testing.T, contentType, body string) {
+		vals, err := ParseBody(contentType, strings.NewReader(body), limit)
+		if err != nil {
+			for _, want := range []error{ErrTooLarge, ErrMediaType, ErrCharset, ErrEncoding, ErrMalformed} {
+				if errors.Is(err, want) {
+					return
+				}
+			}
+			t.Fatalf("ParseBody(%q, %q): unexpected error %v", contentType, body, err)
+		}
+		if len(body) > limit {
+			t.Fatalf("ParseBody accepted a %d byte body over the %d byte limit", len(body), limit)
+		}
+		n := 0
+		for k, vs := range vals {
+			for _, s := range append([]string{k}, vs...) {
+				if !utf8.ValidString(s) || strings.ContainsRune(s, 0) {
+					t.Fatalf("ParseBody(%q, %q) returned invalid text %q", contentType, body, s)
+				}
+			}
+			n += len(vs)
+		}
+		if n > maxFields {
+			t.Fatalf("ParseBody returned %d values, more than %d", n, maxFields)
+		}
+	})
+}
+
+// FuzzParseURLEncoded checks that UTF-8 urlencoded bodies parse like
+// url.ParseQuery does.
+func FuzzParseURLEncoded(f *testing.F) {
+	f.Add("name=Ada&email=ada%40example.com")
+	f.Add("a=1&a=2&b=")
+	f.Add("x=%E2%9C%93&y=caf%C3%A9")
+	f.Fuzz(func(t *testing.T, body string) {
+		want, werr := url.ParseQuery(body)
+		got, err := ParseBody("application/x-www-form-urlencoded", strings.NewReader(body), limit)
+		if err != nil {
+			// Stricter than ParseQuery on text, size and field count.
+			if werr == nil && !errors.Is(err, ErrEncoding) && !errors.Is(err, ErrTooLarge) && !errors.Is(err, ErrMalformed) {
+				t.Fatalf("ParseBody(%q): %v, but ParseQuery accepts it", body, err)
+			}
+			return
+		}
+		if werr != nil {
+			// ParseQuery also rejects semicolons, which forms never send.
+			if strings.Contains(body, ";") {
+				return
+			}
+			t.Fatalf("ParseBody(%q) succeeded, but ParseQuery fails with %v", body, werr)
+		}
+		for k, vs := range want {
+			if strings.Join(got[k], "\x00") != strings.Join(vs, "\x00") {
+				t.Fatalf("ParseBody(%q)[%q] = %q, want %q", body, k, got[k], vs)
+			}
+		}
+		if len(got) != len(want) {
+			t.Fatalf("ParseBody(%q) has %d fields, want %d", body, len(got), len(want))
+		}
+	})
+}
@@ -0,0 +1,42 @@
+// Package geoip resolves IP addresses to countries with a MaxMind GeoLite2
+// Country (or City) database.
+package geoip
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// DB is an open GeoIP database. It is safe for concurrent use.
+type DB struct {
+	r *geoip2.Reader
+}
+
+// Open memory-maps the database at path.
+func Open(path string) (*DB, error) {
+	r, err := geoip2.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open geoip database %s: %w", path, err)
+	}
+	return &DB{r: r}, nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country addr is
+// located in, or "" when it is unknown.
+func (d *DB) Country(addr netip.Addr) string {
+	if !addr.IsValid() {
+		return ""
+	}
+	rec, err := d.r.Country(addr.Unmap().AsSlice())
+	if err != nil {
+		return ""
+	}
+	return rec.Country.IsoCode
+}
+
+// Close unmaps the database.
+func (d *DB) Close() error {
+	return d.r.Close()
+}
@@ -0,0 +1,167 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Comment is a discussion comment or a reply to one.
+type Comment struct {
+	Author    string `json:"author"`
+	AuthorURL string `json:"author_url"`
+	Avatar    string `json:"avatar"`
+	// Body is the comment as rendered and sanitized by GitHub.
+	Body      template.HTML `json:"body_html"`
+	URL       string        `json:"url"`
+	CreatedAt time.Time     `json:"created_at"`
+	Replies   []Comment     `json:"replies,omitempty"`
+}
+
+// Discussion is a discussion thread with its visible comments.
+type Discussion struct {
+	URL      string    `json:"url"`
+	Total    int       `json:"total"`
+	Comments []Comment `json:"comments"`
+}
+
+// Discussions maps pages to GitHub Discussions of one repository by title,
+// the way giscus does, and caches the threads.
+type Discussions struct {
+	client   *Client
+	repo     string // "owner/name"
+	category string // slug used when starting a discussion
+	ttl      time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedDiscussion
+}
+
+type cachedDiscussion struct {
+	d       *Discussion
+	fetched time.Time
+}
+
+// NewDiscussions returns Discussions of repo ("owner/name") read through c,
+// which needs a token. New discussions are suggested in the category with
+// the given slug, and threads are refetched after ttl.
+func NewDiscussions(c *Client, repo, category string, ttl time.Duration) *Discussions {
+	return &Discussions{client: c, repo: repo, category: category, ttl: ttl, cache: make(map[string]cachedDiscussion)}
+}
+
+// NewURL returns the link that starts the discussion titled title, with
+// body prefilled.
+func (ds *Discussions) NewURL(title, body string) string {
+	q := url.Values{"title": {title}, "body": {body}}
+	if ds.category != "" {
+		q.Set("category", ds.category)
+	}
+	return "https://github.com/" + ds.repo + "/discussions/new?" + q.Encode()
+}
+
+// Thread returns the discussion titled title, or nil when there is none
+// yet. If a refetch fails the stale thread is returned with the error.
+func (ds *Discussions) Thread(ctx context.Context, title string) (*Discussion, error) {
+	ds.mu.Lock()
+	cached, ok := ds.cache[title]
+	ds.mu.Unlock()
+	if ok && time.Since(cached.fetched) < ds.ttl {
+		return cached.d, nil
+	}
+	d, err := ds.fetch(ctx, title)
+	if err != nil {
+		return cached.d, err
+	}
+	ds.mu.Lock()
+	ds.cache[title] = cachedDiscussion{d: d, fetched: time.Now()}
+	ds.mu.Unlock()
+	return d, nil
+}
+
+const discussionQuery = `query($q: String!) {
+  search(type: DISCUSSION, query: $q, first: 10) {
+    nodes {
+      ... on Discussion {
+        title
+        url
+        comments(first: 50) {
+          totalCount
+          nodes {
+            ...comment
+            replies(first: 20) { nodes { ...comment } }
+          }
+        }
+      }
+    }
+  }
+}
+fragment comment on DiscussionComment {
+  url
+  bodyHTML
+  createdAt
+  isMinimized
+  author { login avatarUrl url }
+}`
+
+type discussionComment struct {
+	URL         string    `json:"url"`
+	BodyHTML    string    `json:"bodyHTML"`
+	CreatedAt   time.Time `json:"createdAt"`
+	IsMinimized bool      `json:"isMinimized"`
+	Author      *struct {
+		Login     string `json:"login"`
+		AvatarURL string `json:"avatarUrl"`
+		URL       string `json:"url"`
+	} `json:"author"`
+	Replies struct {
+		Nodes []discussionComment `json:"nodes"`
+	} `json:"replies"`
+}
+
+func (ds *Discussions) fetch(ctx context.Context, title string) (*Discussion, error) {
+	var out struct {
+		Search struct {
+			Nodes []struct {
+				Title    string `json:"title"`
+				URL      string `json:"url"`
+				Comments struct {
+					TotalCount int                 `json:"totalCount"`
+					Nodes      []discussionComment `json:"nodes"`
+				} `json:"comments"`
+			} `json:"nodes"`
+		} `json:"search"`
+	}
+	q := fmt.Sprintf("repo:%s in:title %q", ds.repo, title)
+	if err := ds.client.graphql(ctx, discussionQuery, map[string]any{"q": q}, &out); err != nil {
+		return nil, fmt.Errorf("github: discussion %q: %w", title, err)
+	}
+	// Search matches words; the mapping needs the exact title.
+	for _, n := range out.Search.Nodes {
+		if n.Title != title {
+			continue
+		}
+		return &Discussion{URL: n.URL, Total: n.Comments.TotalCount, Comments: convertComments(n.Comments.Nodes)}, nil
+	}
+	return nil, nil
+}
+
+// convertComments drops minimized comments and shows deleted accounts as
+// "ghost", like GitHub does.
+func convertComments(nodes []discussionComment) []Comment {
+	var list []Comment
+	for _, n := range nodes {
+		if n.IsMinimized {
+			continue
+		}
+		c := Comment{Author: "ghost", AuthorURL: "https://github.com/ghost", Body: template.HTML(n.BodyHTML), URL: n.URL, CreatedAt: n.CreatedAt}
+		if n.Author != nil {
+			c.Author, c.AuthorURL, c.Avatar = n.Author.Login, n.Author.URL, n.Author.AvatarURL
+		}
+		c.Replies = convertComments(n.Replies.Nodes)
+		list = append(list, c)
+	}
+	return list
+}
@@ -0,0 +1,170 @@
+// Package github fetches a user's repositories and activity from the GitHub
+// API. Client is the GitHub repos.Provider.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/repos"
+)
+
+// DefaultAPI is the GitHub API origin.
+const DefaultAPI = "https://api.github.com"
+
+// repo is a repository as listed by the REST API.
+type repo struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	URL         string    `json:"html_url"`
+	Language    string    `json:"language"`
+	Stars       int       `json:"stargazers_count"`
+	Topics      []string  `json:"topics"`
+	PushedAt    time.Time `json:"pushed_at"`
+	Fork        bool      `json:"fork"`
+	Archived    bool      `json:"archived"`
+}
+
+// Client lists one user's repositories. Responses are cached with their
+// ETag, so unchanged data costs a conditional request that GitHub doesn't
+// count against the rate limit.
+type Client struct {
+	User string
+	// Token is optional; it raises the rate limit and is required to read
+	// pinned repositories.
+	Token string
+	API   string
+	HTTP  *http.Client
+
+	mu    sync.Mutex
+	etag  string
+	repos []repo
+}
+
+// NewClient returns a Client for user against the public API.
+func NewClient(user, token string) *Client {
+	return &Client{
+		User:  user,
+		Token: token,
+		API:   DefaultAPI,
+		HTTP:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.API+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	return req, nil
+}
+
+// Name implements repos.Provider.
+func (c *Client) Name() string { return "github:" + c.User }
+
+// Repos implements repos.Provider. Repositories pinned on the profile are
+// marked when a token is set.
+func (c *Client) Repos(ctx context.Context) ([]repos.Repo, error) {
+	list, err := c.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pinned, err := c.Pinned(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]repos.Repo, len(list))
+	for i, r := range list {
+		out[i] = repos.Repo{
+			Source:      "github",
+			Name:        r.Name,
+			Description: r.Description,
+			URL:         r.URL,
+			Language:    r.Language,
+			Stars:       r.Stars,
+			Topics:      r.Topics,
+			PushedAt:    r.PushedAt,
+			Fork:        r.Fork,
+			Archived:    r.Archived,
+			Pinned:      slices.Contains(pinned, r.Name),
+		}
+	}
+	return out, nil
+}
+
+// list returns the user's public repositories, most recently pushed first.
+func (c *Client) list(ctx context.Context) ([]repo, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/users/"+c.User+"/repos?type=owner&sort=pushed&per_page=100", nil)
+	if err != nil {
+		return nil, fmt.Errorf("github: list repos: %w", err)
+	}
+	c.mu.Lock()
+	if c.etag != "" {
+		req.Header.Set("If-None-Match", c.etag)
+	}
+	c.mu.Unlock()
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("github: list repos: %w", err)
+	}
+	defer resp.Body.Close()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return slices.Clone(c.repos), nil
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("github: list repos: %s", resp.Status)
+	}
+	var list []repo
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("github: decode repos: %w", err)
+	}
+	c.repos, c.etag = list, resp.Header.Get("ETag")
+	return slices.Clone(list), nil
+}
+
+const pinnedQuery = `query($login: String!) {
+  user(login: $login) {
+    pinnedItems(first: 6, types: REPOSITORY) {
+      nodes { ... on Repository { name } }
+    }
+  }
+}`
+
+// Pinned returns the names of the repositories pinned on the user's profile.
+// It needs a token; without one it returns nil.
+func (c *Client) Pinned(ctx context.Context) ([]string, error) {
+	if c.Token == "" {
+		return nil, nil
+	}
+	var out struct {
+		User struct {
+			PinnedItems struct {
+				Nodes []struct{ Name string }
+			}
+		}
+	}
+	if err := c.graphql(ctx, pinnedQuery, nil, &out); err != nil {
+		return nil, fmt.Errorf("github: pinned repos: %w", err)
+	}
+	var names []string
+	for _, n := range out.User.PinnedItems.Nodes {
+		names = append(names, n.Name)
+	}
+	return names, nil
+}
@@ -0,0 +1,135 @@
+package github
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Stats summarizes the user's activity and the languages of their
+// repositories.
+type Stats struct {
+	// Contributions is the total over the last year, as shown on the
+	// profile's contribution graph. It needs a token and is -1 without one.
+	Contributions int        `json:"contributions"`
+	Repos         int        `json:"repos"`
+	Stars         int        `json:"stars"`
+	Languages     []Language `json:"languages"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Language is a primary language with its share of the user's repositories.
+type Language struct {
+	Name    string  `json:"name"`
+	Repos   int     `json:"repos"`
+	Percent float64 `json:"percent"`
+}
+
+// maxLanguages is how many languages Stats reports; the rest are dropped.
+const maxLanguages = 6
+
+// Stats gathers the user's contribution count and language breakdown.
+// Forks are left out.
+func (c *Client) Stats(ctx context.Context) (Stats, error) {
+	list, err := c.list(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+	st := Stats{Contributions: -1, UpdatedAt: time.Now()}
+	counts := make(map[string]int)
+	for _, r := range list {
+		if r.Fork {
+			continue
+		}
+		st.Repos++
+		st.Stars += r.Stars
+		if r.Language != "" {
+			counts[r.Language]++
+		}
+	}
+	var total int
+	for name, n := range counts {
+		st.Languages = append(st.Languages, Language{Name: name, Repos: n})
+		total += n
+	}
+	slices.SortFunc(st.Languages, func(a, b Language) int {
+		return cmp.Or(b.Repos-a.Repos, cmp.Compare(a.Name, b.Name))
+	})
+	for i := range st.Languages {
+		st.Languages[i].Percent = 100 * float64(st.Languages[i].Repos) / float64(total)
+	}
+	if len(st.Languages) > maxLanguages {
+		st.Languages = st.Languages[:maxLanguages]
+	}
+
+	if c.Token != "" {
+		if st.Contributions, err = c.contributions(ctx); err != nil {
+			return Stats{}, err
+		}
+	}
+	return st, nil
+}
+
+const contributionsQuery = `query($login: String!) {
+  user(login: $login) {
+    contributionsCollection { contributionCalendar { totalContributions } }
+  }
+}`
+
+func (c *Client) contributions(ctx context.Context) (int, error) {
+	var out struct {
+		User struct {
+			ContributionsCollection struct {
+				ContributionCalendar struct {
+					TotalContributions int
+				}
+			}
+		}
+	}
+	if err := c.graphql(ctx, contributionsQuery, nil, &out); err != nil {
+		return 0, fmt.Errorf("github: contributions: %w", err)
+	}
+	return out.User.ContributionsCollection.ContributionCalendar.TotalContributions, nil
+}
+
+// graphql runs query with the user's login as $login, and any other
+// variables in vars, and decodes its data into v.
+func (c *Client) graphql(ctx context.Context, query string, vars map[string]any, v any) error {
+	variables := map[string]any{"login": c.User}
+	maps.Copy(variables, vars)
+	body, err := json.Marshal(map[string]any{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, "/graphql", body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("graphql: %s", resp.Status)
+	}
+	var out struct {
+		Data   json.RawMessage
+		Errors []struct{ Message string }
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("graphql: decode: %w", err)
+	}
+	if len(out.Errors) > 0 {
+		return fmt.Errorf("graphql: %s", out.Errors[0].Message)
+	}
+	return json.Unmarshal(out.Data, v)
+}
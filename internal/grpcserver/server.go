@@ -0,0 +1,120 @@
+// Package grpcserver implements the PortfolioService gRPC API, and its
+// grpc-gateway REST mapping, on top of the data loaded by the handler.
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+
+	"github.com/fpatron/portfolio/internal/content"
+	pb "github.com/fpatron/portfolio/internal/pb/portfoliov1"
+)
+
+// DataSource provides the current page data. *handler.Handler satisfies it.
+type DataSource interface {
+	Data() content.PageData
+}
+
+// Server implements pb.PortfolioServiceServer.
+type Server struct {
+	pb.UnimplementedPortfolioServiceServer
+	src DataSource
+}
+
+// New creates a Server reading from src on every call, so reloads are picked
+// up without restarting.
+func New(src DataSource) *Server {
+	return &Server{src: src}
+}
+
+// Gateway returns an http.Handler serving the REST mapping of the service
+// (GET /v1/...). Calls go straight to s without a network hop.
+func Gateway(ctx context.Context, s *Server) (http.Handler, error) {
+	mux := runtime.NewServeMux()
+	if err := pb.RegisterPortfolioServiceHandlerServer(ctx, mux, s); err != nil {
+		return nil, fmt.Errorf("register gateway: %w", err)
+	}
+	return mux, nil
+}
+
+// GetAbout returns the profile.
+func (s *Server) GetAbout(ctx context.Context, req *pb.GetAboutRequest) (*pb.About, error) {
+	a := s.src.Data().About
+	return &pb.About{
+		Name:              a.Name,
+		Tagline:           a.Tagline,
+		Bio:               a.Bio,
+		Location:          a.Location,
+		Availability:      a.Availability,
+		YearsOfExperience: int32(a.YearsOfExperience),
+		Email:             a.Email,
+		Github:            a.GitHub,
+		Linkedin:          a.LinkedIn,
+		X:                 a.X,
+		ProfilePhoto:      a.ProfilePhoto,
+	}, nil
+}
+
+// ListProjects returns the projects, optionally filtered by tag.
+func (s *Server) ListProjects(ctx context.Context, req *pb.ListProjectsRequest) (*pb.ListProjectsResponse, error) {
+	resp := &pb.ListProjectsResponse{}
+	for _, p := range s.src.Data().Projects {
+		if req.GetTag() != "" && !slices.ContainsFunc(p.Tags, func(t string) bool { return strings.EqualFold(t, req.GetTag()) }) {
+			continue
+		}
+		resp.Projects = append(resp.Projects, &pb.Project{
+			Title:       p.Title,
+			Description: p.Description,
+			Tags:        p.Tags,
+			Link:        p.Link,
+			Image:       p.Image,
+		})
+	}
+	return resp, nil
+}
+
+// ListExperience returns work and education entries, optionally filtered by type.
+func (s *Server) ListExperience(ctx context.Context, req *pb.ListExperienceRequest) (*pb.ListExperienceResponse, error) {
+	resp := &pb.ListExperienceResponse{}
+	for _, e := range s.src.Data().Experience {
+		if req.GetType() != "" && e.Type != req.GetType() {
+			continue
+		}
+		resp.Experience = append(resp.Experience, &pb.Experience{
+			Role:        e.Role,
+			Company:     e.Company,
+			CompanyUrl:  e.CompanyURL,
+			Logo:        e.Logo,
+			StartDate:   e.StartDate,
+			EndDate:     e.EndDate,
+			Dates:       e.Dates,
+			Location:    e.Location,
+			Description: e.Description,
+			Type:        e.Type,
+		})
+	}
+	return resp, nil
+}
+
+// ListSkills returns the skill categories.
+func (s *Server) ListSkills(ctx context.Context, req *pb.ListSkillsRequest) (*pb.ListSkillsResponse, error) {
+	resp := &pb.ListSkillsResponse{}
+	for _, c := range s.src.Data().Skills {
+		resp.Skills = append(resp.Skills, &pb.SkillCategory{Category: c.Category, Skills: c.Skills})
+	}
+	return resp, nil
+}
+
+// ListInterests returns the personal interests.
+func (s *Server) ListInterests(ctx context.Context, req *pb.ListInterestsRequest) (*pb.ListInterestsResponse, error) {
+	resp := &pb.ListInterestsResponse{}
+	for _, i := range s.src.Data().Interests {
+		resp.Interests = append(resp.Interests, &pb.Interest{Emoji: i.Emoji, Label: i.Label, Description: i.Description})
+	}
+	return resp, nil
+}
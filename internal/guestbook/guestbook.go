@@ -0,0 +1,107 @@
+// Package guestbook stores the messages visitors sign the guestbook with.
+// New entries are pending until approved, and only approved ones are
+// shown.
+package guestbook
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/db"
+)
+
+// Moderation statuses.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS guestbook (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		website TEXT NOT NULL,
+		message TEXT NOT NULL,
+		created INTEGER NOT NULL,
+		status TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS guestbook_status ON guestbook (status, created)`,
+}
+
+// Entry is a signed message.
+type Entry struct {
+	ID      int64     `json:"id"`
+	Name    string    `json:"name"`
+	Website string    `json:"website,omitempty"`
+	Message string    `json:"message"`
+	Created time.Time `json:"created"`
+	Status  string    `json:"status"`
+}
+
+// Store persists the guestbook in SQLite.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates the table if needed.
+func NewStore(ctx context.Context, database *sql.DB) (*Store, error) {
+	if err := db.Migrate(ctx, database, schema...); err != nil {
+		return nil, fmt.Errorf("guestbook: %w", err)
+	}
+	return &Store{db: database}, nil
+}
+
+// Sign adds e as pending and returns its ID.
+func (s *Store) Sign(ctx context.Context, e Entry) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO guestbook (name, website, message, created, status) VALUES (?, ?, ?, ?, ?)`,
+		e.Name, e.Website, e.Message, time.Now().Unix(), StatusPending)
+	if err != nil {
+		return 0, fmt.Errorf("sign guestbook: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// Approved returns the approved entries, newest first, at most limit.
+func (s *Store) Approved(ctx context.Context, limit int) ([]Entry, error) {
+	return s.query(ctx, `WHERE status = ? ORDER BY created DESC, id DESC LIMIT ?`, StatusApproved, limit)
+}
+
+// Pending returns the entries awaiting moderation, oldest first.
+func (s *Store) Pending(ctx context.Context) ([]Entry, error) {
+	return s.query(ctx, `WHERE status = ? ORDER BY created, id`, StatusPending)
+}
+
+// SetStatus moderates the entry with the given ID. It reports whether the
+// entry exists.
+func (s *Store) SetStatus(ctx context.Context, id int64, status string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `UPDATE guestbook SET status = ? WHERE id = ?`, status, id)
+	if err != nil {
+		return false, fmt.Errorf("moderate guestbook entry: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (s *Store) query(ctx context.Context, where string, args ...any) ([]Entry, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, website, message, created, status FROM guestbook `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query guestbook: %w", err)
+	}
+	defer rows.Close()
+	var list []Entry
+	for rows.Next() {
+		var e Entry
+		var created int64
+		if err := rows.Scan(&e.ID, &e.Name, &e.Website, &e.Message, &created, &e.Status); err != nil {
+			return nil, fmt.Errorf("query guestbook: %w", err)
+		}
+		e.Created = time.Unix(created, 0)
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
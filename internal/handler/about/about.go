@@ -0,0 +1,69 @@
+// Package about serves the about section: the profile partial, the
+// experience API, the resume as PDF and JSON Resume, and the portfolio
+// bundle.
+package about
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+// Handler serves the about section from the site's current data.
+type Handler struct {
+	render  *render.Renderer
+	data    func() (content.PageData, uint64)
+	baseURL func(*http.Request) string
+
+	resumePDF render.Cache[[]byte]
+	bundle    render.Cache[bundle]
+}
+
+// New returns a Handler rendering with r. data returns the current page
+// data and its version, which changes on every reload, and baseURL the
+// site's origin for a request.
+func New(r *render.Renderer, data func() (content.PageData, uint64), baseURL func(*http.Request) string) *Handler {
+	return &Handler{render: r, data: data, baseURL: baseURL}
+}
+
+// Partial serves the about section partial for HTMX.
+func (h *Handler) Partial(w http.ResponseWriter, r *http.Request) {
+	data, _ := h.data()
+	h.render.Respond(w, r, "about", data, struct {
+		About      content.About           `json:"about"`
+		Skills     []content.SkillCategory `json:"skills"`
+		Experience []content.Experience    `json:"experience"`
+	}{data.About, data.Skills, data.Experience})
+}
+
+// Experience serves the experience list as JSON. It supports ?type=work or
+// ?type=education, ?sort=date (most recent first) and ?limit=&offset=.
+func (h *Handler) Experience(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	p, err := render.ParseListPage(q)
+	if err != nil {
+		render.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	data, _ := h.data()
+	experience := slices.Clone(data.Experience)
+	if typ := q.Get("type"); typ != "" {
+		experience = slices.DeleteFunc(experience, func(e content.Experience) bool { return e.Type != typ })
+	}
+	switch q.Get("sort") {
+	case "":
+	case "date":
+		slices.SortStableFunc(experience, func(a, b content.Experience) int {
+			return b.SortDate().Compare(a.SortDate())
+		})
+	default:
+		render.JSONError(w, http.StatusBadRequest, fmt.Sprintf("unsupported sort %q", q.Get("sort")))
+		return
+	}
+
+	render.JSON(w, http.StatusOK, render.Paginate(r, experience, p))
+}
@@ -0,0 +1,112 @@
+package about
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+var testData = content.PageData{
+	About: content.About{Name: "Ada Example", Bio: "Builds web services in Go."},
+	Skills: []content.SkillCategory{
+		{Category: "Languages", Skills: []string{"Go", "SQL"}},
+	},
+	Experience: []content.Experience{
+		{Role: "Student", Company: "Example University", StartDate: "2016", EndDate: "2019", Type: "education"},
+		{Role: "Engineer", Company: "Example Corp", StartDate: "Jan 2020", EndDate: "Dec 2022", Type: "work"},
+		{Role: "Senior Engineer", Company: "Other Corp", StartDate: "Jan 2023", EndDate: "Dec 2025", Type: "work"},
+	},
+}
+
+func newHandler(t *testing.T) *Handler {
+	t.Helper()
+	tmpl, err := render.Parse(os.DirFS("../../.."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := func() (content.PageData, uint64) { return testData, 1 }
+	baseURL := func(*http.Request) string { return "http://example.com" }
+	return New(render.New(tmpl), data, baseURL)
+}
+
+func TestExperience(t *testing.T) {
+	h := newHandler(t)
+	for _, tt := range []struct {
+		query  string
+		status int
+		roles  []string
+		next   bool
+	}{
+		{"", http.StatusOK, []string{"Student", "Engineer", "Senior Engineer"}, false},
+		{"?type=work", http.StatusOK, []string{"Engineer", "Senior Engineer"}, false},
+		{"?type=education", http.StatusOK, []string{"Student"}, false},
+		{"?sort=date", http.StatusOK, []string{"Senior Engineer", "Engineer", "Student"}, false},
+		{"?type=work&sort=date&limit=1", http.StatusOK, []string{"Senior Engineer"}, true},
+		{"?limit=1&offset=2", http.StatusOK, []string{"Senior Engineer"}, false},
+		{"?type=volunteer", http.StatusOK, []string{}, false},
+		{"?sort=company", http.StatusBadRequest, nil, false},
+		{"?limit=-1", http.StatusBadRequest, nil, false},
+	} {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.Experience(rec, httptest.NewRequest(http.MethodGet, "/api/experience"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var resp render.ListResponse[content.Experience]
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			roles := []string{}
+			for _, e := range resp.Items {
+				roles = append(roles, e.Role)
+			}
+			if !slices.Equal(roles, tt.roles) {
+				t.Errorf("roles = %q, want %q", roles, tt.roles)
+			}
+			if (resp.Next != nil) != tt.next {
+				t.Errorf("next = %v, want a next page: %v", resp.Next, tt.next)
+			}
+		})
+	}
+}
+
+func TestPartial(t *testing.T) {
+	h := newHandler(t)
+
+	rec := httptest.NewRecorder()
+	h.Partial(rec, httptest.NewRequest(http.MethodGet, "/partials/about", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	for _, want := range []string{"Builds web services in Go.", "Example Corp", "Languages"} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("partial doesn't show %q", want)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/partials/about", nil)
+	req.Header.Set("Accept", "application/json")
+	rec = httptest.NewRecorder()
+	h.Partial(rec, req)
+	var resp struct {
+		About      content.About        `json:"about"`
+		Experience []content.Experience `json:"experience"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("JSON partial: %v: %s", err, rec.Body)
+	}
+	if resp.About.Name != "Ada Example" || len(resp.Experience) != 3 {
+		t.Errorf("JSON partial = %+v", resp)
+	}
+}
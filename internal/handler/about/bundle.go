@@ -0,0 +1,164 @@
+package about
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/jsonresume"
+)
+
+// bundle is the cached portfolio archive and the origin its links use.
+type bundle struct {
+	base string
+	zip  []byte
+}
+
+// Bundle serves /download/portfolio.zip: the resume as PDF and JSON
+// Resume, a vCard and a one-page PDF of each project. The archive is built
+// on first request and cached until the data changes.
+func (h *Handler) Bundle(w http.ResponseWriter, r *http.Request) {
+	data, version := h.data()
+	base := h.baseURL(r)
+	build := func() (bundle, error) {
+		pdf, err := h.resumePDF.Get(version, func() ([]byte, error) {
+			return renderResumePDF(data)
+		})
+		if err != nil {
+			return bundle{}, err
+		}
+		b, err := buildBundle(data, base, pdf)
+		return bundle{base: base, zip: b}, err
+	}
+	b, err := h.bundle.Get(version, build)
+	if err == nil && b.base != base {
+		// Without a configured BASE_URL, links follow the requested host,
+		// which the cached archive may not match.
+		b, err = build()
+	}
+	if err != nil {
+		log.Printf("portfolio bundle: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", slug(data.About.Name, "portfolio")+".zip"))
+	w.Header().Set("Content-Length", strconv.Itoa(len(b.zip)))
+	w.Write(b.zip)
+}
+
+type bundleFile struct {
+	name string
+	body []byte
+}
+
+// buildBundle zips the files of the bundle, the resume PDF given.
+func buildBundle(data content.PageData, base string, resume []byte) ([]byte, error) {
+	name := slug(data.About.Name, "portfolio")
+	jsonResume, err := json.MarshalIndent(jsonresume.New(data, base), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode json resume: %w", err)
+	}
+	files := []bundleFile{
+		{resumeFilename(data.About.Name), resume},
+		{name + "-resume.json", jsonResume},
+		{name + ".vcf", []byte(data.About.VCard(base + "/"))},
+	}
+	for _, p := range data.Projects {
+		b, err := renderProjectPDF(data.About, p, base)
+		if err != nil {
+			return nil, fmt.Errorf("project %s: %w", p.Slug, err)
+		}
+		files = append(files, bundleFile{"projects/" + p.Slug + ".pdf", b})
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	now := time.Now()
+	for _, f := range files {
+		dst, err := zw.CreateHeader(&zip.FileHeader{Name: name + "/" + f.name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return nil, fmt.Errorf("zip %s: %w", f.name, err)
+		}
+		if _, err := dst.Write(f.body); err != nil {
+			return nil, fmt.Errorf("zip %s: %w", f.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("zip: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderProjectPDF lays out a one-page summary of p in the style of the
+// resume.
+func renderProjectPDF(a content.About, p content.Project, base string) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "Letter", "")
+	pdf.SetMargins(18, 16, 18)
+	pdf.SetAutoPageBreak(true, 16)
+	pdf.SetTitle(p.Title, true)
+	pdf.SetAuthor(a.Name, true)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 22)
+	pdf.MultiCell(0, 10, tr(p.Title), "", "L", false)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(80, 80, 80)
+	pdf.CellFormat(0, 6, tr("A project by "+a.Name), "", 1, "L", false, 0, "")
+	if len(p.Tags) > 0 {
+		pdf.CellFormat(0, 6, tr(strings.Join(p.Tags, "  ·  ")), "", 1, "L", false, 0, "")
+	}
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.MultiCell(0, 5.5, tr(p.Description), "", "L", false)
+	pdf.Ln(4)
+
+	var facts [][2]string
+	if p.Language != "" {
+		facts = append(facts, [2]string{"Language", p.Language})
+	}
+	if p.Stars > 0 {
+		facts = append(facts, [2]string{"Stars", strconv.Itoa(p.Stars)})
+	}
+	if !p.PushedAt.IsZero() {
+		facts = append(facts, [2]string{"Updated", p.PushedAt.Format("January 2006")})
+	}
+	if p.Version != "" {
+		facts = append(facts, [2]string{"Package", p.PackageRegistry() + " " + p.Version})
+	}
+	if p.Link != "" {
+		facts = append(facts, [2]string{"Link", p.Link})
+	}
+	facts = append(facts, [2]string{"Page", base + "/projects/" + p.Slug})
+	for _, f := range facts {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(28, 6, tr(f[0]), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		if strings.HasPrefix(f[1], "http") {
+			pdf.SetTextColor(37, 99, 235)
+			pdf.CellFormat(0, 6, tr(f[1]), "", 1, "L", false, 0, f[1])
+			pdf.SetTextColor(0, 0, 0)
+		} else {
+			pdf.CellFormat(0, 6, tr(f[1]), "", 1, "L", false, 0, "")
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("render project pdf: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,183 @@
+package about
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/jsonresume"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+// Resume serves the resume rendered from the loaded data as a PDF. The
+// document is built on first request and cached until the data changes.
+func (h *Handler) Resume(w http.ResponseWriter, r *http.Request) {
+	data, version := h.data()
+	pdf, err := h.resumePDF.Get(version, func() ([]byte, error) {
+		return renderResumePDF(data)
+	})
+	if err != nil {
+		log.Printf("resume pdf error: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", resumeFilename(data.About.Name)))
+	w.Write(pdf)
+}
+
+// ResumeFile returns the resume in format, "pdf", "json" for JSON Resume
+// or "vcard", and the file name it is downloaded as. Links point under
+// base.
+func ResumeFile(data content.PageData, base, format string) (name string, body []byte, err error) {
+	switch format {
+	case "pdf":
+		body, err = renderResumePDF(data)
+		return resumeFilename(data.About.Name), body, err
+	case "json":
+		body, err = json.MarshalIndent(jsonresume.New(data, base), "", "  ")
+		if err != nil {
+			return "", nil, fmt.Errorf("encode json resume: %w", err)
+		}
+		return slug(data.About.Name, "resume") + "-resume.json", append(body, '\n'), nil
+	case "vcard":
+		return slug(data.About.Name, "contact") + ".vcf", []byte(data.About.VCard(base + "/")), nil
+	}
+	return "", nil, fmt.Errorf("unknown resume format %q", format)
+}
+
+// JSONResume serves /resume.json, the resume in the JSON Resume format for
+// other resume tools.
+func (h *Handler) JSONResume(w http.ResponseWriter, r *http.Request) {
+	data, _ := h.data()
+	render.JSON(w, http.StatusOK, jsonresume.New(data, h.baseURL(r)))
+}
+
+func resumeFilename(name string) string {
+	if name == "" {
+		return "resume.pdf"
+	}
+	return slug(name, "") + "-resume.pdf"
+}
+
+// slug turns a name into a file name, or returns fallback for an empty
+// name.
+func slug(name, fallback string) string {
+	s := strings.ToLower(strings.Join(strings.Fields(name), "-"))
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// renderResumePDF lays out a single-column resume using the PDF core fonts.
+func renderResumePDF(data content.PageData) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "Letter", "")
+	pdf.SetMargins(18, 16, 18)
+	pdf.SetAutoPageBreak(true, 16)
+	pdf.SetTitle(data.About.Name+" — Resume", true)
+	pdf.SetAuthor(data.About.Name, true)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 22)
+	pdf.CellFormat(0, 10, tr(data.About.Name), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.SetTextColor(80, 80, 80)
+	pdf.CellFormat(0, 6, tr(data.About.Tagline), "", 1, "L", false, 0, "")
+
+	var contact []string
+	for _, s := range []string{data.About.Location, data.About.Email, data.About.GitHub, data.About.LinkedIn} {
+		if s != "" {
+			contact = append(contact, s)
+		}
+	}
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.MultiCell(0, 5, tr(strings.Join(contact, "  |  ")), "", "L", false)
+	pdf.SetTextColor(0, 0, 0)
+
+	heading := func(title string) {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "B", 13)
+		pdf.CellFormat(0, 7, tr(title), "B", 1, "L", false, 0, "")
+		pdf.Ln(2)
+	}
+
+	if data.About.Bio != "" {
+		heading("Summary")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(0, 5, tr(data.About.Bio), "", "L", false)
+	}
+
+	section := func(title, typ string) {
+		var entries []content.Experience
+		for _, e := range data.Experience {
+			if e.Type == typ {
+				entries = append(entries, e)
+			}
+		}
+		if len(entries) == 0 {
+			return
+		}
+		heading(title)
+		for _, e := range entries {
+			pdf.SetFont("Helvetica", "B", 11)
+			pdf.CellFormat(120, 6, tr(e.Role), "", 0, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "", 9)
+			pdf.CellFormat(0, 6, tr(e.DateRange()), "", 1, "R", false, 0, "")
+			pdf.SetFont("Helvetica", "I", 10)
+			line := e.Company
+			if e.Location != "" {
+				line += ", " + e.Location
+			}
+			pdf.CellFormat(0, 5, tr(line), "", 1, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "", 9.5)
+			for _, d := range e.Description {
+				pdf.SetX(22)
+				pdf.MultiCell(0, 4.6, tr("- "+d), "", "L", false)
+			}
+			pdf.Ln(2)
+		}
+	}
+	section("Experience", "work")
+	section("Education", "education")
+
+	if len(data.Skills) > 0 {
+		heading("Skills")
+		for _, c := range data.Skills {
+			pdf.SetFont("Helvetica", "B", 10)
+			pdf.CellFormat(35, 5, tr(c.Category), "", 0, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "", 10)
+			pdf.MultiCell(0, 5, tr(strings.Join(c.Skills, ", ")), "", "L", false)
+		}
+	}
+
+	if len(data.Projects) > 0 {
+		heading("Projects")
+		for _, p := range data.Projects {
+			pdf.SetFont("Helvetica", "B", 10.5)
+			pdf.CellFormat(0, 5.5, tr(p.Title), "", 1, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "", 9.5)
+			pdf.MultiCell(0, 4.6, tr(p.Description), "", "L", false)
+			if p.Link != "" {
+				pdf.SetTextColor(37, 99, 235)
+				pdf.CellFormat(0, 5, tr(p.Link), "", 1, "L", false, 0, p.Link)
+				pdf.SetTextColor(0, 0, 0)
+			}
+			pdf.Ln(1.5)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("render resume pdf: %w", err)
+	}
+	return buf.Bytes(), nil
+}
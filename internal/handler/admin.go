@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/fpatron/portfolio/internal/analytics"
+	"github.com/fpatron/portfolio/internal/chart"
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+// AdminStatsData is passed to the admin stats page.
+type AdminStatsData struct {
+	content.PageData
+	Days   int
+	Report analytics.Report
+	Chart  template.HTML
+}
+
+// AdminStats renders the analytics dashboard. ?days= selects the period
+// (default 30, at most 365).
+func (h *Handler) AdminStats(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Analytics == nil {
+		http.Error(w, "analytics are disabled", http.StatusNotFound)
+		return
+	}
+	days := 30
+	if v, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && v > 0 {
+		days = min(v, 365)
+	}
+
+	rep, err := h.opts.Analytics.Report(r.Context(), days, 10)
+	if err != nil {
+		log.Printf("admin stats: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	points := make([]chart.Point, len(rep.Daily))
+	for i, d := range rep.Daily {
+		points[i] = chart.Point{Label: d.Date.Format("Jan 2"), Value: d.Views, Secondary: d.Visitors}
+	}
+
+	data, _ := h.data()
+	data.Theme = render.Theme(r)
+	w.Header().Set("Cache-Control", "no-store")
+	h.render.Page(w, "admin-stats", AdminStatsData{
+		PageData: data,
+		Days:     days,
+		Report:   rep,
+		Chart:    chart.Bars(points, 720, 180),
+	})
+}
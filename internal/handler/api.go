@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"github.com/fpatron/portfolio/internal/blog"
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/github"
+	"github.com/fpatron/portfolio/internal/render"
+	"github.com/fpatron/portfolio/internal/search"
+	"github.com/fpatron/portfolio/internal/uptime"
+)
+
+// APIResponses maps the paths of the JSON API to a value of the type each
+// responds with when it succeeds. The schemas in schemas/api/ are generated
+// from them.
+var APIResponses = map[string]any{
+	"/api/projects":     render.ListResponse[content.Project]{},
+	"/api/experience":   render.ListResponse[content.Experience]{},
+	"/api/posts":        render.ListResponse[blog.Post]{},
+	"/api/search":       render.ListResponse[search.Result]{},
+	"/api/commands":     render.ListResponse[Command]{},
+	"/api/github/stats": github.Stats{},
+	"/api/status":       uptime.Status{},
+}
+
+// APIError is the type of the API's error responses, written by
+// render.JSONError.
+type APIError struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	badgeGreen = "#4c1"
+	badgeBlue  = "#007ec6"
+	badgeGrey  = "#9f9f9f"
+)
+
+// Badge serves shields.io-style SVG badges built from the loaded data:
+// /badge/projects.svg, /badge/experience.svg and /badge/availability.svg.
+func (h *Handler) Badge(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutSuffix(r.PathValue("name"), ".svg")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, _ := h.data()
+	var label, message, color string
+	switch name {
+	case "projects":
+		label, message, color = "projects", strconv.Itoa(len(data.Projects)), badgeBlue
+	case "experience":
+		label, color = "experience", badgeBlue
+		message = pluralize(data.About.YearsOfExperience, "year")
+	case "availability":
+		label = "availability"
+		if data.About.Availability {
+			message, color = "open to work", badgeGreen
+		} else {
+			message, color = "unavailable", badgeGrey
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	fmt.Fprint(w, renderBadge(label, message, color))
+}
+
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// badgeTextWidth approximates the rendered width of s in 11px Verdana.
+func badgeTextWidth(s string) int {
+	w := 0.0
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("iljI.,:;!|' ", r):
+			w += 3.5
+		case strings.ContainsRune("mwMW", r):
+			w += 10
+		case r >= 'A' && r <= 'Z':
+			w += 7.5
+		default:
+			w += 6.5
+		}
+	}
+	return int(w + 0.5)
+}
+
+// renderBadge draws a flat two-part badge in the shields.io style.
+func renderBadge(label, message, color string) string {
+	lw := badgeTextWidth(label) + 10
+	mw := badgeTextWidth(message) + 10
+	total := lw + mw
+	label, message = html.EscapeString(label), html.EscapeString(message)
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+<title>%[4]s: %[5]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%[7]d" y="14">%[4]s</text>
+<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[5]s</text><text x="%[8]d" y="14">%[5]s</text>
+</g>
+</svg>
+`, total, lw, mw, label, message, color, lw/2, lw+mw/2)
+}
@@ -0,0 +1,157 @@
+// Package blog serves the blog section: the list of posts, the post pages,
+// the latest posts on the home page and the posts API.
+package blog
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/blog"
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+// latestPosts is how many posts the home page shows.
+const latestPosts = 3
+
+// Options configures a Handler.
+type Options struct {
+	// NotFound serves the 404 page for unknown posts. It defaults to
+	// http.NotFound.
+	NotFound http.HandlerFunc
+	// Webmentions advertises the site's webmention endpoint on post pages.
+	Webmentions bool
+}
+
+// Handler serves the blog section from the site's current data.
+type Handler struct {
+	render  *render.Renderer
+	data    func() (content.PageData, uint64)
+	baseURL func(*http.Request) string
+	opts    Options
+}
+
+// New returns a Handler rendering with r. data returns the current page
+// data and its version, and baseURL the site's origin for a request.
+func New(r *render.Renderer, data func() (content.PageData, uint64), baseURL func(*http.Request) string, opts Options) *Handler {
+	if opts.NotFound == nil {
+		opts.NotFound = http.NotFound
+	}
+	return &Handler{render: r, data: data, baseURL: baseURL, opts: opts}
+}
+
+// ListData is the data of the blog page and of the latest posts: the posts
+// they list, all of them or those tagged Tag.
+type ListData struct {
+	content.PageData
+	Posts []blog.Post
+	Tag   string
+}
+
+// List serves the list of posts, filtered by ?tag= (case insensitive), or
+// the posts as JSON without their bodies.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	data, _ := h.data()
+	data.Theme = render.Theme(r)
+	data.BaseURL = h.baseURL(r)
+	data.URL = data.BaseURL + "/blog"
+	data = data.WithMeta()
+	data.Meta.Title, data.Meta.Type = "Blog — "+data.About.Name, "website"
+	data.Meta.Image = data.BaseURL + "/og/blog.png"
+	tag := r.URL.Query().Get("tag")
+	posts := tagged(data.Posts, tag)
+	w.Header().Add("Vary", "Accept")
+	if render.WantsJSON(r) {
+		render.JSON(w, http.StatusOK, withoutBodies(posts))
+		return
+	}
+	h.render.Page(w, "blog", ListData{PageData: data, Posts: posts, Tag: tag})
+}
+
+// Post serves the page of a single post, or the post as JSON.
+func (h *Handler) Post(w http.ResponseWriter, r *http.Request) {
+	data, _ := h.data()
+	data.Theme = render.Theme(r)
+	p, ok := data.FindPost(r.PathValue("slug"))
+	if !ok {
+		h.opts.NotFound(w, r)
+		return
+	}
+	data.Post = &p
+	data.BaseURL = h.baseURL(r)
+	data.URL = data.BaseURL + "/blog/" + p.Slug
+	data = data.WithMeta()
+
+	if h.opts.Webmentions {
+		w.Header().Set("Link", "<"+data.BaseURL+"/webmention>; rel=\"webmention\"")
+	}
+	w.Header().Add("Vary", "Accept")
+	if render.WantsJSON(r) {
+		render.JSON(w, http.StatusOK, p)
+		return
+	}
+	h.render.Page(w, "post", data)
+}
+
+// Partial serves the latest posts for the home page, or them as JSON. It
+// is empty when there are no posts.
+func (h *Handler) Partial(w http.ResponseWriter, r *http.Request) {
+	data, _ := h.data()
+	posts := data.Posts
+	if len(posts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	posts = posts[:min(len(posts), latestPosts)]
+	h.render.Respond(w, r, "blog", ListData{PageData: data, Posts: posts}, withoutBodies(posts))
+}
+
+// API serves the posts, newest first and without their bodies, as JSON. It
+// supports ?tag= (case insensitive), ?sort=date (most recent first) or
+// ?sort=title and ?limit=&offset=.
+func (h *Handler) API(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	p, err := render.ParseListPage(q)
+	if err != nil {
+		render.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	data, _ := h.data()
+	posts := withoutBodies(tagged(data.Posts, q.Get("tag")))
+	switch q.Get("sort") {
+	case "", "date":
+		// Posts are loaded newest first.
+	case "title":
+		slices.SortStableFunc(posts, func(a, b blog.Post) int {
+			return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		})
+	default:
+		render.JSONError(w, http.StatusBadRequest, fmt.Sprintf("unsupported sort %q", q.Get("sort")))
+		return
+	}
+
+	render.JSON(w, http.StatusOK, render.Paginate(r, posts, p))
+}
+
+// tagged returns a copy of the posts tagged tag, or of all of them when
+// tag is empty.
+func tagged(posts []blog.Post, tag string) []blog.Post {
+	posts = slices.Clone(posts)
+	if tag != "" {
+		posts = slices.DeleteFunc(posts, func(p blog.Post) bool { return !p.HasTag(tag) })
+	}
+	return posts
+}
+
+// withoutBodies returns a copy of posts without their HTML, for listings.
+func withoutBodies(posts []blog.Post) []blog.Post {
+	list := make([]blog.Post, len(posts))
+	for i, p := range posts {
+		p.HTML = ""
+		list[i] = p
+	}
+	return list
+}
@@ -0,0 +1,127 @@
+package blog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/blog"
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+func date(month time.Month, day int) time.Time {
+	return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// testPosts are newest first, as blog.Load returns them.
+var testPosts = []blog.Post{
+	{Slug: "htmx", Title: "HTMX partials", Date: date(time.April, 1), Tags: []string{"htmx", "Go"}, Summary: "Swapping sections.", HTML: "<p>Swapping sections.</p>"},
+	{Slug: "go-mux", Title: "Routing with ServeMux", Date: date(time.March, 1), Tags: []string{"go"}, Summary: "Patterns.", HTML: "<p>Patterns.</p>"},
+	{Slug: "sqlite", Title: "analytics in SQLite", Date: date(time.February, 1), Tags: []string{"sql"}, Summary: "Counting.", HTML: "<p>Counting.</p>"},
+	{Slug: "hello", Title: "Hello", Date: date(time.January, 1), Summary: "First.", HTML: "<p>First.</p>"},
+}
+
+func newHandler(t *testing.T, posts []blog.Post, opts Options) *Handler {
+	t.Helper()
+	tmpl, err := render.Parse(os.DirFS("../../.."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := func() (content.PageData, uint64) {
+		return content.PageData{About: content.About{Name: "Ada Example"}, Posts: posts}, 1
+	}
+	baseURL := func(*http.Request) string { return "http://example.com" }
+	return New(render.New(tmpl), data, baseURL, opts)
+}
+
+func TestAPI(t *testing.T) {
+	h := newHandler(t, testPosts, Options{})
+	for _, tt := range []struct {
+		query  string
+		status int
+		slugs  []string
+	}{
+		{"", http.StatusOK, []string{"htmx", "go-mux", "sqlite", "hello"}},
+		{"?sort=date", http.StatusOK, []string{"htmx", "go-mux", "sqlite", "hello"}},
+		{"?tag=GO", http.StatusOK, []string{"htmx", "go-mux"}},
+		{"?tag=rust", http.StatusOK, []string{}},
+		{"?sort=title", http.StatusOK, []string{"sqlite", "hello", "htmx", "go-mux"}},
+		{"?tag=go&sort=title&limit=1", http.StatusOK, []string{"htmx"}},
+		{"?limit=2&offset=2", http.StatusOK, []string{"sqlite", "hello"}},
+		{"?sort=stars", http.StatusBadRequest, nil},
+		{"?offset=-1", http.StatusBadRequest, nil},
+	} {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.API(rec, httptest.NewRequest(http.MethodGet, "/api/posts"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var resp render.ListResponse[blog.Post]
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			slugs := []string{}
+			for _, p := range resp.Items {
+				slugs = append(slugs, p.Slug)
+				if p.HTML != "" {
+					t.Errorf("%s: the list has the post's body", p.Slug)
+				}
+			}
+			if !slices.Equal(slugs, tt.slugs) {
+				t.Errorf("slugs = %q, want %q", slugs, tt.slugs)
+			}
+		})
+	}
+}
+
+func TestPartial(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newHandler(t, nil, Options{}).Partial(rec, httptest.NewRequest(http.MethodGet, "/partials/blog", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("without posts: status = %d, want 204", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	newHandler(t, testPosts, Options{}).Partial(rec, httptest.NewRequest(http.MethodGet, "/partials/blog", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, p := range testPosts[:latestPosts] {
+		if !strings.Contains(body, `href="/blog/`+p.Slug+`"`) {
+			t.Errorf("partial doesn't link to %s", p.Slug)
+		}
+	}
+	if strings.Contains(body, "/blog/hello") {
+		t.Errorf("partial shows more than the %d latest posts", latestPosts)
+	}
+}
+
+func TestPost(t *testing.T) {
+	notFound := func(w http.ResponseWriter, r *http.Request) { http.Error(w, "custom 404", http.StatusNotFound) }
+	h := newHandler(t, testPosts, Options{NotFound: notFound})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /blog/{slug}", h.Post)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/blog/go-mux", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<p>Patterns.</p>") {
+		t.Errorf("post: status = %d, want 200 with the body", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/blog/missing", nil))
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "custom 404") {
+		t.Errorf("unknown post: %d %q, want Options.NotFound", rec.Code, rec.Body)
+	}
+}
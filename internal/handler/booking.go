@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fpatron/portfolio/internal/booking"
+)
+
+// BookingData is rendered by the booking section.
+type BookingData struct {
+	Days []booking.Day `json:"days"`
+	// Zone names the time zone the slots are shown in.
+	Zone string `json:"zone"`
+}
+
+// SetBooking replaces the open slots shown in the booking section.
+func (h *Handler) SetBooking(data BookingData) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.booking = data
+	h.syncVariants(func(v *Handler) { v.booking = data })
+}
+
+// Booking serves the "book a call" partial, or the open slots as JSON. It
+// is empty while there are no open slots.
+func (h *Handler) Booking(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	data := h.booking
+	h.mu.RUnlock()
+	if len(data.Days) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.render.Respond(w, r, "booking", data, data)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/fpatron/portfolio/internal/books"
+)
+
+// BookshelfData is rendered by the "books" partial.
+type BookshelfData struct {
+	Reading []books.Book `json:"reading"`
+	Read    []books.Book `json:"read"` // most recently finished first
+}
+
+// Bookshelf serves the reading list partial, or the shelves as JSON. The
+// books come from the store, or from data/books.json without one. It is
+// empty until books have been imported.
+func (h *Handler) Bookshelf(w http.ResponseWriter, r *http.Request) {
+	var data BookshelfData
+	if h.opts.Books == nil {
+		data = shelves(h.Data().Books)
+	} else {
+		var err error
+		if data.Reading, err = h.opts.Books.Shelf(r.Context(), books.ShelfReading, 6); err == nil {
+			data.Read, err = h.opts.Books.Shelf(r.Context(), books.ShelfRead, 12)
+		}
+		if err != nil {
+			log.Printf("bookshelf: %v", err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+	if len(data.Reading) == 0 && len(data.Read) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.render.Respond(w, r, "books", data, data)
+}
+
+// shelves sorts list into the shelves, with the limits of the store's.
+func shelves(list []books.Book) BookshelfData {
+	var data BookshelfData
+	for _, b := range list {
+		switch b.Shelf {
+		case books.ShelfReading:
+			data.Reading = append(data.Reading, b)
+		case books.ShelfRead:
+			data.Read = append(data.Read, b)
+		}
+	}
+	slices.SortStableFunc(data.Read, func(a, b books.Book) int { return b.Finished.Compare(a.Finished) })
+	data.Reading = data.Reading[:min(len(data.Reading), 6)]
+	data.Read = data.Read[:min(len(data.Read), 12)]
+	return data
+}
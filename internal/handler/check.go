@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/books"
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/github"
+	bloghandler "github.com/fpatron/portfolio/internal/handler/blog"
+	"github.com/fpatron/portfolio/internal/handler/contact"
+	"github.com/fpatron/portfolio/internal/nowplaying"
+	"github.com/fpatron/portfolio/internal/render"
+	"github.com/fpatron/portfolio/internal/stackexchange"
+	"github.com/fpatron/portfolio/internal/strava"
+	"github.com/fpatron/portfolio/internal/talks"
+	"github.com/fpatron/portfolio/internal/uptime"
+	"github.com/fpatron/portfolio/internal/workhours"
+	"github.com/fpatron/portfolio/internal/youtube"
+)
+
+// CheckTemplates executes every template the handlers render by name
+// against representative data: the loaded data files, with whatever they
+// leave empty filled in. It catches a misspelled or renamed field, or a
+// template that does not exist, before a request hits it.
+func (h *Handler) CheckTemplates() error {
+	data, _ := h.data()
+	return checkTemplates(h.render.Templates(), data)
+}
+
+// RenderTemplates renders the templates CheckTemplates checks, with the
+// same data, for tools that inspect the HTML they produce.
+func (h *Handler) RenderTemplates() ([]render.Output, error) {
+	data, _ := h.data()
+	shared, pages := samples(data)
+	return h.render.Templates().Render(shared, pages)
+}
+
+// checkTemplates checks t against samples built from data.
+func checkTemplates(t *render.Templates, data content.PageData) error {
+	shared, pages := samples(data)
+	return t.Check(shared, pages)
+}
+
+// samples returns representative data for the shared templates and the
+// pages the handlers render, by name. A template rendered under a new name
+// belongs here.
+func samples(data content.PageData) (shared, pages map[string]any) {
+	shared = map[string]any{
+		"base":                  data,
+		"about":                 data,
+		"projects":              data,
+		"interests":             data,
+		"availability":          data.About,
+		"videos":                []youtube.Video{},
+		"talks":                 []talks.Talk{},
+		"books":                 BookshelfData{},
+		"social":                SocialData{},
+		"booking":               BookingData{},
+		"webmentions":           WebmentionsData{},
+		"comments":              CommentsData{},
+		"github-stats":          &github.Stats{},
+		"stackexchange":         &stackexchange.Profile{},
+		"strava":                &strava.Activity{},
+		"nowplaying":            &nowplaying.Track{},
+		"status":                &uptime.Status{},
+		"viewers":               0,
+		"subscribers":           0,
+		"search-results":        SearchData{},
+		"newsletter":            contact.NewsletterData{},
+		"newsletter-subscribed": contact.NewsletterData{},
+		"contact-form":          content.ContactForm{},
+		"contact-token":         "",
+		"guestbook":             GuestbookData{},
+		"guestbook-signed":      GuestbookForm{},
+		"localtime":             workhours.Status{},
+		"blog":                  bloghandler.ListData{PageData: data},
+		// Called by the templates above, and checked alone so problems
+		// point at them.
+		"contact":        data,
+		"guestbook-form": GuestbookForm{},
+		"project-card":   content.Project{},
+		"project-like":   content.Project{},
+		"book":           books.Book{},
+		"post-list":      bloghandler.ListData{PageData: data},
+		"comment":        github.Comment{},
+	}
+	pages = map[string]any{
+		"project":           data,
+		"not-found":         data,
+		"blog":              bloghandler.ListData{PageData: data},
+		"post":              data,
+		"indieauth":         IndieAuthData{PageData: data},
+		"admin-stats":       AdminStatsData{PageData: data},
+		"admin-jobs":        AdminJobsData{PageData: data},
+		"admin-webmentions": AdminWebmentionsData{PageData: data},
+		"admin-guestbook":   AdminGuestbookData{PageData: data},
+		"admin-links":       AdminLinksData{PageData: data},
+		"admin-messages":    AdminMessagesData{PageData: data},
+	}
+	for _, m := range []map[string]any{shared, pages} {
+		for name, v := range m {
+			p := reflect.New(reflect.TypeOf(v))
+			p.Elem().Set(reflect.ValueOf(v))
+			fill(p.Elem(), 0)
+			m[name] = p.Elem().Interface()
+		}
+	}
+	return shared, pages
+}
+
+// sampleTime fills in empty times.
+var sampleTime = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+// fill sets the empty fields, elements and pointers under v to sample
+// values, so templates take the branches that real data would. Values v
+// shares with the loaded data are copied before they are changed.
+func fill(v reflect.Value, depth int) {
+	if depth > 8 {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		p := reflect.New(v.Type().Elem())
+		if !v.IsNil() {
+			p.Elem().Set(v.Elem())
+		}
+		v.Set(p)
+		fill(p.Elem(), depth+1)
+	case reflect.Struct:
+		if v.Type() == reflect.TypeFor[time.Time]() {
+			if v.IsZero() {
+				v.Set(reflect.ValueOf(sampleTime))
+			}
+			return
+		}
+		for i := range v.NumField() {
+			if f := v.Field(i); f.CanSet() {
+				fill(f, depth+1)
+			}
+		}
+	case reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), max(v.Len(), 1), max(v.Len(), 1))
+		reflect.Copy(s, v)
+		v.Set(s)
+		for i := range s.Len() {
+			fill(s.Index(i), depth+1)
+		}
+	case reflect.Map:
+		if v.Len() > 0 {
+			return
+		}
+		k, e := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+		fill(k, depth+1)
+		fill(e, depth+1)
+		m := reflect.MakeMap(v.Type())
+		m.SetMapIndex(k, e)
+		v.Set(m)
+	case reflect.String:
+		if v.Len() == 0 {
+			v.SetString("example")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() == 0 {
+			v.SetInt(1)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() == 0 {
+			v.SetUint(1)
+		}
+	case reflect.Float32, reflect.Float64:
+		if v.Float() == 0 {
+			v.SetFloat(1)
+		}
+	case reflect.Bool:
+		v.SetBool(true)
+	}
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/render"
+	"github.com/fpatron/portfolio/internal/shortlinks"
+)
+
+// Command is an entry of the command palette: a place on the site or
+// elsewhere that it jumps to.
+type Command struct {
+	// Group is "section", "project", "post" or "link".
+	Group string `json:"group"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	// Keywords are other words the command is found by.
+	Keywords []string `json:"keywords,omitempty"`
+	// External is set for links leaving the site.
+	External bool `json:"external,omitempty"`
+}
+
+// commands lists the palette's entries for data: the enabled sections of
+// the menu, the projects, the blog posts, the profiles of data/about.json and the short
+// links of data/links.json, in that order. Entries for disabled routes are
+// left out.
+func (h *Handler) commands(data content.PageData) []Command {
+	var cmds []Command
+	for _, s := range data.Sections {
+		if s.Nav.Label == "" {
+			continue
+		}
+		cmds = append(cmds, Command{Group: "section", Title: s.Nav.Label, URL: s.Nav.Href, Keywords: []string{s.Name}})
+	}
+	if data.HasSection("about") {
+		cmds = append(cmds, Command{Group: "section", Title: "Resume (PDF)", URL: "/resume.pdf", Keywords: []string{"cv", "download"}})
+	}
+	for _, p := range data.Projects {
+		keywords := slices.Clone(p.Tags)
+		if p.Language != "" {
+			keywords = append(keywords, p.Language)
+		}
+		cmds = append(cmds, Command{Group: "project", Title: p.Title, URL: "/projects/" + p.Slug, Keywords: keywords})
+	}
+	if data.HasSection("blog") {
+		for _, p := range data.Posts {
+			cmds = append(cmds, Command{Group: "post", Title: p.Title, URL: "/blog/" + p.Slug, Keywords: p.Tags})
+		}
+	}
+	a := data.About
+	var mailto string
+	if a.Email != "" {
+		mailto = "mailto:" + a.Email
+	}
+	for _, p := range []Command{
+		{Title: "GitHub", URL: a.GitHub, Keywords: []string{"code", "repositories"}},
+		{Title: "LinkedIn", URL: a.LinkedIn, Keywords: []string{"work", "career"}},
+		{Title: "X", URL: a.X, Keywords: []string{"twitter"}},
+		{Title: "Email", URL: mailto, Keywords: []string{"contact", "mail", a.Email}},
+	} {
+		if p.URL != "" {
+			p.Group, p.External = "link", true
+			cmds = append(cmds, p)
+		}
+	}
+	for _, l := range data.ShortLinks {
+		title := l.Title
+		if title == "" {
+			title = shortlinks.Prefix + l.Code
+		}
+		cmds = append(cmds, Command{
+			Group:    "link",
+			Title:    title,
+			URL:      shortlinks.Prefix + l.Code,
+			Keywords: []string{l.Code},
+			External: !strings.HasPrefix(l.URL, "/"),
+		})
+	}
+	return slices.DeleteFunc(cmds, func(c Command) bool {
+		return strings.HasPrefix(c.URL, "/") && !data.Serves(c.URL)
+	})
+}
+
+// APICommands serves the command palette's entries as JSON. It supports
+// ?group= (repeatable) and ?limit=&offset=.
+func (h *Handler) APICommands(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	p, err := render.ParseListPage(q)
+	if err != nil {
+		render.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	data, _ := h.data()
+	cmds := h.commands(data)
+	if groups := q["group"]; len(groups) > 0 {
+		cmds = slices.DeleteFunc(cmds, func(c Command) bool { return !slices.Contains(groups, c.Group) })
+	}
+	render.JSON(w, http.StatusOK, render.Paginate(r, cmds, p))
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/github"
+)
+
+// CommentsData is rendered by the "comments" partial.
+type CommentsData struct {
+	// Discussion is nil until someone starts the thread.
+	Discussion *github.Discussion `json:"discussion"`
+	// NewURL starts the thread on GitHub.
+	NewURL string `json:"new_url"`
+}
+
+// Comments serves the GitHub Discussion thread of a project page, or it as
+// JSON. Threads are matched by the title "projects/<slug>".
+func (h *Handler) Comments(w http.ResponseWriter, r *http.Request) {
+	data, _ := h.data()
+	p, ok := data.FindProject(r.PathValue("slug"))
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.comments(w, r, "projects/"+p.Slug, p.Title)
+}
+
+// PostComments serves the thread of a blog post like Comments does for
+// projects. Threads are matched by the title "blog/<slug>".
+func (h *Handler) PostComments(w http.ResponseWriter, r *http.Request) {
+	data, _ := h.data()
+	p, ok := data.FindPost(r.PathValue("slug"))
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.comments(w, r, "blog/"+p.Slug, p.Title)
+}
+
+// comments serves the thread titled title, which is also the path of the
+// page, named name, that it is about.
+func (h *Handler) comments(w http.ResponseWriter, r *http.Request, title, name string) {
+	if h.opts.Discussions == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	d, err := h.opts.Discussions.Thread(r.Context(), title)
+	if err != nil {
+		log.Printf("comments: %v", err)
+		if d == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	page := strings.TrimSuffix(h.baseURL(r), "/") + "/" + title
+	out := CommentsData{
+		Discussion: d,
+		NewURL:     h.opts.Discussions.NewURL(title, "Comments on ["+name+"]("+page+")"),
+	}
+	h.render.Respond(w, r, "comments", out, out)
+}
@@ -0,0 +1,207 @@
+// Package contact serves the contact section: the contact form, whose
+// funnel it records, and the newsletter signup.
+package contact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fpatron/portfolio/internal/analytics"
+	"github.com/fpatron/portfolio/internal/captcha"
+	"github.com/fpatron/portfolio/internal/clientip"
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/form"
+	"github.com/fpatron/portfolio/internal/metrics"
+	"github.com/fpatron/portfolio/internal/newsletter"
+	"github.com/fpatron/portfolio/internal/ratelimit"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+// Submission is a message sent through the contact form.
+type Submission struct {
+	Name    string
+	Email   string
+	Message string
+	// IP and UserAgent identify the client that sent it.
+	IP        string
+	UserAgent string
+}
+
+// Options configures a Handler.
+type Options struct {
+	// Deliver sends a submission on, reporting whether it reached at least
+	// one destination along with the errors of those it didn't.
+	Deliver func(context.Context, Submission) (bool, error)
+	// Analytics, when set, records the contact funnel.
+	Analytics *analytics.Recorder
+	// Newsletter, when set, backs the newsletter signup form.
+	Newsletter newsletter.Provider
+	// Captcha, when set, verifies the form's CAPTCHA.
+	Captcha *captcha.Verifier
+	// Limiter, when set, limits how often each address may post the form.
+	Limiter *ratelimit.Limiter
+	// Guard rejects submissions from bots. It defaults to a minimum fill
+	// time of 3 seconds and 5 submissions per address an hour.
+	Guard *Guard
+}
+
+// Bounds of the contact form's fields, in characters. The form's
+// maxlength and minlength attributes match them.
+const (
+	maxName    = 100
+	maxEmail   = 254
+	minMessage = 10
+	maxMessage = 5000
+)
+
+// Handler serves the contact section.
+type Handler struct {
+	render *render.Renderer
+	opts   Options
+}
+
+// New returns a Handler rendering with r.
+func New(r *render.Renderer, opts Options) *Handler {
+	if opts.Guard == nil {
+		opts.Guard = NewGuard(3*time.Second, 5, time.Hour)
+	}
+	return &Handler{render: r, opts: opts}
+}
+
+// funnelGoals maps contact funnel steps to the analytics goals storing them.
+var funnelGoals = map[string]string{
+	metrics.StepViewed:    analytics.GoalContactViewed,
+	metrics.StepSubmitted: analytics.GoalContact,
+	metrics.StepValidated: analytics.GoalContactValidated,
+	metrics.StepDelivered: analytics.GoalContactDelivered,
+}
+
+// funnel records that a visitor reached step of the contact flow, both in
+// the Prometheus counters and, when enabled, in analytics.
+func (h *Handler) funnel(r *http.Request, step string) {
+	metrics.ContactFunnel.WithLabelValues(step).Inc()
+	if h.opts.Analytics != nil {
+		h.opts.Analytics.RecordGoal(r, funnelGoals[step])
+	}
+}
+
+// Viewed is the beacon the contact form sends when it scrolls into view,
+// the first step of the contact funnel. It returns the form's token.
+func (h *Handler) Viewed(w http.ResponseWriter, r *http.Request) {
+	h.funnel(r, metrics.StepViewed)
+	w.Header().Set("Cache-Control", "no-store")
+	h.render.HTML(w, "contact-token", h.opts.Guard.Token())
+}
+
+// Submit handles the contact form POST and returns a success fragment, or
+// the form again with an error when the Guard rejects it.
+func (h *Handler) Submit(w http.ResponseWriter, r *http.Request) {
+	h.funnel(r, metrics.StepSubmitted)
+	vals, err := form.Parse(r, form.MaxBytes)
+	if err != nil {
+		form.Error(w, err)
+		return
+	}
+	ip := clientip.FromRequest(r).String()
+	f := content.ContactForm{
+		Name:    strings.TrimSpace(vals.Get("name")),
+		Email:   strings.TrimSpace(vals.Get("email")),
+		Message: strings.TrimSpace(vals.Get("message")),
+		Token:   vals.Get("token"),
+		Captcha: h.opts.Captcha.Widget(),
+	}
+	if h.opts.Limiter != nil {
+		if ok, wait := h.opts.Limiter.Allow(ip); !ok {
+			log.Printf("contact form rate limited (ip=%s)", ip)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			f.Error = "You're sending messages too quickly. Please wait a moment and try again."
+			h.reject(w, http.StatusTooManyRequests, f)
+			return
+		}
+	}
+	if f.Errors = checkForm(f); f.Errors != (content.ContactFormErrors{}) {
+		h.reject(w, http.StatusOK, f)
+		return
+	}
+	if err := h.opts.Guard.Check(vals.Get("website"), f.Token, ip); err != nil {
+		log.Printf("contact form rejected: %v (ip=%s)", err, ip)
+		f.Token = h.opts.Guard.Token()
+		f.Error = "Your message couldn't be sent. Please wait a few seconds and try again."
+		if errors.Is(err, errTooMany) {
+			f.Error = "You have sent several messages already. Please try again later."
+		}
+		h.reject(w, http.StatusOK, f)
+		return
+	}
+	if h.opts.Captcha != nil {
+		if err := h.opts.Captcha.Verify(r.Context(), vals, ip); err != nil {
+			log.Printf("contact form rejected: %v (ip=%s)", err, ip)
+			f.Error = "Please complete the verification and try again."
+			if !errors.Is(err, captcha.ErrFailed) {
+				f.Error = "The verification could not be checked. Please try again later."
+			}
+			h.reject(w, http.StatusOK, f)
+			return
+		}
+	}
+	h.funnel(r, metrics.StepValidated)
+	log.Printf("contact form submission: name=%q email=%q message_len=%d", f.Name, f.Email, len(f.Message))
+
+	delivered, err := h.opts.Deliver(r.Context(), Submission{
+		Name:      f.Name,
+		Email:     f.Email,
+		Message:   f.Message,
+		IP:        ip,
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		log.Printf("contact delivery: %v", err)
+	}
+	if delivered {
+		h.funnel(r, metrics.StepDelivered)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, `<div class="contact-success"><p>Thanks for reaching out — I'll be in touch soon.</p></div>`)
+}
+
+// reject renders f again, with what was typed into it and its errors,
+// with the given status.
+func (h *Handler) reject(w http.ResponseWriter, status int, f content.ContactForm) {
+	w.Header().Set("Cache-Control", "no-store")
+	h.render.HTMLStatus(w, status, "contact-form", f)
+}
+
+// checkForm returns the errors of f's fields.
+func checkForm(f content.ContactForm) content.ContactFormErrors {
+	var errs content.ContactFormErrors
+	switch {
+	case f.Name == "":
+		errs.Name = "Please enter your name."
+	case utf8.RuneCountInString(f.Name) > maxName:
+		errs.Name = "Your name is too long."
+	}
+	if f.Email == "" {
+		errs.Email = "Please enter your email address."
+	} else if addr, err := mail.ParseAddress(f.Email); err != nil || addr.Address != f.Email || len(f.Email) > maxEmail {
+		errs.Email = "That doesn't look like an email address."
+	}
+	switch n := utf8.RuneCountInString(f.Message); {
+	case n == 0:
+		errs.Message = "Please write a message."
+	case n < minMessage:
+		errs.Message = "Your message is too short."
+	case n > maxMessage:
+		errs.Message = "Your message is too long."
+	}
+	return errs
+}
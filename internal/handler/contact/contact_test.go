@@ -0,0 +1,179 @@
+package contact
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/ratelimit"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+func TestCheckForm(t *testing.T) {
+	valid := content.ContactForm{Name: "Ada", Email: "ada@example.com", Message: "Hello there, nice site."}
+	for _, tt := range []struct {
+		name string
+		edit func(*content.ContactForm)
+		want content.ContactFormErrors
+	}{
+		{"valid", func(*content.ContactForm) {}, content.ContactFormErrors{}},
+		{"no name", func(f *content.ContactForm) { f.Name = "" }, content.ContactFormErrors{Name: "Please enter your name."}},
+		{"long name", func(f *content.ContactForm) { f.Name = strings.Repeat("é", maxName+1) }, content.ContactFormErrors{Name: "Your name is too long."}},
+		{"name at the bound", func(f *content.ContactForm) { f.Name = strings.Repeat("é", maxName) }, content.ContactFormErrors{}},
+		{"no email", func(f *content.ContactForm) { f.Email = "" }, content.ContactFormErrors{Email: "Please enter your email address."}},
+		{"bad email", func(f *content.ContactForm) { f.Email = "ada" }, content.ContactFormErrors{Email: "That doesn't look like an email address."}},
+		{"email with a name", func(f *content.ContactForm) { f.Email = "Ada <ada@example.com>" }, content.ContactFormErrors{Email: "That doesn't look like an email address."}},
+		{"no message", func(f *content.ContactForm) { f.Message = "" }, content.ContactFormErrors{Message: "Please write a message."}},
+		{"short message", func(f *content.ContactForm) { f.Message = "Hi!" }, content.ContactFormErrors{Message: "Your message is too short."}},
+		{"long message", func(f *content.ContactForm) { f.Message = strings.Repeat("a", maxMessage+1) }, content.ContactFormErrors{Message: "Your message is too long."}},
+		{"all empty", func(f *content.ContactForm) { *f = content.ContactForm{} }, content.ContactFormErrors{
+			Name:    "Please enter your name.",
+			Email:   "Please enter your email address.",
+			Message: "Please write a message.",
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			f := valid
+			tt.edit(&f)
+			if got := checkForm(f); got != tt.want {
+				t.Errorf("checkForm = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// clock is a time that tests move forward.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func newHandler(t *testing.T, opts Options) (*Handler, *[]Submission) {
+	t.Helper()
+	tmpl, err := render.Parse(os.DirFS("../../.."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent []Submission
+	opts.Deliver = func(_ context.Context, s Submission) (bool, error) {
+		sent = append(sent, s)
+		return true, nil
+	}
+	return New(render.New(tmpl), opts), &sent
+}
+
+func post(h *Handler, vals url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/contact", strings.NewReader(vals.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.Submit(rec, req)
+	return rec
+}
+
+func TestSubmit(t *testing.T) {
+	c := &clock{t: time.Date(2026, time.January, 14, 9, 30, 0, 0, time.UTC)}
+	guard := NewGuard(3*time.Second, 5, time.Hour)
+	guard.Now = c.now
+	h, sent := newHandler(t, Options{Guard: guard})
+	token := guard.Token()
+	c.t = c.t.Add(5 * time.Second)
+
+	form := func(edit func(url.Values)) url.Values {
+		vals := url.Values{
+			"name":    {" Ada "},
+			"email":   {"ada@example.com"},
+			"message": {"Hello there, nice site."},
+			"token":   {token},
+		}
+		edit(vals)
+		return vals
+	}
+	for _, tt := range []struct {
+		name string
+		vals url.Values
+		want []string
+	}{
+		{"invalid fields", form(func(v url.Values) { v.Set("email", "ada"); v.Del("message") }),
+			[]string{"That doesn&#39;t look like an email address.", "Please write a message.", `value="ada"`}},
+		{"honeypot", form(func(v url.Values) { v.Set("website", "http://spam.example") }),
+			[]string{"Your message couldn&#39;t be sent."}},
+		{"no token", form(func(v url.Values) { v.Del("token") }),
+			[]string{"Your message couldn&#39;t be sent."}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := post(h, tt.vals)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			body := rec.Body.String()
+			for _, s := range tt.want {
+				if !strings.Contains(body, s) {
+					t.Errorf("response doesn't have %q:\n%s", s, body)
+				}
+			}
+			if strings.Contains(body, "contact-success") {
+				t.Error("rejected submission was accepted")
+			}
+		})
+	}
+	if len(*sent) > 0 {
+		t.Fatalf("rejected submissions were delivered: %+v", *sent)
+	}
+
+	rec := post(h, form(func(url.Values) {}))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "contact-success") {
+		t.Fatalf("valid submission: %d %s", rec.Code, rec.Body)
+	}
+	if len(*sent) != 1 || (*sent)[0].Name != "Ada" || (*sent)[0].Email != "ada@example.com" {
+		t.Errorf("delivered %+v, want one submission from Ada", *sent)
+	}
+}
+
+func TestSubmitTooFast(t *testing.T) {
+	c := &clock{t: time.Date(2026, time.January, 14, 9, 30, 0, 0, time.UTC)}
+	guard := NewGuard(3*time.Second, 5, time.Hour)
+	guard.Now = c.now
+	h, sent := newHandler(t, Options{Guard: guard})
+	vals := url.Values{"name": {"Ada"}, "email": {"ada@example.com"}, "message": {"Hello there, nice site."}, "token": {guard.Token()}}
+
+	c.t = c.t.Add(time.Second)
+	if rec := post(h, vals); strings.Contains(rec.Body.String(), "contact-success") {
+		t.Error("form sent a second after it was shown was accepted")
+	}
+	c.t = c.t.Add(3 * time.Second)
+	if rec := post(h, vals); !strings.Contains(rec.Body.String(), "contact-success") {
+		t.Errorf("form sent 4 seconds after it was shown: %s", rec.Body)
+	}
+	if len(*sent) != 1 {
+		t.Errorf("%d submissions delivered, want 1", len(*sent))
+	}
+}
+
+func TestSubmitRateLimited(t *testing.T) {
+	guard := NewGuard(0, 0, time.Hour)
+	limiter := ratelimit.PerMinute(1, 1)
+	h, sent := newHandler(t, Options{Guard: guard, Limiter: limiter})
+	vals := url.Values{"name": {"Ada"}, "email": {"ada@example.com"}, "message": {"Hello there, nice site."}, "token": {guard.Token()}}
+
+	if rec := post(h, vals); !strings.Contains(rec.Body.String(), "contact-success") {
+		t.Fatalf("first submission: %d %s", rec.Code, rec.Body)
+	}
+	rec := post(h, vals)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second submission: status = %d, want 429", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("429 without Retry-After")
+	}
+	if !strings.Contains(rec.Body.String(), "Hello there, nice site.") {
+		t.Error("the form lost the message")
+	}
+	if len(*sent) != 1 {
+		t.Errorf("%d submissions delivered, want 1", len(*sent))
+	}
+}
@@ -0,0 +1,132 @@
+package contact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenMaxAge is how long a form token stays valid.
+const tokenMaxAge = 24 * time.Hour
+
+// Reasons a Guard rejects a submission.
+var (
+	errNoToken  = errors.New("missing or invalid form token")
+	errExpired  = errors.New("form token expired")
+	errTooFast  = errors.New("form filled in too fast")
+	errHoneypot = errors.New("honeypot field filled in")
+	errTooMany  = errors.New("too many submissions")
+)
+
+// Guard keeps bots away from the contact form. The form carries a token
+// signed with the time it was shown, so posts without one, or sent sooner
+// than MinFillTime after it, are rejected; and each address may submit at
+// most Limit times per Window.
+type Guard struct {
+	MinFillTime time.Duration
+	Limit       int
+	Window      time.Duration
+	// Now returns the current time; it defaults to time.Now.
+	Now func() time.Time
+
+	key []byte // signs tokens; they don't survive a restart
+
+	mu   sync.Mutex
+	hits map[string][]time.Time
+}
+
+// NewGuard returns a Guard with the given settings.
+func NewGuard(minFillTime time.Duration, limit int, window time.Duration) *Guard {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return &Guard{MinFillTime: minFillTime, Limit: limit, Window: window, key: key, hits: make(map[string][]time.Time)}
+}
+
+func (g *Guard) now() time.Time {
+	if g.Now != nil {
+		return g.Now()
+	}
+	return time.Now()
+}
+
+// Token returns a token for a form shown now.
+func (g *Guard) Token() string {
+	ts := strconv.FormatInt(g.now().UnixMilli(), 10)
+	return ts + "." + g.sign(ts)
+}
+
+func (g *Guard) sign(ts string) string {
+	m := hmac.New(sha256.New, g.key)
+	m.Write([]byte(ts))
+	return hex.EncodeToString(m.Sum(nil)[:16])
+}
+
+// checkToken verifies token and that the form was shown at least
+// MinFillTime ago.
+func (g *Guard) checkToken(token string) error {
+	ts, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(g.sign(ts))) {
+		return errNoToken
+	}
+	ms, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return errNoToken
+	}
+	age := g.now().Sub(time.UnixMilli(ms))
+	switch {
+	case age > tokenMaxAge:
+		return errExpired
+	case age < g.MinFillTime:
+		return errTooFast
+	}
+	return nil
+}
+
+// allow counts a submission from addr and reports whether it is within
+// the limit.
+func (g *Guard) allow(addr string) bool {
+	if g.Limit <= 0 {
+		return true
+	}
+	now := g.now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	// Forget the addresses that have been quiet for a window, so the map
+	// doesn't grow with every visitor.
+	for a, times := range g.hits {
+		if now.Sub(times[len(times)-1]) >= g.Window {
+			delete(g.hits, a)
+		}
+	}
+	recent := g.hits[addr]
+	for len(recent) > 0 && now.Sub(recent[0]) >= g.Window {
+		recent = recent[1:]
+	}
+	if len(recent) >= g.Limit {
+		g.hits[addr] = recent
+		return false
+	}
+	g.hits[addr] = append(recent, now)
+	return true
+}
+
+// Check vets a submission: honeypot is the value of the field hidden from
+// people, token the form's token and addr the client address.
+func (g *Guard) Check(honeypot, token, addr string) error {
+	if honeypot != "" {
+		return errHoneypot
+	}
+	if err := g.checkToken(token); err != nil {
+		return err
+	}
+	if !g.allow(addr) {
+		return errTooMany
+	}
+	return nil
+}
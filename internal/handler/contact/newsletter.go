@@ -0,0 +1,63 @@
+package contact
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/form"
+	"github.com/fpatron/portfolio/internal/newsletter"
+)
+
+// NewsletterData is rendered by the newsletter signup form.
+type NewsletterData struct {
+	Email string
+	Error string
+}
+
+// NewsletterForm serves the signup form partial. It is empty when no
+// newsletter provider is configured.
+func (h *Handler) NewsletterForm(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Newsletter == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.render.HTML(w, "newsletter", NewsletterData{})
+}
+
+// Subscribe handles the newsletter form POST. It returns a confirmation
+// fragment, or the form again with an error message.
+func (h *Handler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Newsletter == nil {
+		http.NotFound(w, r)
+		return
+	}
+	vals, err := form.Parse(r, form.MaxBytes)
+	if err != nil {
+		form.Error(w, err)
+		return
+	}
+	data := NewsletterData{Email: strings.TrimSpace(vals.Get("email"))}
+	if addr, err := mail.ParseAddress(data.Email); err != nil || addr.Address != data.Email {
+		data.Error = "That doesn't look like an email address."
+		h.render.HTML(w, "newsletter", data)
+		return
+	}
+
+	err = h.opts.Newsletter.Subscribe(r.Context(), data.Email)
+	switch {
+	case errors.Is(err, newsletter.ErrRejected):
+		log.Printf("newsletter: %v", err)
+		data.Error = "That address can't be subscribed. Please check it and try again."
+		h.render.HTML(w, "newsletter", data)
+		return
+	case err != nil:
+		log.Printf("newsletter: subscribe: %v", err)
+		data.Error = "Something went wrong. Please try again later."
+		h.render.HTML(w, "newsletter", data)
+		return
+	}
+	h.render.HTML(w, "newsletter-subscribed", data)
+}
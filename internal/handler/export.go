@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"time"
+)
+
+// exportRoots are the directories of fsys included in an export.
+var exportRoots = []string{"data", "content"}
+
+// exportFiles lists every regular file under exportRoots, skipping roots
+// that don't exist.
+func (h *Handler) exportFiles() ([]string, error) {
+	var files []string
+	for _, root := range exportRoots {
+		err := fs.WalkDir(h.fsys, root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type().IsRegular() {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// Export streams every data and content file as one bundle for backups and
+// migrations. ?format=json (default) produces an object mapping file paths to
+// their contents, with JSON files embedded as-is; ?format=zip produces an
+// archive of the files.
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	files, err := h.exportFiles()
+	if err != nil {
+		log.Printf("export: list files: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	stamp := time.Now().UTC().Format("20060102-150405")
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="portfolio-export-%s.json"`, stamp))
+		err = h.writeJSONExport(w, files)
+	case "zip":
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="portfolio-export-%s.zip"`, stamp))
+		err = h.writeZipExport(w, files)
+	default:
+		http.Error(w, "unsupported format", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		// Headers are already sent; the truncated body is the only signal.
+		log.Printf("export: %v", err)
+	}
+}
+
+func (h *Handler) writeJSONExport(w io.Writer, files []string) error {
+	if _, err := fmt.Fprintf(w, `{"exported_at":%q,"files":{`, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	for i, f := range files {
+		b, err := fs.ReadFile(h.fsys, f)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", f, err)
+		}
+		value := json.RawMessage(b)
+		if path.Ext(f) != ".json" || !json.Valid(b) {
+			if value, err = json.Marshal(string(b)); err != nil {
+				return err
+			}
+		}
+		key, _ := json.Marshal(f)
+		sep := ","
+		if i == 0 {
+			sep = ""
+		}
+		if _, err := fmt.Fprintf(w, "%s%s:%s", sep, key, compactJSON(value)); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "}}\n")
+	return err
+}
+
+func compactJSON(b []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return b
+	}
+	return buf.Bytes()
+}
+
+func (h *Handler) writeZipExport(w io.Writer, files []string) error {
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		src, err := h.fsys.Open(f)
+		if err != nil {
+			return fmt.Errorf("open %s: %w", f, err)
+		}
+		dst, err := zw.CreateHeader(&zip.FileHeader{Name: f, Method: zip.Deflate, Modified: time.Now()})
+		if err == nil {
+			_, err = io.Copy(dst, src)
+		}
+		src.Close()
+		if err != nil {
+			return fmt.Errorf("write %s: %w", f, err)
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"bytes"
+	"cmp"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/feed"
+)
+
+// FeedOptions configures /feed.xml and /atom.xml.
+type FeedOptions struct {
+	// Title is the feed's title; the name of data/about.json when empty.
+	Title string
+	// Posts and Projects are the most items of each kind. Zero uses the
+	// defaults, 20 posts and 10 projects, and a negative limit leaves the
+	// kind out.
+	Posts, Projects int
+}
+
+// feedDocs are the cached feed documents and the origin their links use.
+type feedDocs struct {
+	base      string
+	updated   time.Time
+	rss, atom []byte
+}
+
+// RSS serves the feed of blog posts and projects as RSS 2.0.
+func (h *Handler) RSS(w http.ResponseWriter, r *http.Request) {
+	h.serveFeed(w, r, "application/rss+xml; charset=utf-8", func(f feedDocs) []byte { return f.rss })
+}
+
+// Atom serves the feed of blog posts and projects as Atom.
+func (h *Handler) Atom(w http.ResponseWriter, r *http.Request) {
+	h.serveFeed(w, r, "application/atom+xml; charset=utf-8", func(f feedDocs) []byte { return f.atom })
+}
+
+// serveFeed serves one of the feeds, built on first request and cached
+// until the data changes.
+func (h *Handler) serveFeed(w http.ResponseWriter, r *http.Request, contentType string, doc func(feedDocs) []byte) {
+	data, version := h.data()
+	base := h.baseURL(r)
+	build := func() (feedDocs, error) {
+		f := h.feed(data, base)
+		rss, err := feed.RSS(f)
+		if err != nil {
+			return feedDocs{}, err
+		}
+		f.Self = base + "/atom.xml"
+		atom, err := feed.Atom(f)
+		return feedDocs{base: base, updated: f.Updated, rss: rss, atom: atom}, err
+	}
+	f, err := h.feedDocs.Get(version, build)
+	if err == nil && f.base != base {
+		// Without a configured BASE_URL, links follow the requested host,
+		// which the cached feeds may not match.
+		f, err = build()
+	}
+	if err != nil {
+		log.Printf("feed: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	http.ServeContent(w, r, "", f.updated, bytes.NewReader(doc(f)))
+}
+
+// feed lists the latest posts and projects of the enabled sections, newest
+// first. Projects without a date, those not synced from a repository,
+// come last in the order of the data files.
+func (h *Handler) feed(data content.PageData, base string) feed.Feed {
+	f := feed.Feed{
+		Title:       cmp.Or(h.opts.Feed.Title, data.About.Name),
+		Description: data.About.Tagline,
+		Link:        base + "/",
+		Self:        base + "/feed.xml",
+		Author:      data.About.Name,
+	}
+	if data.HasSection("blog") {
+		for _, p := range data.Posts[:feedLimit(h.opts.Feed.Posts, 20, len(data.Posts))] {
+			f.Items = append(f.Items, feed.Item{
+				Title:      p.Title,
+				Link:       base + "/blog/" + p.Slug,
+				Summary:    p.Summary,
+				Content:    string(p.HTML),
+				Categories: p.Tags,
+				Published:  p.Date,
+			})
+		}
+	}
+	if data.HasSection("projects") {
+		projects := slices.Clone(data.Projects)
+		slices.SortStableFunc(projects, func(a, b content.Project) int { return b.PushedAt.Compare(a.PushedAt) })
+		for _, p := range projects[:feedLimit(h.opts.Feed.Projects, 10, len(projects))] {
+			f.Items = append(f.Items, feed.Item{
+				Title:      p.Title,
+				Link:       base + "/projects/" + p.Slug,
+				Summary:    p.Description,
+				Categories: p.Tags,
+				Published:  p.PushedAt,
+			})
+		}
+	}
+	slices.SortStableFunc(f.Items, func(a, b feed.Item) int { return b.Published.Compare(a.Published) })
+	if len(f.Items) > 0 {
+		f.Updated = f.Items[0].Published
+	}
+	if f.Updated.IsZero() {
+		// Nothing is dated: the feed changes when the data is loaded.
+		f.Updated = time.Now().Truncate(time.Second)
+	}
+	return f
+}
+
+// feedLimit returns how many of n items a feed takes for the configured
+// limit and its default.
+func feedLimit(limit, def, n int) int {
+	if limit == 0 {
+		limit = def
+	}
+	return min(max(limit, 0), n)
+}
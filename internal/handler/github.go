@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fpatron/portfolio/internal/github"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+// SetGitHubStats replaces the stats served by the GitHub endpoints. On a
+// failed refresh the caller simply doesn't call it, so the last good stats
+// keep being served.
+func (h *Handler) SetGitHubStats(st github.Stats) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.githubStats = &st
+	h.syncVariants(func(v *Handler) { v.githubStats = &st })
+}
+
+func (h *Handler) gitHubStats() *github.Stats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.githubStats
+}
+
+// APIGitHubStats serves the cached GitHub stats as JSON.
+func (h *Handler) APIGitHubStats(w http.ResponseWriter, r *http.Request) {
+	st := h.gitHubStats()
+	if st == nil {
+		render.JSONError(w, http.StatusServiceUnavailable, "github stats not available yet")
+		return
+	}
+	render.JSON(w, http.StatusOK, st)
+}
+
+// GitHubStats serves the GitHub stats partial. It is empty until the first
+// fetch succeeds.
+func (h *Handler) GitHubStats(w http.ResponseWriter, r *http.Request) {
+	st := h.gitHubStats()
+	if st == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.render.HTML(w, "github-stats", st)
+}
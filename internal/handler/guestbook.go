@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/form"
+	"github.com/fpatron/portfolio/internal/guestbook"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+// Guestbook limits.
+const (
+	guestbookEntries    = 50 // approved entries shown
+	guestbookMaxName    = 80
+	guestbookMaxWebsite = 200
+	guestbookMaxMessage = 1000
+)
+
+// GuestbookData is rendered by the "guestbook" partial.
+type GuestbookData struct {
+	Entries []guestbook.Entry `json:"entries"` // newest first
+	Form    GuestbookForm     `json:"-"`
+}
+
+// GuestbookForm is rendered by the signing form, filled in again when a
+// submission is rejected.
+type GuestbookForm struct {
+	Name    string
+	Website string
+	Message string
+	Error   string
+}
+
+// AdminGuestbookData is passed to the moderation page.
+type AdminGuestbookData struct {
+	content.PageData
+	Pending  []guestbook.Entry
+	Approved []guestbook.Entry
+}
+
+// Guestbook serves the guestbook section: the signing form and the approved
+// entries, or the entries as JSON. It is empty without a database.
+func (h *Handler) Guestbook(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Guestbook == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	list, err := h.opts.Guestbook.Approved(r.Context(), guestbookEntries)
+	if err != nil {
+		log.Printf("guestbook: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	data := GuestbookData{Entries: list}
+	h.render.Respond(w, r, "guestbook", data, data)
+}
+
+// SignGuestbook handles the guestbook form POST. It returns a thank-you
+// fragment, or the form again with an error message. The entry waits for
+// moderation before it is shown.
+func (h *Handler) SignGuestbook(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Guestbook == nil {
+		http.NotFound(w, r)
+		return
+	}
+	vals, err := form.Parse(r, form.MaxBytes)
+	if err != nil {
+		form.Error(w, err)
+		return
+	}
+	f := GuestbookForm{
+		Name:    strings.TrimSpace(vals.Get("name")),
+		Website: strings.TrimSpace(vals.Get("website")),
+		Message: strings.TrimSpace(vals.Get("message")),
+	}
+	// The company field is hidden from people; bots that fill it in are
+	// thanked like everyone else, and their entry is dropped.
+	if vals.Get("company") != "" {
+		h.render.HTML(w, "guestbook-signed", f)
+		return
+	}
+	if f.Error = checkGuestbookForm(f); f.Error != "" {
+		h.render.HTML(w, "guestbook-form", f)
+		return
+	}
+	id, err := h.opts.Guestbook.Sign(r.Context(), guestbook.Entry{Name: f.Name, Website: f.Website, Message: f.Message})
+	if err != nil {
+		log.Printf("guestbook: %v", err)
+		f.Error = "Something went wrong. Please try again later."
+		h.render.HTML(w, "guestbook-form", f)
+		return
+	}
+	log.Printf("guestbook: entry %d awaiting moderation: name=%q message_len=%d", id, f.Name, len(f.Message))
+	h.render.HTML(w, "guestbook-signed", f)
+}
+
+// checkGuestbookForm returns what is wrong with a submission, or "".
+func checkGuestbookForm(f GuestbookForm) string {
+	switch {
+	case f.Name == "":
+		return "Please enter your name."
+	case utf8.RuneCountInString(f.Name) > guestbookMaxName:
+		return "Your name is too long."
+	case f.Message == "":
+		return "Please write a message."
+	case utf8.RuneCountInString(f.Message) > guestbookMaxMessage:
+		return "Your message is over " + strconv.Itoa(guestbookMaxMessage) + " characters."
+	}
+	if f.Website != "" {
+		u, err := url.Parse(f.Website)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(f.Website) > guestbookMaxWebsite {
+			return "Your website should be a link starting with https://."
+		}
+	}
+	return ""
+}
+
+// AdminGuestbook renders the queue of entries awaiting moderation and the
+// latest approved ones.
+func (h *Handler) AdminGuestbook(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Guestbook == nil {
+		http.Error(w, "the guestbook is disabled", http.StatusNotFound)
+		return
+	}
+	pending, err := h.opts.Guestbook.Pending(r.Context())
+	if err != nil {
+		log.Printf("admin guestbook: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	approved, err := h.opts.Guestbook.Approved(r.Context(), guestbookEntries)
+	if err != nil {
+		log.Printf("admin guestbook: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	data, _ := h.data()
+	data.Theme = render.Theme(r)
+	w.Header().Set("Cache-Control", "no-store")
+	h.render.Page(w, "admin-guestbook", AdminGuestbookData{PageData: data, Pending: pending, Approved: approved})
+}
+
+// ModerateGuestbook approves or rejects an entry; rejecting an approved
+// one hides it again. The form field status is "approved" or "rejected".
+// As with ModerateWebmention, only HTMX requests are accepted.
+func (h *Handler) ModerateGuestbook(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Guestbook == nil {
+		http.Error(w, "the guestbook is disabled", http.StatusNotFound)
+		return
+	}
+	if r.Header.Get("HX-Request") == "" {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	status := r.FormValue("status")
+	if err != nil || (status != guestbook.StatusApproved && status != guestbook.StatusRejected) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	ok, err := h.opts.Guestbook.SetStatus(r.Context(), id, status)
+	if err != nil {
+		log.Printf("moderate guestbook: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	// An empty 200 lets hx-swap="outerHTML" remove the row.
+	w.WriteHeader(http.StatusOK)
+}
@@ -1,125 +1,391 @@
 package handler
 
 import (
+	"cmp"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"io/fs"
-	"log"
 	"net/http"
-	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
 
-	gomail "gopkg.in/mail.v2"
+	"github.com/fpatron/portfolio/internal/analytics"
+	"github.com/fpatron/portfolio/internal/blog"
+	"github.com/fpatron/portfolio/internal/books"
+	"github.com/fpatron/portfolio/internal/captcha"
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/github"
+	"github.com/fpatron/portfolio/internal/guestbook"
+	"github.com/fpatron/portfolio/internal/handler/about"
+	bloghandler "github.com/fpatron/portfolio/internal/handler/blog"
+	"github.com/fpatron/portfolio/internal/handler/contact"
+	"github.com/fpatron/portfolio/internal/handler/projects"
+	"github.com/fpatron/portfolio/internal/images"
+	"github.com/fpatron/portfolio/internal/inbox"
+	"github.com/fpatron/portfolio/internal/indieauth"
+	"github.com/fpatron/portfolio/internal/jsonresume"
+	"github.com/fpatron/portfolio/internal/likes"
+	"github.com/fpatron/portfolio/internal/newsletter"
+	"github.com/fpatron/portfolio/internal/nowplaying"
+	"github.com/fpatron/portfolio/internal/pkgstats"
+	"github.com/fpatron/portfolio/internal/ratelimit"
+	"github.com/fpatron/portfolio/internal/render"
+	"github.com/fpatron/portfolio/internal/repos"
+	"github.com/fpatron/portfolio/internal/scheduler"
+	"github.com/fpatron/portfolio/internal/search"
+	"github.com/fpatron/portfolio/internal/shortlinks"
+	"github.com/fpatron/portfolio/internal/sse"
+	"github.com/fpatron/portfolio/internal/stackexchange"
+	"github.com/fpatron/portfolio/internal/strava"
+	"github.com/fpatron/portfolio/internal/terminal"
+	"github.com/fpatron/portfolio/internal/uptime"
+	"github.com/fpatron/portfolio/internal/webmention"
+	"github.com/fpatron/portfolio/internal/workhours"
+	"github.com/fpatron/portfolio/internal/youtube"
 )
 
-// Project represents a portfolio project loaded from data/projects.json.
-type Project struct {
-	Title       string   `json:"title"`
-	Description string   `json:"description"`
-	Tags        []string `json:"tags"`
-	Link        string   `json:"link"`
-	Image       string   `json:"image"`
-}
-
-// Interest represents a personal interest loaded from data/interests.json.
-type Interest struct {
-	Emoji       string `json:"emoji"`
-	Label       string `json:"label"`
-	Description string `json:"description"`
-}
-
-// SkillCategory represents a labeled group of skills loaded from data/skills.json.
-type SkillCategory struct {
-	Category string   `json:"category"`
-	Skills   []string `json:"skills"`
-}
-
-// Experience represents a single entry in data/experience.json.
-type Experience struct {
-	Role        string   `json:"role"`
-	Company     string   `json:"company"`
-	CompanyURL  string   `json:"company_url"`
-	Logo        string   `json:"logo"`
-	StartDate   string   `json:"start_date"`
-	EndDate     string   `json:"end_date"`
-	Dates       []string `json:"dates"`
-	Location    string   `json:"location"`
-	Description []string `json:"description"`
-	Type        string   `json:"type"` // "work" or "education"
-}
-
-// About holds profile data loaded from data/about.json.
-type About struct {
-	Name              string `json:"name"`
-	Tagline           string `json:"tagline"`
-	Bio               string `json:"bio"`
-	Location          string `json:"location"`
-	Availability      bool   `json:"availability"`
-	YearsOfExperience int    `json:"years_of_experience"`
-	Email             string `json:"email"`
-	GitHub            string `json:"github"`
-	LinkedIn          string `json:"linkedin"`
-	X                 string `json:"x"`
-	ProfilePhoto      string `json:"profile_photo"`
-}
-
-// PageData is passed to all templates.
-type PageData struct {
-	About      About
-	Projects   []Project
-	Interests  []Interest
-	Skills     []SkillCategory
-	Experience []Experience
-}
-
-// Handler holds parsed templates and pre-loaded page data.
+// Options configures a Handler.
+type Options struct {
+	// BaseURL is the canonical origin of the site, e.g.
+	// "https://francispatron.com". When empty it is derived from each request.
+	BaseURL string
+	// AnalyticsScript is a trusted <script> tag added to every page.
+	AnalyticsScript template.HTML
+	// Analytics, when set, records outbound clicks and contact conversions
+	// and backs the admin stats page.
+	Analytics *analytics.Recorder
+	// UTMSource, when set, tags outbound project links with utm_source,
+	// utm_medium=portfolio and utm_campaign=<project slug>.
+	UTMSource string
+	// Hooks, when set, extend the handler: data load hooks adjust the data
+	// files and contact hooks deliver contact form messages.
+	Hooks *Hooks
+	// Events is the SSE broker whose open streams back the live viewer count.
+	Events *sse.Broker
+	// Books, when set, backs the bookshelf section.
+	Books *books.Store
+	// IndieAuth, when set, makes the site an IndieAuth identity whose
+	// consent screen is served by IndieAuthorize.
+	IndieAuth *indieauth.Server
+	// Webmentions, when set, stores the mentions shown under project pages
+	// and backs the moderation queue.
+	Webmentions *webmention.Store
+	// ShortLinks, when set, stores the short links added from the admin
+	// page, next to those of data/links.json.
+	ShortLinks *shortlinks.Store
+	// Likes, when set, counts the likes of the projects.
+	Likes *likes.Store
+	// Guestbook, when set, stores the guestbook entries and backs their
+	// moderation queue.
+	Guestbook *guestbook.Store
+	// Inbox, when set, stores the contact form messages and backs their
+	// admin page.
+	Inbox *inbox.Store
+	// Discussions, when set, supplies the GitHub Discussion threads shown
+	// as comments under project pages.
+	Discussions *github.Discussions
+	// Images, when set, caches remote project images and serves them from
+	// the site's origin.
+	Images *images.Cache
+	// Newsletter, when set, backs the newsletter signup form.
+	Newsletter newsletter.Provider
+	// Captcha, when set, verifies the CAPTCHA of the contact form.
+	Captcha *captcha.Verifier
+	// ContactLimiter, when set, rate limits the contact form's posts.
+	ContactLimiter *ratelimit.Limiter
+	// ContactGuard, when set, replaces the contact form's default
+	// defenses against bots.
+	ContactGuard *contact.Guard
+	// Hours, when set, are the working hours the contact section shows the
+	// local time against.
+	Hours *workhours.Hours
+	// Now returns the current time the local time is shown at; it defaults
+	// to time.Now.
+	Now func() time.Time
+	// Jobs, when set, backs the admin jobs page.
+	Jobs *scheduler.Scheduler
+	// DisabledSections names sections to leave out: their routes, menu
+	// links, home page elements and sitemap entries.
+	DisabledSections []string
+	// DisabledRoutes are paths, such as "/contact" or "/api", whose routes
+	// and the routes under them answer with the 404 page. A section whose
+	// main route is disabled is left out like a disabled one.
+	DisabledRoutes []string
+	// Feed configures the feeds of blog posts and projects.
+	Feed FeedOptions
+	// Drafts lists the blog posts marked as drafts along with the others.
+	Drafts bool
+	// Strict makes New and Reload reject templates that CheckTemplates
+	// finds fault with.
+	Strict bool
+	// Lang is the language of the templates and data files, "en" when
+	// empty, and Languages those the site is served in; see Locale.
+	Lang      string
+	Languages []string
+}
+
+// Handler serves the site from its templates and pre-loaded page data. The
+// sections with handler packages of their own are served through it.
 type Handler struct {
-	tmpl     *template.Template
-	pageData PageData
+	fsys fs.FS
+	opts Options
+
+	render *render.Renderer
+
+	// The sections with handler packages of their own.
+	about    *about.Handler
+	projects *projects.Handler
+	blog     *bloghandler.Handler
+	contact  *contact.Handler
+
+	mu       sync.RWMutex
+	files    content.PageData          // as loaded from data/
+	repos    []repos.Repo              // from the last repository sync
+	packages map[string]pkgstats.Stats // by Project.Package
+	pageData content.PageData          // files merged with repos and packages
+	version  uint64
+
+	githubStats *github.Stats
+	social      SocialData
+	nowPlaying  *nowplaying.Track
+	strava      *strava.Activity
+	subscribers int
+	booking     BookingData
+	uptime      *uptime.Status
+	videos      []youtube.Video
+
+	stackExchange *stackexchange.Profile
+
+	// variants render other templates and data files with the same
+	// synced data; see Variant.
+	variants []*Handler
+
+	searchIdx render.Cache[*search.Index]
+	feedDocs  render.Cache[feedDocs]
+	qrCodes   qrCache
+	ogImages  ogCache
 }
 
 // New creates a Handler by parsing templates and loading JSON data from fsys.
-func New(fsys fs.FS) (*Handler, error) {
-	tmpl, err := template.ParseFS(fsys, "templates/*.html")
+func New(fsys fs.FS, opts Options) (*Handler, error) {
+	tmpl, err := render.Parse(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := loadPageData(fsys)
 	if err != nil {
-		return nil, fmt.Errorf("parse templates: %w", err)
+		return nil, err
+	}
+
+	opts.BaseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	if err := opts.Hooks.loadData(&data); err != nil {
+		return nil, err
+	}
+	h := &Handler{
+		fsys:     fsys,
+		opts:     opts,
+		render:   render.New(tmpl),
+		files:    data,
+		pageData: data,
 	}
+	h.about = about.New(h.render, h.data, h.baseURL)
+	h.projects = projects.New(h.render, h.data, h.baseURL, projects.Options{
+		Analytics:   opts.Analytics,
+		UTMSource:   opts.UTMSource,
+		Webmentions: opts.Webmentions != nil,
+		Likes:       opts.Likes,
+	})
+	h.blog = bloghandler.New(h.render, h.data, h.baseURL, bloghandler.Options{
+		NotFound:    h.NotFound,
+		Webmentions: opts.Webmentions != nil,
+	})
+	h.contact = contact.New(h.render, contact.Options{
+		Deliver:    opts.Hooks.deliver,
+		Analytics:  opts.Analytics,
+		Newsletter: opts.Newsletter,
+		Guard:      opts.ContactGuard,
+		Captcha:    opts.Captcha,
+		Limiter:    opts.ContactLimiter,
+	})
+	if opts.Strict {
+		if err := h.CheckTemplates(); err != nil {
+			return nil, fmt.Errorf("check templates: %w", err)
+		}
+	}
+	return h, nil
+}
 
-	var about About
-	if err := loadJSON(fsys, "data/about.json", &about); err != nil {
-		return nil, fmt.Errorf("load about.json: %w", err)
+// Reload re-reads the templates and JSON data files. On failure the
+// previously loaded ones stay in place. Values derived from the data are
+// rebuilt on next use.
+func (h *Handler) Reload() error {
+	tmpl, err := render.Parse(h.fsys)
+	if err != nil {
+		return err
 	}
+	data, err := loadPageData(h.fsys)
+	if err != nil {
+		return err
+	}
+	if err := h.opts.Hooks.loadData(&data); err != nil {
+		return err
+	}
+	if h.opts.Strict {
+		if err := checkTemplates(tmpl, h.decorate(data)); err != nil {
+			return fmt.Errorf("check templates: %w", err)
+		}
+	}
+	h.render.Set(tmpl)
+	h.mu.Lock()
+	h.files = data
+	h.merge()
+	h.mu.Unlock()
+	for _, v := range h.variants {
+		if err := v.Reload(); err != nil {
+			return fmt.Errorf("variant: %w", err)
+		}
+	}
+	return nil
+}
 
-	var projects []Project
-	if err := loadJSON(fsys, "data/projects.json", &projects); err != nil {
-		return nil, fmt.Errorf("load projects.json: %w", err)
+// merge rebuilds pageData from the data files, repositories and package
+// statistics, and bumps the data version. The caller holds h.mu.
+func (h *Handler) merge() {
+	h.pageData = mergePackages(mergeRepos(h.files, h.repos), h.packages)
+	h.version++
+}
+
+// data returns the current page data and its version.
+func (h *Handler) data() (content.PageData, uint64) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.decorate(h.pageData), h.version
+}
+
+// decorate adds the fields that come from the options rather than the data
+// files to data.
+func (h *Handler) decorate(data content.PageData) content.PageData {
+	data.AnalyticsScript = h.opts.AnalyticsScript
+	data.IndieAuth = h.opts.IndieAuth != nil
+	data.Lang = cmp.Or(h.opts.Lang, "en")
+	data.Languages = h.opts.Languages
+	data.DisabledRoutes = h.opts.DisabledRoutes
+	data.ContactForm.Captcha = h.opts.Captcha.Widget()
+	if !h.opts.Drafts {
+		data.Posts = blog.Published(data.Posts)
+	}
+	for _, sec := range h.Sections() {
+		data.Sections = append(data.Sections, sec.Section)
 	}
+	return data
+}
+
+// Data returns the currently loaded page data.
+func (h *Handler) Data() content.PageData {
+	data, _ := h.data()
+	return data
+}
 
-	var interests []Interest
-	if err := loadJSON(fsys, "data/interests.json", &interests); err != nil {
-		return nil, fmt.Errorf("load interests.json: %w", err)
+func loadPageData(fsys fs.FS) (content.PageData, error) {
+	// A resume in the JSON Resume format can stand in for the files
+	// below, which are then optional. Those present take precedence.
+	var resume *jsonresume.Resume
+	if err := loadOptionalJSON(fsys, jsonresume.File, &resume); err != nil {
+		return content.PageData{}, fmt.Errorf("load resume.json: %w", err)
+	}
+	load := loadJSON
+	if resume != nil {
+		load = loadOptionalJSON
 	}
 
-	var skills []SkillCategory
-	if err := loadJSON(fsys, "data/skills.json", &skills); err != nil {
-		return nil, fmt.Errorf("load skills.json: %w", err)
+	var about *content.About
+	if err := load(fsys, "data/about.json", &about); err != nil {
+		return content.PageData{}, fmt.Errorf("load about.json: %w", err)
 	}
 
-	var experience []Experience
-	if err := loadJSON(fsys, "data/experience.json", &experience); err != nil {
-		return nil, fmt.Errorf("load experience.json: %w", err)
+	var projects []content.Project
+	if err := load(fsys, "data/projects.json", &projects); err != nil {
+		return content.PageData{}, fmt.Errorf("load projects.json: %w", err)
 	}
 
-	return &Handler{
-		tmpl: tmpl,
-		pageData: PageData{
-			About:      about,
-			Projects:   projects,
-			Interests:  interests,
-			Skills:     skills,
-			Experience: experience,
-		},
-	}, nil
+	var interests []content.Interest
+	if err := load(fsys, "data/interests.json", &interests); err != nil {
+		return content.PageData{}, fmt.Errorf("load interests.json: %w", err)
+	}
+
+	var skills []content.SkillCategory
+	if err := load(fsys, "data/skills.json", &skills); err != nil {
+		return content.PageData{}, fmt.Errorf("load skills.json: %w", err)
+	}
+
+	var experience []content.Experience
+	if err := load(fsys, "data/experience.json", &experience); err != nil {
+		return content.PageData{}, fmt.Errorf("load experience.json: %w", err)
+	}
+
+	if resume != nil {
+		r := resume.Data()
+		if about == nil {
+			about = &r.About
+		}
+		if projects == nil {
+			projects = r.Projects
+		}
+		if interests == nil {
+			interests = r.Interests
+		}
+		if skills == nil {
+			skills = r.Skills
+		}
+		if experience == nil {
+			experience = r.Experience
+		}
+	}
+	if about == nil {
+		about = new(content.About)
+	}
+	for i := range projects {
+		if projects[i].Slug == "" {
+			projects[i].Slug = slugify(projects[i].Title)
+		}
+	}
+
+	data := content.PageData{
+		About:      *about,
+		Projects:   projects,
+		Interests:  interests,
+		Skills:     skills,
+		Experience: experience,
+	}
+
+	// The fetched files are optional. Repositories from data/repos.json
+	// are merged like synced ones, which update them at runtime.
+	var fetched []repos.Repo
+	if err := loadOptionalJSON(fsys, "data/repos.json", &fetched); err != nil {
+		return content.PageData{}, fmt.Errorf("load repos.json: %w", err)
+	}
+	data = mergeRepos(data, fetched)
+	if err := loadOptionalJSON(fsys, "data/talks.json", &data.Talks); err != nil {
+		return content.PageData{}, fmt.Errorf("load talks.json: %w", err)
+	}
+	if err := loadOptionalJSON(fsys, "data/books.json", &data.Books); err != nil {
+		return content.PageData{}, fmt.Errorf("load books.json: %w", err)
+	}
+	if err := loadOptionalJSON(fsys, "data/links.json", &data.ShortLinks); err != nil {
+		return content.PageData{}, fmt.Errorf("load links.json: %w", err)
+	}
+	posts, err := blog.Load(fsys, blog.Dir)
+	if err != nil {
+		return content.PageData{}, fmt.Errorf("load posts: %w", err)
+	}
+	data.Posts = posts
+	return data, nil
 }
 
 func loadJSON(fsys fs.FS, path string, v any) error {
@@ -131,62 +397,70 @@ func loadJSON(fsys fs.FS, path string, v any) error {
 	return json.NewDecoder(f).Decode(v)
 }
 
-func (h *Handler) execute(w http.ResponseWriter, name string, data any) {
-	if err := h.tmpl.ExecuteTemplate(w, name, data); err != nil {
-		log.Printf("template %q error: %v", name, err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+// loadOptionalJSON is loadJSON for files that may be absent, which leave v
+// unchanged.
+func loadOptionalJSON(fsys fs.FS, path string, v any) error {
+	if err := loadJSON(fsys, path, v); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
 	}
+	return nil
 }
 
-// Index serves the full single-page application.
-func (h *Handler) Index(w http.ResponseWriter, r *http.Request) {
-	h.execute(w, "base", h.pageData)
-}
-
-// About serves the about section partial for HTMX.
-func (h *Handler) About(w http.ResponseWriter, r *http.Request) {
-	h.execute(w, "about", h.pageData)
+// slugify lowercases s and joins its alphanumeric runs with hyphens.
+func slugify(s string) string {
+	var sb strings.Builder
+	for _, f := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if sb.Len() > 0 {
+			sb.WriteByte('-')
+		}
+		sb.WriteString(f)
+	}
+	return sb.String()
 }
 
-// Projects serves the projects grid partial for HTMX.
-func (h *Handler) Projects(w http.ResponseWriter, r *http.Request) {
-	h.execute(w, "projects", h.pageData)
+// baseURL returns the configured canonical origin, falling back to the
+// scheme and host the request arrived on.
+func (h *Handler) baseURL(r *http.Request) string {
+	if h.opts.BaseURL != "" {
+		return h.opts.BaseURL
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
 }
 
-// Interests serves the interests grid partial for HTMX.
-func (h *Handler) Interests(w http.ResponseWriter, r *http.Request) {
-	h.execute(w, "interests", h.pageData)
+// Fragment renders the named template to a string, for pushing HTML
+// fragments over channels other than an HTTP response.
+func (h *Handler) Fragment(name string, data any) (string, error) {
+	return h.render.Fragment(name, data)
 }
 
-// Contact handles the contact form POST and returns a success fragment.
-func (h *Handler) Contact(w http.ResponseWriter, r *http.Request) {
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, "bad request", http.StatusBadRequest)
+// Index serves the full single-page application, all page data as JSON, or
+// a text rendition of it to curl and other terminal clients.
+func (h *Handler) Index(w http.ResponseWriter, r *http.Request) {
+	data, _ := h.data()
+	data.BaseURL = h.baseURL(r)
+	data.URL = data.BaseURL + "/"
+	data.Theme = render.Theme(r)
+	data = data.WithMeta()
+	w.Header().Add("Vary", "User-Agent")
+	if text, color := terminal.Wants(r); text {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Add("Vary", "Accept")
+		io.WriteString(w, terminal.Render(data, color))
 		return
 	}
-	name := r.FormValue("name")
-	email := r.FormValue("email")
-	message := r.FormValue("message")
-	log.Printf("contact form submission: name=%q email=%q message_len=%d", name, email, len(message))
-
-	gmailUser := os.Getenv("GMAIL_USER")
-	gmailPass := os.Getenv("GMAIL_APP_PASSWORD")
-	if gmailUser != "" && gmailPass != "" {
-		m := gomail.NewMessage()
-		m.SetHeader("From", gmailUser)
-		m.SetHeader("To", gmailUser)
-		m.SetHeader("Reply-To", email)
-		m.SetHeader("Subject", fmt.Sprintf("[francispatron.dev] New message from %s", name))
-		m.SetBody("text/plain", fmt.Sprintf("Sent from francispatron.com\n\nName: %s\nEmail: %s\n\n%s", name, email, message))
-
-		d := gomail.NewDialer("smtp.gmail.com", 465, gmailUser, gmailPass)
-		if err := d.DialAndSend(m); err != nil {
-			log.Printf("failed to send email: %v", err)
-		}
-	}
+	h.render.Respond(w, r, "base", data, data)
+}
 
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	fmt.Fprint(w, `<div class="contact-success"><p>Thanks for reaching out — I'll be in touch soon.</p></div>`)
+// Interests serves the interests grid partial for HTMX.
+func (h *Handler) Interests(w http.ResponseWriter, r *http.Request) {
+	data, _ := h.data()
+	h.render.Respond(w, r, "interests", data, data.Interests)
 }
 
 // Health returns 200 OK for health checks.
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/handler/contact"
+	"github.com/fpatron/portfolio/internal/images"
+	"github.com/fpatron/portfolio/internal/inbox"
+	"github.com/fpatron/portfolio/internal/mailer"
+	"github.com/fpatron/portfolio/internal/notify"
+)
+
+// Hooks are extension points called at fixed moments of the site's
+// lifecycle. The built-in integrations use them too. Register hooks before
+// the server starts; registering is not safe while requests are served.
+type Hooks struct {
+	dataLoad []func(*content.PageData) error
+	request  []func(http.Handler) http.Handler
+	contact  []func(context.Context, contact.Submission) error
+	publish  []func(context.Context, content.PageData) error
+	routes   []func(*http.ServeMux)
+}
+
+// OnDataLoad registers f to adjust the data files each time they are
+// loaded, before synced data is merged in. An error fails the load.
+func (k *Hooks) OnDataLoad(f func(*content.PageData) error) {
+	k.dataLoad = append(k.dataLoad, f)
+}
+
+// OnRequest registers middleware for every request. Each one wraps those
+// registered before it.
+func (k *Hooks) OnRequest(mw func(http.Handler) http.Handler) {
+	k.request = append(k.request, mw)
+}
+
+// OnContactSubmission registers f to deliver contact form messages. A
+// message counts as delivered when at least one of them succeeds.
+func (k *Hooks) OnContactSubmission(f func(context.Context, contact.Submission) error) {
+	k.contact = append(k.contact, f)
+}
+
+// OnPublish registers f to announce the site's content elsewhere, at
+// startup and after each reload.
+func (k *Hooks) OnPublish(f func(context.Context, content.PageData) error) {
+	k.publish = append(k.publish, f)
+}
+
+// ExtraRoutes registers f to add routes to the server's mux.
+func (k *Hooks) ExtraRoutes(f func(*http.ServeMux)) {
+	k.routes = append(k.routes, f)
+}
+
+// Middleware wraps next in the OnRequest middleware.
+func (k *Hooks) Middleware(next http.Handler) http.Handler {
+	if k == nil {
+		return next
+	}
+	for _, mw := range k.request {
+		next = mw(next)
+	}
+	return next
+}
+
+// Routes adds the ExtraRoutes to mux.
+func (k *Hooks) Routes(mux *http.ServeMux) {
+	if k == nil {
+		return
+	}
+	for _, f := range k.routes {
+		f(mux)
+	}
+}
+
+// Publish runs the OnPublish hooks and returns their joined errors.
+func (k *Hooks) Publish(ctx context.Context, data content.PageData) error {
+	if k == nil {
+		return nil
+	}
+	var errs []error
+	for _, f := range k.publish {
+		errs = append(errs, f(ctx, data))
+	}
+	return errors.Join(errs...)
+}
+
+// loadData runs the OnDataLoad hooks on data.
+func (k *Hooks) loadData(data *content.PageData) error {
+	if k == nil {
+		return nil
+	}
+	for _, f := range k.dataLoad {
+		if err := f(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliver runs the OnContactSubmission hooks and reports whether any
+// succeeded, along with the errors of those that failed.
+func (k *Hooks) deliver(ctx context.Context, s contact.Submission) (bool, error) {
+	if k == nil {
+		return false, nil
+	}
+	delivered := false
+	var errs []error
+	for _, f := range k.contact {
+		if err := f(ctx, s); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delivered = true
+	}
+	return delivered, errors.Join(errs...)
+}
+
+// MailContact returns a contact hook that emails messages to q's sender
+// address, with an HTML body rendered from emails' contact.html. With
+// autoReply, the sender also gets a confirmation rendered from emails'
+// autoreply.html and texts' autoreply.txt. The mails are queued, so the
+// submission doesn't wait on the SMTP server.
+func MailContact(q *mailer.Queue, emails *template.Template, texts *texttemplate.Template, autoReply bool) func(context.Context, contact.Submission) error {
+	return func(_ context.Context, s contact.Submission) error {
+		var html strings.Builder
+		if err := emails.ExecuteTemplate(&html, "contact.html", s); err != nil {
+			return fmt.Errorf("render contact email: %w", err)
+		}
+		err := q.Send(mailer.Message{
+			To:      []string{q.From()},
+			ReplyTo: s.Email,
+			Subject: fmt.Sprintf("[francispatron.dev] New message from %s", s.Name),
+			Text:    fmt.Sprintf("Sent from francispatron.com\n\nName: %s\nEmail: %s\n\n%s", s.Name, s.Email, s.Message),
+			HTML:    html.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("send email: %w", err)
+		}
+		if autoReply {
+			// The message reached the owner; a failed confirmation is
+			// only logged.
+			if err := sendAutoReply(q, emails, texts, s.Email); err != nil {
+				log.Printf("contact auto-reply: %v", err)
+			}
+		}
+		return nil
+	}
+}
+
+// sendAutoReply queues the confirmation of a message to its sender, to.
+// The address is all the submitter chooses, so the templates get no data:
+// a confirmation carrying the name or the message would let anyone mail
+// text of their choosing to someone else's address.
+func sendAutoReply(q *mailer.Queue, emails *template.Template, texts *texttemplate.Template, to string) error {
+	var html, text strings.Builder
+	if err := emails.ExecuteTemplate(&html, "autoreply.html", nil); err != nil {
+		return fmt.Errorf("render auto-reply: %w", err)
+	}
+	if err := texts.ExecuteTemplate(&text, "autoreply.txt", nil); err != nil {
+		return fmt.Errorf("render auto-reply: %w", err)
+	}
+	return q.Send(mailer.Message{
+		To:      []string{to},
+		ReplyTo: q.From(),
+		Subject: "Thanks for your message",
+		Text:    text.String(),
+		HTML:    html.String(),
+	})
+}
+
+// StoreContact returns a contact hook that keeps messages in store.
+func StoreContact(store *inbox.Store) func(context.Context, contact.Submission) error {
+	return func(ctx context.Context, s contact.Submission) error {
+		_, err := store.Add(ctx, inbox.Message{
+			Name:      s.Name,
+			Email:     s.Email,
+			Message:   s.Message,
+			IP:        s.IP,
+			UserAgent: s.UserAgent,
+		})
+		return err
+	}
+}
+
+// NotifyContact returns a contact hook that forwards messages through n,
+// e.g. to Telegram or a chat webhook, with the text rendered from texts'
+// contact.txt.
+func NotifyContact(n notify.Notifier, texts *texttemplate.Template) func(context.Context, contact.Submission) error {
+	return func(ctx context.Context, s contact.Submission) error {
+		var text strings.Builder
+		if err := texts.ExecuteTemplate(&text, "contact.txt", s); err != nil {
+			return fmt.Errorf("render notification: %w", err)
+		}
+		if err := n.Notify(ctx, strings.TrimSpace(text.String())); err != nil {
+			return fmt.Errorf("send notification: %w", err)
+		}
+		return nil
+	}
+}
+
+// LocalImages returns a data hook that points remote project images at
+// cache, so they are served from the site's origin.
+func LocalImages(cache *images.Cache) func(*content.PageData) error {
+	return func(data *content.PageData) error {
+		for i, p := range data.Projects {
+			if strings.HasPrefix(p.Image, "https://") || strings.HasPrefix(p.Image, "http://") {
+				data.Projects[i].Image = cache.Register(p.Image)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/indieauth"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+// IndieAuthData is passed to the consent page.
+type IndieAuthData struct {
+	content.PageData
+	indieauth.Pending
+	Client string // the client's host, for display
+	Me     string
+}
+
+// IndieAuthProfile returns the profile shared with IndieAuth clients.
+func (h *Handler) IndieAuthProfile() indieauth.Profile {
+	data, _ := h.data()
+	p := indieauth.Profile{
+		Name:  data.About.Name,
+		URL:   h.opts.BaseURL + "/",
+		Email: data.About.Email,
+	}
+	if data.About.ProfilePhoto != "" {
+		p.Photo = h.opts.BaseURL + data.About.ProfilePhoto
+	}
+	return p
+}
+
+// IndieAuthorize shows the owner the consent screen for an IndieAuth
+// authorization request. It must be served behind the admin login.
+func (h *Handler) IndieAuthorize(w http.ResponseWriter, r *http.Request) {
+	if h.opts.IndieAuth == nil {
+		http.NotFound(w, r)
+		return
+	}
+	p, err := h.opts.IndieAuth.Begin(r.URL.Query())
+	if err != nil {
+		http.Error(w, "invalid authorization request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	client := p.ClientID
+	if u, err := url.Parse(p.ClientID); err == nil {
+		client = u.Host
+	}
+	data, _ := h.data()
+	data.Theme = render.Theme(r)
+	w.Header().Set("Cache-Control", "no-store")
+	h.render.Page(w, "indieauth", IndieAuthData{PageData: data, Pending: p, Client: client, Me: h.opts.IndieAuth.Me})
+}
+
+// IndieAuthDecide submits the consent form and sends the browser back to the
+// client.
+func (h *Handler) IndieAuthDecide(w http.ResponseWriter, r *http.Request) {
+	if h.opts.IndieAuth == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	redirect, err := h.opts.IndieAuth.Finish(r.PostForm.Get("ticket"), r.PostForm.Get("decision") == "approve", r.PostForm["scope"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, redirect, http.StatusSeeOther)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/render"
+	"github.com/fpatron/portfolio/internal/scheduler"
+)
+
+// AdminJobsData is passed to the admin jobs page.
+type AdminJobsData struct {
+	content.PageData
+	Jobs []scheduler.Status
+}
+
+// AdminJobs lists the background jobs with their last results, or returns
+// them as JSON.
+func (h *Handler) AdminJobs(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Jobs == nil {
+		http.Error(w, "background jobs are disabled", http.StatusNotFound)
+		return
+	}
+	jobs := h.opts.Jobs.Status()
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Add("Vary", "Accept")
+	if render.WantsJSON(r) {
+		render.JSON(w, http.StatusOK, jobs)
+		return
+	}
+	data, _ := h.data()
+	data.Theme = render.Theme(r)
+	h.render.Page(w, "admin-jobs", AdminJobsData{PageData: data, Jobs: jobs})
+}
+
+// RunJob runs the job named by the job form value now. Like moderation, it only
+// accepts requests from the admin pages' HTMX buttons.
+func (h *Handler) RunJob(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Jobs == nil {
+		http.Error(w, "background jobs are disabled", http.StatusNotFound)
+		return
+	}
+	if r.Header.Get("HX-Request") == "" {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	name := r.FormValue("job")
+	if err := h.opts.Jobs.Trigger(name); err != nil {
+		if errors.Is(err, scheduler.ErrUnknownJob) {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	log.Printf("admin: triggered job %q", name)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(`<span class="admin-muted">Queued</span>`))
+}
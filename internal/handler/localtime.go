@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+)
+
+// LocalTime serves the owner's local time and whether they are working,
+// which the partial refreshes every minute. It is empty without
+// Options.Hours.
+func (h *Handler) LocalTime(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Hours == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	st := h.opts.Hours.At(h.now())
+	w.Header().Set("Cache-Control", "no-store")
+	h.render.Respond(w, r, "localtime", st, st)
+}
+
+// now returns the current time of Options.Now.
+func (h *Handler) now() time.Time {
+	if h.opts.Now != nil {
+		return h.opts.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/inbox"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+// messagesPerPage is the number of contact messages per admin page.
+const messagesPerPage = 25
+
+// AdminMessagesData is passed to the contact messages page.
+type AdminMessagesData struct {
+	content.PageData
+	Messages []inbox.Message
+	Total    int
+	Unread   int
+	// Page is the current page, from 1, out of Pages. Prev and Next are
+	// the pages around it, 0 past the first and last.
+	Page  int
+	Pages int
+	Prev  int
+	Next  int
+}
+
+// AdminMessages lists the stored contact messages, newest first. ?page=
+// selects the page.
+func (h *Handler) AdminMessages(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Inbox == nil {
+		http.Error(w, "storing messages needs a database", http.StatusNotFound)
+		return
+	}
+	page, _ := strconv.Atoi(r.FormValue("page"))
+	h.adminMessages(w, r, page)
+}
+
+func (h *Handler) adminMessages(w http.ResponseWriter, r *http.Request, page int) {
+	total, unread, err := h.opts.Inbox.Count(r.Context())
+	if err != nil {
+		log.Printf("admin messages: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	pages := max((total+messagesPerPage-1)/messagesPerPage, 1)
+	page = min(max(page, 1), pages)
+	messages, err := h.opts.Inbox.List(r.Context(), (page-1)*messagesPerPage, messagesPerPage)
+	if err != nil {
+		log.Printf("admin messages: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	next := page + 1
+	if next > pages {
+		next = 0
+	}
+	data, _ := h.data()
+	data.Theme = render.Theme(r)
+	w.Header().Set("Cache-Control", "no-store")
+	h.render.Page(w, "admin-messages", AdminMessagesData{
+		PageData: data,
+		Messages: messages,
+		Total:    total,
+		Unread:   unread,
+		Page:     page,
+		Pages:    pages,
+		Prev:     page - 1,
+		Next:     next,
+	})
+}
+
+// MarkMessage marks a contact message as read, or as unread with the form
+// field read=false, and renders the page of the form field page again.
+// Only HTMX requests are accepted.
+func (h *Handler) MarkMessage(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Inbox == nil {
+		http.Error(w, "storing messages needs a database", http.StatusNotFound)
+		return
+	}
+	if r.Header.Get("HX-Request") == "" {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	read, rerr := strconv.ParseBool(r.FormValue("read"))
+	if err != nil || rerr != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	ok, err := h.opts.Inbox.MarkRead(r.Context(), id, read)
+	if err != nil {
+		log.Printf("mark message: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	page, _ := strconv.Atoi(r.FormValue("page"))
+	h.adminMessages(w, r, page)
+}
@@ -0,0 +1,27 @@
+package handler
+
+import "net/http"
+
+// SetSubscribers replaces the subscriber count shown next to the signup
+// form.
+func (h *Handler) SetSubscribers(n int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subscribers = n
+	h.syncVariants(func(v *Handler) { v.subscribers = n })
+}
+
+// Subscribers serves the subscriber count partial, or the count as JSON. It
+// is empty until a count has been fetched.
+func (h *Handler) Subscribers(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	n := h.subscribers
+	h.mu.RUnlock()
+	if n == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.render.Respond(w, r, "subscribers", n, struct {
+		Subscribers int `json:"subscribers"`
+	}{n})
+}
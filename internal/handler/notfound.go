@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+// NotFound serves the 404 page, or a JSON error to clients asking for JSON.
+// Disabled routes answer with it.
+func (h *Handler) NotFound(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept")
+	if render.WantsJSON(r) {
+		render.JSONError(w, http.StatusNotFound, "not found")
+		return
+	}
+	data, _ := h.data()
+	data.Theme = render.Theme(r)
+	data.BaseURL = h.baseURL(r)
+	h.render.PageStatus(w, http.StatusNotFound, "not-found", data)
+}
+
+// routeDisabled reports whether path is one of Options.DisabledRoutes or
+// lies under one. "/" only disables the home page.
+func (h *Handler) routeDisabled(path string) bool {
+	return content.RouteDisabled(h.opts.DisabledRoutes, path)
+}
+
+// DisableRoutes answers requests for disabled routes with NotFound, whether
+// or not a section declares them.
+func (h *Handler) DisableRoutes(next http.Handler) http.Handler {
+	if len(h.opts.DisabledRoutes) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.routeDisabled(r.URL.Path) {
+			h.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/fpatron/portfolio/internal/nowplaying"
+)
+
+// NowPlayingTopic is the SSE topic carrying the now playing partial.
+const NowPlayingTopic = "nowplaying"
+
+// SetNowPlaying replaces the current track, nil when nothing is playing.
+// It reports whether the track changed.
+func (h *Handler) SetNowPlaying(t *nowplaying.Track) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if reflect.DeepEqual(h.nowPlaying, t) {
+		return false
+	}
+	h.nowPlaying = t
+	h.syncVariants(func(v *Handler) { v.nowPlaying = t })
+	return true
+}
+
+// NowPlaying serves the now playing partial, or the track as JSON. It is
+// empty when nothing is playing.
+func (h *Handler) NowPlaying(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	t := h.nowPlaying
+	h.mu.RUnlock()
+	if t == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.render.Respond(w, r, "nowplaying", t, t)
+}
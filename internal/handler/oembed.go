@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+const (
+	oembedDefaultWidth = 500
+	oembedHeight       = 220
+)
+
+// OEmbedResponse is a rich-type oEmbed 1.0 response.
+type OEmbedResponse struct {
+	Version      string `json:"version"`
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	AuthorURL    string `json:"author_url"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url"`
+	CacheAge     int    `json:"cache_age"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+}
+
+// OEmbed implements an oEmbed provider for the site's own URLs: the home
+// page, /projects/{slug} and /blog/{slug}. Only the JSON format is supported.
+func (h *Handler) OEmbed(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if f := q.Get("format"); f != "" && f != "json" {
+		http.Error(w, "only json format is supported", http.StatusNotImplemented)
+		return
+	}
+	target, err := url.Parse(q.Get("url"))
+	if err != nil || target.Host == "" {
+		http.Error(w, "invalid url", http.StatusBadRequest)
+		return
+	}
+
+	base := h.baseURL(r)
+	if b, err := url.Parse(base); err != nil || !strings.EqualFold(b.Host, target.Host) {
+		http.NotFound(w, r)
+		return
+	}
+
+	width := oembedDefaultWidth
+	if v, err := strconv.Atoi(q.Get("maxwidth")); err == nil && v > 0 {
+		width = min(width, v)
+	}
+	height := oembedHeight
+	if v, err := strconv.Atoi(q.Get("maxheight")); err == nil && v > 0 {
+		height = min(height, v)
+	}
+
+	data, _ := h.data()
+	resp := OEmbedResponse{
+		Version:      "1.0",
+		Type:         "rich",
+		AuthorName:   data.About.Name,
+		AuthorURL:    base + "/",
+		ProviderName: data.About.Name,
+		ProviderURL:  base + "/",
+		CacheAge:     3600,
+		Width:        width,
+		Height:       height,
+	}
+
+	var title, desc, link string
+	switch p := strings.TrimSuffix(target.Path, "/"); {
+	case p == "":
+		title, desc, link = data.About.Name+" — "+data.About.Tagline, data.About.Bio, base+"/"
+		if data.About.ProfilePhoto != "" {
+			resp.ThumbnailURL = base + data.About.ProfilePhoto
+		}
+	case strings.HasPrefix(p, "/projects/"):
+		proj, ok := data.FindProject(strings.TrimPrefix(p, "/projects/"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		title, desc, link = proj.Title, proj.Description, base+"/projects/"+proj.Slug
+		if proj.Image != "" {
+			resp.ThumbnailURL = base + proj.Image
+		}
+	case strings.HasPrefix(p, "/blog/"):
+		post, ok := data.FindPost(strings.TrimPrefix(p, "/blog/"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		title, desc, link = post.Title, post.Summary, base+"/blog/"+post.Slug
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	resp.Title = title
+	resp.HTML = fmt.Sprintf(
+		`<blockquote class="portfolio-embed" style="max-width:%dpx"><p><strong><a href="%s">%s</a></strong></p><p>%s</p><p>— <a href="%s">%s</a></p></blockquote>`,
+		width,
+		html.EscapeString(link), html.EscapeString(title),
+		html.EscapeString(desc),
+		html.EscapeString(base+"/"), html.EscapeString(data.About.Name),
+	)
+	render.JSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/blog"
+	"github.com/fpatron/portfolio/internal/ogimage"
+)
+
+// ogCacheEntries bounds the social cards kept in memory.
+const ogCacheEntries = 128
+
+// ogCache keeps rendered social cards by the hash of their content, so a
+// card is drawn again only when its text changes.
+type ogCache struct {
+	mu     sync.Mutex
+	images map[string][]byte
+}
+
+func (c *ogCache) get(card ogimage.Card) ([]byte, error) {
+	key := card.Hash()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if b, ok := c.images[key]; ok {
+		return b, nil
+	}
+	b, err := ogimage.Render(card)
+	if err != nil {
+		return nil, err
+	}
+	if c.images == nil || len(c.images) >= ogCacheEntries {
+		c.images = make(map[string][]byte)
+	}
+	c.images[key] = b
+	return b, nil
+}
+
+// OGImage serves /og/{page}.png, the social card of a page: home, blog,
+// projects/{slug} or blog/{slug}.
+func (h *Handler) OGImage(w http.ResponseWriter, r *http.Request) {
+	page, ok := strings.CutSuffix(r.PathValue("page"), ".png")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	data, _ := h.data()
+	card := ogimage.Card{Name: data.About.Name, Tagline: data.About.Tagline}
+	if u, err := url.Parse(h.baseURL(r)); err == nil {
+		card.Site = u.Host
+	}
+	kind, slug, _ := strings.Cut(page, "/")
+	switch {
+	case page == "home":
+	case page == "blog" && data.HasSection("blog"):
+		card.Title, card.Subtitle = "Blog", "Posts by "+data.About.Name
+	case kind == "projects":
+		p, ok := data.FindProject(slug)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		card.Title, card.Subtitle = p.Title, strings.Join(p.Tags, ", ")
+	case kind == "blog" && data.HasSection("blog"):
+		i := slices.IndexFunc(data.Posts, func(p blog.Post) bool { return p.Slug == slug })
+		if i < 0 {
+			http.NotFound(w, r)
+			return
+		}
+		p := data.Posts[i]
+		card.Title = p.Title
+		card.Subtitle = p.Date.Format("January 2, 2006")
+		if len(p.Tags) > 0 {
+			card.Subtitle += " — " + strings.Join(p.Tags, ", ")
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	b, err := h.ogImages.get(card)
+	if err != nil {
+		log.Printf("og image: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("ETag", `"`+card.Hash()+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(b))
+}
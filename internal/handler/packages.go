@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/pkgstats"
+)
+
+// PackageRefs returns the packages published by projects in projects.json.
+func (h *Handler) PackageRefs() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var refs []string
+	for _, p := range h.files.Projects {
+		if p.Package != "" && !slices.Contains(refs, p.Package) {
+			refs = append(refs, p.Package)
+		}
+	}
+	return refs
+}
+
+// SetPackages updates the package statistics shown on project cards.
+// Packages missing from stats keep their previous statistics, so a failed
+// lookup does not hide a badge.
+func (h *Handler) SetPackages(stats map[string]pkgstats.Stats) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	next := maps.Clone(h.packages)
+	if next == nil {
+		next = make(map[string]pkgstats.Stats, len(stats))
+	}
+	maps.Copy(next, stats)
+	if maps.Equal(h.packages, next) {
+		return
+	}
+	h.packages = next
+	h.merge()
+	h.syncVariants(func(v *Handler) {
+		v.packages = next
+		v.merge()
+	})
+}
+
+// mergePackages adds version and download statistics to data's projects.
+func mergePackages(data content.PageData, stats map[string]pkgstats.Stats) content.PageData {
+	if len(stats) == 0 {
+		return data
+	}
+	projects := slices.Clone(data.Projects)
+	for i, p := range projects {
+		if s, ok := stats[p.Package]; ok {
+			projects[i].Version, projects[i].Downloads, projects[i].PackageURL = s.Version, s.Downloads, s.URL
+		}
+	}
+	data.Projects = projects
+	return data
+}
@@ -0,0 +1,89 @@
+package projects
+
+import (
+	"crypto/rand"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/fpatron/portfolio/internal/clientip"
+	"github.com/fpatron/portfolio/internal/content"
+)
+
+// sessionCookie identifies a visitor's browser to debounce their likes,
+// along with their address.
+const sessionCookie = "like_session"
+
+// Like adds the visitor's like to a project and returns its like button
+// with the new count, or the count as JSON. Likes from an address or
+// browser that liked the project recently are not counted again. Only HTMX
+// requests are accepted, so other sites cannot post likes for their
+// visitors.
+func (h *Handler) Like(w http.ResponseWriter, r *http.Request) {
+	data, _ := h.data()
+	p, ok := data.FindProject(r.PathValue("slug"))
+	if h.opts.Likes == nil || !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Header.Get("HX-Request") == "" {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	session := ""
+	if c, err := r.Cookie(sessionCookie); err == nil {
+		session = c.Value
+	} else {
+		session = rand.Text()
+		http.SetCookie(w, &http.Cookie{
+			Name:     sessionCookie,
+			Value:    session,
+			Path:     "/",
+			MaxAge:   365 * 24 * 60 * 60,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	n, _, err := h.opts.Likes.Like(r.Context(), p.Slug, voters(r, session)...)
+	if err != nil {
+		log.Printf("likes: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	p.Likes = &content.Likes{Count: n, Liked: true}
+	w.Header().Set("Cache-Control", "no-store")
+	h.render.Respond(w, r, "project-like", p, p.Likes)
+}
+
+// withLikes returns a copy of projects with their like counts when likes
+// are enabled and the counts can be read.
+func (h *Handler) withLikes(r *http.Request, projects []content.Project) []content.Project {
+	projects = slices.Clone(projects)
+	if h.opts.Likes == nil {
+		return projects
+	}
+	counts, err := h.opts.Likes.Counts(r.Context())
+	if err != nil {
+		log.Printf("likes: %v", err)
+		return projects
+	}
+	session := ""
+	if c, err := r.Cookie(sessionCookie); err == nil {
+		session = c.Value
+	}
+	vs := voters(r, session)
+	for i, p := range projects {
+		projects[i].Likes = &content.Likes{Count: counts[p.Slug], Liked: h.opts.Likes.Liked(p.Slug, vs...)}
+	}
+	return projects
+}
+
+// voters identifies the visitor behind r to the like debouncing: by their
+// address and their browser session.
+func voters(r *http.Request, session string) []string {
+	vs := []string{"ip:" + clientip.FromRequest(r).String()}
+	if session != "" {
+		vs = append(vs, "session:"+session)
+	}
+	return vs
+}
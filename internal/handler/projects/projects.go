@@ -0,0 +1,144 @@
+// Package projects serves the projects section: the project grid, the
+// project pages, the outbound links and the projects API.
+package projects
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/analytics"
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/likes"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+// Options configures a Handler.
+type Options struct {
+	// Analytics, when set, records outbound clicks.
+	Analytics *analytics.Recorder
+	// UTMSource, when set, tags outbound project links with utm_source,
+	// utm_medium=portfolio and utm_campaign=<project slug>.
+	UTMSource string
+	// Webmentions advertises the site's webmention endpoint on project
+	// pages.
+	Webmentions bool
+	// Likes, when set, counts the likes shown on the project cards.
+	Likes *likes.Store
+}
+
+// Handler serves the projects section from the site's current data.
+type Handler struct {
+	render  *render.Renderer
+	data    func() (content.PageData, uint64)
+	baseURL func(*http.Request) string
+	opts    Options
+}
+
+// New returns a Handler rendering with r. data returns the current page
+// data and its version, and baseURL the site's origin for a request.
+func New(r *render.Renderer, data func() (content.PageData, uint64), baseURL func(*http.Request) string, opts Options) *Handler {
+	return &Handler{render: r, data: data, baseURL: baseURL, opts: opts}
+}
+
+// Partial serves the projects grid partial for HTMX.
+func (h *Handler) Partial(w http.ResponseWriter, r *http.Request) {
+	data, _ := h.data()
+	data.Projects = h.withLikes(r, data.Projects)
+	h.render.Respond(w, r, "projects", data, data.Projects)
+}
+
+// Page serves the standalone page of a single project.
+func (h *Handler) Page(w http.ResponseWriter, r *http.Request) {
+	data, _ := h.data()
+	data.Theme = render.Theme(r)
+	p, ok := data.FindProject(r.PathValue("slug"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	data.Project = &p
+	data.BaseURL = h.baseURL(r)
+	data.URL = data.BaseURL + "/projects/" + p.Slug
+	data = data.WithMeta()
+
+	if h.opts.Webmentions {
+		w.Header().Set("Link", "<"+data.BaseURL+"/webmention>; rel=\"webmention\"")
+	}
+	w.Header().Add("Vary", "Accept")
+	if render.WantsJSON(r) {
+		render.JSON(w, http.StatusOK, p)
+		return
+	}
+	h.render.Page(w, "project", data)
+}
+
+// Outbound records a click on a project's link and redirects to it.
+func (h *Handler) Outbound(w http.ResponseWriter, r *http.Request) {
+	data, _ := h.data()
+	p, ok := data.FindProject(r.PathValue("slug"))
+	if !ok || p.Link == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if h.opts.Analytics != nil {
+		h.opts.Analytics.RecordClick(r, p.Slug)
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, h.outboundURL(p), http.StatusFound)
+}
+
+// outboundURL returns the project's link, UTM-tagged when configured.
+func (h *Handler) outboundURL(p content.Project) string {
+	if h.opts.UTMSource == "" {
+		return p.Link
+	}
+	u, err := url.Parse(p.Link)
+	if err != nil {
+		return p.Link
+	}
+	q := u.Query()
+	q.Set("utm_source", h.opts.UTMSource)
+	q.Set("utm_medium", "portfolio")
+	q.Set("utm_campaign", p.Slug)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// API serves the project list as JSON. It supports ?tag= (case
+// insensitive), ?sort=title or ?sort=date (most recent first) and
+// ?limit=&offset=.
+func (h *Handler) API(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	p, err := render.ParseListPage(q)
+	if err != nil {
+		render.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	data, _ := h.data()
+	projects := h.withLikes(r, data.Projects)
+	if tag := q.Get("tag"); tag != "" {
+		projects = slices.DeleteFunc(projects, func(p content.Project) bool {
+			return !slices.ContainsFunc(p.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+		})
+	}
+	switch q.Get("sort") {
+	case "":
+	case "title":
+		slices.SortStableFunc(projects, func(a, b content.Project) int {
+			return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		})
+	case "date":
+		slices.SortStableFunc(projects, func(a, b content.Project) int {
+			return b.SortDate().Compare(a.SortDate())
+		})
+	default:
+		render.JSONError(w, http.StatusBadRequest, fmt.Sprintf("unsupported sort %q", q.Get("sort")))
+		return
+	}
+
+	render.JSON(w, http.StatusOK, render.Paginate(r, projects, p))
+}
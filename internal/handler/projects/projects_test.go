@@ -0,0 +1,142 @@
+package projects
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+var testData = content.PageData{
+	About: content.About{Name: "Ada Example"},
+	Projects: []content.Project{
+		{Slug: "site", Title: "Site", Description: "A portfolio.", Tags: []string{"Go", "HTMX"}, Link: "https://github.com/example/site", Date: "Mar 2024"},
+		{Slug: "parser", Title: "parser", Description: "A JSON parser.", Tags: []string{"go", "JSON"}, PushedAt: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		{Slug: "bot", Title: "Bot", Description: "A chat bot.", Tags: []string{"Python"}, Date: "2023"},
+	},
+}
+
+func newHandler(t *testing.T, opts Options) *Handler {
+	t.Helper()
+	tmpl, err := render.Parse(os.DirFS("../../.."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := func() (content.PageData, uint64) { return testData, 1 }
+	baseURL := func(*http.Request) string { return "http://example.com" }
+	return New(render.New(tmpl), data, baseURL, opts)
+}
+
+func TestAPI(t *testing.T) {
+	h := newHandler(t, Options{})
+	for _, tt := range []struct {
+		query  string
+		status int
+		slugs  []string
+	}{
+		{"", http.StatusOK, []string{"site", "parser", "bot"}},
+		{"?tag=go", http.StatusOK, []string{"site", "parser"}},
+		{"?tag=JSON", http.StatusOK, []string{"parser"}},
+		{"?tag=rust", http.StatusOK, []string{}},
+		{"?sort=title", http.StatusOK, []string{"bot", "parser", "site"}},
+		{"?sort=date", http.StatusOK, []string{"parser", "site", "bot"}},
+		{"?tag=go&sort=title&limit=1", http.StatusOK, []string{"parser"}},
+		{"?offset=2", http.StatusOK, []string{"bot"}},
+		{"?sort=stars", http.StatusBadRequest, nil},
+		{"?limit=abc", http.StatusBadRequest, nil},
+	} {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.API(rec, httptest.NewRequest(http.MethodGet, "/api/projects"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var resp render.ListResponse[content.Project]
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			slugs := []string{}
+			for _, p := range resp.Items {
+				slugs = append(slugs, p.Slug)
+			}
+			if !slices.Equal(slugs, tt.slugs) {
+				t.Errorf("slugs = %q, want %q", slugs, tt.slugs)
+			}
+		})
+	}
+}
+
+func TestPartial(t *testing.T) {
+	h := newHandler(t, Options{})
+	rec := httptest.NewRecorder()
+	h.Partial(rec, httptest.NewRequest(http.MethodGet, "/partials/projects", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	for _, p := range testData.Projects {
+		if !strings.Contains(rec.Body.String(), `href="/projects/`+p.Slug+`"`) {
+			t.Errorf("partial doesn't link to %s", p.Slug)
+		}
+	}
+	// Only projects with a link get an outbound link.
+	if got := strings.Count(rec.Body.String(), `href="/out/`); got != 1 {
+		t.Errorf("partial has %d outbound links, want 1", got)
+	}
+}
+
+func TestPage(t *testing.T) {
+	h := newHandler(t, Options{Webmentions: true})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /projects/{slug}", h.Page)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/projects/site", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if got, want := rec.Header().Get("Link"), `<http://example.com/webmention>; rel="webmention"`; got != want {
+		t.Errorf("Link = %q, want %q", got, want)
+	}
+	if !strings.Contains(rec.Body.String(), "A portfolio.") {
+		t.Error("page doesn't show the description")
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/projects/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown project: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestOutbound(t *testing.T) {
+	for _, tt := range []struct {
+		utm      string
+		slug     string
+		status   int
+		location string
+	}{
+		{"", "site", http.StatusFound, "https://github.com/example/site"},
+		{"portfolio", "site", http.StatusFound, "https://github.com/example/site?utm_campaign=site&utm_medium=portfolio&utm_source=portfolio"},
+		{"", "parser", http.StatusNotFound, ""},
+		{"", "missing", http.StatusNotFound, ""},
+	} {
+		h := newHandler(t, Options{UTMSource: tt.utm})
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /out/{slug}", h.Outbound)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/out/"+tt.slug, nil))
+		if rec.Code != tt.status || rec.Header().Get("Location") != tt.location {
+			t.Errorf("utm %q, /out/%s: %d %q, want %d %q", tt.utm, tt.slug, rec.Code, rec.Header().Get("Location"), tt.status, tt.location)
+		}
+	}
+}
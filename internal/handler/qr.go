@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+)
+
+const (
+	qrDefaultSize = 256
+	qrMinSize     = 64
+	qrMaxSize     = 2048
+	// qrQuietZone is the margin around the code, in modules, that readers
+	// need to find it.
+	qrQuietZone = 4
+	// qrCacheEntries bounds the rendered codes kept for the current data.
+	qrCacheEntries = 64
+)
+
+// qrCache keeps the rendered QR codes of the current data version, by
+// format, size and content.
+type qrCache struct {
+	mu      sync.Mutex
+	version uint64
+	images  map[string][]byte
+}
+
+func (c *qrCache) get(version uint64, key string, build func() ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.images == nil || c.version != version || len(c.images) >= qrCacheEntries {
+		c.version, c.images = version, make(map[string][]byte)
+	}
+	if b, ok := c.images[key]; ok {
+		return b, nil
+	}
+	b, err := build()
+	if err != nil {
+		return nil, err
+	}
+	c.images[key] = b
+	return b, nil
+}
+
+// QR serves /qr.png and /qr.svg, a QR code of the site's URL or, with
+// ?data=vcard, of a contact card built from data/about.json. ?size= sets
+// the width in pixels, 256 by default.
+func (h *Handler) QR(w http.ResponseWriter, r *http.Request) {
+	format := strings.TrimPrefix(r.URL.Path, "/qr.")
+	q := r.URL.Query()
+	size := qrDefaultSize
+	if s := q.Get("size"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < qrMinSize || n > qrMaxSize {
+			http.Error(w, fmt.Sprintf("size must be a number of pixels from %d to %d", qrMinSize, qrMaxSize), http.StatusBadRequest)
+			return
+		}
+		size = n
+	}
+
+	data, version := h.data()
+	var text string
+	switch q.Get("data") {
+	case "", "url":
+		text = h.baseURL(r) + "/"
+	case "vcard":
+		text = data.About.VCard(h.baseURL(r) + "/")
+	default:
+		http.Error(w, "data must be url or vcard", http.StatusBadRequest)
+		return
+	}
+
+	key := fmt.Sprintf("%s %d %s", format, size, text)
+	b, err := h.qrCodes.get(version, key, func() ([]byte, error) {
+		code, err := qr.Encode(text, qr.M, qr.Auto)
+		if err != nil {
+			return nil, fmt.Errorf("encode qr code: %w", err)
+		}
+		if format == "svg" {
+			return renderQRSVG(code, size), nil
+		}
+		return renderQRPNG(code, size)
+	})
+	if err != nil {
+		log.Printf("qr: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(b)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:8])+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	if format == "svg" {
+		w.Header().Set("Content-Type", "image/svg+xml")
+	} else {
+		w.Header().Set("Content-Type", "image/png")
+	}
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(b))
+}
+
+// qrScale returns the width of a module in pixels for a code of n modules
+// drawn about size pixels wide, and the resulting width of the image.
+func qrScale(n, size int) (module, width int) {
+	module = max(size/(n+2*qrQuietZone), 1)
+	return module, module * (n + 2*qrQuietZone)
+}
+
+func renderQRPNG(code barcode.Barcode, size int) ([]byte, error) {
+	n := code.Bounds().Dx()
+	module, width := qrScale(n, size)
+	img := image.NewPaletted(image.Rect(0, 0, width, width), color.Palette{color.White, color.Black})
+	for y := range n {
+		for x := range n {
+			if !qrDark(code, x, y) {
+				continue
+			}
+			for py := range module {
+				row := img.Pix[(qrQuietZone+y)*module*img.Stride+py*img.Stride:]
+				for px := range module {
+					row[(qrQuietZone+x)*module+px] = 1
+				}
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderQRSVG draws the code as a single path, a unit square per dark
+// module, scaled by the viewBox.
+func renderQRSVG(code barcode.Barcode, size int) []byte {
+	n := code.Bounds().Dx()
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, n+2*qrQuietZone, n+2*qrQuietZone)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="#fff"/><path fill="#000" d="`)
+	for y := range n {
+		for x := range n {
+			if qrDark(code, x, y) {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x+qrQuietZone, y+qrQuietZone)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>` + "\n")
+	return b.Bytes()
+}
+
+func qrDark(code barcode.Barcode, x, y int) bool {
+	r, _, _, _ := code.At(x, y).RGBA()
+	return r < 0x8000
+}
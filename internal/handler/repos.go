@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/repos"
+)
+
+// SetRepos replaces the synced repositories merged into the projects list.
+// Unchanged results keep the current data version, so derived caches stay
+// valid.
+func (h *Handler) SetRepos(list []repos.Repo) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if reflect.DeepEqual(h.repos, list) {
+		return
+	}
+	h.repos = list
+	h.merge()
+	h.syncVariants(func(v *Handler) {
+		v.repos = list
+		v.merge()
+	})
+}
+
+// mergeRepos adds repository metadata to data's projects. Projects from
+// projects.json whose link points at a repository gain its stars, language
+// and last push, and keep their curated text; other repositories are
+// appended as new projects.
+func mergeRepos(data content.PageData, list []repos.Repo) content.PageData {
+	if len(list) == 0 {
+		return data
+	}
+	projects := slices.Clone(data.Projects)
+	byLink := make(map[string]int, len(projects))
+	slugs := make(map[string]bool, len(projects))
+	for i, p := range projects {
+		byLink[normalizeRepoURL(p.Link)] = i
+		slugs[p.Slug] = true
+	}
+	for _, r := range list {
+		if i, ok := byLink[normalizeRepoURL(r.URL)]; ok {
+			p := &projects[i]
+			p.Source, p.Stars, p.Language, p.PushedAt = r.Source, r.Stars, r.Language, r.PushedAt
+			if p.Description == "" {
+				p.Description = r.Description
+			}
+			continue
+		}
+		slug := slugify(r.Name)
+		if slugs[slug] {
+			continue
+		}
+		slugs[slug] = true
+		var tags []string
+		if r.Language != "" {
+			tags = append(tags, r.Language)
+		}
+		projects = append(projects, content.Project{
+			Slug:        slug,
+			Title:       r.Name,
+			Description: r.Description,
+			Tags:        append(tags, r.Topics...),
+			Link:        r.URL,
+			Source:      r.Source,
+			Stars:       r.Stars,
+			Language:    r.Language,
+			PushedAt:    r.PushedAt,
+			Synced:      true,
+		})
+	}
+	data.Projects = projects
+	return data
+}
+
+// normalizeRepoURL makes repository links comparable.
+func normalizeRepoURL(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "/"), ".git")
+	s = strings.TrimPrefix(s, "http://")
+	s = strings.TrimPrefix(s, "https://")
+	return strings.TrimPrefix(s, "www.")
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/schemas"
+)
+
+// Section is a part of the site. It declares everything needed to serve
+// it, and the routes, the menu, the home page and sitemap.xml are all built
+// from the list of sections, so a section cannot be wired halfway. Its
+// Name also identifies it in Options.DisabledSections.
+//
+// The main route of a section is its partial, or else its first form or
+// route: the section is left out when Options.DisabledRoutes covers it.
+type Section struct {
+	content.Section
+	// Routes are the pages, partials and API endpoints the section serves.
+	Routes []Route
+	// Forms are the routes taking the section's form submissions.
+	Forms []Route
+	// Sitemap, when set, returns the section's pages.
+	Sitemap func(content.PageData) []SitemapPage
+}
+
+// SitemapPage is a page listed in sitemap.xml: its path and, when the data
+// dates it, when it last changed.
+type SitemapPage struct {
+	Path     string
+	Modified time.Time
+}
+
+// Route is a ServeMux pattern and its handler.
+type Route struct {
+	Pattern string
+	Handler http.HandlerFunc
+}
+
+// sections returns the site's sections in the order of the home page.
+func (h *Handler) sections() []Section {
+	return []Section{
+		{
+			Section: content.Section{Name: "home", Nav: content.NavLink{Label: "Home", Href: "/#home"}},
+			Routes: []Route{
+				{"GET /{$}", h.Index},
+				{"GET /partials/viewers", h.Viewers},
+				{"GET /partials/nowplaying", h.NowPlaying},
+				{"GET /partials/status", h.Status},
+				{"GET /api/status", h.APIStatus},
+				{"GET /api/search", h.APISearch},
+				{"GET /partials/search", h.SearchPartial},
+				{"GET /api/commands", h.APICommands},
+				{"GET /go/{code}", h.ShortLink},
+				{"GET /oembed", h.OEmbed},
+				{"GET /og/{page...}", h.OGImage},
+			},
+			Sitemap: func(data content.PageData) []SitemapPage {
+				return []SitemapPage{{Path: "/", Modified: lastModified(data)}}
+			},
+		},
+		{
+			Section: content.Section{
+				Name:    "about",
+				Nav:     content.NavLink{Label: "About", Href: "/#about"},
+				Partial: "/partials/about",
+				Loading: true,
+			},
+			Routes: []Route{
+				{"GET /partials/about", h.about.Partial},
+				{"GET /partials/stackoverflow", h.StackExchange},
+				{"GET /api/experience", h.about.Experience},
+				{"GET /resume.pdf", h.about.Resume},
+				{"GET /resume.json", h.about.JSONResume},
+				{"GET /download/portfolio.zip", h.about.Bundle},
+			},
+		},
+		{
+			Section: content.Section{
+				Name:    "projects",
+				Nav:     content.NavLink{Label: "Projects", Href: "/#projects"},
+				Partial: "/partials/projects",
+				Loading: true,
+			},
+			Routes: []Route{
+				{"GET /partials/projects", h.projects.Partial},
+				{"GET /partials/github", h.GitHubStats},
+				{"GET /partials/webmentions/{slug}", h.Webmentions},
+				{"GET /partials/comments/{slug}", h.Comments},
+				{"GET /projects/{slug}", h.projects.Page},
+				{"GET /out/{slug}", h.projects.Outbound},
+				{"GET /badge/{name}", h.Badge},
+				{"GET /api/projects", h.projects.API},
+				{"GET /api/github/stats", h.APIGitHubStats},
+			},
+			Forms: []Route{{"POST /projects/{slug}/like", h.projects.Like}},
+			Sitemap: func(data content.PageData) []SitemapPage {
+				pages := make([]SitemapPage, 0, len(data.Projects))
+				for _, p := range data.Projects {
+					pages = append(pages, SitemapPage{Path: "/projects/" + p.Slug, Modified: p.PushedAt})
+				}
+				return pages
+			},
+		},
+		{
+			Section: content.Section{
+				Name:    "blog",
+				Nav:     content.NavLink{Label: "Blog", Href: "/#blog"},
+				Partial: "/partials/blog",
+			},
+			Routes: []Route{
+				{"GET /partials/blog", h.blog.Partial},
+				{"GET /partials/webmentions/blog/{slug}", h.PostWebmentions},
+				{"GET /partials/comments/blog/{slug}", h.PostComments},
+				{"GET /blog", h.blog.List},
+				{"GET /blog/{slug}", h.blog.Post},
+				{"GET /api/posts", h.blog.API},
+			},
+			Sitemap: func(data content.PageData) []SitemapPage {
+				if len(data.Posts) == 0 {
+					return nil
+				}
+				// Posts are newest first, so the list changed with the first.
+				pages := []SitemapPage{{Path: "/blog", Modified: data.Posts[0].Date}}
+				for _, p := range data.Posts {
+					pages = append(pages, SitemapPage{Path: "/blog/" + p.Slug, Modified: p.Date})
+				}
+				return pages
+			},
+		},
+		{
+			Section: content.Section{
+				Name:    "interests",
+				Nav:     content.NavLink{Label: "Interests", Href: "/#interests"},
+				Partial: "/partials/interests",
+				Loading: true,
+			},
+			Routes: []Route{
+				{"GET /partials/interests", h.Interests},
+				{"GET /partials/strava", h.Strava},
+			},
+		},
+		{Section: content.Section{Name: "videos", Partial: "/partials/videos"}, Routes: []Route{{"GET /partials/videos", h.Videos}}},
+		{Section: content.Section{Name: "talks", Partial: "/partials/talks"}, Routes: []Route{{"GET /partials/talks", h.Talks}}},
+		{Section: content.Section{Name: "books", Partial: "/partials/books"}, Routes: []Route{{"GET /partials/books", h.Bookshelf}}},
+		{Section: content.Section{Name: "social", Partial: "/partials/social"}, Routes: []Route{{"GET /partials/social", h.Social}}},
+		{Section: content.Section{Name: "booking", Partial: "/partials/booking"}, Routes: []Route{{"GET /partials/booking", h.Booking}}},
+		{
+			Section: content.Section{Name: "guestbook", Partial: "/partials/guestbook"},
+			Routes:  []Route{{"GET /partials/guestbook", h.Guestbook}},
+			Forms:   []Route{{"POST /guestbook", h.SignGuestbook}},
+		},
+		{
+			Section: content.Section{Name: "contact", Nav: content.NavLink{Label: "Connect", Href: "/#contact", Button: true}},
+			Routes: []Route{
+				{"GET /partials/newsletter", h.contact.NewsletterForm},
+				{"GET /partials/subscribers", h.Subscribers},
+				{"GET /partials/localtime", h.LocalTime},
+				{"GET /contact.vcf", h.VCard},
+				{"GET /qr.png", h.QR},
+				{"GET /qr.svg", h.QR},
+			},
+			Forms: []Route{
+				{"POST /contact", h.contact.Submit},
+				{"POST /contact/viewed", h.contact.Viewed},
+				{"POST /subscribe", h.contact.Subscribe},
+			},
+		},
+	}
+}
+
+// main returns the path of the section's main route.
+func (s Section) main() string {
+	if s.Partial != "" {
+		return s.Partial
+	}
+	for _, routes := range [][]Route{s.Forms, s.Routes} {
+		if len(routes) > 0 {
+			return routePath(routes[0].Pattern)
+		}
+	}
+	return ""
+}
+
+// routePath returns the path of a ServeMux pattern, without its method or
+// a trailing {$}.
+func routePath(pattern string) string {
+	_, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		path = pattern
+	}
+	return strings.TrimSuffix(path, "{$}")
+}
+
+// enabled reports whether s is neither disabled by name nor through its
+// main route.
+func (h *Handler) enabled(s Section) bool {
+	return !slices.Contains(h.opts.DisabledSections, s.Name) && !h.routeDisabled(s.main())
+}
+
+// Sections returns the enabled sections in the order of the home page.
+func (h *Handler) Sections() []Section {
+	return slices.DeleteFunc(h.sections(), func(s Section) bool { return !h.enabled(s) })
+}
+
+// SectionNames lists the names of every section, enabled or not.
+func SectionNames() []string {
+	var names []string
+	for _, s := range (&Handler{}).sections() {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// handle registers routes on mux. The routes of disabled sections and
+// disabled routes answer with the 404 page, rather than falling through to
+// the home page.
+func (h *Handler) handle(mux *http.ServeMux, s Section, routes []Route) {
+	on := h.enabled(s)
+	for _, r := range routes {
+		if on && !h.routeDisabled(routePath(r.Pattern)) {
+			mux.HandleFunc(r.Pattern, r.Handler)
+		} else {
+			mux.HandleFunc(r.Pattern, h.NotFound)
+		}
+	}
+}
+
+// PublicRoutes registers the GET routes of the sections, sitemap.xml, the
+// feeds and the data file schemas on mux, and the 404 page for the paths no
+// other route serves. The server, the tenant sites and the export command
+// share them.
+func (h *Handler) PublicRoutes(mux *http.ServeMux) {
+	for _, s := range h.sections() {
+		h.handle(mux, s, s.Routes)
+	}
+	mux.HandleFunc("GET /", h.NotFound)
+	mux.HandleFunc("GET /sitemap.xml", h.Sitemap)
+	mux.HandleFunc("GET /feed.xml", h.RSS)
+	mux.HandleFunc("GET /atom.xml", h.Atom)
+	mux.HandleFunc("POST /theme", h.SetTheme)
+	mux.Handle("GET "+schemas.Path, http.StripPrefix(schemas.Path, http.FileServerFS(schemas.FS)))
+}
+
+// FormRoutes registers the form submission routes of the sections on mux.
+func (h *Handler) FormRoutes(mux *http.ServeMux) {
+	for _, s := range h.sections() {
+		h.handle(mux, s, s.Forms)
+	}
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/render"
+	"github.com/fpatron/portfolio/internal/search"
+)
+
+// searchDocuments flattens the page data into searchable documents, leaving
+// out those on disabled routes.
+func searchDocuments(data content.PageData) []search.Document {
+	var docs []search.Document
+	for _, p := range data.Projects {
+		docs = append(docs, search.Document{
+			Type:  "project",
+			Title: p.Title,
+			URL:   "/projects/" + p.Slug,
+			Tags:  p.Tags,
+			Body:  p.Description,
+		})
+	}
+	if data.HasSection("blog") {
+		for _, p := range data.Posts {
+			docs = append(docs, search.Document{
+				Type:  "post",
+				Title: p.Title,
+				URL:   "/blog/" + p.Slug,
+				Tags:  p.Tags,
+				Body:  p.Summary,
+			})
+		}
+	}
+	for _, e := range data.Experience {
+		docs = append(docs, search.Document{
+			Type:  "experience",
+			Title: e.Role + " — " + e.Company,
+			URL:   "/#about",
+			Tags:  []string{e.Type},
+			Body:  strings.Join(e.Description, " "),
+		})
+	}
+	for _, c := range data.Skills {
+		for _, s := range c.Skills {
+			docs = append(docs, search.Document{
+				Type:  "skill",
+				Title: s,
+				URL:   "/#about",
+				Tags:  []string{c.Category},
+			})
+		}
+	}
+	for _, t := range data.Talks {
+		docs = append(docs, search.Document{
+			Type:  "talk",
+			Title: t.Title,
+			URL:   "/#talks",
+			Tags:  []string{t.Event},
+			Body:  strings.TrimSpace(t.Event + " " + t.Location),
+		})
+	}
+	for _, i := range data.Interests {
+		docs = append(docs, search.Document{
+			Type:  "interest",
+			Title: i.Label,
+			URL:   "/#interests",
+			Body:  i.Description,
+		})
+	}
+	return slices.DeleteFunc(docs, func(d search.Document) bool { return !data.Serves(d.URL) })
+}
+
+// searchIndex returns the index for the current data, rebuilding it after a
+// reload.
+func (h *Handler) searchIndex() *search.Index {
+	data, version := h.data()
+	idx, _ := h.searchIdx.Get(version, func() (*search.Index, error) {
+		return search.NewIndex(searchDocuments(data)), nil
+	})
+	return idx
+}
+
+// APISearch serves ranked search results across all content as JSON. It
+// supports ?q=, ?type= (repeatable) and ?limit=&offset=.
+func (h *Handler) APISearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	p, err := render.ParseListPage(q)
+	if err != nil {
+		render.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	results := h.searchIndex().Search(q.Get("q"), q["type"]...)
+	render.JSON(w, http.StatusOK, render.Paginate(r, results, p))
+}
+
+// searchGroups are the groups of the search partial, in display order.
+var searchGroups = []struct{ Type, Label string }{
+	{"project", "Projects"},
+	{"post", "Posts"},
+	{"experience", "Experience"},
+	{"skill", "Skills"},
+	{"talk", "Talks"},
+	{"interest", "Interests"},
+}
+
+// searchGroupLimit is the number of results the search partial shows per
+// group.
+const searchGroupLimit = 5
+
+// SearchData is rendered by the search-results template.
+type SearchData struct {
+	Query  string        `json:"query"`
+	Groups []SearchGroup `json:"groups"`
+}
+
+// SearchGroup holds the best results of one type of content.
+type SearchGroup struct {
+	Type    string          `json:"type"`
+	Label   string          `json:"label"`
+	Results []search.Result `json:"results"`
+	// More is the number of results left out.
+	More int `json:"more"`
+}
+
+// SearchPartial serves the results of the search box for ?q=, grouped by
+// type of content. An empty query renders nothing, which clears the
+// results.
+func (h *Handler) SearchPartial(w http.ResponseWriter, r *http.Request) {
+	d := SearchData{Query: strings.TrimSpace(r.URL.Query().Get("q")), Groups: []SearchGroup{}}
+	results := h.searchIndex().Search(d.Query)
+	for _, g := range searchGroups {
+		group := SearchGroup{Type: g.Type, Label: g.Label}
+		for _, res := range results {
+			if res.Type != g.Type {
+				continue
+			}
+			if len(group.Results) == searchGroupLimit {
+				group.More++
+				continue
+			}
+			group.Results = append(group.Results, res)
+		}
+		if len(group.Results) > 0 {
+			d.Groups = append(d.Groups, group)
+		}
+	}
+	h.render.Respond(w, r, "search-results", d, d)
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/render"
+	"github.com/fpatron/portfolio/internal/shortlinks"
+)
+
+// shortLinkClicksDays is the period the admin page counts clicks over.
+const shortLinkClicksDays = 30
+
+// AdminLinksData is passed to the short links page.
+type AdminLinksData struct {
+	content.PageData
+	Links []AdminLink
+	Form  AdminLinkForm
+}
+
+// AdminLink is a short link with its recent clicks.
+type AdminLink struct {
+	shortlinks.Link
+	Clicks int
+	// Stored is set for the links added from the admin page, which can be
+	// deleted there, unlike those of data/links.json.
+	Stored bool
+}
+
+// AdminLinkForm is the form adding a short link, filled in again when it
+// is rejected.
+type AdminLinkForm struct {
+	Code  string
+	URL   string
+	Title string
+	Error string
+}
+
+// ShortLink redirects a short link to its URL and records the click. Links
+// of data/links.json take precedence over stored ones.
+func (h *Handler) ShortLink(w http.ResponseWriter, r *http.Request) {
+	code := strings.ToLower(r.PathValue("code"))
+	l, ok := h.findShortLink(code)
+	if !ok && h.opts.ShortLinks != nil {
+		var err error
+		if l, ok, err = h.opts.ShortLinks.Get(r.Context(), code); err != nil {
+			log.Printf("short link: %v", err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+	if !ok {
+		h.NotFound(w, r)
+		return
+	}
+	if h.opts.Analytics != nil {
+		h.opts.Analytics.RecordClick(r, shortlinks.Prefix+l.Code)
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, l.URL, http.StatusFound)
+}
+
+func (h *Handler) findShortLink(code string) (shortlinks.Link, bool) {
+	for _, l := range h.Data().ShortLinks {
+		if l.Code == code {
+			return l, true
+		}
+	}
+	return shortlinks.Link{}, false
+}
+
+// ValidateShortLinks checks the links of data/links.json: their codes and
+// URLs, and that no code is used twice.
+func ValidateShortLinks(links []shortlinks.Link) error {
+	var errs []error
+	seen := make(map[string]bool)
+	for i, l := range links {
+		if err := l.Check(); err != nil {
+			errs = append(errs, fmt.Errorf("link %d: %w", i, err))
+		}
+		if seen[l.Code] {
+			errs = append(errs, fmt.Errorf("link %d: code %q is used twice", i, l.Code))
+		}
+		seen[l.Code] = true
+	}
+	return errors.Join(errs...)
+}
+
+// AdminLinks renders the short links with their clicks of the last 30 days,
+// and the form adding one.
+func (h *Handler) AdminLinks(w http.ResponseWriter, r *http.Request) {
+	h.adminLinks(w, r, AdminLinkForm{})
+}
+
+func (h *Handler) adminLinks(w http.ResponseWriter, r *http.Request, form AdminLinkForm) {
+	data, _ := h.data()
+	data.Theme = render.Theme(r)
+	var links []AdminLink
+	for _, l := range data.ShortLinks {
+		links = append(links, AdminLink{Link: l})
+	}
+	if h.opts.ShortLinks != nil {
+		stored, err := h.opts.ShortLinks.List(r.Context())
+		if err != nil {
+			log.Printf("admin links: %v", err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		for _, l := range stored {
+			if _, ok := h.findShortLink(l.Code); !ok {
+				links = append(links, AdminLink{Link: l, Stored: true})
+			}
+		}
+	}
+	if h.opts.Analytics != nil {
+		clicks, err := h.opts.Analytics.Clicks(r.Context(), shortLinkClicksDays)
+		if err != nil {
+			log.Printf("admin links: %v", err)
+		}
+		for i := range links {
+			links[i].Clicks = clicks[shortlinks.Prefix+links[i].Code]
+		}
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	h.render.Page(w, "admin-links", AdminLinksData{PageData: data, Links: links, Form: form})
+}
+
+// AddShortLink adds the short link posted by the admin page's form and
+// renders the page again, with an error when the link is rejected. Like
+// the other admin forms, it only accepts HTMX requests.
+func (h *Handler) AddShortLink(w http.ResponseWriter, r *http.Request) {
+	if h.opts.ShortLinks == nil {
+		http.Error(w, "short links need a database", http.StatusNotFound)
+		return
+	}
+	if r.Header.Get("HX-Request") == "" {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	f := AdminLinkForm{
+		Code:  strings.ToLower(strings.TrimSpace(r.FormValue("code"))),
+		URL:   strings.TrimSpace(r.FormValue("url")),
+		Title: strings.TrimSpace(r.FormValue("title")),
+	}
+	l := shortlinks.Link{Code: f.Code, URL: f.URL, Title: f.Title}
+	if err := l.Check(); err != nil {
+		f.Error = "Invalid link: " + err.Error() + "."
+	} else if _, ok := h.findShortLink(l.Code); ok {
+		f.Error = fmt.Sprintf("%s%s is already in use.", shortlinks.Prefix, l.Code)
+	} else if err := h.opts.ShortLinks.Add(r.Context(), l); errors.Is(err, shortlinks.ErrExists) {
+		f.Error = fmt.Sprintf("%s%s is already in use.", shortlinks.Prefix, l.Code)
+	} else if err != nil {
+		log.Printf("admin links: %v", err)
+		f.Error = "Something went wrong. Please try again."
+	} else {
+		f = AdminLinkForm{}
+	}
+	h.adminLinks(w, r, f)
+}
+
+// DeleteShortLink deletes a short link added from the admin page. Only
+// HTMX requests are accepted.
+func (h *Handler) DeleteShortLink(w http.ResponseWriter, r *http.Request) {
+	if h.opts.ShortLinks == nil {
+		http.Error(w, "short links need a database", http.StatusNotFound)
+		return
+	}
+	if r.Header.Get("HX-Request") == "" {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	ok, err := h.opts.ShortLinks.Delete(r.Context(), r.PathValue("code"))
+	if err != nil {
+		log.Printf("admin links: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	// An empty 200 lets hx-swap="outerHTML" remove the row.
+	w.WriteHeader(http.StatusOK)
+}
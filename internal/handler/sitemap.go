@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/xml"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/content"
+)
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Sitemap serves sitemap.xml, listing the pages of the enabled sections
+// with the dates the data gives them.
+func (h *Handler) Sitemap(w http.ResponseWriter, r *http.Request) {
+	data := h.Data()
+	base := h.baseURL(r)
+	var set sitemapURLSet
+	for _, s := range h.Sections() {
+		if s.Sitemap == nil {
+			continue
+		}
+		for _, p := range s.Sitemap(data) {
+			if h.routeDisabled(p.Path) {
+				continue
+			}
+			set.URLs = append(set.URLs, sitemapURL{Loc: base + p.Path, LastMod: w3cDate(p.Modified)})
+		}
+	}
+	out, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		log.Printf("sitemap: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	w.Write(out)
+	w.Write([]byte("\n"))
+}
+
+// lastModified returns the date of the latest post or repository push, or
+// zero when nothing in data is dated.
+func lastModified(data content.PageData) time.Time {
+	var t time.Time
+	for _, p := range data.Posts {
+		t = later(t, p.Date)
+	}
+	for _, p := range data.Projects {
+		t = later(t, p.PushedAt)
+	}
+	return t
+}
+
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// w3cDate formats t for lastmod: a date alone when t is midnight UTC, as
+// the dates of posts are, and empty when t is zero.
+func w3cDate(t time.Time) string {
+	switch {
+	case t.IsZero():
+		return ""
+	case t.Equal(t.Truncate(24 * time.Hour)):
+		return t.UTC().Format(time.DateOnly)
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fpatron/portfolio/internal/mastodon"
+)
+
+// SocialData is rendered by the "social" partial.
+type SocialData struct {
+	Profile string          `json:"profile"`
+	Posts   []mastodon.Post `json:"posts"`
+}
+
+// SetSocial replaces the posts served by the social partial. A failed
+// refresh leaves the previous posts in place.
+func (h *Handler) SetSocial(profile string, posts []mastodon.Post) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.social = SocialData{Profile: profile, Posts: posts}
+	h.syncVariants(func(v *Handler) { v.social = h.social })
+}
+
+// Social serves the latest Mastodon posts partial, or the posts as JSON. It
+// is empty until the first fetch succeeds.
+func (h *Handler) Social(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	data := h.social
+	h.mu.RUnlock()
+	if len(data.Posts) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.render.Respond(w, r, "social", data, data)
+}
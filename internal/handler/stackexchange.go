@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fpatron/portfolio/internal/stackexchange"
+)
+
+// SetStackExchange replaces the profile shown in the about section. A
+// failed refresh leaves the previous profile in place.
+func (h *Handler) SetStackExchange(p *stackexchange.Profile) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stackExchange = p
+	h.syncVariants(func(v *Handler) { v.stackExchange = p })
+}
+
+// StackExchange serves the Stack Overflow flair partial, or the profile as
+// JSON. It is empty until the profile has been fetched. Browsers and CDNs
+// may reuse a stale copy for a day while they revalidate it.
+func (h *Handler) StackExchange(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	p := h.stackExchange
+	h.mu.RUnlock()
+	if p == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=3600, stale-while-revalidate=86400")
+	h.render.Respond(w, r, "stackexchange", p, p)
+}
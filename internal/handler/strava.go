@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fpatron/portfolio/internal/strava"
+)
+
+// SetStrava replaces the activity shown in the interests section. A failed
+// refresh leaves the previous one in place.
+func (h *Handler) SetStrava(a *strava.Activity) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.strava = a
+	h.syncVariants(func(v *Handler) { v.strava = a })
+}
+
+// Strava serves the latest Strava activity partial, or the activity as
+// JSON. It is empty until an activity has been fetched.
+func (h *Handler) Strava(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	a := h.strava
+	h.mu.RUnlock()
+	if a == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.render.Respond(w, r, "strava", a, a)
+}
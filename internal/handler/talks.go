@@ -0,0 +1,14 @@
+package handler
+
+import "net/http"
+
+// Talks serves the talks partial, or the talks as JSON. It is empty
+// without data/talks.json.
+func (h *Handler) Talks(w http.ResponseWriter, r *http.Request) {
+	list := h.Data().Talks
+	if len(list) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.render.Respond(w, r, "talks", list, list)
+}
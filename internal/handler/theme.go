@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fpatron/portfolio/internal/render"
+)
+
+// SetTheme serves POST /theme, which keeps the color theme given as the
+// theme form value, "dark" or "light", in a cookie, so pages render in it
+// from the first paint. "system" or no value clears the cookie.
+func (h *Handler) SetTheme(w http.ResponseWriter, r *http.Request) {
+	theme := r.FormValue("theme")
+	c := &http.Cookie{
+		Name:     render.ThemeCookie,
+		Value:    theme,
+		Path:     "/",
+		MaxAge:   365 * 24 * 60 * 60,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	switch {
+	case render.ValidTheme(theme):
+	case theme == "" || theme == "system":
+		c.Value, c.MaxAge = "", -1
+	default:
+		http.Error(w, `theme must be "dark", "light" or "system"`, http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, c)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fpatron/portfolio/internal/render"
+	"github.com/fpatron/portfolio/internal/uptime"
+)
+
+// SetUptime replaces the status reported by the footer badge and
+// /api/status. A failed refresh leaves the previous status in place.
+func (h *Handler) SetUptime(st *uptime.Status) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.uptime = st
+	h.syncVariants(func(v *Handler) { v.uptime = st })
+}
+
+func (h *Handler) uptimeStatus() *uptime.Status {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.uptime
+}
+
+// APIStatus serves the status reported by the uptime monitor as JSON.
+func (h *Handler) APIStatus(w http.ResponseWriter, r *http.Request) {
+	st := h.uptimeStatus()
+	if st == nil {
+		render.JSONError(w, http.StatusServiceUnavailable, "status not available yet")
+		return
+	}
+	render.JSON(w, http.StatusOK, st)
+}
+
+// Status serves the footer status badge. It is empty until the monitor has
+// been read.
+func (h *Handler) Status(w http.ResponseWriter, r *http.Request) {
+	st := h.uptimeStatus()
+	if st == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.render.HTML(w, "status", st)
+}
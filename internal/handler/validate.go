@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/images"
+	"github.com/fpatron/portfolio/internal/pkgstats"
+)
+
+// ValidateProjects reports entries of data/projects.json that would render
+// without a title or description, share a page, or link nowhere useful.
+func ValidateProjects(list []content.Project) error {
+	var errs []error
+	slugs := make(map[string]bool, len(list))
+	for i, p := range list {
+		fail := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("projects[%d] (%s): %s", i, p.Title, fmt.Sprintf(format, args...)))
+		}
+		if p.Title == "" {
+			fail("missing title")
+		}
+		if p.Description == "" {
+			fail("missing description")
+		}
+		slug := p.Slug
+		if slug == "" {
+			slug = slugify(p.Title)
+		}
+		if slugs[slug] {
+			fail("duplicate slug %q", slug)
+		}
+		slugs[slug] = true
+		if p.Link != "" {
+			if u, err := url.Parse(p.Link); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				fail("link %q is not an http(s) URL", p.Link)
+			}
+		}
+		if p.Package != "" {
+			if _, _, err := pkgstats.Parse(p.Package); err != nil {
+				fail("%v", err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateExperience reports entries of data/experience.json that the
+// timeline cannot render properly.
+func ValidateExperience(list []content.Experience) error {
+	var errs []error
+	for i, e := range list {
+		fail := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("experience[%d] (%s): %s", i, e.Company, fmt.Sprintf(format, args...)))
+		}
+		if e.Role == "" {
+			fail("missing role")
+		}
+		if e.Company == "" {
+			fail("missing company")
+		}
+		if e.Type != "work" && e.Type != "education" {
+			fail("type %q is not work or education", e.Type)
+		}
+		if len(e.Dates) == 0 && e.StartDate == "" {
+			fail("missing start_date or dates")
+		}
+		for _, d := range append([]string{e.StartDate, e.EndDate}, e.Dates...) {
+			if _, ok := content.ParseLooseDate(d); d != "" && !ok {
+				fail("unrecognized date %q", d)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateSkills reports empty or duplicate categories in data/skills.json.
+func ValidateSkills(list []content.SkillCategory) error {
+	var errs []error
+	seen := make(map[string]bool, len(list))
+	for i, c := range list {
+		switch {
+		case c.Category == "":
+			errs = append(errs, fmt.Errorf("skills[%d]: missing category", i))
+		case seen[c.Category]:
+			errs = append(errs, fmt.Errorf("skills[%d]: duplicate category %q", i, c.Category))
+		}
+		seen[c.Category] = true
+		if len(c.Skills) == 0 {
+			errs = append(errs, fmt.Errorf("skills[%d] (%s): no skills", i, c.Category))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateAssets reports images referenced by data that are missing from
+// the static files in fsys. Remote URLs are not checked.
+func ValidateAssets(data content.PageData, fsys fs.FS) error {
+	var errs []error
+	check := func(field, ref string) {
+		name, ok := strings.CutPrefix(ref, "/static/")
+		if !ok {
+			return
+		}
+		if _, err := fs.Stat(fsys, "static/"+name); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s does not exist", field, ref))
+		}
+	}
+	check("about.profile_photo", data.About.ProfilePhoto)
+	for i, p := range data.Projects {
+		check(fmt.Sprintf("projects[%d] (%s).image", i, p.Title), p.Image)
+	}
+	for i, e := range data.Experience {
+		check(fmt.Sprintf("experience[%d] (%s).logo", i, e.Company), e.Logo)
+	}
+	return errors.Join(errs...)
+}
+
+// LintImages reports the static images that are too large or in the wrong
+// format for what the data files use them for, and those the templates and
+// data files refer to that are served at full size.
+func (h *Handler) LintImages(l images.Limits) ([]images.Problem, error) {
+	data := h.Data()
+	roles := make(map[string]images.Role)
+	set := func(ref string, role images.Role) {
+		if name, ok := strings.CutPrefix(ref, "/static/"); ok {
+			roles["static/"+name] = role
+		}
+	}
+	refs, err := templateStatic(h.fsys)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
+		set(ref, images.Unknown)
+	}
+	set(data.About.ProfilePhoto, images.Photo)
+	for _, p := range data.Projects {
+		set(p.Image, images.Photo)
+	}
+	for _, e := range data.Experience {
+		set(e.Logo, images.Icon)
+	}
+	return images.Lint(h.fsys, "static", roles, l)
+}
+
+// staticRef matches the static files the templates refer to.
+var staticRef = regexp.MustCompile(`/static/[^"'\s)?#{}]+`)
+
+// templateStatic returns the static files the templates of fsys refer to,
+// as /static/ paths.
+func templateStatic(fsys fs.FS) ([]string, error) {
+	var refs []string
+	err := fs.WalkDir(fsys, "templates", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(name) != ".html" {
+			return err
+		}
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, staticRef.FindAllString(string(b), -1)...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("lint images: %w", err)
+	}
+	return refs, nil
+}
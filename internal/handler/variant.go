@@ -0,0 +1,45 @@
+package handler
+
+import "io/fs"
+
+// Variant returns a handler that renders the templates and data files of
+// fsys with h's options and synced data, for serving another version of the
+// site side by side. The setters of h update its variants too, and Reload
+// reloads them. Create variants before serving requests.
+func (h *Handler) Variant(fsys fs.FS) (*Handler, error) {
+	return h.variant(fsys, h.opts)
+}
+
+// Locale returns a variant of h, as Variant does, that renders the site
+// in lang from the files of fsys, usually i18n.FS.
+func (h *Handler) Locale(fsys fs.FS, lang string) (*Handler, error) {
+	opts := h.opts
+	opts.Lang = lang
+	return h.variant(fsys, opts)
+}
+
+func (h *Handler) variant(fsys fs.FS, opts Options) (*Handler, error) {
+	v, err := New(fsys, opts)
+	if err != nil {
+		return nil, err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	v.repos, v.packages = h.repos, h.packages
+	v.githubStats, v.social, v.nowPlaying, v.strava = h.githubStats, h.social, h.nowPlaying, h.strava
+	v.subscribers, v.booking, v.uptime, v.videos = h.subscribers, h.booking, h.uptime, h.videos
+	v.stackExchange = h.stackExchange
+	v.merge()
+	h.variants = append(h.variants, v)
+	return v, nil
+}
+
+// syncVariants runs f on each variant while holding its lock, to copy
+// synced data the caller has just set on h.
+func (h *Handler) syncVariants(f func(v *Handler)) {
+	for _, v := range h.variants {
+		v.mu.Lock()
+		f(v)
+		v.mu.Unlock()
+	}
+}
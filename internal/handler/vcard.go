@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+)
+
+// VCard serves /contact.vcf, a vCard of the site's owner built from
+// data/about.json, which phones offer to add to the contacts.
+func (h *Handler) VCard(w http.ResponseWriter, r *http.Request) {
+	data, _ := h.data()
+	w.Header().Set("Content-Type", "text/vcard; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", cmp.Or(slugify(data.About.Name), "contact")+".vcf"))
+	fmt.Fprint(w, data.About.VCard(h.baseURL(r)+"/"))
+}
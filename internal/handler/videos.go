@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fpatron/portfolio/internal/youtube"
+)
+
+// SetVideos replaces the uploads shown in the videos section. Thumbnails
+// are served through the image cache when there is one, so visitors don't
+// load them from YouTube.
+func (h *Handler) SetVideos(list []youtube.Video) {
+	if h.opts.Images != nil {
+		for i := range list {
+			if list[i].Thumbnail != "" {
+				list[i].Thumbnail = h.opts.Images.Register(list[i].Thumbnail)
+			}
+		}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.videos = list
+	h.syncVariants(func(v *Handler) { v.videos = list })
+}
+
+// Videos serves the latest YouTube uploads partial, or them as JSON. It is
+// empty until the feed has been read.
+func (h *Handler) Videos(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	list := h.videos
+	h.mu.RUnlock()
+	if len(list) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.render.Respond(w, r, "videos", list, list)
+}
@@ -0,0 +1,23 @@
+package handler
+
+import "net/http"
+
+// ViewersTopic is the SSE topic carrying the live viewer count. Each open
+// stream subscribed to it is one page being viewed.
+const ViewersTopic = "viewers"
+
+// viewers returns the number of pages currently open.
+func (h *Handler) viewers() int {
+	if h.opts.Events == nil {
+		return 0
+	}
+	return h.opts.Events.Subscribers(ViewersTopic)
+}
+
+// Viewers serves the "N people viewing" partial, or the count as JSON.
+func (h *Handler) Viewers(w http.ResponseWriter, r *http.Request) {
+	n := h.viewers()
+	h.render.Respond(w, r, "viewers", n, struct {
+		Viewers int `json:"viewers"`
+	}{n})
+}
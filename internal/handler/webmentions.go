@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/render"
+	"github.com/fpatron/portfolio/internal/webmention"
+)
+
+// WebmentionsData is rendered by the "webmentions" partial.
+type WebmentionsData struct {
+	Reactions []webmention.Mention `json:"reactions"` // likes, reposts and bookmarks
+	Replies   []webmention.Mention `json:"replies"`   // replies and mentions
+}
+
+// AdminWebmentionsData is passed to the moderation page.
+type AdminWebmentionsData struct {
+	content.PageData
+	Pending []webmention.Mention
+	Sent    []webmention.Delivery
+}
+
+// WebmentionTarget reports whether u is a page that accepts webmentions and
+// returns its canonical URL. Project pages and blog posts do; the site's
+// canonical origin must be configured.
+func (h *Handler) WebmentionTarget(u *url.URL) (string, bool) {
+	base, err := url.Parse(h.opts.BaseURL)
+	if err != nil || base.Host == "" || !strings.EqualFold(u.Host, base.Host) {
+		return "", false
+	}
+	page := strings.TrimSuffix(u.Path, "/")
+	data, _ := h.data()
+	if slug, ok := strings.CutPrefix(page, "/projects/"); ok {
+		if _, ok := data.FindProject(slug); !ok {
+			return "", false
+		}
+	} else if slug, ok := strings.CutPrefix(page, "/blog/"); ok {
+		if _, ok := data.FindPost(slug); !ok {
+			return "", false
+		}
+	} else {
+		return "", false
+	}
+	return strings.TrimSuffix(h.opts.BaseURL, "/") + page, true
+}
+
+// Webmentions serves the approved mentions of a project page, or them as
+// JSON. It is empty when there are none.
+func (h *Handler) Webmentions(w http.ResponseWriter, r *http.Request) {
+	h.webmentions(w, r, "/projects/"+r.PathValue("slug"))
+}
+
+// PostWebmentions serves the approved mentions of a blog post like
+// Webmentions does for projects.
+func (h *Handler) PostWebmentions(w http.ResponseWriter, r *http.Request) {
+	h.webmentions(w, r, "/blog/"+r.PathValue("slug"))
+}
+
+// webmentions serves the approved mentions of the page at path.
+func (h *Handler) webmentions(w http.ResponseWriter, r *http.Request, path string) {
+	if h.opts.Webmentions == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	target := strings.TrimSuffix(h.baseURL(r), "/") + path
+	list, err := h.opts.Webmentions.Approved(r.Context(), target)
+	if err != nil {
+		log.Printf("webmentions: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if len(list) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	var data WebmentionsData
+	for _, m := range list {
+		switch m.Type {
+		case webmention.TypeLike, webmention.TypeRepost, webmention.TypeBookmark:
+			data.Reactions = append(data.Reactions, m)
+		default:
+			data.Replies = append(data.Replies, m)
+		}
+	}
+	h.render.Respond(w, r, "webmentions", data, data)
+}
+
+// AdminWebmentions renders the queue of mentions awaiting moderation and
+// the outcome of recently sent ones.
+func (h *Handler) AdminWebmentions(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Webmentions == nil {
+		http.Error(w, "webmentions are disabled", http.StatusNotFound)
+		return
+	}
+	pending, err := h.opts.Webmentions.Pending(r.Context())
+	if err != nil {
+		log.Printf("admin webmentions: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	sent, err := h.opts.Webmentions.Deliveries(r.Context(), 50)
+	if err != nil {
+		log.Printf("admin webmentions: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	data, _ := h.data()
+	data.Theme = render.Theme(r)
+	w.Header().Set("Cache-Control", "no-store")
+	h.render.Page(w, "admin-webmentions", AdminWebmentionsData{PageData: data, Pending: pending, Sent: sent})
+}
+
+// ModerateWebmention approves or rejects a pending mention. The form field
+// status is "approved" or "rejected". Only HTMX requests are accepted: the
+// custom header cannot be sent cross-site without a CORS preflight, which
+// keeps other sites from moderating with the admin's credentials.
+func (h *Handler) ModerateWebmention(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Webmentions == nil {
+		http.Error(w, "webmentions are disabled", http.StatusNotFound)
+		return
+	}
+	if r.Header.Get("HX-Request") == "" {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	status := r.FormValue("status")
+	if err != nil || (status != webmention.StatusApproved && status != webmention.StatusRejected) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	ok, err := h.opts.Webmentions.SetStatus(r.Context(), id, status)
+	if err != nil {
+		log.Printf("moderate webmention: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	// An empty 200 lets hx-swap="outerHTML" remove the row.
+	w.WriteHeader(http.StatusOK)
+}
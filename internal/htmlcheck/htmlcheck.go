@@ -0,0 +1,200 @@
+// Package htmlcheck finds well-formedness mistakes in HTML: unclosed and
+// stray tags, misnested elements, duplicate ids and malformed attributes.
+// It is a linter for the site's templates, run on the responses the server
+// renders in dev mode, rather than a conformance checker.
+package htmlcheck
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Problem is a mistake found in a document.
+type Problem struct {
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// void elements have no end tag.
+var void = set("area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "source", "track", "wbr")
+
+// optionalEnd elements may be left for the parser to close.
+var optionalEnd = set("p", "li", "dt", "dd", "option", "optgroup", "tr", "td", "th", "thead", "tbody", "tfoot", "colgroup", "caption", "rt", "rp", "html", "head", "body")
+
+// closesP elements close an open <p>, so the content meant to be inside
+// the paragraph ends up after it.
+var closesP = set("address", "article", "aside", "blockquote", "details", "div", "dl", "fieldset", "figure", "footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hr", "main", "nav", "ol", "p", "pre", "section", "table", "ul")
+
+// noSelfNesting elements cannot contain themselves.
+var noSelfNesting = set("a", "button", "form", "label")
+
+func set(names ...string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[n] = true
+	}
+	return m
+}
+
+// element is an open element.
+type element struct {
+	name string
+	line int
+}
+
+// Check reads an HTML document or fragment from r and returns the problems
+// found in it, in document order.
+func Check(r io.Reader) []Problem {
+	var (
+		problems []Problem
+		open     []element
+		ids      = make(map[string]int)
+		line     = 1
+	)
+	report := func(line int, format string, args ...any) {
+		problems = append(problems, Problem{line, fmt.Sprintf(format, args...)})
+	}
+	z := html.NewTokenizer(r)
+	for {
+		tt := z.Next()
+		start := line
+		line += bytes.Count(z.Raw(), []byte("\n"))
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				report(start, "%v", err)
+			}
+			for _, e := range slices.Backward(open) {
+				if !optionalEnd[e.name] {
+					report(e.line, "<%s> is never closed", e.name)
+				}
+			}
+			return problems
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			checkAttrs(t, start, ids, report)
+			if t.Data == "p" || closesP[t.Data] {
+				if n := len(open); n > 0 && open[n-1].name == "p" {
+					if t.Data != "p" {
+						report(start, "<%s> inside <p> closes the paragraph", t.Data)
+					}
+					open = open[:n-1]
+				}
+			}
+			if noSelfNesting[t.Data] && slices.ContainsFunc(open, func(e element) bool { return e.name == t.Data }) {
+				report(start, "<%s> nested in another <%s>", t.Data, t.Data)
+			}
+			if tt == html.StartTagToken && !void[t.Data] {
+				open = append(open, element{t.Data, start})
+			}
+		case html.EndTagToken:
+			t := z.Token()
+			if void[t.Data] {
+				report(start, "</%s>: <%s> has no end tag", t.Data, t.Data)
+				continue
+			}
+			i := len(open) - 1
+			for i >= 0 && open[i].name != t.Data {
+				i--
+			}
+			if i < 0 {
+				report(start, "</%s> closes nothing", t.Data)
+				continue
+			}
+			for _, e := range open[i+1:] {
+				if !optionalEnd[e.name] {
+					report(e.line, "<%s> is not closed before </%s> on line %d", e.name, t.Data, start)
+				}
+			}
+			open = open[:i]
+		}
+	}
+}
+
+// checkAttrs reports malformed, repeated and empty attributes and ids used
+// before.
+func checkAttrs(t html.Token, line int, ids map[string]int, report func(int, string, ...any)) {
+	seen := make(map[string]bool, len(t.Attr))
+	for _, a := range t.Attr {
+		switch {
+		case a.Key == "" || strings.ContainsAny(a.Key, "\"'<={}"):
+			report(line, "<%s> has a malformed attribute %q", t.Data, a.Key)
+			continue
+		case seen[a.Key]:
+			report(line, "<%s> repeats the %s attribute", t.Data, a.Key)
+		}
+		seen[a.Key] = true
+		if a.Key != "id" {
+			continue
+		}
+		switch first, ok := ids[a.Val]; {
+		case a.Val == "" || strings.ContainsAny(a.Val, " \t\n"):
+			report(line, "<%s> has an invalid id %q", t.Data, a.Val)
+		case ok:
+			report(line, "duplicate id %q, first used on line %d", a.Val, first)
+		default:
+			ids[a.Val] = line
+		}
+	}
+}
+
+// Middleware logs the problems of the HTML responses of next. The responses
+// themselves pass through unchanged.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cw := &copyWriter{ResponseWriter: w}
+		next.ServeHTTP(cw, r)
+		if !cw.html {
+			return
+		}
+		for _, p := range Check(&cw.buf) {
+			log.Printf("html: %s: %s", r.URL.Path, p)
+		}
+	})
+}
+
+// copyWriter keeps a copy of an HTML response body.
+type copyWriter struct {
+	http.ResponseWriter
+	decided bool
+	html    bool
+	buf     bytes.Buffer
+}
+
+func (w *copyWriter) WriteHeader(code int) {
+	if !w.decided {
+		w.decided = true
+		mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+		w.html = mediaType == "text/html" && w.Header().Get("Content-Encoding") == ""
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *copyWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.html {
+		w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *copyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
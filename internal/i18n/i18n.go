@@ -0,0 +1,184 @@
+// Package i18n serves the site in several languages. Each language other
+// than the default has its own data files and template strings, and each
+// visitor is routed to the site of their language.
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"golang.org/x/text/language"
+
+	"github.com/fpatron/portfolio/internal/sitefs"
+)
+
+// Cookie holds the language a visitor picked.
+const Cookie = "lang"
+
+// StringsFile holds the translations of the template strings, under the
+// data directory of a language.
+const StringsFile = "data/strings.json"
+
+type langKey struct{}
+
+// Lang returns the language of the request with ctx, or "" when the site
+// has a single language.
+func Lang(ctx context.Context) string {
+	l, _ := ctx.Value(langKey{}).(string)
+	return l
+}
+
+// Strings are the translations of template strings, by their English text.
+type Strings map[string]string
+
+// T returns the translation of s, or s when it has none.
+func (t Strings) T(s string) string {
+	if v, ok := t[s]; ok && v != "" {
+		return v
+	}
+	return s
+}
+
+// LoadStrings reads the StringsFile of fsys. A site without one has no
+// translations.
+func LoadStrings(fsys fs.FS) (Strings, error) {
+	b, err := fs.ReadFile(fsys, StringsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var t Strings
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", StringsFile, err)
+	}
+	return t, nil
+}
+
+// FS returns the files of fsys for lang: data/<lang>/<name> replaces
+// data/<name> where it exists, and the templates, static files and other
+// data files are shared.
+func FS(fsys fs.FS, lang string) fs.FS {
+	return sitefs.FS{Base: fsys, Theme: dataFS{fsys, lang}}
+}
+
+// dataFS has the data files of one language at the paths of the default
+// ones.
+type dataFS struct {
+	fsys fs.FS
+	lang string
+}
+
+func (d dataFS) Open(name string) (fs.File, error) {
+	if sub, ok := strings.CutPrefix(name, "data/"); ok {
+		return d.fsys.Open("data/" + d.lang + "/" + sub)
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// Languages routes visitors to the site of their language.
+type Languages struct {
+	def     string
+	tags    []language.Tag
+	names   []string
+	sites   map[string]*http.ServeMux
+	matcher language.Matcher
+}
+
+// New returns Languages whose default, def, is served by the site that
+// Route wraps. Add the other languages before serving requests.
+func New(def string) *Languages {
+	l := &Languages{def: def, sites: make(map[string]*http.ServeMux)}
+	l.add(def)
+	return l
+}
+
+// Add serves lang with the routes of site. Routes site does not have use
+// the default language's.
+func (l *Languages) Add(lang string, site *http.ServeMux) {
+	l.sites[lang] = site
+	l.add(lang)
+}
+
+func (l *Languages) add(lang string) {
+	l.names = append(l.names, lang)
+	l.tags = append(l.tags, language.Make(lang))
+	l.matcher = language.NewMatcher(l.tags)
+}
+
+// valid reports whether lang is one of the site's languages.
+func (l *Languages) valid(lang string) bool {
+	_, ok := l.sites[lang]
+	return ok || lang == l.def
+}
+
+// Select is middleware that picks each request's language and adds it to
+// the request's context: a ?lang= parameter, which is kept in a cookie,
+// else the cookie, else the best match of the Accept-Language header,
+// else the default.
+func (l *Languages) Select(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := r.URL.Query().Get(Cookie)
+		if l.valid(lang) {
+			http.SetCookie(w, &http.Cookie{
+				Name:     Cookie,
+				Value:    lang,
+				Path:     "/",
+				MaxAge:   365 * 24 * 60 * 60,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		} else if c, err := r.Cookie(Cookie); err == nil && l.valid(c.Value) {
+			lang = c.Value
+		} else {
+			lang = l.match(r.Header.Get("Accept-Language"))
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), langKey{}, lang)))
+	})
+}
+
+// match returns the language that best fits an Accept-Language header.
+func (l *Languages) match(accept string) string {
+	tags, _, err := language.ParseAcceptLanguage(accept)
+	if err != nil || len(tags) == 0 {
+		return l.def
+	}
+	_, i, conf := l.matcher.Match(tags...)
+	if conf == language.No {
+		return l.def
+	}
+	return l.names[i]
+}
+
+// Route serves the routes of each language's site to the visitors of that
+// language, and everything else from def. Responses vary by language, so
+// shared caches keep them apart.
+func (l *Languages) Route(def http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := Lang(r.Context())
+		w.Header().Add("Vary", "Accept-Language, Cookie")
+		site, ok := l.sites[lang]
+		if !ok {
+			if lang == l.def {
+				w.Header().Set("Content-Language", lang)
+			}
+			def.ServeHTTP(w, r)
+			return
+		}
+		_, pattern := site.Handler(r)
+		// "GET /" is the 404 page of the paths it doesn't serve, which
+		// the site handles.
+		if pattern == "" || pattern == "GET /" {
+			def.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Content-Language", lang)
+		site.ServeHTTP(w, r)
+	})
+}
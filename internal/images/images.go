@@ -0,0 +1,209 @@
+// Package images fetches remote images referenced by the content, scales
+// them down and caches them on disk, so pages serve them from the site's own
+// origin instead of hotlinking.
+package images
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif" // decode GIF sources
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // decode WebP sources
+)
+
+// Path is the URL prefix cached images are served under.
+const Path = "/images/"
+
+// maxSource is the largest remote image that is downloaded.
+const maxSource = 20 << 20
+
+// maxPixels is the largest image that is decoded, since a small compressed
+// file can describe an image too large to hold in memory.
+const maxPixels = 40_000_000
+
+// Cache serves registered remote images from a directory, fetching each on
+// first request.
+type Cache struct {
+	dir      string
+	maxWidth int
+	client   *http.Client
+
+	mu      sync.Mutex
+	sources map[string]string      // key -> remote URL
+	fetches map[string]*sync.Mutex // key -> lock held while fetching it
+}
+
+// New returns a Cache storing images in dir, which is created if needed.
+// Images wider than maxWidth are scaled down to it.
+func New(dir string, maxWidth int) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create image cache: %w", err)
+	}
+	return &Cache{
+		dir:      dir,
+		maxWidth: maxWidth,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		sources:  make(map[string]string),
+		fetches:  make(map[string]*sync.Mutex),
+	}, nil
+}
+
+// Register allows remote to be served and returns its local path. Only
+// registered URLs are fetched, so the endpoint is not an open proxy.
+func (c *Cache) Register(remote string) string {
+	sum := sha256.Sum256([]byte(remote))
+	key := hex.EncodeToString(sum[:12])
+	c.mu.Lock()
+	c.sources[key] = remote
+	c.mu.Unlock()
+	return Path + key
+}
+
+// ServeHTTP serves GET /images/{key}.
+func (c *Cache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	c.mu.Lock()
+	remote, ok := c.sources[key]
+	c.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	file := filepath.Join(c.dir, key)
+	if err := c.ensure(r.Context(), key, file, remote); err != nil {
+		log.Printf("images: %s: %v", remote, err)
+		http.Error(w, "image unavailable", http.StatusBadGateway)
+		return
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	http.ServeContent(w, r, "", st.ModTime(), f)
+}
+
+// ensure downloads and scales remote into file unless it is cached. Requests
+// for the same key wait for a single fetch; different keys fetch in
+// parallel.
+func (c *Cache) ensure(ctx context.Context, key, file, remote string) error {
+	c.mu.Lock()
+	lock, ok := c.fetches[key]
+	if !ok {
+		lock = new(sync.Mutex)
+		c.fetches[key] = lock
+	}
+	c.mu.Unlock()
+	lock.Lock()
+	defer lock.Unlock()
+	if _, err := os.Stat(file); err == nil {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, remote, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch: %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSource))
+	if err != nil {
+		return fmt.Errorf("fetch: %w", err)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return fmt.Errorf("decode: %dx%d image is too large", cfg.Width, cfg.Height)
+	}
+	src, _, err := image.Decode(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := encode(&buf, c.scale(src)); err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// Prune removes the cached images that are no longer registered and were
+// last fetched more than maxAge ago, such as those of removed projects. It
+// returns how many it removed.
+func (c *Cache) Prune(maxAge time.Duration) (int, error) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return 0, fmt.Errorf("prune image cache: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, e := range entries {
+		if _, ok := c.sources[e.Name()]; ok || e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, e.Name())); err != nil {
+			return n, fmt.Errorf("prune image cache: %w", err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// scale returns img scaled down to the maximum width, or img itself when it
+// is narrow enough.
+func (c *Cache) scale(img image.Image) image.Image {
+	b := img.Bounds()
+	if b.Dx() <= c.maxWidth {
+		return img
+	}
+	h := b.Dy() * c.maxWidth / b.Dx()
+	dst := image.NewRGBA(image.Rect(0, 0, c.maxWidth, max(h, 1)))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// encode writes images with transparency as PNG and the rest as JPEG.
+func encode(w io.Writer, img image.Image) error {
+	if o, ok := img.(interface{ Opaque() bool }); ok && !o.Opaque() {
+		return png.Encode(w, img)
+	}
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+}
@@ -0,0 +1,145 @@
+package images
+
+import (
+	"fmt"
+	"image"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Role is what the content uses an image for, which decides the size and
+// format it should have.
+type Role int
+
+const (
+	// Unknown is the role of images the data files do not give one, such
+	// as those the templates refer to.
+	Unknown Role = iota
+	// Photo is a picture such as a profile photo or project screenshot,
+	// best stored as JPEG or WebP.
+	Photo
+	// Icon is a logo or icon shown small, best stored as PNG or SVG.
+	Icon
+)
+
+// Limits are the thresholds of Lint.
+type Limits struct {
+	Bytes     int64 // largest file
+	Width     int   // widest or tallest photo or other image, in pixels
+	IconWidth int   // widest or tallest icon, in pixels
+}
+
+// DefaultLimits suit the site's layout.
+var DefaultLimits = Limits{Bytes: 200 << 10, Width: 1600, IconWidth: 256}
+
+// Problem is an image that Lint flags.
+type Problem struct {
+	File    string
+	Message string
+}
+
+func (p Problem) String() string { return p.File + ": " + p.Message }
+
+// Lint reports the images under dir in fsys that are over the limits or in
+// a format unsuited to their role. roles holds the role of the files the
+// templates and data files refer to, by path in fsys. Unlike remote images,
+// which the Cache scales down to its width, static images are served as
+// they are, so Lint also reports the referenced ones, other than icons,
+// wider than an icon that have no scaled variant beside them: a file named
+// after the image with its width, such as profile-640w.jpg for profile.jpg.
+func Lint(fsys fs.FS, dir string, roles map[string]Role, l Limits) ([]Problem, error) {
+	var problems []Problem
+	err := fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		ext := strings.ToLower(path.Ext(name))
+		switch ext {
+		case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".svg", ".ico", ".avif":
+		default:
+			return nil
+		}
+		report := func(format string, args ...any) {
+			problems = append(problems, Problem{name, fmt.Sprintf(format, args...)})
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() > l.Bytes {
+			report("%d KB, over the %d KB limit", info.Size()>>10, l.Bytes>>10)
+		}
+		role, referenced := roles[name]
+		if ext == ".svg" || ext == ".ico" || ext == ".avif" {
+			if role == Photo && ext != ".avif" {
+				report("photo stored as %s; use JPEG or WebP", strings.ToUpper(ext[1:]))
+			}
+			return nil
+		}
+
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		cfg, format, err := image.DecodeConfig(f)
+		f.Close()
+		if err != nil {
+			report("cannot decode: %v", err)
+			return nil
+		}
+		limit, kind := l.Width, "image"
+		if role == Icon {
+			limit, kind = l.IconWidth, "icon"
+		}
+		if cfg.Width > limit || cfg.Height > limit {
+			report("%dx%d pixels, over the %d pixels of an %s; scale it down, as static images are not resized", cfg.Width, cfg.Height, limit, kind)
+		}
+		if referenced && role != Icon && cfg.Width > l.IconWidth && !hasVariant(fsys, name) {
+			report("served at full size, as the image cache only scales remote images; add a scaled variant such as %s", variantName(name, cfg.Width/2))
+		}
+		switch {
+		case role == Photo && (format == "png" || format == "gif"):
+			report("photo stored as %s; JPEG or WebP is several times smaller", strings.ToUpper(format))
+		case role == Icon && format == "jpeg":
+			report("icon stored as JPEG, which blurs edges and has no transparency; use PNG or SVG")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("lint images: %w", err)
+	}
+	return problems, nil
+}
+
+// variantName returns the name of the variant of name scaled to width.
+func variantName(name string, width int) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "-" + strconv.Itoa(width) + "w" + ext
+}
+
+// hasVariant reports whether the directory of name holds a scaled variant
+// of it, named as variantName does.
+func hasVariant(fsys fs.FS, name string) bool {
+	dir, file := path.Split(name)
+	entries, err := fs.ReadDir(fsys, path.Clean(dir))
+	if err != nil {
+		return false
+	}
+	ext := path.Ext(file)
+	stem := strings.TrimSuffix(file, ext) + "-"
+	for _, e := range entries {
+		w, ok := strings.CutPrefix(e.Name(), stem)
+		if !ok {
+			continue
+		}
+		if w, ok = strings.CutSuffix(w, "w"+ext); !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(w); err == nil && n > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// testImage returns a w×h image in format, "png" or "jpeg", padded to at
+// least size bytes; decoders stop before the padding.
+func testImage(t *testing.T, format string, w, h, size int) *fstest.MapFile {
+	t.Helper()
+	var buf bytes.Buffer
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	var err error
+	if format == "png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pad := size - buf.Len(); pad > 0 {
+		buf.Write(make([]byte, pad))
+	}
+	return &fstest.MapFile{Data: buf.Bytes()}
+}
+
+func TestLint(t *testing.T) {
+	limits := Limits{Bytes: 4 << 10, Width: 200, IconWidth: 50}
+	for _, tt := range []struct {
+		name  string
+		files map[string]*fstest.MapFile
+		roles map[string]Role
+		want  []string // "file: part of the message"
+	}{
+		{
+			name:  "within the limits",
+			files: map[string]*fstest.MapFile{"static/photo.jpg": testImage(t, "jpeg", 40, 30, 0), "static/logo.png": testImage(t, "png", 50, 50, 0)},
+			roles: map[string]Role{"static/photo.jpg": Photo, "static/logo.png": Icon},
+		},
+		{
+			name:  "too many bytes",
+			files: map[string]*fstest.MapFile{"static/photo.jpg": testImage(t, "jpeg", 40, 30, 5<<10)},
+			want:  []string{"static/photo.jpg: 5 KB, over the 4 KB limit"},
+		},
+		{
+			name:  "too wide",
+			files: map[string]*fstest.MapFile{"static/wide.jpg": testImage(t, "jpeg", 201, 10, 0)},
+			want:  []string{"static/wide.jpg: 201x10 pixels, over the 200 pixels of an image"},
+		},
+		{
+			name:  "too tall",
+			files: map[string]*fstest.MapFile{"static/tall.png": testImage(t, "png", 10, 201, 0)},
+			want:  []string{"static/tall.png: 10x201 pixels, over the 200 pixels of an image"},
+		},
+		{
+			name:  "icon too wide",
+			files: map[string]*fstest.MapFile{"static/logo.png": testImage(t, "png", 51, 51, 0)},
+			roles: map[string]Role{"static/logo.png": Icon},
+			want:  []string{"static/logo.png: 51x51 pixels, over the 50 pixels of an icon"},
+		},
+		{
+			name:  "photo as PNG",
+			files: map[string]*fstest.MapFile{"static/photo.png": testImage(t, "png", 40, 40, 0)},
+			roles: map[string]Role{"static/photo.png": Photo},
+			want:  []string{"static/photo.png: photo stored as PNG"},
+		},
+		{
+			name:  "photo as SVG",
+			files: map[string]*fstest.MapFile{"static/photo.svg": {Data: []byte("<svg/>")}},
+			roles: map[string]Role{"static/photo.svg": Photo},
+			want:  []string{"static/photo.svg: photo stored as SVG"},
+		},
+		{
+			name:  "icon as JPEG",
+			files: map[string]*fstest.MapFile{"static/logo.jpg": testImage(t, "jpeg", 40, 40, 0)},
+			roles: map[string]Role{"static/logo.jpg": Icon},
+			want:  []string{"static/logo.jpg: icon stored as JPEG"},
+		},
+		{
+			name:  "referenced without a scaled variant",
+			files: map[string]*fstest.MapFile{"static/photo.jpg": testImage(t, "jpeg", 120, 80, 0), "static/banner.png": testImage(t, "png", 60, 20, 0)},
+			roles: map[string]Role{"static/photo.jpg": Photo, "static/banner.png": Unknown},
+			want: []string{
+				"static/banner.png: served at full size, as the image cache only scales remote images; add a scaled variant such as static/banner-30w.png",
+				"static/photo.jpg: served at full size, as the image cache only scales remote images; add a scaled variant such as static/photo-60w.jpg",
+			},
+		},
+		{
+			name: "referenced with a scaled variant",
+			files: map[string]*fstest.MapFile{
+				"static/photo.jpg":     testImage(t, "jpeg", 120, 80, 0),
+				"static/photo-60w.jpg": testImage(t, "jpeg", 60, 40, 0),
+			},
+			roles: map[string]Role{"static/photo.jpg": Photo},
+		},
+		{
+			name: "variant in another format or unnumbered",
+			files: map[string]*fstest.MapFile{
+				"static/photo.jpg":        testImage(t, "jpeg", 120, 80, 0),
+				"static/photo-60w.png":    testImage(t, "png", 60, 40, 0),
+				"static/photo-smallw.jpg": testImage(t, "jpeg", 60, 40, 0),
+			},
+			roles: map[string]Role{"static/photo.jpg": Photo},
+			want:  []string{"static/photo.jpg: served at full size"},
+		},
+		{
+			name:  "unreferenced",
+			files: map[string]*fstest.MapFile{"static/photo.jpg": testImage(t, "jpeg", 120, 80, 0)},
+		},
+		{
+			name: "not an image or undecodable",
+			files: map[string]*fstest.MapFile{
+				"static/style.css":  {Data: []byte("body{}")},
+				"static/broken.png": {Data: []byte("not a png")},
+			},
+			want: []string{"static/broken.png: cannot decode"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, err := Lint(fstest.MapFS(tt.files), "static", tt.roles, limits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, p := range problems {
+				got = append(got, p.String())
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("problems = %q, want %q", got, tt.want)
+			}
+			for _, w := range tt.want {
+				if !slices.ContainsFunc(got, func(g string) bool { return strings.HasPrefix(g, w) }) {
+					t.Errorf("problems = %q, want one starting with %q", got, w)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package importer
+
+import (
+	"io"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/content"
+)
+
+// ParseExperienceCSV reads experience entries from a CSV whose columns are
+// named after the fields of data/experience.json: role, company,
+// company_url, logo, location, start_date, end_date, type and description.
+// Each line of a description becomes a separate bullet, and type defaults to
+// work.
+func ParseExperienceCSV(r io.Reader) ([]content.Experience, error) {
+	rows, err := readCSV(r, "experience CSV", "role", "company", "start_date")
+	if err != nil {
+		return nil, err
+	}
+	var list []content.Experience
+	for _, r := range rows {
+		typ := strings.ToLower(r.get("type"))
+		if typ == "" {
+			typ = "work"
+		}
+		list = append(list, content.Experience{
+			Role:        r.get("role"),
+			Company:     r.get("company"),
+			CompanyURL:  r.get("company_url"),
+			Logo:        r.get("logo"),
+			StartDate:   r.get("start_date"),
+			EndDate:     r.get("end_date"),
+			Location:    r.get("location"),
+			Description: paragraphs(r.get("description")),
+			Type:        typ,
+		})
+	}
+	return list, nil
+}
+
+// ParseSkillsCSV reads skills from a CSV with category and skill columns,
+// one skill per line.
+func ParseSkillsCSV(r io.Reader) ([]content.SkillCategory, error) {
+	rows, err := readCSV(r, "skills CSV", "category", "skill")
+	if err != nil {
+		return nil, err
+	}
+	pairs := make([][2]string, 0, len(rows))
+	for _, r := range rows {
+		pairs = append(pairs, [2]string{r.get("category"), r.get("skill")})
+	}
+	return groupSkills(pairs), nil
+}
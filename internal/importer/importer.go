@@ -0,0 +1,94 @@
+// Package importer converts résumé exports into the entries of
+// data/experience.json and data/skills.json.
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/content"
+)
+
+// row is a CSV record keyed by column name.
+type row map[string]string
+
+// readCSV reads a CSV file with a header line and returns its records. It
+// fails when one of the required columns is missing.
+func readCSV(r io.Reader, name string, required ...string) ([]row, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	for i, h := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+	}
+	for _, col := range required {
+		if !slices.ContainsFunc(header, func(h string) bool { return strings.EqualFold(h, col) }) {
+			return nil, fmt.Errorf("read %s: missing column %q", name, col)
+		}
+	}
+
+	var rows []row
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+		r := make(row, len(header))
+		for i, h := range header {
+			if i < len(rec) {
+				r[strings.ToLower(h)] = strings.TrimSpace(rec[i])
+			}
+		}
+		rows = append(rows, r)
+	}
+}
+
+// get returns the value of the column, matched case-insensitively.
+func (r row) get(col string) string {
+	return r[strings.ToLower(col)]
+}
+
+// paragraphs splits free text into the description bullets of an entry,
+// dropping blank lines and leading bullet characters.
+func paragraphs(s string) []string {
+	out := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "•*-–·"))
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// groupSkills collects (category, skill) pairs into categories, keeping the
+// order in which categories and skills first appear.
+func groupSkills(pairs [][2]string) []content.SkillCategory {
+	var list []content.SkillCategory
+	index := make(map[string]int)
+	seen := make(map[[2]string]bool)
+	for _, p := range pairs {
+		if p[1] == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		i, ok := index[p[0]]
+		if !ok {
+			i = len(list)
+			index[p[0]] = i
+			list = append(list, content.SkillCategory{Category: p[0]})
+		}
+		list[i].Skills = append(list[i].Skills, p[1])
+	}
+	return list
+}
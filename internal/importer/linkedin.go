@@ -0,0 +1,79 @@
+package importer
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/fpatron/portfolio/internal/content"
+)
+
+// LinkedInSkillsCategory is the category given to skills from a LinkedIn
+// export, which does not group them.
+const LinkedInSkillsCategory = "Skills"
+
+// ParseLinkedIn reads Positions.csv, Education.csv and Skills.csv from an
+// extracted LinkedIn data export (or the archive itself, through
+// zip.Reader). Only Positions.csv is required.
+func ParseLinkedIn(fsys fs.FS) ([]content.Experience, []content.SkillCategory, error) {
+	positions, err := openCSV(fsys, "Positions.csv", "Company Name", "Title", "Started On")
+	if err != nil {
+		return nil, nil, err
+	}
+	var experience []content.Experience
+	for _, r := range positions {
+		experience = append(experience, content.Experience{
+			Role:        r.get("Title"),
+			Company:     r.get("Company Name"),
+			StartDate:   r.get("Started On"),
+			EndDate:     orPresent(r.get("Finished On")),
+			Location:    r.get("Location"),
+			Description: paragraphs(r.get("Description")),
+			Type:        "work",
+		})
+	}
+
+	education, err := openCSV(fsys, "Education.csv", "School Name", "Start Date")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, err
+	}
+	for _, r := range education {
+		role := r.get("Degree Name")
+		if role == "" {
+			role = r.get("Notes")
+		}
+		experience = append(experience, content.Experience{
+			Role:        role,
+			Company:     r.get("School Name"),
+			StartDate:   r.get("Start Date"),
+			EndDate:     orPresent(r.get("End Date")),
+			Description: paragraphs(r.get("Activities")),
+			Type:        "education",
+		})
+	}
+
+	skills, err := openCSV(fsys, "Skills.csv", "Name")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, err
+	}
+	var pairs [][2]string
+	for _, r := range skills {
+		pairs = append(pairs, [2]string{LinkedInSkillsCategory, r.get("Name")})
+	}
+	return experience, groupSkills(pairs), nil
+}
+
+func openCSV(fsys fs.FS, name string, required ...string) ([]row, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readCSV(f, name, required...)
+}
+
+func orPresent(s string) string {
+	if s == "" {
+		return "Present"
+	}
+	return s
+}
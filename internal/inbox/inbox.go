@@ -0,0 +1,106 @@
+// Package inbox stores the messages sent through the contact form, so they
+// survive restarts and can be read from the admin pages whatever else
+// delivers them.
+package inbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/db"
+)
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS contact_messages (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		email TEXT NOT NULL,
+		message TEXT NOT NULL,
+		ip TEXT NOT NULL,
+		user_agent TEXT NOT NULL,
+		created INTEGER NOT NULL,
+		read INTEGER NOT NULL DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS contact_messages_created ON contact_messages (created)`,
+}
+
+// Message is a stored contact form submission.
+type Message struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Message   string    `json:"message"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Created   time.Time `json:"created"`
+	Read      bool      `json:"read"`
+}
+
+// Store persists the messages in SQLite.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates the table if needed.
+func NewStore(ctx context.Context, database *sql.DB) (*Store, error) {
+	if err := db.Migrate(ctx, database, schema...); err != nil {
+		return nil, fmt.Errorf("inbox: %w", err)
+	}
+	return &Store{db: database}, nil
+}
+
+// Add stores m as unread and returns its ID.
+func (s *Store) Add(ctx context.Context, m Message) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO contact_messages (name, email, message, ip, user_agent, created) VALUES (?, ?, ?, ?, ?, ?)`,
+		m.Name, m.Email, m.Message, m.IP, m.UserAgent, time.Now().Unix())
+	if err != nil {
+		return 0, fmt.Errorf("store contact message: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// List returns at most limit messages, newest first, skipping the first
+// offset.
+func (s *Store) List(ctx context.Context, offset, limit int) ([]Message, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, email, message, ip, user_agent, created, read FROM contact_messages
+		ORDER BY created DESC, id DESC LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list contact messages: %w", err)
+	}
+	defer rows.Close()
+	var list []Message
+	for rows.Next() {
+		var m Message
+		var created int64
+		if err := rows.Scan(&m.ID, &m.Name, &m.Email, &m.Message, &m.IP, &m.UserAgent, &created, &m.Read); err != nil {
+			return nil, fmt.Errorf("list contact messages: %w", err)
+		}
+		m.Created = time.Unix(created, 0)
+		list = append(list, m)
+	}
+	return list, rows.Err()
+}
+
+// Count returns the number of messages and how many of them are unread.
+func (s *Store) Count(ctx context.Context) (total, unread int, err error) {
+	err = s.db.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(SUM(read = 0), 0) FROM contact_messages`).Scan(&total, &unread)
+	if err != nil {
+		return 0, 0, fmt.Errorf("count contact messages: %w", err)
+	}
+	return total, unread, nil
+}
+
+// MarkRead marks the message with the given ID as read or unread. It
+// reports whether the message exists.
+func (s *Store) MarkRead(ctx context.Context, id int64, read bool) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `UPDATE contact_messages SET read = ? WHERE id = ?`, read, id)
+	if err != nil {
+		return false, fmt.Errorf("mark contact message: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,431 @@
+// Package indieauth implements an IndieAuth (https://indieauth.spec.indieweb.org/)
+// authorization server for the site owner, so the site's URL can be used
+// to sign in to IndieWeb services. Authenticating the owner is left to the
+// caller, which shows the consent screen behind its own login.
+package indieauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/db"
+)
+
+// Routes served by Register, relative to the site root. AuthorizationPath
+// is also where the caller serves the consent screen (GET).
+const (
+	MetadataPath      = "/.well-known/oauth-authorization-server"
+	AuthorizationPath = "/auth"
+	TokenPath         = "/token"
+	RevocationPath    = "/token/revoke"
+	IntrospectionPath = "/token/introspect"
+)
+
+const (
+	codeTTL    = 10 * time.Minute
+	consentTTL = 30 * time.Minute
+)
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS indieauth_tokens (
+		hash TEXT PRIMARY KEY,
+		client_id TEXT NOT NULL,
+		scope TEXT NOT NULL,
+		issued INTEGER NOT NULL,
+		revoked INTEGER NOT NULL DEFAULT 0
+	)`,
+}
+
+// Profile is the owner's profile returned with the profile and email
+// scopes.
+type Profile struct {
+	Name  string `json:"name,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Photo string `json:"photo,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// Request is a validated authorization request.
+type Request struct {
+	ClientID      string
+	RedirectURI   string
+	State         string
+	CodeChallenge string
+	Scopes        []string
+}
+
+// Pending is an authorization request waiting for the owner's decision.
+// Ticket identifies it in the consent form, which keeps other sites from
+// submitting a decision on the owner's behalf.
+type Pending struct {
+	Request
+	Ticket string
+}
+
+type grant struct {
+	Request
+	expires time.Time
+}
+
+// Server is the authorization and token endpoint of a single identity.
+type Server struct {
+	// Me is the owner's canonical profile URL, e.g. "https://example.com/".
+	Me string
+	// Profile, when set, is called whenever a client asks for the owner's
+	// profile.
+	Profile func() Profile
+	// Now, if set, replaces time.Now as the server's clock.
+	Now func() time.Time
+	db  *sql.DB
+
+	mu      sync.Mutex
+	pending map[string]grant // by ticket
+	codes   map[string]grant // by code
+}
+
+// New creates the token table if needed. me is the owner's profile URL.
+func New(ctx context.Context, database *sql.DB, me string) (*Server, error) {
+	u, err := url.Parse(me)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("indieauth: invalid profile URL %q", me)
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	if err := db.Migrate(ctx, database, schema...); err != nil {
+		return nil, fmt.Errorf("indieauth: %w", err)
+	}
+	return &Server{
+		Me:      u.String(),
+		db:      database,
+		pending: make(map[string]grant),
+		codes:   make(map[string]grant),
+	}, nil
+}
+
+func (s *Server) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+func (s *Server) url(path string) string {
+	return strings.TrimSuffix(s.Me, "/") + path
+}
+
+// Register mounts the metadata, code redemption, token, revocation and
+// introspection endpoints on mux. Introspection is wrapped in protect,
+// since the spec requires resource servers to authenticate.
+func (s *Server) Register(mux *http.ServeMux, protect func(http.HandlerFunc) http.Handler) {
+	mux.HandleFunc("GET "+MetadataPath, s.metadata)
+	mux.HandleFunc("POST "+AuthorizationPath, s.redeem)
+	mux.HandleFunc("POST "+TokenPath, s.token)
+	mux.HandleFunc("GET "+TokenPath, s.verify)
+	mux.HandleFunc("POST "+RevocationPath, s.revoke)
+	mux.Handle("POST "+IntrospectionPath, protect(s.introspect))
+}
+
+func (s *Server) metadata(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                         s.Me,
+		"authorization_endpoint":                         s.url(AuthorizationPath),
+		"token_endpoint":                                 s.url(TokenPath),
+		"revocation_endpoint":                            s.url(RevocationPath),
+		"introspection_endpoint":                         s.url(IntrospectionPath),
+		"response_types_supported":                       []string{"code"},
+		"grant_types_supported":                          []string{"authorization_code"},
+		"code_challenge_methods_supported":               []string{"S256"},
+		"scopes_supported":                               []string{"profile", "email"},
+		"authorization_response_iss_parameter_supported": true,
+	})
+}
+
+// Begin validates an authorization request and holds it until Finish. Only
+// redirect URIs on the client's own origin are accepted, so client
+// metadata never needs to be fetched.
+func (s *Server) Begin(q url.Values) (Pending, error) {
+	if rt := q.Get("response_type"); rt != "code" && rt != "" && rt != "id" {
+		return Pending{}, fmt.Errorf("unsupported response_type %q", rt)
+	}
+	client, err := parseURL(q.Get("client_id"))
+	if err != nil {
+		return Pending{}, fmt.Errorf("invalid client_id: %w", err)
+	}
+	redirect, err := parseURL(q.Get("redirect_uri"))
+	if err != nil {
+		return Pending{}, fmt.Errorf("invalid redirect_uri: %w", err)
+	}
+	if redirect.Scheme != client.Scheme || redirect.Host != client.Host {
+		return Pending{}, errors.New("redirect_uri must be on the client_id's origin")
+	}
+	if q.Get("state") == "" {
+		return Pending{}, errors.New("missing state")
+	}
+	if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		return Pending{}, errors.New("a code_challenge with method S256 is required")
+	}
+
+	p := Pending{
+		Request: Request{
+			ClientID:      client.String(),
+			RedirectURI:   redirect.String(),
+			State:         q.Get("state"),
+			CodeChallenge: q.Get("code_challenge"),
+			Scopes:        strings.Fields(q.Get("scope")),
+		},
+		Ticket: randomToken(),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prune(s.pending, s.now())
+	s.pending[p.Ticket] = grant{p.Request, s.now().Add(consentTTL)}
+	return p, nil
+}
+
+// Finish records the owner's decision on a pending request and returns the
+// URL to send the browser back to the client with. Approved requests get
+// an authorization code limited to scopes, which must have been requested.
+func (s *Server) Finish(ticket string, approve bool, scopes []string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g, ok := s.pending[ticket]
+	delete(s.pending, ticket)
+	if !ok || s.now().After(g.expires) {
+		return "", errors.New("unknown or expired authorization request")
+	}
+
+	u, _ := url.Parse(g.RedirectURI)
+	q := u.Query()
+	q.Set("state", g.State)
+	q.Set("iss", s.Me)
+	if approve {
+		g.Scopes = slices.DeleteFunc(slices.Clone(scopes), func(sc string) bool { return !slices.Contains(g.Scopes, sc) })
+		g.expires = s.now().Add(codeTTL)
+		code := randomToken()
+		prune(s.codes, s.now())
+		s.codes[code] = g
+		q.Set("code", code)
+	} else {
+		q.Set("error", "access_denied")
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// exchange redeems a code once, checking it against the client's request
+// and PKCE verifier.
+func (s *Server) exchange(r *http.Request) (Request, error) {
+	code := r.FormValue("code")
+	s.mu.Lock()
+	g, ok := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+	if !ok || s.now().After(g.expires) {
+		return Request{}, errors.New("unknown or expired code")
+	}
+	if !sameURL(r.FormValue("client_id"), g.ClientID) || !sameURL(r.FormValue("redirect_uri"), g.RedirectURI) {
+		return Request{}, errors.New("client_id or redirect_uri does not match the request")
+	}
+	sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	if subtle.ConstantTimeCompare([]byte(challenge), []byte(g.CodeChallenge)) != 1 {
+		return Request{}, errors.New("code_verifier does not match")
+	}
+	return g.Request, nil
+}
+
+// response is the body of a successful code redemption.
+func (s *Server) response(req Request) map[string]any {
+	resp := map[string]any{"me": s.Me}
+	if slices.Contains(req.Scopes, "profile") && s.Profile != nil {
+		p := s.Profile()
+		if !slices.Contains(req.Scopes, "email") {
+			p.Email = ""
+		}
+		resp["profile"] = p
+	}
+	return resp
+}
+
+// redeem exchanges a code for the owner's identity only, for clients that
+// just sign the user in.
+func (s *Server) redeem(w http.ResponseWriter, r *http.Request) {
+	req, err := s.exchange(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_grant", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, s.response(req))
+}
+
+// token exchanges a code for an access token.
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if gt := r.FormValue("grant_type"); gt != "authorization_code" {
+		writeError(w, http.StatusBadRequest, "unsupported_grant_type", fmt.Sprintf("unsupported grant_type %q", gt))
+		return
+	}
+	req, err := s.exchange(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_grant", err.Error())
+		return
+	}
+	if len(req.Scopes) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_grant", "no scope was granted; redeem the code at the authorization endpoint")
+		return
+	}
+
+	token := randomToken()
+	scope := strings.Join(req.Scopes, " ")
+	_, err = s.db.ExecContext(r.Context(), `INSERT INTO indieauth_tokens (hash, client_id, scope, issued) VALUES (?, ?, ?, ?)`,
+		hashToken(token), req.ClientID, scope, s.now().Unix())
+	if err != nil {
+		log.Printf("indieauth: issue token: %v", err)
+		writeError(w, http.StatusInternalServerError, "server_error", "could not issue token")
+		return
+	}
+	log.Printf("indieauth: issued token to %s (%s)", req.ClientID, scope)
+	resp := s.response(req)
+	resp["access_token"] = token
+	resp["token_type"] = "Bearer"
+	resp["scope"] = scope
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// tokenInfo describes an active token.
+type tokenInfo struct {
+	client string
+	scope  string
+	issued int64
+}
+
+// lookup returns the active token's details, or false when the token is
+// unknown or revoked.
+func (s *Server) lookup(ctx context.Context, token string) (tokenInfo, bool, error) {
+	var t tokenInfo
+	err := s.db.QueryRowContext(ctx, `SELECT client_id, scope, issued FROM indieauth_tokens WHERE hash = ? AND revoked = 0`,
+		hashToken(token)).Scan(&t.client, &t.scope, &t.issued)
+	if errors.Is(err, sql.ErrNoRows) {
+		return t, false, nil
+	}
+	return t, err == nil, err
+}
+
+// verify is the legacy token verification: GET with the token as a Bearer
+// credential.
+func (s *Server) verify(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid_request", "missing bearer token")
+		return
+	}
+	t, ok, err := s.lookup(r.Context(), token)
+	if err != nil {
+		log.Printf("indieauth: verify: %v", err)
+		writeError(w, http.StatusInternalServerError, "server_error", "could not verify token")
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid_token", "token is unknown or revoked")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"me": s.Me, "client_id": t.client, "scope": t.scope})
+}
+
+func (s *Server) introspect(w http.ResponseWriter, r *http.Request) {
+	t, ok, err := s.lookup(r.Context(), r.FormValue("token"))
+	if err != nil {
+		log.Printf("indieauth: introspect: %v", err)
+		writeError(w, http.StatusInternalServerError, "server_error", "could not verify token")
+		return
+	}
+	if !ok {
+		writeJSON(w, http.StatusOK, map[string]bool{"active": false})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"active": true, "me": s.Me, "client_id": t.client, "scope": t.scope, "iat": t.issued,
+	})
+}
+
+// revoke always answers 200, as RFC 7009 requires even for unknown tokens.
+func (s *Server) revoke(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.db.ExecContext(r.Context(), `UPDATE indieauth_tokens SET revoked = 1 WHERE hash = ?`,
+		hashToken(r.FormValue("token"))); err != nil {
+		log.Printf("indieauth: revoke: %v", err)
+		writeError(w, http.StatusInternalServerError, "server_error", "could not revoke token")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func parseURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil || u.Fragment != "" {
+		return nil, errors.New("must be an absolute http(s) URL without credentials or fragment")
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u, nil
+}
+
+// sameURL reports whether raw, as sent by a client, is the URL want stored
+// by Begin once both are normalized by parseURL.
+func sameURL(raw, want string) bool {
+	u, err := parseURL(raw)
+	return err == nil && u.String() == want
+}
+
+// prune drops the grants expired at now.
+func prune(m map[string]grant, now time.Time) {
+	for k, g := range m {
+		if now.After(g.expires) {
+			delete(m, k)
+		}
+	}
+}
+
+func randomToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// hashToken is how tokens are stored, so a leaked database holds no usable
+// credentials.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("indieauth: encode: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, desc string) {
+	writeJSON(w, status, map[string]string{"error": code, "error_description": desc})
+}
@@ -0,0 +1,295 @@
+// Package jsonresume converts the site's data to and from the JSON Resume
+// format, https://jsonresume.org.
+package jsonresume
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/content"
+)
+
+// File is the data file read in place of the missing native ones.
+const File = "data/resume.json"
+
+// SchemaURL is the version of the schema resumes are written in.
+const SchemaURL = "https://raw.githubusercontent.com/jsonresume/resume-schema/v1.0.0/schema.json"
+
+// Resume is a resume in the JSON Resume format. Only the sections the
+// site has are read and written; the others are ignored.
+type Resume struct {
+	Schema    string      `json:"$schema"`
+	Basics    Basics      `json:"basics"`
+	Work      []Work      `json:"work,omitempty"`
+	Education []Education `json:"education,omitempty"`
+	Skills    []Skill     `json:"skills,omitempty"`
+	Projects  []Project   `json:"projects,omitempty"`
+	Interests []Interest  `json:"interests,omitempty"`
+}
+
+// Basics is who the resume is about.
+type Basics struct {
+	Name     string    `json:"name"`
+	Label    string    `json:"label,omitempty"`
+	Image    string    `json:"image,omitempty"`
+	Email    string    `json:"email,omitempty"`
+	URL      string    `json:"url,omitempty"`
+	Summary  string    `json:"summary,omitempty"`
+	Location *Location `json:"location,omitempty"`
+	Profiles []Profile `json:"profiles,omitempty"`
+}
+
+// Location is where the person is based.
+type Location struct {
+	City   string `json:"city,omitempty"`
+	Region string `json:"region,omitempty"`
+}
+
+// Profile is an account on a social network.
+type Profile struct {
+	Network  string `json:"network"`
+	Username string `json:"username,omitempty"`
+	URL      string `json:"url"`
+}
+
+// Work is a job.
+type Work struct {
+	Name       string   `json:"name"`
+	Position   string   `json:"position"`
+	URL        string   `json:"url,omitempty"`
+	Location   string   `json:"location,omitempty"`
+	StartDate  string   `json:"startDate,omitempty"`
+	EndDate    string   `json:"endDate,omitempty"`
+	Summary    string   `json:"summary,omitempty"`
+	Highlights []string `json:"highlights,omitempty"`
+}
+
+// Education is a degree or course of study.
+type Education struct {
+	Institution string   `json:"institution"`
+	URL         string   `json:"url,omitempty"`
+	Area        string   `json:"area"`
+	StudyType   string   `json:"studyType,omitempty"`
+	StartDate   string   `json:"startDate,omitempty"`
+	EndDate     string   `json:"endDate,omitempty"`
+	Courses     []string `json:"courses,omitempty"`
+}
+
+// Skill is a group of skills.
+type Skill struct {
+	Name     string   `json:"name"`
+	Keywords []string `json:"keywords"`
+}
+
+// Project is a piece of work the person shows.
+type Project struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	URL         string   `json:"url,omitempty"`
+	Keywords    []string `json:"keywords,omitempty"`
+}
+
+// Interest is a hobby or other interest.
+type Interest struct {
+	Name     string   `json:"name"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// New converts the page data to a resume. Project URLs point at their
+// pages under base.
+func New(data content.PageData, base string) Resume {
+	a := data.About
+	res := Resume{
+		Schema: SchemaURL,
+		Basics: Basics{
+			Name:    a.Name,
+			Label:   a.Tagline,
+			Email:   a.Email,
+			URL:     base + "/",
+			Summary: a.Bio,
+		},
+	}
+	if a.ProfilePhoto != "" {
+		res.Basics.Image = base + a.ProfilePhoto
+	}
+	if a.Location != "" {
+		city, region, _ := strings.Cut(a.Location, ",")
+		res.Basics.Location = &Location{City: strings.TrimSpace(city), Region: strings.TrimSpace(region)}
+	}
+	for _, p := range []struct{ network, url string }{{"GitHub", a.GitHub}, {"LinkedIn", a.LinkedIn}, {"X", a.X}} {
+		if p.url == "" {
+			continue
+		}
+		var username string
+		if u, err := url.Parse(p.url); err == nil {
+			username = strings.TrimPrefix(u.Path[strings.LastIndex(u.Path, "/")+1:], "@")
+		}
+		res.Basics.Profiles = append(res.Basics.Profiles, Profile{Network: p.network, Username: username, URL: p.url})
+	}
+	for _, e := range data.Experience {
+		start, end := dates(e)
+		switch e.Type {
+		case "work":
+			res.Work = append(res.Work, Work{
+				Name:       e.Company,
+				Position:   e.Role,
+				URL:        e.CompanyURL,
+				Location:   e.Location,
+				StartDate:  start,
+				EndDate:    end,
+				Highlights: e.Description,
+			})
+		case "education":
+			res.Education = append(res.Education, Education{
+				Institution: e.Company,
+				URL:         e.CompanyURL,
+				Area:        e.Role,
+				StartDate:   start,
+				EndDate:     end,
+				Courses:     e.Description,
+			})
+		}
+	}
+	for _, c := range data.Skills {
+		res.Skills = append(res.Skills, Skill{Name: c.Category, Keywords: c.Skills})
+	}
+	for _, p := range data.Projects {
+		res.Projects = append(res.Projects, Project{
+			Name:        p.Title,
+			Description: p.Description,
+			URL:         base + "/projects/" + p.Slug,
+			Keywords:    p.Tags,
+		})
+	}
+	for _, i := range data.Interests {
+		res.Interests = append(res.Interests, Interest{Name: i.Label})
+	}
+	return res
+}
+
+// dates returns the entry's dates as JSON Resume's ISO 8601 dates,
+// "2023-07" or "2018". An entry with a list of dates spans from the first
+// to the last, and a current one has no end date.
+func dates(e content.Experience) (start, end string) {
+	from, to := e.StartDate, e.EndDate
+	if len(e.Dates) > 0 {
+		from, to = e.Dates[0], e.Dates[len(e.Dates)-1]
+	}
+	return isoDate(from), isoDate(to)
+}
+
+func isoDate(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "present") {
+		return ""
+	}
+	t, ok := content.ParseLooseDate(s)
+	if !ok {
+		return ""
+	}
+	if len(s) == 4 {
+		return t.Format("2006")
+	}
+	return t.Format("2006-01")
+}
+
+// Data is the part of the site's data a resume holds.
+type Data struct {
+	About      content.About
+	Experience []content.Experience
+	Skills     []content.SkillCategory
+	Projects   []content.Project
+	Interests  []content.Interest
+}
+
+// Data converts the resume to the site's data. Work and education become
+// experience entries, with dates as data/experience.json has them.
+func (r Resume) Data() Data {
+	b := r.Basics
+	d := Data{About: content.About{
+		Name:         b.Name,
+		Tagline:      b.Label,
+		Bio:          b.Summary,
+		Email:        b.Email,
+		ProfilePhoto: b.Image,
+	}}
+	if l := b.Location; l != nil {
+		d.About.Location = strings.Trim(l.City+", "+l.Region, ", ")
+	}
+	for _, p := range b.Profiles {
+		switch strings.ToLower(p.Network) {
+		case "github":
+			d.About.GitHub = p.URL
+		case "linkedin":
+			d.About.LinkedIn = p.URL
+		case "x", "twitter":
+			d.About.X = p.URL
+		}
+	}
+	for _, w := range r.Work {
+		desc := w.Highlights
+		if w.Summary != "" {
+			desc = append([]string{w.Summary}, desc...)
+		}
+		start, end := looseDates(w.StartDate, w.EndDate)
+		d.Experience = append(d.Experience, content.Experience{
+			Role:        w.Position,
+			Company:     w.Name,
+			CompanyURL:  w.URL,
+			StartDate:   start,
+			EndDate:     end,
+			Location:    w.Location,
+			Description: desc,
+			Type:        "work",
+		})
+	}
+	for _, e := range r.Education {
+		start, end := looseDates(e.StartDate, e.EndDate)
+		d.Experience = append(d.Experience, content.Experience{
+			Role:        strings.Trim(e.StudyType+", "+e.Area, ", "),
+			Company:     e.Institution,
+			CompanyURL:  e.URL,
+			StartDate:   start,
+			EndDate:     end,
+			Description: e.Courses,
+			Type:        "education",
+		})
+	}
+	for _, s := range r.Skills {
+		d.Skills = append(d.Skills, content.SkillCategory{Category: s.Name, Skills: s.Keywords})
+	}
+	for _, p := range r.Projects {
+		d.Projects = append(d.Projects, content.Project{
+			Title:       p.Name,
+			Description: p.Description,
+			Link:        p.URL,
+			Tags:        p.Keywords,
+		})
+	}
+	for _, i := range r.Interests {
+		d.Interests = append(d.Interests, content.Interest{Label: i.Name, Description: strings.Join(i.Keywords, ", ")})
+	}
+	return d
+}
+
+// looseDates returns ISO 8601 start and end dates as data/experience.json
+// writes them, "Jul 2023" or "2018", with "Present" for a missing end.
+func looseDates(start, end string) (string, string) {
+	start = looseDate(start)
+	end = looseDate(end)
+	if end == "" && start != "" {
+		end = "Present"
+	}
+	return start, end
+}
+
+func looseDate(s string) string {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{"2006-01-02", "2006-01"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("Jan 2006")
+		}
+	}
+	return s
+}
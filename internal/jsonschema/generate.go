@@ -0,0 +1,110 @@
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	marshalerType = reflect.TypeFor[json.Marshaler]()
+	textType      = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// Generate returns the schema of the JSON encoding/json produces for values
+// of type t. Properties that are always encoded are required. Objects may
+// have other properties, so a schema generated before a field is added
+// still accepts the output, while one generated before a field is removed
+// or renamed does not.
+func Generate(t reflect.Type) *Schema {
+	return generate(t, make(map[reflect.Type]bool))
+}
+
+func generate(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: types{"string"}, Format: "date-time"}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		return &Schema{}
+	case t.Implements(textType) || reflect.PointerTo(t).Implements(textType):
+		return &Schema{Type: types{"string"}}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: types{"boolean"}}
+	case reflect.String:
+		return &Schema{Type: types{"string"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: types{"integer"}}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0.0
+		return &Schema{Type: types{"integer"}, Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: types{"number"}}
+	case reflect.Pointer:
+		return nullable(generate(t.Elem(), seen))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: types{"string", "null"}}
+		}
+		return &Schema{Type: types{"array", "null"}, Items: generate(t.Elem(), seen)}
+	case reflect.Array:
+		return &Schema{Type: types{"array"}, Items: generate(t.Elem(), seen)}
+	case reflect.Map:
+		return &Schema{Type: types{"object", "null"}, AdditionalProperties: generate(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return &Schema{Type: types{"object"}} // recursive
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		s := &Schema{Type: types{"object"}, Properties: make(map[string]*Schema)}
+		addFields(s, t, seen)
+		return s
+	}
+	return &Schema{}
+}
+
+// addFields adds the properties of the fields of struct type t to s,
+// following the rules of encoding/json for names, omitted fields and
+// embedded structs.
+func addFields(s *Schema, t reflect.Type, seen map[reflect.Type]bool) {
+	for f := range t.Fields() {
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addFields(s, ft, seen)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = generate(f.Type, seen)
+		if !strings.Contains(","+opts+",", ",omitempty,") && !strings.Contains(","+opts+",", ",omitzero,") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func nullable(s *Schema) *Schema {
+	if len(s.Type) > 0 && !slices.Contains(s.Type, "null") {
+		s.Type = append(s.Type, "null")
+	}
+	return s
+}
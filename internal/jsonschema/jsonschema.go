@@ -0,0 +1,357 @@
+// Package jsonschema validates JSON documents against the subset of JSON
+// Schema (draft 2020-12) the site's schemas use: type, enum, const,
+// properties, required, additionalProperties, items, the string, number
+// and array bounds, pattern, format (uri, email, date, date-time) and
+// references to $defs.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a compiled schema.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 types              `json:"type,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Const                *any               `json:"const,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	UniqueItems          bool               `json:"uniqueItems,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+
+	// never is set for the false schema, which nothing matches.
+	never   bool
+	pattern *regexp.Regexp
+	root    *Schema
+}
+
+// types is the type keyword, a name or a list of names.
+type types []string
+
+func (t *types) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		err := json.Unmarshal(b, &s)
+		*t = types{s}
+		return err
+	}
+	return json.Unmarshal(b, (*[]string)(t))
+}
+
+func (t types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON accepts the boolean schemas true and false too.
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	switch string(bytes.TrimSpace(b)) {
+	case "true":
+		return nil
+	case "false":
+		s.never = true
+		return nil
+	}
+	type plain Schema
+	return json.Unmarshal(b, (*plain)(s))
+}
+
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.never {
+		return []byte("false"), nil
+	}
+	type plain Schema
+	return json.Marshal((*plain)(s))
+}
+
+// Compile parses a schema document.
+func Compile(b []byte) (*Schema, error) {
+	s := new(Schema)
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("parse schema: %w", err)
+	}
+	if err := s.compile(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// compile resolves the patterns and references of s and its subschemas.
+func (s *Schema) compile(root *Schema) error {
+	if s == nil {
+		return nil
+	}
+	s.root = root
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("schema pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	if s.Ref != "" {
+		if _, err := s.resolve(); err != nil {
+			return err
+		}
+	}
+	for _, sub := range s.subschemas() {
+		if err := sub.compile(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) subschemas() []*Schema {
+	subs := []*Schema{s.AdditionalProperties, s.Items}
+	for _, m := range []map[string]*Schema{s.Defs, s.Properties} {
+		for _, sub := range m {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// resolve returns the schema s.Ref points to, which must be in the $defs
+// of the document.
+func (s *Schema) resolve() (*Schema, error) {
+	name, ok := strings.CutPrefix(s.Ref, "#/$defs/")
+	if !ok {
+		return nil, fmt.Errorf("schema $ref %q: only #/$defs/ references are supported", s.Ref)
+	}
+	def := s.root.Defs[name]
+	if def == nil {
+		return nil, fmt.Errorf("schema $ref %q: no such definition", s.Ref)
+	}
+	return def, nil
+}
+
+// ValidateJSON decodes the document b and validates it, returning an error
+// per problem, joined, each prefixed with the JSON Pointer of the value.
+func (s *Schema) ValidateJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	return s.Validate(v)
+}
+
+// Validate validates a value decoded by encoding/json, with json.Number or
+// float64 numbers.
+func (s *Schema) Validate(v any) error {
+	var errs []error
+	s.validate("", v, &errs)
+	return errors.Join(errs...)
+}
+
+func (s *Schema) validate(ptr string, v any, errs *[]error) {
+	if s == nil {
+		return
+	}
+	fail := func(format string, args ...any) {
+		where := ptr
+		if where == "" {
+			where = "/"
+		}
+		*errs = append(*errs, fmt.Errorf("%s: %s", where, fmt.Sprintf(format, args...)))
+	}
+	if s.never {
+		fail("not allowed")
+		return
+	}
+	if s.Ref != "" {
+		def, _ := s.resolve()
+		def.validate(ptr, v, errs)
+	}
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return is(t, v) }) {
+		fail("%s, want %s", typeOf(v), strings.Join(s.Type, " or "))
+		return
+	}
+	if s.Enum != nil && !slices.ContainsFunc(s.Enum, func(e any) bool { return equal(e, v) }) {
+		fail("%s is not one of %s", show(v), showAll(s.Enum))
+	}
+	if s.Const != nil && !equal(*s.Const, v) {
+		fail("%s, want %s", show(v), show(*s.Const))
+	}
+
+	switch v := v.(type) {
+	case string:
+		n := len([]rune(v))
+		if s.MinLength != nil && n < *s.MinLength {
+			if *s.MinLength == 1 {
+				fail("empty")
+			} else {
+				fail("%d characters, want at least %d", n, *s.MinLength)
+			}
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("%d characters, want at most %d", n, *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("%q does not match %s", v, s.Pattern)
+		}
+		if msg := checkFormat(s.Format, v); msg != "" {
+			fail("%q is not %s", v, msg)
+		}
+	case json.Number, float64:
+		f := number(v)
+		if s.Minimum != nil && f < *s.Minimum {
+			fail("%v is less than %v", f, *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			fail("%v is more than %v", f, *s.Maximum)
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("%d items, want at least %d", len(v), *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("%d items, want at most %d", len(v), *s.MaxItems)
+		}
+		for i, item := range v {
+			if s.UniqueItems && slices.ContainsFunc(v[:i], func(e any) bool { return equal(e, item) }) {
+				fail("duplicate item %s", show(item))
+			}
+			s.Items.validate(ptr+"/"+strconv.Itoa(i), item, errs)
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				fail("missing property %q", name)
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(v)) {
+			sub := s.Properties[name]
+			if sub == nil {
+				sub = s.AdditionalProperties
+			}
+			sub.validate(ptr+"/"+escape(name), v[name], errs)
+		}
+	}
+}
+
+// is reports whether v is of the JSON Schema type t.
+func is(t string, v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case string:
+		return t == "string"
+	case json.Number, float64:
+		f := number(v)
+		return t == "number" || t == "integer" && f == math.Trunc(f)
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	}
+	return false
+}
+
+func typeOf(v any) string {
+	for _, t := range []string{"null", "boolean", "string", "integer", "number", "array", "object"} {
+		if is(t, v) {
+			return t
+		}
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func number(v any) float64 {
+	if n, ok := v.(json.Number); ok {
+		f, _ := n.Float64()
+		return f
+	}
+	return v.(float64)
+}
+
+// equal compares JSON values, numbers by value.
+func equal(a, b any) bool {
+	if is("number", a) && is("number", b) {
+		return number(a) == number(b)
+	}
+	ja, err1 := json.Marshal(a)
+	jb, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && bytes.Equal(ja, jb)
+}
+
+// checkFormat returns what v fails to be in format, or "" if it is valid or
+// the format is not checked.
+func checkFormat(format, v string) string {
+	switch format {
+	case "uri":
+		if u, err := url.Parse(v); err != nil || u.Scheme == "" {
+			return "an absolute URI"
+		}
+	case "email":
+		if _, err := mail.ParseAddress(v); err != nil || strings.ContainsAny(v, "<> ") {
+			return "an email address"
+		}
+	case "date":
+		if _, err := time.Parse(time.DateOnly, v); err != nil {
+			return "a YYYY-MM-DD date"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, v); err != nil {
+			return "an RFC 3339 date and time"
+		}
+	}
+	return ""
+}
+
+func show(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if len(b) > 60 {
+		return string(b[:57]) + "..."
+	}
+	return string(b)
+}
+
+func showAll(vs []any) string {
+	s := make([]string, len(vs))
+	for i, v := range vs {
+		s[i] = show(v)
+	}
+	return strings.Join(s, ", ")
+}
+
+// escape escapes a property name for a JSON Pointer.
+func escape(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
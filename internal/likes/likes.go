@@ -0,0 +1,136 @@
+// Package likes counts the likes of projects. Counts are stored in SQLite;
+// who liked what is only kept in memory, long enough to ignore repeated
+// likes from the same visitor.
+package likes
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/db"
+)
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS project_likes (
+		slug TEXT PRIMARY KEY,
+		count INTEGER NOT NULL
+	)`,
+}
+
+// Store persists the like counts and debounces likes.
+type Store struct {
+	db     *sql.DB
+	window time.Duration
+
+	mu     sync.Mutex
+	key    []byte               // hashes voters, so addresses are not kept
+	recent map[string]time.Time // by hashed slug and voter, when they liked
+}
+
+// NewStore creates the table if needed. A visitor's likes of a project
+// after the first count again once window has passed.
+func NewStore(ctx context.Context, database *sql.DB, window time.Duration) (*Store, error) {
+	if err := db.Migrate(ctx, database, schema...); err != nil {
+		return nil, fmt.Errorf("likes: %w", err)
+	}
+	key := make([]byte, 32)
+	rand.Read(key)
+	return &Store{db: database, window: window, key: key, recent: make(map[string]time.Time)}, nil
+}
+
+// Counts returns the like count of every liked project, by slug.
+func (s *Store) Counts(ctx context.Context) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT slug, count FROM project_likes`)
+	if err != nil {
+		return nil, fmt.Errorf("query likes: %w", err)
+	}
+	defer rows.Close()
+	counts := make(map[string]int)
+	for rows.Next() {
+		var slug string
+		var n int
+		if err := rows.Scan(&slug, &n); err != nil {
+			return nil, fmt.Errorf("query likes: %w", err)
+		}
+		counts[slug] = n
+	}
+	return counts, rows.Err()
+}
+
+// Like adds a like to slug from a visitor identified by voters, such as
+// their address and session, and returns the new count. The like is not
+// counted when any of the voters liked slug within the window; counted
+// reports whether it was.
+func (s *Store) Like(ctx context.Context, slug string, voters ...string) (count int, counted bool, err error) {
+	now := time.Now()
+	s.mu.Lock()
+	for k, t := range s.recent {
+		if now.Sub(t) >= s.window {
+			delete(s.recent, k)
+		}
+	}
+	keys := s.keys(slug, voters)
+	counted = true
+	for _, k := range keys {
+		if _, ok := s.recent[k]; ok {
+			counted = false
+		}
+	}
+	if counted {
+		for _, k := range keys {
+			s.recent[k] = now
+		}
+	}
+	s.mu.Unlock()
+
+	if !counted {
+		err = s.db.QueryRowContext(ctx, `SELECT count FROM project_likes WHERE slug = ?`, slug).Scan(&count)
+		if err == sql.ErrNoRows {
+			err = nil
+		}
+	} else {
+		err = s.db.QueryRowContext(ctx, `
+			INSERT INTO project_likes (slug, count) VALUES (?, 1)
+			ON CONFLICT (slug) DO UPDATE SET count = count + 1
+			RETURNING count`, slug).Scan(&count)
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("like %s: %w", slug, err)
+	}
+	return count, counted, nil
+}
+
+// Liked reports whether any of voters liked slug within the window.
+func (s *Store) Liked(slug string, voters ...string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range s.keys(slug, voters) {
+		if t, ok := s.recent[k]; ok && time.Since(t) < s.window {
+			return true
+		}
+	}
+	return false
+}
+
+// keys returns the keys of recent for slug and each non-empty voter. The
+// caller holds s.mu.
+func (s *Store) keys(slug string, voters []string) []string {
+	var keys []string
+	for _, v := range voters {
+		if v == "" {
+			continue
+		}
+		m := hmac.New(sha256.New, s.key)
+		m.Write([]byte(slug))
+		m.Write([]byte{0})
+		m.Write([]byte(v))
+		keys = append(keys, string(m.Sum(nil)[:16]))
+	}
+	return keys
+}
@@ -0,0 +1,166 @@
+// Package livereload reloads open pages when the site's source files
+// change, for editing content against a local server.
+package livereload
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Topic is the SSE topic that announces changes. Its events carry a
+// version number that grows with each change.
+const Topic = "livereload"
+
+// Script reloads the page when the version on Topic changes. The broker
+// replays the latest version to new streams, so a page only reloads on
+// versions it has not seen.
+const Script = `<script>
+(function () {
+  var seen;
+  new EventSource("/events?topic=` + Topic + `").addEventListener("` + Topic + `", function (e) {
+    if (seen !== undefined && e.data !== seen) location.reload();
+    seen = e.data;
+  });
+})();
+</script>
+`
+
+// Watch polls the files below dirs every interval and calls onChange with
+// the paths that changed, were added or were removed. It returns when ctx
+// is done.
+func Watch(ctx context.Context, dirs []string, interval time.Duration, onChange func(changed []string)) {
+	last := snapshot(dirs)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		next := snapshot(dirs)
+		var changed []string
+		for p, mod := range next {
+			if old, ok := last[p]; !ok || !old.Equal(mod) {
+				changed = append(changed, p)
+			}
+		}
+		for p := range last {
+			if _, ok := next[p]; !ok {
+				changed = append(changed, p)
+			}
+		}
+		last = next
+		if len(changed) > 0 {
+			onChange(changed)
+		}
+	}
+}
+
+// snapshot maps each regular file below dirs to its modification time.
+// Directories that are missing or unreadable are skipped.
+func snapshot(dirs []string) map[string]time.Time {
+	files := make(map[string]time.Time)
+	for _, dir := range dirs {
+		filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				files[p] = info.ModTime()
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+// Versioner publishes change versions on Topic.
+type Versioner struct {
+	publish func(topic, data string)
+	version int
+}
+
+// NewVersioner returns a Versioner that publishes through publish, usually
+// an sse.Broker's Publish method, and announces the first version.
+func NewVersioner(publish func(topic, data string)) *Versioner {
+	v := &Versioner{publish: publish}
+	v.Bump()
+	return v
+}
+
+// Bump announces a new version, reloading open pages. It is not safe for
+// concurrent use.
+func (v *Versioner) Bump() {
+	v.version++
+	v.publish(Topic, strconv.Itoa(v.version))
+}
+
+// Inject adds Script to the HTML documents served by next, before their
+// closing body tag. Other responses pass through unchanged.
+func Inject(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		iw := &injectWriter{ResponseWriter: w}
+		next.ServeHTTP(iw, r)
+		iw.finish()
+	})
+}
+
+type injectWriter struct {
+	http.ResponseWriter
+	decided bool
+	html    bool
+	status  int
+	buf     bytes.Buffer
+}
+
+func (w *injectWriter) WriteHeader(code int) {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if mediaType == "text/html" && w.Header().Get("Content-Encoding") == "" {
+		w.html, w.status = true, code
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *injectWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.html {
+		return w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *injectWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish writes a buffered HTML response with the script added.
+func (w *injectWriter) finish() {
+	if !w.html {
+		return
+	}
+	body := w.buf.Bytes()
+	if i := bytes.LastIndex(body, []byte("</body>")); i >= 0 {
+		body = fmt.Appendf(nil, "%s%s%s", body[:i], Script, body[i:])
+	}
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(body)
+}
@@ -0,0 +1,150 @@
+// Package mailer sends email through an SMTP account and renders the email
+// templates under templates/email.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io/fs"
+	texttemplate "text/template"
+	"time"
+
+	gomail "gopkg.in/mail.v2"
+
+	"github.com/fpatron/portfolio/internal/worker"
+)
+
+// Message is an email to send. Text is the plain-text body; HTML, when set,
+// is attached as the preferred alternative.
+type Message struct {
+	To      []string
+	ReplyTo string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer sends messages from a single account.
+type Mailer struct {
+	from   string
+	dialer *gomail.Dialer
+}
+
+// Config describes an SMTP account.
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// From is the sending address; it defaults to Username.
+	From string
+	// StartTLS requires the connection to be upgraded with STARTTLS.
+	// Without it, port 465 uses implicit TLS and other ports upgrade only
+	// when the server offers it.
+	StartTLS bool
+}
+
+// New returns a Mailer sending through the SMTP server described by cfg.
+func New(cfg Config) *Mailer {
+	d := gomail.NewDialer(cfg.Host, cfg.Port, cfg.Username, cfg.Password)
+	if cfg.StartTLS {
+		d.SSL = false
+		d.StartTLSPolicy = gomail.MandatoryStartTLS
+	}
+	from := cfg.From
+	if from == "" {
+		from = cfg.Username
+	}
+	return &Mailer{from: from, dialer: d}
+}
+
+// Gmail returns a Mailer sending as user through Gmail with an app password.
+func Gmail(user, appPassword string) *Mailer {
+	return New(Config{Host: "smtp.gmail.com", Port: 465, Username: user, Password: appPassword})
+}
+
+// From returns the sending address, which is also where mail meant for the
+// site owner is delivered.
+func (m *Mailer) From() string {
+	return m.from
+}
+
+// Send delivers msg.
+func (m *Mailer) Send(msg Message) error {
+	g := gomail.NewMessage()
+	g.SetHeader("From", m.from)
+	g.SetHeader("To", msg.To...)
+	if msg.ReplyTo != "" {
+		g.SetHeader("Reply-To", msg.ReplyTo)
+	}
+	g.SetHeader("Subject", msg.Subject)
+	g.SetBody("text/plain", msg.Text)
+	if msg.HTML != "" {
+		g.AddAlternative("text/html", msg.HTML)
+	}
+	if err := m.dialer.DialAndSend(g); err != nil {
+		return fmt.Errorf("send mail %q: %w", msg.Subject, err)
+	}
+	return nil
+}
+
+// Queue sends messages in the background on a worker pool, so callers
+// such as HTTP handlers don't wait on the SMTP server. Failed deliveries
+// are retried with exponential backoff.
+type Queue struct {
+	Mailer *Mailer
+	Pool   *worker.Pool
+	// Attempts is the number of tries per message, 4 by default.
+	Attempts int
+	// Backoff is the wait before the first retry, 30 seconds by default;
+	// it doubles after each failure.
+	Backoff time.Duration
+}
+
+// Send queues msg for delivery. It fails only when the pool can't take
+// the task; delivery errors are reported by the pool once retries run out.
+func (q *Queue) Send(msg Message) error {
+	attempts := q.Attempts
+	if attempts <= 0 {
+		attempts = 4
+	}
+	backoff := q.Backoff
+	if backoff <= 0 {
+		backoff = 30 * time.Second
+	}
+	err := q.Pool.Go("send mail", func(ctx context.Context) error {
+		return worker.Retry(ctx, attempts, backoff, func(context.Context) error {
+			return q.Mailer.Send(msg)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("queue mail %q: %w", msg.Subject, err)
+	}
+	return nil
+}
+
+// From returns the sending address of the queue's Mailer.
+func (q *Queue) From() string {
+	return q.Mailer.From()
+}
+
+// ParseTemplates parses the email templates in fsys's templates/email
+// directory. Each file is addressed by its base name.
+func ParseTemplates(fsys fs.FS) (*template.Template, error) {
+	t, err := template.ParseFS(fsys, "templates/email/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("parse email templates: %w", err)
+	}
+	return t, nil
+}
+
+// ParseTextTemplates parses the plain-text email templates in fsys's
+// templates/email directory, the .txt files, addressed by base name.
+func ParseTextTemplates(fsys fs.FS) (*texttemplate.Template, error) {
+	t, err := texttemplate.ParseFS(fsys, "templates/email/*.txt")
+	if err != nil {
+		return nil, fmt.Errorf("parse email templates: %w", err)
+	}
+	return t, nil
+}
@@ -0,0 +1,190 @@
+// Package mastodon fetches an account's latest public posts through its
+// instance's REST API.
+package mastodon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Post is a public status, reduced to plain text.
+type Post struct {
+	URL        string    `json:"url"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+	Replies    int       `json:"replies"`
+	Boosts     int       `json:"boosts"`
+	Favourites int       `json:"favourites"`
+	Media      []Media   `json:"media,omitempty"`
+}
+
+// Media is an image attached to a post.
+type Media struct {
+	PreviewURL  string `json:"preview_url"`
+	Description string `json:"description"`
+}
+
+// Paragraphs splits the post text on blank lines, for rendering.
+func (p Post) Paragraphs() []string {
+	return strings.Split(p.Text, "\n\n")
+}
+
+// Client reads one account, given as "user@instance.social".
+type Client struct {
+	User     string
+	Instance string // origin, e.g. "https://instance.social"
+	HTTP     *http.Client
+
+	mu sync.Mutex
+	id string
+}
+
+// NewClient parses account ("user@instance" or "@user@instance"). The
+// instance may include a scheme; it defaults to https.
+func NewClient(account string) (*Client, error) {
+	user, host, ok := strings.Cut(strings.TrimPrefix(account, "@"), "@")
+	if !ok || user == "" || host == "" {
+		return nil, fmt.Errorf("mastodon: invalid account %q, want user@instance", account)
+	}
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	return &Client{
+		User:     user,
+		Instance: strings.TrimSuffix(host, "/"),
+		HTTP:     &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+// Profile returns the URL of the account's profile page.
+func (c *Client) Profile() string {
+	return c.Instance + "/@" + c.User
+}
+
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Instance+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// accountID looks up and remembers the account's ID on its instance.
+func (c *Client) accountID(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.id != "" {
+		return c.id, nil
+	}
+	var acct struct{ ID string }
+	if err := c.get(ctx, "/api/v1/accounts/lookup?acct="+url.QueryEscape(c.User), &acct); err != nil {
+		return "", err
+	}
+	c.id = acct.ID
+	return c.id, nil
+}
+
+type status struct {
+	URL              string    `json:"url"`
+	Content          string    `json:"content"`
+	CreatedAt        time.Time `json:"created_at"`
+	Visibility       string    `json:"visibility"`
+	RepliesCount     int       `json:"replies_count"`
+	ReblogsCount     int       `json:"reblogs_count"`
+	FavouritesCount  int       `json:"favourites_count"`
+	Sensitive        bool      `json:"sensitive"`
+	MediaAttachments []struct {
+		Type        string `json:"type"`
+		PreviewURL  string `json:"preview_url"`
+		Description string `json:"description"`
+	} `json:"media_attachments"`
+}
+
+// Posts returns up to limit of the account's latest public posts, leaving
+// out replies and boosts.
+func (c *Client) Posts(ctx context.Context, limit int) ([]Post, error) {
+	id, err := c.accountID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("mastodon: lookup %s: %w", c.User, err)
+	}
+	var statuses []status
+	path := fmt.Sprintf("/api/v1/accounts/%s/statuses?exclude_replies=true&exclude_reblogs=true&limit=%d", url.PathEscape(id), limit)
+	if err := c.get(ctx, path, &statuses); err != nil {
+		return nil, fmt.Errorf("mastodon: statuses: %w", err)
+	}
+	posts := []Post{}
+	for _, s := range statuses {
+		if s.Visibility != "public" {
+			continue
+		}
+		p := Post{
+			URL:        s.URL,
+			Text:       plainText(s.Content),
+			CreatedAt:  s.CreatedAt,
+			Replies:    s.RepliesCount,
+			Boosts:     s.ReblogsCount,
+			Favourites: s.FavouritesCount,
+		}
+		if !s.Sensitive {
+			for _, m := range s.MediaAttachments {
+				if m.Type == "image" {
+					p.Media = append(p.Media, Media{PreviewURL: m.PreviewURL, Description: m.Description})
+				}
+			}
+		}
+		posts = append(posts, p)
+	}
+	return posts, nil
+}
+
+// plainText converts a status's HTML content to text, keeping paragraph and
+// line breaks. Templates escape the result, so nothing from the instance is
+// rendered as markup.
+func plainText(content string) string {
+	var sb strings.Builder
+	var skip bool
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return strings.TrimSpace(sb.String())
+		case html.TextToken:
+			if !skip {
+				sb.Write(z.Text())
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "script" || string(name) == "style" {
+				skip = false
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "script", "style":
+				skip = true
+			case "br":
+				sb.WriteString("\n")
+			case "p":
+				if sb.Len() > 0 {
+					sb.WriteString("\n\n")
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Package metrics defines the Prometheus metrics exported at /metrics.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every metric of the process.
+var Registry = prometheus.NewRegistry()
+
+// Contact funnel steps, in order.
+const (
+	StepViewed    = "viewed"
+	StepSubmitted = "submitted"
+	StepValidated = "validated"
+	StepDelivered = "delivered"
+)
+
+// FunnelSteps lists the contact funnel steps in order.
+var FunnelSteps = []string{StepViewed, StepSubmitted, StepValidated, StepDelivered}
+
+// ContactFunnel counts visitors reaching each step of the contact flow.
+var ContactFunnel = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "portfolio_contact_funnel_total",
+	Help: "Contact form events by funnel step (viewed, submitted, validated, delivered).",
+}, []string{"step"})
+
+// TenantRequests counts requests by tenant and status code when the
+// server hosts several sites. The primary site is the "default" tenant.
+var TenantRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "portfolio_tenant_requests_total",
+	Help: "HTTP requests by tenant and status code.",
+}, []string{"tenant", "code"})
+
+// Worker pool task results.
+const (
+	TaskOK       = "ok"
+	TaskError    = "error"
+	TaskPanic    = "panic"
+	TaskDropped  = "dropped"
+	TaskCanceled = "canceled"
+)
+
+// WorkerTasks counts background tasks by name and result.
+var WorkerTasks = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "portfolio_worker_tasks_total",
+	Help: "Background tasks by task and result (ok, error, panic, dropped, canceled).",
+}, []string{"task", "result"})
+
+// WorkerTaskDuration observes how long background tasks run.
+var WorkerTaskDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "portfolio_worker_task_duration_seconds",
+	Help:    "Run time of background tasks by task.",
+	Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+}, []string{"task"})
+
+// WorkerQueued and WorkerBusy are the background tasks waiting for a
+// worker and those running.
+var (
+	WorkerQueued = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "portfolio_worker_queued",
+		Help: "Background tasks waiting for a worker.",
+	})
+	WorkerBusy = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "portfolio_worker_busy",
+		Help: "Workers running a background task.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		ContactFunnel,
+		TenantRequests,
+		WorkerTasks,
+		WorkerTaskDuration,
+		WorkerQueued,
+		WorkerBusy,
+	)
+	// Export zeroes for every step so rate() works before the first event.
+	for _, s := range FunnelSteps {
+		ContactFunnel.WithLabelValues(s)
+	}
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
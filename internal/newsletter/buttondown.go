@@ -0,0 +1,45 @@
+package newsletter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Buttondown subscribes addresses through the Buttondown API.
+type Buttondown struct {
+	key     string
+	baseURL string
+}
+
+// NewButtondown returns a Buttondown provider using an API key.
+func NewButtondown(key string) *Buttondown {
+	return &Buttondown{key: key, baseURL: "https://api.buttondown.com"}
+}
+
+func (b *Buttondown) auth(req *http.Request) {
+	req.Header.Set("Authorization", "Token "+b.key)
+}
+
+// Subscribe creates an unactivated subscriber, which Buttondown sends a
+// confirmation email to.
+func (b *Buttondown) Subscribe(ctx context.Context, address string) error {
+	err := call(ctx, http.MethodPost, b.baseURL+"/v1/subscribers", b.auth, map[string]string{
+		"email_address": address,
+		"type":          "unactivated",
+	}, nil)
+	if errors.Is(err, ErrRejected) && strings.Contains(err.Error(), "email_already_exists") {
+		return nil
+	}
+	return err
+}
+
+// Subscribers returns the number of regular, confirmed subscribers.
+func (b *Buttondown) Subscribers(ctx context.Context) (int, error) {
+	var page struct {
+		Count int `json:"count"`
+	}
+	err := call(ctx, http.MethodGet, b.baseURL+"/v1/subscribers?type=regular", b.auth, nil, &page)
+	return page.Count, err
+}
@@ -0,0 +1,56 @@
+package newsletter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Listmonk subscribes addresses to a list on a self-hosted Listmonk
+// instance.
+type Listmonk struct {
+	baseURL string
+	user    string
+	token   string
+	list    int
+}
+
+// NewListmonk returns a Listmonk provider for the list with the given ID,
+// authenticating as an API user. The list should be double opt-in.
+func NewListmonk(baseURL, user, token, list string) (*Listmonk, error) {
+	id, err := strconv.Atoi(list)
+	if err != nil {
+		return nil, fmt.Errorf("listmonk: list %q is not a numeric ID", list)
+	}
+	return &Listmonk{baseURL: strings.TrimSuffix(baseURL, "/"), user: user, token: token, list: id}, nil
+}
+
+func (l *Listmonk) auth(req *http.Request) {
+	req.SetBasicAuth(l.user, l.token)
+}
+
+// Subscribe creates a subscriber on the list without preconfirming it, so
+// Listmonk sends the opt-in email. A 409 means the address already exists.
+func (l *Listmonk) Subscribe(ctx context.Context, address string) error {
+	name, _, _ := strings.Cut(address, "@")
+	return call(ctx, http.MethodPost, l.baseURL+"/api/subscribers", l.auth, map[string]any{
+		"email":                    address,
+		"name":                     name,
+		"status":                   "enabled",
+		"lists":                    []int{l.list},
+		"preconfirm_subscriptions": false,
+	}, nil, http.StatusConflict)
+}
+
+// Subscribers returns the number of confirmed subscriptions to the list.
+func (l *Listmonk) Subscribers(ctx context.Context) (int, error) {
+	var resp struct {
+		Data struct {
+			Statuses map[string]int `json:"subscriber_statuses"`
+		} `json:"data"`
+	}
+	err := call(ctx, http.MethodGet, fmt.Sprintf("%s/api/lists/%d", l.baseURL, l.list), l.auth, nil, &resp)
+	return resp.Data.Statuses["confirmed"], err
+}
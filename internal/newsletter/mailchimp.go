@@ -0,0 +1,55 @@
+package newsletter
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Mailchimp subscribes addresses to a Mailchimp audience.
+type Mailchimp struct {
+	key     string
+	list    string
+	baseURL string
+}
+
+// NewMailchimp returns a Mailchimp provider for the audience list. The data
+// center is taken from the suffix of the API key, as in "...-us21".
+func NewMailchimp(key, list string) (*Mailchimp, error) {
+	_, dc, ok := strings.Cut(key, "-")
+	if !ok || dc == "" {
+		return nil, fmt.Errorf("mailchimp: API key has no data center suffix")
+	}
+	return &Mailchimp{key: key, list: list, baseURL: "https://" + dc + ".api.mailchimp.com"}, nil
+}
+
+func (m *Mailchimp) auth(req *http.Request) {
+	req.SetBasicAuth("portfolio", m.key)
+}
+
+// Subscribe adds address as a pending member, which makes Mailchimp send
+// the opt-in confirmation. Existing members are left unchanged.
+func (m *Mailchimp) Subscribe(ctx context.Context, address string) error {
+	sum := md5.Sum([]byte(strings.ToLower(address)))
+	u := fmt.Sprintf("%s/3.0/lists/%s/members/%s", m.baseURL, url.PathEscape(m.list), hex.EncodeToString(sum[:]))
+	return call(ctx, http.MethodPut, u, m.auth, map[string]string{
+		"email_address": address,
+		"status_if_new": "pending",
+	}, nil)
+}
+
+// Subscribers returns the audience's subscribed member count.
+func (m *Mailchimp) Subscribers(ctx context.Context) (int, error) {
+	var list struct {
+		Stats struct {
+			MemberCount int `json:"member_count"`
+		} `json:"stats"`
+	}
+	u := fmt.Sprintf("%s/3.0/lists/%s?fields=stats.member_count", m.baseURL, url.PathEscape(m.list))
+	err := call(ctx, http.MethodGet, u, m.auth, nil, &list)
+	return list.Stats.MemberCount, err
+}
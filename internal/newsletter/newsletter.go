@@ -0,0 +1,74 @@
+// Package newsletter subscribes visitors to the site's newsletter through
+// Buttondown, Mailchimp or Listmonk. Every provider uses double opt-in: the
+// provider emails a confirmation link before the address is subscribed.
+package newsletter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrRejected is returned when the provider refuses an address, for example
+// because it is malformed or previously unsubscribed.
+var ErrRejected = errors.New("newsletter: address rejected")
+
+// Provider is a newsletter service.
+type Provider interface {
+	// Subscribe asks the provider to send a confirmation email to
+	// address. Addresses that are already subscribed are not an error.
+	Subscribe(ctx context.Context, address string) error
+	// Subscribers returns the number of confirmed subscribers.
+	Subscribers(ctx context.Context) (int, error)
+}
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// call sends a JSON request and decodes a successful response into out when
+// it is non-nil. Statuses listed in ok are treated as success without a body.
+func call(ctx context.Context, method, url string, auth func(*http.Request), in, out any, ok ...int) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	auth(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	for _, code := range ok {
+		if resp.StatusCode == code {
+			return nil
+		}
+	}
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		if out == nil {
+			return nil
+		}
+		return json.NewDecoder(resp.Body).Decode(out)
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnprocessableEntity:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%w: %s", ErrRejected, bytes.TrimSpace(msg))
+	default:
+		return fmt.Errorf("%s %s: %s", method, req.URL.Path, resp.Status)
+	}
+}
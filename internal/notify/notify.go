@@ -0,0 +1,109 @@
+// Package notify sends short messages to the site owner: contact form
+// submissions and alerts about failing background work.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/worker"
+)
+
+// Notifier delivers a plain-text message to the site owner.
+type Notifier interface {
+	Notify(ctx context.Context, text string) error
+}
+
+// Queue is a Notifier that sends in the background on a worker pool, so
+// callers don't wait on the remote service. Failed sends are retried with
+// exponential backoff.
+type Queue struct {
+	Notifier Notifier
+	Pool     *worker.Pool
+	// Name labels the pool task, such as "telegram".
+	Name string
+	// Attempts is the number of tries per message, 4 by default.
+	Attempts int
+	// Backoff is the wait before the first retry, 30 seconds by default;
+	// it doubles after each failure.
+	Backoff time.Duration
+}
+
+// Notify queues text for delivery. It fails only when the pool can't take
+// the task; delivery errors are logged by the pool once retries run out.
+func (q *Queue) Notify(_ context.Context, text string) error {
+	attempts := q.Attempts
+	if attempts <= 0 {
+		attempts = 4
+	}
+	backoff := q.Backoff
+	if backoff <= 0 {
+		backoff = 30 * time.Second
+	}
+	err := q.Pool.Go("notify "+q.Name, func(ctx context.Context) error {
+		return worker.Retry(ctx, attempts, backoff, func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			defer cancel()
+			return q.Notifier.Notify(ctx, text)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("notify: %s: %w", q.Name, err)
+	}
+	return nil
+}
+
+// ParseTemplates parses the message templates in fsys's templates/notify
+// directory. Each file is addressed by its base name.
+func ParseTemplates(fsys fs.FS) (*template.Template, error) {
+	t, err := template.ParseFS(fsys, "templates/notify/*.txt")
+	if err != nil {
+		return nil, fmt.Errorf("parse notification templates: %w", err)
+	}
+	return t, nil
+}
+
+// Alerts sends error alerts through a Notifier, at most once per cooldown
+// for the same key so a job failing on every run doesn't flood the chat.
+type Alerts struct {
+	n        Notifier
+	cooldown time.Duration
+	pool     *worker.Pool
+
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// NewAlerts returns Alerts delivered through n on pool's workers.
+func NewAlerts(n Notifier, cooldown time.Duration, pool *worker.Pool) *Alerts {
+	return &Alerts{n: n, cooldown: cooldown, pool: pool, last: make(map[string]time.Time)}
+}
+
+// Alert sends text in the background unless an alert with the same key
+// was sent within the cooldown.
+func (a *Alerts) Alert(key, text string) {
+	a.mu.Lock()
+	if t, ok := a.last[key]; ok && time.Since(t) < a.cooldown {
+		a.mu.Unlock()
+		return
+	}
+	a.last[key] = time.Now()
+	a.mu.Unlock()
+
+	err := a.pool.Go("alert", func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		if err := a.n.Notify(ctx, text); err != nil {
+			return fmt.Errorf("notify: alert %q: %w", key, err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("notify: alert %q: %v", key, err)
+	}
+}
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// maxMessage is the longest text Telegram accepts in one message.
+const maxMessage = 4096
+
+// Telegram sends messages to a chat through a Telegram bot.
+type Telegram struct {
+	token  string
+	chatID string
+	apiURL string
+	client *http.Client
+}
+
+// NewTelegram returns a Notifier posting as the bot with the given token to
+// chatID, which the bot must be a member of (or which has started a chat
+// with it).
+func NewTelegram(token, chatID string) *Telegram {
+	return &Telegram{
+		token:  token,
+		chatID: chatID,
+		apiURL: "https://api.telegram.org",
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify sends text as a plain message, truncated to Telegram's limit.
+func (t *Telegram) Notify(ctx context.Context, text string) error {
+	if r := []rune(text); len(r) > maxMessage {
+		text = string(r[:maxMessage-1]) + "…"
+	}
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  t.chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.apiURL+"/bot"+t.token+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// The URL holds the bot token; keep it out of logs.
+		if uerr, ok := errors.AsType[*url.Error](err); ok {
+			err = uerr.Err
+		}
+		return fmt.Errorf("telegram: send message: %w", err)
+	}
+	defer resp.Body.Close()
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("telegram: send message: %s", resp.Status)
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram: send message: %s", result.Description)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// maxDiscordMessage is the longest content Discord accepts in one message.
+const maxDiscordMessage = 2000
+
+// Webhook posts messages to an incoming webhook: Slack's and Discord's are
+// recognized by their URL, and any other receives the payload template's
+// output, or {"text": ...} without one.
+type Webhook struct {
+	url     string
+	payload *template.Template
+	client  *http.Client
+}
+
+// NewWebhook returns a Notifier posting to rawURL. payload, when not
+// empty, is a text/template for the JSON body, executed with the message
+// as .Text; its json function encodes a value as JSON, quotes included.
+func NewWebhook(rawURL, payload string) (*Webhook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("webhook: invalid URL %q", rawURL)
+	}
+	w := &Webhook{url: rawURL, client: &http.Client{Timeout: 10 * time.Second}}
+	if payload != "" {
+		w.payload, err = template.New("payload").Funcs(template.FuncMap{"json": jsonString}).Parse(payload)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: parse payload: %w", err)
+		}
+	}
+	return w, nil
+}
+
+func jsonString(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// body returns the JSON to post for text.
+func (w *Webhook) body(text string) ([]byte, error) {
+	if w.payload != nil {
+		var b bytes.Buffer
+		if err := w.payload.Execute(&b, struct{ Text string }{text}); err != nil {
+			return nil, fmt.Errorf("render payload: %w", err)
+		}
+		return b.Bytes(), nil
+	}
+	u, _ := url.Parse(w.url)
+	switch {
+	case u.Host == "hooks.slack.com":
+		return json.Marshal(map[string]any{"text": text})
+	case (u.Host == "discord.com" || u.Host == "discordapp.com") && strings.HasPrefix(u.Path, "/api/webhooks/"):
+		if r := []rune(text); len(r) > maxDiscordMessage {
+			text = string(r[:maxDiscordMessage-1]) + "…"
+		}
+		return json.Marshal(map[string]any{
+			"content":          text,
+			"allowed_mentions": map[string]any{"parse": []string{}},
+		})
+	default:
+		return json.Marshal(map[string]any{"text": text})
+	}
+}
+
+// Notify posts text to the webhook.
+func (w *Webhook) Notify(ctx context.Context, text string) error {
+	body, err := w.body(text)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		// Webhook URLs embed their secret; keep them out of logs.
+		if uerr, ok := errors.AsType[*url.Error](err); ok {
+			err = uerr.Err
+		}
+		return fmt.Errorf("webhook: post: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("webhook: post: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
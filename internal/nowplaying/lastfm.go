@@ -0,0 +1,93 @@
+package nowplaying
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// LastFMAPI is the Last.fm API root.
+const LastFMAPI = "https://ws.audioscrobbler.com/2.0/"
+
+// LastFM reads a user's scrobbles. When nothing is playing, it returns the
+// last scrobbled track.
+type LastFM struct {
+	User   string
+	APIKey string
+	API    string
+	HTTP   *http.Client
+}
+
+// NewLastFM returns a Last.fm provider for user.
+func NewLastFM(user, apiKey string) *LastFM {
+	return &LastFM{User: user, APIKey: apiKey, API: LastFMAPI, HTTP: &http.Client{Timeout: 10 * time.Second}}
+}
+
+type lastfmTrack struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Artist struct {
+		Text string `json:"#text"`
+	} `json:"artist"`
+	Album struct {
+		Text string `json:"#text"`
+	} `json:"album"`
+	Image []struct {
+		Size string `json:"size"`
+		Text string `json:"#text"`
+	} `json:"image"`
+	Attr struct {
+		NowPlaying string `json:"nowplaying"`
+	} `json:"@attr"`
+}
+
+func (l *LastFM) NowPlaying(ctx context.Context) (*Track, error) {
+	q := url.Values{
+		"method":  {"user.getrecenttracks"},
+		"user":    {l.User},
+		"api_key": {l.APIKey},
+		"format":  {"json"},
+		"limit":   {"1"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.API+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		RecentTracks struct {
+			Track json.RawMessage `json:"track"`
+		} `json:"recenttracks"`
+	}
+	if _, err := do(l.HTTP, req, &body); err != nil {
+		return nil, err
+	}
+	// A single track is sent as an object rather than a list.
+	var tracks []lastfmTrack
+	if err := json.Unmarshal(body.RecentTracks.Track, &tracks); err != nil {
+		var t lastfmTrack
+		if json.Unmarshal(body.RecentTracks.Track, &t) == nil {
+			tracks = []lastfmTrack{t}
+		}
+	}
+	if len(tracks) == 0 {
+		return nil, nil
+	}
+	t := tracks[0]
+	track := &Track{
+		Title:   t.Name,
+		Artist:  t.Artist.Text,
+		Album:   t.Album.Text,
+		URL:     t.URL,
+		Playing: t.Attr.NowPlaying == "true",
+		Source:  "lastfm",
+	}
+	// Images are listed from small to extralarge; medium fits the widget.
+	for _, img := range t.Image {
+		if img.Text != "" && (track.Image == "" || img.Size == "medium") {
+			track.Image = img.Text
+		}
+	}
+	return track, nil
+}
@@ -0,0 +1,46 @@
+// Package nowplaying fetches the track the site owner is listening to from
+// Spotify or Last.fm.
+package nowplaying
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Track is a track being played, or the last one played.
+type Track struct {
+	Title  string `json:"title"`
+	Artist string `json:"artist"`
+	Album  string `json:"album,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Image  string `json:"image,omitempty"`
+	// Playing is false for a recently played track.
+	Playing bool   `json:"playing"`
+	Source  string `json:"source"` // "spotify" or "lastfm"
+}
+
+// Provider reports what is playing. A nil track means nothing is.
+type Provider interface {
+	NowPlaying(ctx context.Context) (*Track, error)
+}
+
+// do sends req and decodes a 200 JSON response into v. It reports false
+// for 204 No Content.
+func do(client *http.Client, req *http.Request, v any) (bool, error) {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, json.NewDecoder(resp.Body).Decode(v)
+	case http.StatusNoContent:
+		return false, nil
+	default:
+		return false, fmt.Errorf("%s %s: %s", req.Method, req.URL.Path, resp.Status)
+	}
+}
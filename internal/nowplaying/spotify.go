@@ -0,0 +1,131 @@
+package nowplaying
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SpotifyAPI      = "https://api.spotify.com/v1"
+	SpotifyAccounts = "https://accounts.spotify.com"
+)
+
+// Spotify reads the owner's player through the Web API. It needs an app's
+// client credentials and a refresh token granted the
+// user-read-currently-playing scope.
+type Spotify struct {
+	ClientID     string
+	ClientSecret string
+	RefreshToken string
+	API          string
+	Accounts     string
+	HTTP         *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewSpotify returns a Spotify provider.
+func NewSpotify(clientID, clientSecret, refreshToken string) *Spotify {
+	return &Spotify{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RefreshToken: refreshToken,
+		API:          SpotifyAPI,
+		Accounts:     SpotifyAccounts,
+		HTTP:         &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// accessToken returns a valid access token, refreshing it shortly before
+// it expires.
+func (s *Spotify) accessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Now().Before(s.expires) {
+		return s.token, nil
+	}
+	form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {s.RefreshToken}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Accounts+"/api/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.ClientID, s.ClientSecret)
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if _, err := do(s.HTTP, req, &body); err != nil {
+		return "", fmt.Errorf("refresh spotify token: %w", err)
+	}
+	s.token = body.AccessToken
+	s.expires = time.Now().Add(time.Duration(body.ExpiresIn)*time.Second - time.Minute)
+	return s.token, nil
+}
+
+func (s *Spotify) NowPlaying(ctx context.Context) (*Track, error) {
+	token, err := s.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.API+"/me/player/currently-playing", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var body struct {
+		IsPlaying bool `json:"is_playing"`
+		Item      *struct {
+			Name    string `json:"name"`
+			Artists []struct {
+				Name string `json:"name"`
+			} `json:"artists"`
+			Album struct {
+				Name   string `json:"name"`
+				Images []struct {
+					URL   string `json:"url"`
+					Width int    `json:"width"`
+				} `json:"images"`
+			} `json:"album"`
+			ExternalURLs struct {
+				Spotify string `json:"spotify"`
+			} `json:"external_urls"`
+		} `json:"item"`
+	}
+	ok, err := do(s.HTTP, req, &body)
+	if err != nil {
+		return nil, err
+	}
+	// Nothing is playing, or it is an episode or ad, which have no item.
+	if !ok || !body.IsPlaying || body.Item == nil {
+		return nil, nil
+	}
+	it := body.Item
+	artists := make([]string, len(it.Artists))
+	for i, a := range it.Artists {
+		artists[i] = a.Name
+	}
+	track := &Track{
+		Title:   it.Name,
+		Artist:  strings.Join(artists, ", "),
+		Album:   it.Album.Name,
+		URL:     it.ExternalURLs.Spotify,
+		Playing: true,
+		Source:  "spotify",
+	}
+	// Images are listed largest first; take the smallest that is still
+	// at least 64px wide.
+	for _, img := range it.Album.Images {
+		if track.Image == "" || img.Width >= 64 {
+			track.Image = img.URL
+		}
+	}
+	return track, nil
+}
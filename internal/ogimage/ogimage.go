@@ -0,0 +1,172 @@
+// Package ogimage draws the social cards that link previews show for the
+// site's pages, with the Go fonts built into the binary.
+package ogimage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// The size Open Graph and Twitter recommend for large cards.
+const (
+	Width  = 1200
+	Height = 630
+)
+
+const margin = 80
+
+// Colors of the site's light theme.
+var (
+	background = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	text       = color.RGBA{0x1A, 0x1A, 0x2E, 0xFF}
+	muted      = color.RGBA{0x6B, 0x70, 0x84, 0xFF}
+	accent     = color.RGBA{0x25, 0x63, 0xEB, 0xFF}
+)
+
+// Card is the text of a social card. Title is the page's, such as a
+// project's; the home page's card has none and shows Name large instead.
+type Card struct {
+	Title    string
+	Subtitle string
+	Name     string
+	Tagline  string
+	// Site is the host shown at the bottom, e.g. "francispatron.com".
+	Site string
+}
+
+// Hash identifies the card's content, for caching its image.
+func (c Card) Hash() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{c.Title, c.Subtitle, c.Name, c.Tagline, c.Site}, "\x00")))
+	return hex.EncodeToString(sum[:12])
+}
+
+// drawing serializes Render, as font faces are not safe for concurrent use.
+var drawing sync.Mutex
+
+type faces struct {
+	title, heading, body font.Face
+}
+
+var loadFaces = sync.OnceValues(func() (faces, error) {
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return faces{}, err
+	}
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return faces{}, err
+	}
+	var f faces
+	for _, face := range []struct {
+		dst  *font.Face
+		font *opentype.Font
+		size float64
+	}{
+		{&f.title, bold, 64},
+		{&f.heading, bold, 36},
+		{&f.body, regular, 30},
+	} {
+		if *face.dst, err = opentype.NewFace(face.font, &opentype.FaceOptions{Size: face.size, DPI: 72, Hinting: font.HintingFull}); err != nil {
+			return faces{}, err
+		}
+	}
+	return f, nil
+})
+
+// Render draws c as a PNG of Width by Height pixels.
+func Render(c Card) ([]byte, error) {
+	f, err := loadFaces()
+	if err != nil {
+		return nil, fmt.Errorf("load fonts: %w", err)
+	}
+	drawing.Lock()
+	defer drawing.Unlock()
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, Width, 16), image.NewUniform(accent), image.Point{}, draw.Src)
+
+	y := margin + 40
+	if c.Title != "" {
+		write(img, f.heading, accent, c.Name, margin, y)
+		y += 100
+		for _, line := range wrap(f.title, c.Title, Width-2*margin, 3) {
+			write(img, f.title, text, line, margin, y)
+			y += 78
+		}
+	} else {
+		// The home page's card centers the name.
+		y = Height/2 - 20
+		for _, line := range wrap(f.title, c.Name, Width-2*margin, 2) {
+			write(img, f.title, text, line, margin, y)
+			y += 78
+		}
+	}
+	sub := c.Subtitle
+	if c.Title == "" {
+		sub = c.Tagline
+	}
+	for _, line := range wrap(f.body, sub, Width-2*margin, 2) {
+		write(img, f.body, muted, line, margin, y+10)
+		y += 42
+	}
+
+	footer := c.Site
+	if c.Title != "" && c.Tagline != "" {
+		footer = c.Tagline + " — " + c.Site
+	}
+	write(img, f.body, muted, footer, margin, Height-margin+20)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode card: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// write draws s with its baseline at y.
+func write(img draw.Image, face font.Face, c color.Color, s string, x, y int) {
+	d := font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(s)
+}
+
+// wrap breaks s into at most lines lines of width pixels, ending the last
+// with an ellipsis when s does not fit.
+func wrap(face font.Face, s string, width, lines int) []string {
+	var out []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		next := strings.TrimSpace(line + " " + word)
+		if line != "" && font.MeasureString(face, next).Ceil() > width {
+			out = append(out, line)
+			line = word
+		} else {
+			line = next
+		}
+	}
+	if line != "" {
+		out = append(out, line)
+	}
+	if len(out) > lines {
+		out = out[:lines]
+		last := out[lines-1]
+		for last != "" && font.MeasureString(face, last+"…").Ceil() > width {
+			last = strings.TrimSpace(last[:strings.LastIndex(last, " ")+1])
+		}
+		out[lines-1] = last + "…"
+	}
+	return out
+}
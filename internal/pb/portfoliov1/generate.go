@@ -0,0 +1,5 @@
+// Package portfoliov1 contains the generated protobuf, gRPC and grpc-gateway
+// code for proto/portfolio/v1/portfolio.proto.
+package portfoliov1
+
+//go:generate protoc -I ../../../proto --go_out=../../.. --go_opt=module=github.com/fpatron/portfolio --go-grpc_out=../../.. --go-grpc_opt=module=github.com/fpatron/portfolio --grpc-gateway_out=../../.. --grpc-gateway_opt=module=github.com/fpatron/portfolio portfolio/v1/portfolio.proto
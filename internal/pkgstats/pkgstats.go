@@ -0,0 +1,143 @@
+// Package pkgstats reads the latest version and download counts of
+// published Go modules and npm packages.
+package pkgstats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Registry endpoints. pkg.go.dev has no API; it is fed by the module proxy.
+const (
+	GoProxy     = "https://proxy.golang.org"
+	NPMRegistry = "https://registry.npmjs.org"
+	NPMAPI      = "https://api.npmjs.org"
+)
+
+// Stats describes a package's latest release.
+type Stats struct {
+	Version string `json:"version"`
+	// Downloads is the number of downloads in the last week. The Go
+	// module proxy does not publish download counts, so it is zero for Go
+	// modules.
+	Downloads int    `json:"weekly_downloads,omitempty"`
+	URL       string `json:"url"`
+}
+
+// Client reads package statistics.
+type Client struct {
+	goProxy     string
+	npmRegistry string
+	npmAPI      string
+	client      *http.Client
+}
+
+// NewClient returns a Client for the public registries.
+func NewClient() *Client {
+	return &Client{goProxy: GoProxy, npmRegistry: NPMRegistry, npmAPI: NPMAPI, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Parse splits a package reference such as "go:github.com/user/mod" or
+// "npm:@scope/name" into its registry and name.
+func Parse(ref string) (registry, name string, err error) {
+	registry, name, ok := strings.Cut(ref, ":")
+	if !ok || name == "" || (registry != "go" && registry != "npm") {
+		return "", "", fmt.Errorf("pkgstats: invalid package %q: want go:<module> or npm:<name>", ref)
+	}
+	return registry, name, nil
+}
+
+// Fetch returns the statistics of each package in refs. Packages that
+// could not be read are left out and their errors joined.
+func (c *Client) Fetch(ctx context.Context, refs []string) (map[string]Stats, error) {
+	out := make(map[string]Stats, len(refs))
+	var errs []error
+	for _, ref := range refs {
+		s, err := c.Stats(ctx, ref)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		out[ref] = s
+	}
+	return out, errors.Join(errs...)
+}
+
+// Stats returns the statistics of one package.
+func (c *Client) Stats(ctx context.Context, ref string) (Stats, error) {
+	registry, name, err := Parse(ref)
+	if err != nil {
+		return Stats{}, err
+	}
+	if registry == "go" {
+		return c.goModule(ctx, name)
+	}
+	return c.npmPackage(ctx, name)
+}
+
+func (c *Client) goModule(ctx context.Context, module string) (Stats, error) {
+	var latest struct {
+		Version string `json:"Version"`
+	}
+	if err := c.get(ctx, c.goProxy+"/"+escapeModule(module)+"/@latest", &latest); err != nil {
+		return Stats{}, fmt.Errorf("pkgstats: go module %s: %w", module, err)
+	}
+	return Stats{Version: latest.Version, URL: "https://pkg.go.dev/" + module}, nil
+}
+
+func (c *Client) npmPackage(ctx context.Context, name string) (Stats, error) {
+	var latest struct {
+		Version string `json:"version"`
+	}
+	if err := c.get(ctx, c.npmRegistry+"/"+url.PathEscape(name)+"/latest", &latest); err != nil {
+		return Stats{}, fmt.Errorf("pkgstats: npm package %s: %w", name, err)
+	}
+	var downloads struct {
+		Downloads int `json:"downloads"`
+	}
+	if err := c.get(ctx, c.npmAPI+"/downloads/point/last-week/"+name, &downloads); err != nil {
+		return Stats{}, fmt.Errorf("pkgstats: npm downloads %s: %w", name, err)
+	}
+	return Stats{
+		Version:   "v" + strings.TrimPrefix(latest.Version, "v"),
+		Downloads: downloads.Downloads,
+		URL:       "https://www.npmjs.com/package/" + name,
+	}, nil
+}
+
+func (c *Client) get(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// escapeModule applies the module proxy's case encoding, which replaces
+// each upper-case letter with "!" and its lower-case form.
+func escapeModule(module string) string {
+	var b strings.Builder
+	for _, r := range module {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,267 @@
+// Package preview renders the site with the data files of another git ref,
+// so content changes on a branch can be reviewed before they are merged.
+package preview
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/handler"
+	"github.com/fpatron/portfolio/internal/sitefs"
+)
+
+// Prefix is the path previews are served under, followed by the ref.
+const Prefix = "/_preview/"
+
+// maxSites bounds how many previews are kept; the least recently used is
+// removed when another ref is requested.
+const maxSites = 8
+
+// localLink matches site-relative links in rendered HTML.
+var localLink = regexp.MustCompile(`((?:href|src|hx-get|action)=")(/[^"/][^"]*|/)"`)
+
+// Server serves /_preview/{ref}/... from the site files with data/ taken
+// from ref in a git repository.
+type Server struct {
+	// Fetch, when set, runs "git fetch" before resolving a ref, at most
+	// once a minute, so branches pushed to the remote can be previewed.
+	Fetch bool
+
+	repo    string
+	dataDir string
+	base    fs.FS
+	baseURL string
+
+	mu        sync.Mutex
+	sites     map[string]*site
+	lastFetch time.Time
+}
+
+// site is the preview of one commit.
+type site struct {
+	commit string
+	dir    string
+	mux    *http.ServeMux
+	used   time.Time
+}
+
+// New returns a Server for the git repository at repo, whose data files
+// are in dataDir, relative to the repository root. The other site files
+// come from base.
+func New(repo, dataDir string, base fs.FS, baseURL string) *Server {
+	return &Server{repo: repo, dataDir: path.Clean(filepath.ToSlash(dataDir)), base: base, baseURL: baseURL, sites: make(map[string]*site)}
+}
+
+// ServeHTTP serves GET /_preview/{ref}/{path...}. Links in HTML responses
+// are rewritten to stay in the preview, and a banner names the commit.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ref := r.PathValue("ref")
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		http.NotFound(w, r)
+		return
+	}
+	site, err := s.site(r.Context(), ref)
+	if errors.Is(err, errUnknownRef) {
+		http.Error(w, fmt.Sprintf("unknown ref %q", ref), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("preview %s: %v", ref, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	prefix := Prefix + escapeRef(ref)
+	req := r.Clone(r.Context())
+	req.URL.Path = "/" + r.PathValue("path")
+	req.URL.RawPath = ""
+	rec := httptest.NewRecorder()
+	site.mux.ServeHTTP(rec, req)
+
+	body := rec.Body.Bytes()
+	mediaType, _, _ := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	if mediaType == "text/html" {
+		body = localLink.ReplaceAllFunc(body, func(m []byte) []byte {
+			sub := localLink.FindSubmatch(m)
+			link := html.UnescapeString(string(sub[2]))
+			if strings.HasPrefix(link, "/static/") || strings.HasPrefix(link, "/events") {
+				return m
+			}
+			return fmt.Appendf(nil, `%s%s%s"`, sub[1], prefix, html.EscapeString(link))
+		})
+		if i := bytes.LastIndex(body, []byte("</body>")); i >= 0 {
+			banner := fmt.Sprintf(`<div style="position:fixed;bottom:0;left:0;right:0;z-index:1000;padding:.4rem;text-align:center;font:14px sans-serif;background:#fde68a;color:#000">Preview of <strong>%s</strong> at %s</div>`, html.EscapeString(ref), site.commit[:7])
+			body = append(body[:i:i], append([]byte(banner), body[i:]...)...)
+		}
+	}
+	if loc := rec.Header().Get("Location"); strings.HasPrefix(loc, "/") && !strings.HasPrefix(loc, "//") {
+		rec.Header().Set("Location", prefix+loc)
+	}
+	for k, v := range rec.Header() {
+		w.Header()[k] = v
+	}
+	w.Header().Del("Content-Length")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	w.WriteHeader(rec.Code)
+	w.Write(body)
+}
+
+// escapeRef escapes the slashes of branch names such as feature/x, so the
+// ref stays a single path segment.
+func escapeRef(ref string) string {
+	return strings.ReplaceAll(ref, "/", "%2F")
+}
+
+var errUnknownRef = errors.New("unknown ref")
+
+// site returns the preview of ref's current commit, extracting its data
+// files when the ref has moved.
+func (s *Server) site(ctx context.Context, ref string) (*site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Fetch && time.Since(s.lastFetch) > time.Minute {
+		s.lastFetch = time.Now()
+		if _, err := s.git(ctx, "fetch", "--quiet", "--prune"); err != nil {
+			log.Printf("preview: %v", err)
+		}
+	}
+	commit, err := s.resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if cur, ok := s.sites[ref]; ok && cur.commit == commit {
+		cur.used = time.Now()
+		return cur, nil
+	}
+	next, err := s.build(ctx, commit)
+	if err != nil {
+		return nil, err
+	}
+	if old, ok := s.sites[ref]; ok {
+		os.RemoveAll(old.dir)
+	}
+	next.used = time.Now()
+	s.sites[ref] = next
+	s.evict()
+	return next, nil
+}
+
+// evict removes the least recently used previews beyond maxSites.
+func (s *Server) evict() {
+	for len(s.sites) > maxSites {
+		var oldest string
+		for ref, site := range s.sites {
+			if oldest == "" || site.used.Before(s.sites[oldest].used) {
+				oldest = ref
+			}
+		}
+		os.RemoveAll(s.sites[oldest].dir)
+		delete(s.sites, oldest)
+	}
+}
+
+// resolve returns the commit ref points to, trying the remote-tracking
+// branch when there is no local one.
+func (s *Server) resolve(ctx context.Context, ref string) (string, error) {
+	for _, name := range []string{ref, "origin/" + ref} {
+		out, err := s.git(ctx, "rev-parse", "--verify", "--quiet", "--end-of-options", name+"^{commit}")
+		if err == nil {
+			return strings.TrimSpace(string(out)), nil
+		}
+	}
+	return "", errUnknownRef
+}
+
+// build extracts the data files of commit and builds a handler over them.
+func (s *Server) build(ctx context.Context, commit string) (*site, error) {
+	archive, err := s.git(ctx, "archive", "--format=tar", commit, "--", s.dataDir)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "portfolio-preview-")
+	if err != nil {
+		return nil, err
+	}
+	if err := untar(bytes.NewReader(archive), dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("extract %s: %w", commit, err)
+	}
+	files := sitefs.FS{Base: s.base, Data: os.DirFS(filepath.Join(dir, filepath.FromSlash(s.dataDir)))}
+	h, err := handler.New(files, handler.Options{BaseURL: s.baseURL})
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	h.PublicRoutes(mux)
+	log.Printf("preview: built %s", commit[:7])
+	return &site{commit: commit, dir: dir, mux: mux}, nil
+}
+
+// Close removes the extracted data files.
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ref, site := range s.sites {
+		os.RemoveAll(site.dir)
+		delete(s.sites, ref)
+	}
+}
+
+func (s *Server) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", s.repo}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// untar writes the regular files of the tar stream r below dir.
+func untar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg || !filepath.IsLocal(hdr.Name) {
+			continue
+		}
+		name := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return err
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, b, 0o644); err != nil {
+			return err
+		}
+	}
+}
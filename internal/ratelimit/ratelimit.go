@@ -0,0 +1,79 @@
+// Package ratelimit limits how often each client may use an endpoint, with
+// a token bucket per client.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter holds a token bucket per key, such as a client address. Each
+// bucket holds up to Burst tokens and refills at Rate tokens per second;
+// a request takes one.
+type Limiter struct {
+	Rate  float64
+	Burst int
+	// Now returns the current time; it defaults to time.Now.
+	Now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// PerMinute returns a Limiter allowing n requests a minute per key, with
+// bursts of up to burst.
+func PerMinute(n, burst int) *Limiter {
+	return &Limiter{Rate: float64(n) / 60, Burst: max(burst, 1), buckets: make(map[string]*bucket)}
+}
+
+func (l *Limiter) now() time.Time {
+	if l.Now != nil {
+		return l.Now()
+	}
+	return time.Now()
+}
+
+// Allow takes a token from key's bucket. When it is empty, it reports
+// false and how long until the next token.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(float64(l.Burst), b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if l.Rate <= 0 {
+		return false, time.Duration(math.MaxInt64)
+	}
+	return false, time.Duration((1 - b.tokens) / l.Rate * float64(time.Second))
+}
+
+// sweep drops, at most once a minute, the buckets that have refilled, so
+// the map doesn't grow with every client. The caller holds l.mu.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.Rate >= float64(l.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package render
+
+import "sync"
+
+// Cache memoizes a value derived from the page data, such as a rendered
+// document, until the data changes. Versions identify the data.
+type Cache[T any] struct {
+	mu      sync.Mutex
+	valid   bool
+	version uint64
+	val     T
+}
+
+// Get returns the cached value for version, calling build when the cache is
+// empty or was filled from an older version. Build errors are not cached.
+func (c *Cache[T]) Get(version uint64, build func() (T, error)) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.valid && c.version == version {
+		return c.val, nil
+	}
+	v, err := build()
+	if err != nil {
+		return v, err
+	}
+	c.val, c.version, c.valid = v, version, true
+	return v, nil
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"maps"
+	"path"
+	"slices"
+)
+
+// Output is a template executed by Render.
+type Output struct {
+	Name string // of the shared template or the page
+	File string // defining the template, such as templates/about.html
+	Page bool
+	HTML []byte
+}
+
+// Check executes each named shared template and page with its data, failing
+// on map keys the data lacks as well as on missing fields, and reports the
+// names that do not exist or do not execute.
+func (t *Templates) Check(shared, pages map[string]any) error {
+	_, err := t.Render(shared, pages)
+	return err
+}
+
+// Render executes the templates like Check and returns the output of those
+// that succeed, in name order with the pages last.
+func (t *Templates) Render(shared, pages map[string]any) ([]Output, error) {
+	// Templates cannot be cloned once they have run, so the check parses
+	// its own copy.
+	strict, err := Parse(t.fsys)
+	if err != nil {
+		return nil, err
+	}
+	strict.shared.Option("missingkey=error")
+	var (
+		out  []Output
+		errs []error
+	)
+	for _, name := range slices.Sorted(maps.Keys(shared)) {
+		b, err := execute(strict.shared, name, shared[name])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		file := path.Join("templates", strict.shared.Lookup(name).Tree.ParseName)
+		out = append(out, Output{Name: name, File: file, HTML: b})
+	}
+	for _, page := range slices.Sorted(maps.Keys(pages)) {
+		p, ok := strict.pages[page]
+		if !ok {
+			errs = append(errs, fmt.Errorf("page %q not found", page))
+			continue
+		}
+		p.Option("missingkey=error")
+		b, err := execute(p, "base", pages[page])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("page %q: %w", page, err))
+			continue
+		}
+		out = append(out, Output{Name: page, File: "templates/pages/" + page + ".html", Page: true, HTML: b})
+	}
+	return out, errors.Join(errs...)
+}
+
+func execute(t *template.Template, name string, data any) ([]byte, error) {
+	if t.Lookup(name) == nil {
+		return nil, fmt.Errorf("template %q not found", name)
+	}
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,113 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// WantsJSON reports whether the request's Accept header prefers
+// application/json over text/html. Wildcards never select JSON, so browsers
+// and HTMX keep getting HTML.
+func WantsJSON(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return false
+	}
+	var jsonQ, htmlQ float64
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		switch mediaType {
+		case "application/json":
+			jsonQ = max(jsonQ, q)
+		case "text/html":
+			htmlQ = max(htmlQ, q)
+		}
+	}
+	return jsonQ > 0 && jsonQ > htmlQ
+}
+
+// JSON writes v as a JSON response with the given status.
+func JSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("json encode error: %v", err)
+	}
+}
+
+// JSONError writes {"error": msg} with the given status.
+func JSONError(w http.ResponseWriter, status int, msg string) {
+	JSON(w, status, map[string]string{"error": msg})
+}
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// ListResponse is the envelope returned by every JSON list endpoint.
+type ListResponse[T any] struct {
+	Items []T     `json:"items"`
+	Total int     `json:"total"`
+	Next  *string `json:"next"`
+}
+
+// ListPage holds the parsed limit/offset query parameters of a list
+// request.
+type ListPage struct {
+	limit  int
+	offset int
+}
+
+// ParseListPage reads the limit and offset parameters of q.
+func ParseListPage(q url.Values) (ListPage, error) {
+	p := ListPage{limit: defaultPageLimit}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return p, fmt.Errorf("invalid limit %q", v)
+		}
+		p.limit = min(n, maxPageLimit)
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("invalid offset %q", v)
+		}
+		p.offset = n
+	}
+	return p, nil
+}
+
+// Paginate slices items according to p and builds the envelope, including a
+// link to the next page that preserves the request's other query parameters.
+func Paginate[T any](r *http.Request, items []T, p ListPage) ListResponse[T] {
+	resp := ListResponse[T]{Items: []T{}, Total: len(items)}
+	if p.offset < len(items) {
+		end := min(p.offset+p.limit, len(items))
+		resp.Items = items[p.offset:end]
+		if end < len(items) {
+			q := r.URL.Query()
+			q.Set("limit", strconv.Itoa(p.limit))
+			q.Set("offset", strconv.Itoa(end))
+			next := r.URL.Path + "?" + q.Encode()
+			resp.Next = &next
+		}
+	}
+	return resp
+}
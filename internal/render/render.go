@@ -0,0 +1,179 @@
+// Package render renders the site's responses: the HTML templates, shared
+// by the handler packages of every section, and their JSON counterparts.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/i18n"
+)
+
+// Funcs are the functions available to every template.
+var Funcs = template.FuncMap{
+	// datetime formats t for the datetime attribute of a <time> element.
+	"datetime": func(t time.Time) string { return t.Format(time.RFC3339) },
+	// t translates a template string with the data/strings.json of the
+	// site's language.
+	"t": func(s string) string { return s },
+}
+
+// Templates is a parsed template set: the shared templates under
+// templates/ and the pages under templates/pages/ built on them.
+type Templates struct {
+	fsys   fs.FS
+	shared *template.Template
+	pages  map[string]*template.Template
+}
+
+// Parse parses the templates of fsys, translated with its strings file.
+func Parse(fsys fs.FS) (*Templates, error) {
+	strs, err := i18n.LoadStrings(fsys)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := template.New("").Funcs(Funcs).Funcs(template.FuncMap{"t": strs.T}).ParseFS(fsys, "templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("parse templates: %w", err)
+	}
+	pages, err := parsePages(fsys, shared)
+	if err != nil {
+		return nil, err
+	}
+	return &Templates{fsys: fsys, shared: shared, pages: pages}, nil
+}
+
+// parsePages builds one template set per file in templates/pages/. Each page
+// is a clone of the shared set that overrides the "content" block rendered
+// by "base".
+func parsePages(fsys fs.FS, shared *template.Template) (map[string]*template.Template, error) {
+	files, err := fs.Glob(fsys, "templates/pages/*.html")
+	if err != nil {
+		return nil, err
+	}
+	pages := make(map[string]*template.Template, len(files))
+	for _, f := range files {
+		t, err := shared.Clone()
+		if err != nil {
+			return nil, err
+		}
+		if t, err = t.ParseFS(fsys, f); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", f, err)
+		}
+		pages[strings.TrimSuffix(path.Base(f), ".html")] = t
+	}
+	return pages, nil
+}
+
+// Renderer renders responses with the current templates, which Set swaps
+// when the site is reloaded.
+type Renderer struct {
+	mu sync.RWMutex
+	t  *Templates
+}
+
+// New returns a Renderer using t.
+func New(t *Templates) *Renderer {
+	return &Renderer{t: t}
+}
+
+// Set replaces the templates.
+func (r *Renderer) Set(t *Templates) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.t = t
+}
+
+func (r *Renderer) templates() *Templates {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.t
+}
+
+var buffers = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// write executes t into a buffer and copies it to w, so a template that
+// fails halfway sends an error page rather than a truncated one.
+func write(w http.ResponseWriter, status int, t *template.Template, name string, data any) error {
+	buf := buffers.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		buffers.Put(buf)
+	}()
+	if err := t.ExecuteTemplate(buf, name, data); err != nil {
+		return err
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+	w.WriteHeader(status)
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// HTML renders the named shared template, a partial or the home page.
+func (r *Renderer) HTML(w http.ResponseWriter, name string, data any) {
+	r.HTMLStatus(w, http.StatusOK, name, data)
+}
+
+// HTMLStatus renders the named shared template like HTML, with the given
+// status.
+func (r *Renderer) HTMLStatus(w http.ResponseWriter, status int, name string, data any) {
+	if err := write(w, status, r.templates().shared, name, data); err != nil {
+		log.Printf("template %q error: %v", name, err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
+
+// Page renders a full page from templates/pages/<page>.html.
+func (r *Renderer) Page(w http.ResponseWriter, page string, data any) {
+	r.PageStatus(w, http.StatusOK, page, data)
+}
+
+// PageStatus renders a full page like Page, with the given status.
+func (r *Renderer) PageStatus(w http.ResponseWriter, status int, page string, data any) {
+	t, ok := r.templates().pages[page]
+	if !ok {
+		log.Printf("page %q not found", page)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if err := write(w, status, t, "base", data); err != nil {
+		log.Printf("page %q error: %v", page, err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
+
+// Respond renders the named template, or encodes v as JSON when the client
+// asked for it, so HTML and JSON are always produced from the same data.
+func (r *Renderer) Respond(w http.ResponseWriter, req *http.Request, name string, data any, v any) {
+	w.Header().Add("Vary", "Accept")
+	if WantsJSON(req) {
+		JSON(w, http.StatusOK, v)
+		return
+	}
+	r.HTML(w, name, data)
+}
+
+// Fragment renders the named template to a string, for pushing HTML
+// fragments over channels other than an HTTP response.
+func (r *Renderer) Fragment(name string, data any) (string, error) {
+	var sb strings.Builder
+	if err := r.templates().shared.ExecuteTemplate(&sb, name, data); err != nil {
+		return "", fmt.Errorf("render %q: %w", name, err)
+	}
+	return sb.String(), nil
+}
+
+// Templates returns the current templates.
+func (r *Renderer) Templates() *Templates {
+	return r.templates()
+}
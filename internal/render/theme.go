@@ -0,0 +1,21 @@
+package render
+
+import "net/http"
+
+// ThemeCookie holds the color theme a visitor picked with the toggle.
+const ThemeCookie = "theme"
+
+// Theme returns the color theme of the request's cookie, "dark" or
+// "light", or "" to follow the browser's preference.
+func Theme(r *http.Request) string {
+	c, err := r.Cookie(ThemeCookie)
+	if err != nil || !ValidTheme(c.Value) {
+		return ""
+	}
+	return c.Value
+}
+
+// ValidTheme reports whether theme is "dark" or "light".
+func ValidTheme(theme string) bool {
+	return theme == "dark" || theme == "light"
+}
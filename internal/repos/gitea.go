@@ -0,0 +1,79 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// CodebergAPI is Codeberg's origin; it runs Forgejo, which serves the Gitea
+// API.
+const CodebergAPI = "https://codeberg.org"
+
+// Gitea lists a user's repositories on a Gitea or Forgejo instance such as
+// Codeberg.
+type Gitea struct {
+	// Source names the instance in project metadata, e.g. "codeberg".
+	Source string
+	User   string
+	Token  string // optional access token
+	API    string // instance origin
+	HTTP   *http.Client
+}
+
+// NewGitea returns a Gitea provider for user on the instance at api.
+func NewGitea(source, user, token, api string) *Gitea {
+	return &Gitea{Source: source, User: user, Token: token, API: api, HTTP: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Name implements Provider.
+func (g *Gitea) Name() string { return g.Source + ":" + g.User }
+
+type giteaRepo struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	HTMLURL     string    `json:"html_url"`
+	Language    string    `json:"language"`
+	Stars       int       `json:"stars_count"`
+	Topics      []string  `json:"topics"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Fork        bool      `json:"fork"`
+	Archived    bool      `json:"archived"`
+	Private     bool      `json:"private"`
+}
+
+// Repos implements Provider.
+func (g *Gitea) Repos(ctx context.Context) ([]Repo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.API+"/api/v1/users/"+url.PathEscape(g.User)+"/repos?limit=50", nil)
+	if err != nil {
+		return nil, err
+	}
+	if g.Token != "" {
+		req.Header.Set("Authorization", "token "+g.Token)
+	}
+	var list []giteaRepo
+	if err := getJSON(g.HTTP, req, &list); err != nil {
+		return nil, fmt.Errorf("list repos: %w", err)
+	}
+	out := make([]Repo, 0, len(list))
+	for _, r := range list {
+		if r.Private {
+			continue
+		}
+		out = append(out, Repo{
+			Source:      g.Source,
+			Name:        r.Name,
+			Description: r.Description,
+			URL:         r.HTMLURL,
+			Language:    r.Language,
+			Stars:       r.Stars,
+			Topics:      r.Topics,
+			PushedAt:    r.UpdatedAt,
+			Fork:        r.Fork,
+			Archived:    r.Archived,
+		})
+	}
+	return out, nil
+}
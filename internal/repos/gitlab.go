@@ -0,0 +1,106 @@
+package repos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// GitLab lists a user's projects on gitlab.com or a self-managed instance.
+type GitLab struct {
+	User  string
+	Token string // optional personal access token
+	API   string // instance origin, e.g. "https://gitlab.com"
+	HTTP  *http.Client
+}
+
+// NewGitLab returns a GitLab provider for user on the instance at api.
+func NewGitLab(user, token, api string) *GitLab {
+	return &GitLab{User: user, Token: token, API: api, HTTP: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Name implements Provider.
+func (g *GitLab) Name() string { return "gitlab:" + g.User }
+
+type gitlabProject struct {
+	ID             int       `json:"id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	WebURL         string    `json:"web_url"`
+	StarCount      int       `json:"star_count"`
+	Topics         []string  `json:"topics"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	Archived       bool      `json:"archived"`
+	ForkedFrom     *struct{} `json:"forked_from_project"`
+}
+
+// Repos implements Provider. GitLab doesn't list a primary language, so it
+// is looked up separately for each project.
+func (g *GitLab) Repos(ctx context.Context) ([]Repo, error) {
+	var projects []gitlabProject
+	path := "/api/v4/users/" + url.PathEscape(g.User) + "/projects?visibility=public&order_by=last_activity_at&per_page=100"
+	if err := g.get(ctx, path, &projects); err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	out := make([]Repo, 0, len(projects))
+	for _, p := range projects {
+		r := Repo{
+			Source:      "gitlab",
+			Name:        p.Name,
+			Description: p.Description,
+			URL:         p.WebURL,
+			Stars:       p.StarCount,
+			Topics:      p.Topics,
+			PushedAt:    p.LastActivityAt,
+			Fork:        p.ForkedFrom != nil,
+			Archived:    p.Archived,
+		}
+		if !r.Fork && !r.Archived {
+			var langs map[string]float64
+			if err := g.get(ctx, fmt.Sprintf("/api/v4/projects/%d/languages", p.ID), &langs); err == nil {
+				r.Language = topLanguage(langs)
+			}
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func (g *GitLab) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.API+path, nil)
+	if err != nil {
+		return err
+	}
+	if g.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", g.Token)
+	}
+	return getJSON(g.HTTP, req, v)
+}
+
+// topLanguage returns the language with the largest share.
+func topLanguage(shares map[string]float64) string {
+	var top string
+	for name, pct := range shares {
+		if top == "" || pct > shares[top] || (pct == shares[top] && name < top) {
+			top = name
+		}
+	}
+	return top
+}
+
+// getJSON performs req and decodes a 200 response into v.
+func getJSON(client *http.Client, req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s", req.Method, req.URL.Path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,80 @@
+// Package repos syncs repositories from code hosting accounts so they can be
+// merged into the projects grid. Each host is a Provider; the GitHub one
+// lives in package github.
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Repo is the subset of a repository's metadata shown on the site.
+type Repo struct {
+	Source      string    `json:"source"` // provider name, e.g. "github"
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	URL         string    `json:"url"`
+	Language    string    `json:"language,omitempty"`
+	Stars       int       `json:"stars"`
+	Topics      []string  `json:"topics,omitempty"`
+	PushedAt    time.Time `json:"pushed_at,omitzero"`
+	Fork        bool      `json:"fork,omitempty"`
+	Archived    bool      `json:"archived,omitempty"`
+	Pinned      bool      `json:"pinned,omitempty"`
+}
+
+// Provider lists one account's repositories on a code host.
+type Provider interface {
+	// Name identifies the provider and account in logs, e.g.
+	// "gitlab:fpatron".
+	Name() string
+	// Repos returns the account's public repositories.
+	Repos(ctx context.Context) ([]Repo, error)
+}
+
+// Featured reports whether r is worth showing: pinned, or an active
+// original with at least minStars stars.
+func Featured(r Repo, minStars int) bool {
+	return r.Pinned || (!r.Fork && !r.Archived && r.Stars >= minStars)
+}
+
+// Syncer combines the featured repositories of several providers. A
+// provider that fails keeps contributing its last good result.
+type Syncer struct {
+	providers []Provider
+	minStars  int
+
+	mu   sync.Mutex
+	last map[string][]Repo
+}
+
+// NewSyncer returns a Syncer over providers.
+func NewSyncer(minStars int, providers ...Provider) *Syncer {
+	return &Syncer{providers: providers, minStars: minStars, last: make(map[string][]Repo)}
+}
+
+// Sync fetches every provider and returns the featured repositories, most
+// starred first. The error joins the failures of individual providers.
+func (s *Syncer) Sync(ctx context.Context) ([]Repo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for _, p := range s.providers {
+		rs, err := p.Repos(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+			continue
+		}
+		s.last[p.Name()] = slices.DeleteFunc(rs, func(r Repo) bool { return !Featured(r, s.minStars) })
+	}
+	var out []Repo
+	for _, p := range s.providers {
+		out = append(out, s.last[p.Name()]...)
+	}
+	slices.SortStableFunc(out, func(a, b Repo) int { return b.Stars - a.Stars })
+	return out, errors.Join(errs...)
+}
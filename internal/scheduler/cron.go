@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronFields are the fields of a cron spec with their ranges.
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// Cron parses a five-field cron spec, "minute hour day-of-month month
+// day-of-week" in UTC, such as "30 4 * * 1-5". Fields take *, numbers,
+// ranges (a-b), lists (a,b) and steps (*/15, a-b/2); Sunday is 0 or 7. As
+// in cron, when both day fields are restricted a day matching either one
+// qualifies.
+func Cron(spec string) (Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("cron %q: want 5 fields, got %d", spec, len(fields))
+	}
+	var sets [5]uint64
+	for i, f := range fields {
+		max := cronFields[i].max
+		if i == 4 {
+			max = 7 // Sunday as 7
+		}
+		set, err := parseCronField(f, cronFields[i].min, max)
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %s: %w", spec, cronFields[i].name, err)
+		}
+		sets[i] = set
+	}
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+	minutes, hours, days, months, weekdays := sets[0], sets[1], sets[2], sets[3], sets[4]
+	anyDay, anyWeekday := fields[2] == "*", fields[4] == "*"
+
+	dayMatches := func(t time.Time) bool {
+		dom := days&(1<<t.Day()) != 0
+		dow := weekdays&(1<<int(t.Weekday())) != 0
+		switch {
+		case anyDay && anyWeekday:
+			return true
+		case anyDay:
+			return dow
+		case anyWeekday:
+			return dom
+		}
+		return dom || dow
+	}
+
+	schedule := func(t time.Time) time.Time {
+		t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+		// Five years covers every valid spec, including February 29.
+		for end := t.AddDate(5, 0, 0); t.Before(end); {
+			switch {
+			case months&(1<<int(t.Month())) == 0:
+				t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			case !dayMatches(t):
+				t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			case hours&(1<<t.Hour()) == 0:
+				t = t.Truncate(time.Hour).Add(time.Hour)
+			case minutes&(1<<t.Minute()) == 0:
+				t = t.Add(time.Minute)
+			default:
+				return t
+			}
+		}
+		return time.Time{}
+	}
+	// A spec such as "0 0 31 2 *" never matches.
+	if schedule(time.Now()).IsZero() {
+		return nil, fmt.Errorf("cron %q: never matches", spec)
+	}
+	return schedule, nil
+}
+
+// parseCronField returns the set of values f matches, as a bit mask.
+func parseCronField(f string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(f, ",") {
+		rng, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepText)
+			}
+			step = n
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("invalid value %q", a)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(b); err != nil {
+					return 0, fmt.Errorf("invalid value %q", b)
+				}
+			} else if hasStep {
+				hi = max
+			}
+			if lo < min || hi > max || lo > hi {
+				return 0, fmt.Errorf("%q is out of range %d-%d", rng, min, max)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
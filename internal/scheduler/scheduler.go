@@ -0,0 +1,188 @@
+// Package scheduler runs recurring background jobs in-process, on fixed
+// intervals or cron schedules, and keeps the result of their last run.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/worker"
+)
+
+// Schedule returns the next time a job should run after t.
+type Schedule func(t time.Time) time.Time
+
+// Every runs a job at a fixed interval.
+func Every(d time.Duration) Schedule {
+	return func(t time.Time) time.Time { return t.Add(d) }
+}
+
+// Job is a named unit of recurring work.
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+	// Immediate runs the job once as soon as the scheduler starts, before
+	// following the schedule.
+	Immediate bool
+}
+
+// Status is a job's state as of the call to Status.
+type Status struct {
+	Name    string    `json:"name"`
+	Running bool      `json:"running"`
+	Next    time.Time `json:"next,omitzero"` // zero before Run and while running
+	// LastRun is when the last completed run started, LastDuration how
+	// long it took and LastError why it failed, empty when it succeeded.
+	LastRun      time.Time     `json:"last_run,omitzero"`
+	LastDuration time.Duration `json:"last_duration,omitempty"`
+	LastError    string        `json:"last_error,omitempty"`
+	Runs         int           `json:"runs"`
+	Failures     int           `json:"failures"`
+}
+
+// OK reports whether the job's last run succeeded, or it has not run yet.
+func (s Status) OK() bool { return s.LastError == "" }
+
+// Scheduler runs each added job on its schedule until its context is done.
+// A job that panics fails that run only.
+type Scheduler struct {
+	// OnError, when set, is called with the name of a job whose run
+	// failed and its error. It must be set before Run is called.
+	OnError func(job string, err error)
+
+	pool    *worker.Pool
+	mu      sync.Mutex
+	jobs    []*entry
+	started bool
+}
+
+// entry is a job and its state, guarded by the Scheduler's mu.
+type entry struct {
+	Job
+	status  Status
+	trigger chan struct{}
+}
+
+// New creates an empty Scheduler whose jobs run on pool's workers.
+func New(pool *worker.Pool) *Scheduler {
+	return &Scheduler{pool: pool}
+}
+
+// Add registers a job. Jobs must be added before Run is called.
+func (s *Scheduler) Add(j Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, &entry{Job: j, status: Status{Name: j.Name}, trigger: make(chan struct{}, 1)})
+}
+
+// Status returns the state of every job, in the order they were added.
+func (s *Scheduler) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Status, len(s.jobs))
+	for i, e := range s.jobs {
+		out[i] = e.status
+	}
+	return out
+}
+
+// ErrUnknownJob is returned by Trigger for a name no job has.
+var ErrUnknownJob = errors.New("unknown job")
+
+// Trigger runs the named job as soon as it is not running, without
+// changing its schedule. Triggering a job that is already due has no
+// further effect.
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.jobs {
+		if e.Name != name {
+			continue
+		}
+		if !s.started {
+			return fmt.Errorf("job %q: scheduler is not running", name)
+		}
+		select {
+		case e.trigger <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+	return fmt.Errorf("job %q: %w", name, ErrUnknownJob)
+}
+
+// Run starts every job and blocks until ctx is done and running jobs have
+// returned.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	jobs := append([]*entry(nil), s.jobs...)
+	s.started = true
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, e := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	next := e.Schedule(time.Now())
+	if e.Immediate {
+		next = time.Now()
+	}
+	for {
+		s.mu.Lock()
+		e.status.Next = next
+		s.mu.Unlock()
+		t := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+			next = e.Schedule(time.Now())
+		case <-e.trigger:
+			t.Stop()
+		}
+		s.run(ctx, e)
+	}
+}
+
+// run runs the job once and records the result.
+func (s *Scheduler) run(ctx context.Context, e *entry) {
+	s.mu.Lock()
+	e.status.Running, e.status.Next = true, time.Time{}
+	s.mu.Unlock()
+	start := time.Now()
+	err := s.pool.Do(ctx, e.Name, e.Run)
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	s.mu.Lock()
+	e.status.Running = false
+	e.status.LastRun, e.status.LastDuration, e.status.LastError = start, elapsed, ""
+	e.status.Runs++
+	if err != nil {
+		e.status.LastError = err.Error()
+		e.status.Failures++
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		log.Printf("scheduler: %s: %v", e.Name, err)
+		if s.OnError != nil {
+			s.OnError(e.Name, err)
+		}
+		return
+	}
+	log.Printf("scheduler: %s done in %s", e.Name, elapsed)
+}
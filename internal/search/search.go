@@ -0,0 +1,244 @@
+// Package search implements a small in-memory ranked search over site
+// content. Callers describe their content as Documents; the index has no
+// knowledge of projects, skills or any other concrete type.
+package search
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Field weights: a term found in the title counts more than one found in
+// the tags, which counts more than one found in the body.
+const (
+	titleWeight = 5
+	tagWeight   = 3
+	bodyWeight  = 1
+)
+
+// Document is a searchable piece of content.
+type Document struct {
+	Type  string   `json:"type"`
+	Title string   `json:"title"`
+	URL   string   `json:"url"`
+	Tags  []string `json:"tags,omitempty"`
+	Body  string   `json:"-"`
+}
+
+// Result is a Document that matched a query.
+type Result struct {
+	Document
+	Snippet string  `json:"snippet,omitempty"`
+	Score   float64 `json:"score"`
+}
+
+type entry struct {
+	doc   Document
+	title []string
+	tags  []string
+	body  []string
+}
+
+// Index holds pre-tokenized documents.
+type Index struct {
+	entries []entry
+}
+
+// NewIndex tokenizes docs for searching.
+func NewIndex(docs []Document) *Index {
+	idx := &Index{entries: make([]entry, 0, len(docs))}
+	for _, d := range docs {
+		idx.entries = append(idx.entries, entry{
+			doc:   d,
+			title: tokenize(d.Title),
+			tags:  tokenize(strings.Join(d.Tags, " ")),
+			body:  tokenize(d.Body),
+		})
+	}
+	return idx
+}
+
+// Search returns the documents matching every term of q, best match first.
+// Restricting types limits results to documents of those types.
+func (idx *Index) Search(q string, types ...string) []Result {
+	terms := tokenize(q)
+	if len(terms) == 0 {
+		return nil
+	}
+	var results []Result
+	for _, e := range idx.entries {
+		if len(types) > 0 && !slices.Contains(types, e.doc.Type) {
+			continue
+		}
+		score, ok := e.score(terms)
+		if !ok {
+			continue
+		}
+		results = append(results, Result{
+			Document: e.doc,
+			Snippet:  snippet(e.doc.Body, terms),
+			Score:    score,
+		})
+	}
+	slices.SortStableFunc(results, func(a, b Result) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Title, b.Title)
+	})
+	return results
+}
+
+func (e entry) score(terms []string) (float64, bool) {
+	total := 0.0
+	for _, t := range terms {
+		best := max(
+			match(e.title, t)*titleWeight,
+			match(e.tags, t)*tagWeight,
+			match(e.body, t)*bodyWeight,
+		)
+		if best == 0 {
+			return 0, false
+		}
+		total += best
+	}
+	return total, true
+}
+
+// match scores how well term matches any of words: 1 for an exact word,
+// 0.75 for a word prefix, 0.4 for a substring, 0.3 for a word within the
+// typos term allows and 0 otherwise.
+func match(words []string, term string) float64 {
+	best := 0.0
+	typos := allowedTypos(term)
+	for _, w := range words {
+		switch {
+		case w == term:
+			return 1
+		case strings.HasPrefix(w, term):
+			best = max(best, 0.75)
+		case strings.Contains(w, term):
+			best = max(best, 0.4)
+		case typos > 0 && best < 0.3 && distance(w, term, typos) <= typos:
+			best = 0.3
+		}
+	}
+	return best
+}
+
+// allowedTypos is the edit distance a term may be from a word and still
+// match it: none for short terms, which would match too much, one from
+// four letters and two from eight.
+func allowedTypos(term string) int {
+	switch n := len([]rune(term)); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	}
+	return 0
+}
+
+// distance returns the edit distance between a and b, counting swapped
+// adjacent letters as one edit, or limit+1 once it is known to be over
+// limit.
+func distance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return limit + 1
+	}
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
+
+// indexLower returns the byte offset in s of the first occurrence of term,
+// which is lowercase, comparing s lowercased as tokenize does, or -1.
+// Lowercasing changes the length of some runes, so the offset is found in
+// s itself rather than in strings.ToLower(s).
+func indexLower(s, term string) int {
+	for i := range s {
+		if hasLowerPrefix(s[i:], term) {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasLowerPrefix reports whether s lowercased begins with prefix.
+func hasLowerPrefix(s, prefix string) bool {
+	for _, r := range s {
+		if prefix == "" {
+			return true
+		}
+		p, n := utf8.DecodeRuneInString(prefix)
+		if unicode.ToLower(r) != p {
+			return false
+		}
+		prefix = prefix[n:]
+	}
+	return prefix == ""
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	})
+}
+
+const snippetRadius = 60
+
+// snippet returns a short excerpt of body around the first matching term.
+func snippet(body string, terms []string) string {
+	if body == "" {
+		return ""
+	}
+	at := -1
+	for _, t := range terms {
+		if i := indexLower(body, t); i >= 0 && (at < 0 || i < at) {
+			at = i
+		}
+	}
+	if at < 0 {
+		at = 0
+	}
+	runes := []rune(body)
+	// Convert the byte offset into a rune offset.
+	at = len([]rune(body[:at]))
+	start := max(0, at-snippetRadius)
+	end := min(len(runes), at+snippetRadius)
+	s := strings.TrimSpace(string(runes[start:end]))
+	if start > 0 {
+		s = "…" + s
+	}
+	if end < len(runes) {
+		s += "…"
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+package search
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("word ", 30)
+	for _, tt := range []struct {
+		name, body string
+		terms      []string
+		want       string
+	}{
+		{"empty", "", []string{"go"}, ""},
+		{"short", "Written in Go.", []string{"go"}, "Written in Go."},
+		{"no match", "Written in Go.", []string{"rust"}, "Written in Go."},
+		{"around the match", long + "Built with HTMX. " + long, []string{"htmx"}, "HTMX"},
+		{"earliest term", long + "alpha " + long + "beta", []string{"beta", "alpha"}, "alpha"},
+		// İ is two bytes and lowercases to one, Ⱥ two and lowercases to
+		// three: offsets in the lowercased body are off in the body.
+		{"shorter when lowercased", strings.Repeat("İ", 200) + " Kubernetes", []string{"kubernetes"}, "Kubernetes"},
+		{"longer when lowercased", strings.Repeat("Ⱥ", 200) + " Kubernetes", []string{"kubernetes"}, "Kubernetes"},
+		{"non-ASCII term", long + "Éléphant rose " + long, []string{"éléphant"}, "Éléphant rose"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := snippet(tt.body, tt.terms)
+			if !utf8.ValidString(got) {
+				t.Fatalf("snippet = %q, not valid UTF-8", got)
+			}
+			if !strings.Contains(got, tt.want) || (tt.want == "" && got != "") {
+				t.Errorf("snippet = %q, want it around %q", got, tt.want)
+			}
+			if n := utf8.RuneCountInString(got); n > 2*snippetRadius+2 {
+				t.Errorf("snippet has %d runes, want at most %d", n, 2*snippetRadius+2)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	idx := NewIndex([]Document{
+		{Type: "project", Title: "Grafikon", Tags: []string{"go"}, Body: strings.Repeat("Ⱥ", 100) + " Über die Ölförderung in Zürich"},
+		{Type: "post", Title: "Zürich notes", Body: "A walk around the lake."},
+	})
+	results := idx.Search("ZÜRICH")
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if results[0].Title != "Zürich notes" {
+		t.Errorf("first result = %q, want the title match", results[0].Title)
+	}
+	if !strings.Contains(results[1].Snippet, "Zürich") {
+		t.Errorf("snippet = %q, want it around Zürich", results[1].Snippet)
+	}
+	if got := idx.Search("zürich", "post"); len(got) != 1 || got[0].Type != "post" {
+		t.Errorf("search of posts = %+v", got)
+	}
+}
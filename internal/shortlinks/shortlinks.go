@@ -0,0 +1,134 @@
+// Package shortlinks keeps the short links served under /go/. They are
+// defined in data/links.json or added from the admin page, which stores
+// them in SQLite.
+package shortlinks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/db"
+)
+
+// Prefix is the path the short links are served under.
+const Prefix = "/go/"
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS short_links (
+		code TEXT PRIMARY KEY,
+		url TEXT NOT NULL,
+		title TEXT NOT NULL,
+		created INTEGER NOT NULL
+	)`,
+}
+
+// Link is a short link: Prefix+Code redirects to URL.
+type Link struct {
+	Code  string `json:"code"`
+	URL   string `json:"url"` // absolute http(s) URL or site path
+	Title string `json:"title,omitempty"`
+	// Created is set for the links added from the admin page.
+	Created time.Time `json:"-"`
+}
+
+var codePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// Check reports what is wrong with l, if anything.
+func (l Link) Check() error {
+	if !codePattern.MatchString(l.Code) {
+		return fmt.Errorf("code %q: use 1 to 32 lowercase letters, digits and dashes", l.Code)
+	}
+	if strings.HasPrefix(l.URL, "/") && !strings.HasPrefix(l.URL, "//") {
+		return nil
+	}
+	u, err := url.Parse(l.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url %q: use an http(s) URL or a path on the site", l.URL)
+	}
+	return nil
+}
+
+// ErrExists is returned by Add for a code that is taken.
+var ErrExists = errors.New("shortlinks: code already in use")
+
+// Store persists the links added from the admin page in SQLite.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates the table if needed.
+func NewStore(ctx context.Context, database *sql.DB) (*Store, error) {
+	if err := db.Migrate(ctx, database, schema...); err != nil {
+		return nil, fmt.Errorf("shortlinks: %w", err)
+	}
+	return &Store{db: database}, nil
+}
+
+// Get returns the link with the given code, or false if there is none.
+func (s *Store) Get(ctx context.Context, code string) (Link, bool, error) {
+	l := Link{Code: code}
+	var created int64
+	err := s.db.QueryRowContext(ctx, `SELECT url, title, created FROM short_links WHERE code = ?`, code).
+		Scan(&l.URL, &l.Title, &created)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Link{}, false, nil
+	}
+	if err != nil {
+		return Link{}, false, fmt.Errorf("get short link: %w", err)
+	}
+	l.Created = time.Unix(created, 0)
+	return l, true, nil
+}
+
+// List returns the stored links, newest first.
+func (s *Store) List(ctx context.Context) ([]Link, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT code, url, title, created FROM short_links ORDER BY created DESC, code`)
+	if err != nil {
+		return nil, fmt.Errorf("list short links: %w", err)
+	}
+	defer rows.Close()
+	var list []Link
+	for rows.Next() {
+		var l Link
+		var created int64
+		if err := rows.Scan(&l.Code, &l.URL, &l.Title, &created); err != nil {
+			return nil, fmt.Errorf("list short links: %w", err)
+		}
+		l.Created = time.Unix(created, 0)
+		list = append(list, l)
+	}
+	return list, rows.Err()
+}
+
+// Add stores l, which must pass Check. It returns ErrExists when the code
+// is taken.
+func (s *Store) Add(ctx context.Context, l Link) error {
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO short_links (code, url, title, created) VALUES (?, ?, ?, ?)
+		ON CONFLICT (code) DO NOTHING`,
+		l.Code, l.URL, l.Title, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("add short link: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrExists
+	}
+	return nil
+}
+
+// Delete removes the link with the given code. It reports whether there
+// was one.
+func (s *Store) Delete(ctx context.Context, code string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM short_links WHERE code = ?`, code)
+	if err != nil {
+		return false, fmt.Errorf("delete short link: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
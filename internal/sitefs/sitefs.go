@@ -0,0 +1,74 @@
+// Package sitefs layers a site's own data files and theme over the
+// built-in content.
+package sitefs
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// FS layers site content over Base, usually the built-in files. Data,
+// when set, replaces data/ entirely. Theme, when set, overrides individual
+// templates and static files; the files it does not have come from Base.
+type FS struct {
+	Base  fs.FS
+	Data  fs.FS
+	Theme fs.FS
+}
+
+func (s FS) Open(name string) (fs.File, error) {
+	if sub, ok := s.dataPath(name); ok {
+		return s.Data.Open(sub)
+	}
+	if s.Theme != nil {
+		f, err := s.Theme.Open(name)
+		if err == nil {
+			if info, err := f.Stat(); err == nil && !info.IsDir() {
+				return f, nil
+			}
+			f.Close()
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return s.Base.Open(name)
+}
+
+// ReadDir merges the theme's entries into Base's, so globs over
+// templates/ see both.
+func (s FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if sub, ok := s.dataPath(name); ok {
+		return fs.ReadDir(s.Data, sub)
+	}
+	entries, err := fs.ReadDir(s.Base, name)
+	if s.Theme == nil {
+		return entries, err
+	}
+	themed, themeErr := fs.ReadDir(s.Theme, name)
+	if themeErr != nil {
+		return entries, err
+	}
+	for _, e := range themed {
+		i := slices.IndexFunc(entries, func(b fs.DirEntry) bool { return b.Name() == e.Name() })
+		if i < 0 {
+			entries = append(entries, e)
+		} else if !e.IsDir() {
+			entries[i] = e
+		}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return entries, nil
+}
+
+// dataPath maps name to the data file system when it is below data/.
+func (s FS) dataPath(name string) (string, bool) {
+	if s.Data == nil {
+		return "", false
+	}
+	if name == "data" {
+		return ".", true
+	}
+	return strings.CutPrefix(name, "data/")
+}
@@ -0,0 +1,202 @@
+// Package sse implements a Server-Sent Events broker with per-topic
+// subscriptions, used to push live widget updates to the page.
+package sse
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHeartbeat is how often an idle stream receives a keep-alive comment.
+const DefaultHeartbeat = 20 * time.Second
+
+// Event is a single message published to a topic.
+type Event struct {
+	ID    uint64
+	Topic string
+	Data  string
+}
+
+type subscriber struct {
+	topics map[string]bool
+	ch     chan Event
+}
+
+// Broker fans published events out to every subscriber of the event's topic.
+// The latest event of each topic is retained and replayed to new subscribers
+// so widgets render immediately instead of waiting for the next change.
+type Broker struct {
+	// Heartbeat is the keep-alive interval; zero means DefaultHeartbeat.
+	Heartbeat time.Duration
+	// Watch, if set, is called with a topic's new subscriber count whenever
+	// a stream subscribed to it opens or closes. It may call Publish.
+	Watch func(topic string, subscribers int)
+
+	mu     sync.Mutex
+	subs   map[*subscriber]struct{}
+	counts map[string]int
+	last   map[string]Event
+	nextID uint64
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewBroker creates an empty Broker.
+func NewBroker() *Broker {
+	return &Broker{
+		subs:   make(map[*subscriber]struct{}),
+		counts: make(map[string]int),
+		last:   make(map[string]Event),
+		done:   make(chan struct{}),
+	}
+}
+
+// Publish sends data to all current subscribers of topic. Subscribers that
+// are too slow to keep up miss the event rather than blocking the publisher.
+func (b *Broker) Publish(topic, data string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	ev := Event{ID: b.nextID, Topic: topic, Data: data}
+	b.last[topic] = ev
+	for s := range b.subs {
+		if !s.topics[topic] {
+			continue
+		}
+		select {
+		case s.ch <- ev:
+		default:
+		}
+	}
+}
+
+// Close ends all open streams. It is meant to be registered with
+// http.Server.RegisterOnShutdown so long-lived connections don't hold up a
+// graceful shutdown.
+func (b *Broker) Close() {
+	b.closeOnce.Do(func() { close(b.done) })
+}
+
+// Subscribers returns the number of open streams subscribed to topic.
+func (b *Broker) Subscribers(topic string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.counts[topic]
+}
+
+func (b *Broker) subscribe(topics []string) *subscriber {
+	s := &subscriber{topics: make(map[string]bool), ch: make(chan Event, 16)}
+	b.mu.Lock()
+	for _, t := range topics {
+		if s.topics[t] {
+			continue
+		}
+		s.topics[t] = true
+		b.counts[t]++
+		if ev, ok := b.last[t]; ok {
+			s.ch <- ev
+		}
+	}
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+	b.notify(s)
+	return s
+}
+
+func (b *Broker) unsubscribe(s *subscriber) {
+	b.mu.Lock()
+	delete(b.subs, s)
+	for t := range s.topics {
+		b.counts[t]--
+	}
+	b.mu.Unlock()
+	b.notify(s)
+}
+
+// notify reports the subscriber counts of s's topics to Watch.
+func (b *Broker) notify(s *subscriber) {
+	if b.Watch == nil {
+		return
+	}
+	for t := range s.topics {
+		b.Watch(t, b.Subscribers(t))
+	}
+}
+
+// ServeHTTP streams events for the topics named by the ?topic= query
+// parameter, which may be repeated or comma-separated.
+func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var topics []string
+	for _, v := range r.URL.Query()["topic"] {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				topics = append(topics, t)
+			}
+		}
+	}
+	if len(topics) == 0 {
+		http.Error(w, "missing topic", http.StatusBadRequest)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// Streams outlive the server's WriteTimeout.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("sse: clear write deadline: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		log.Printf("sse: flush unsupported: %v", err)
+		return
+	}
+
+	s := b.subscribe(topics)
+	defer b.unsubscribe(s)
+
+	heartbeat := b.Heartbeat
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeat
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-b.done:
+			return
+		case ev := <-s.ch:
+			if err := writeEvent(w, ev); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, ev Event) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "id: %d\nevent: %s\n", ev.ID, ev.Topic)
+	for _, line := range strings.Split(ev.Data, "\n") {
+		fmt.Fprintf(&sb, "data: %s\n", line)
+	}
+	sb.WriteString("\n")
+	_, err := fmt.Fprint(w, sb.String())
+	return err
+}
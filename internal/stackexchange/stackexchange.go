@@ -0,0 +1,136 @@
+// Package stackexchange fetches a user's reputation, badges and top tags on
+// a Stack Exchange site such as Stack Overflow.
+package stackexchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// API is the Stack Exchange API origin.
+const API = "https://api.stackexchange.com/2.3"
+
+// Tag is one of the user's top tags by answer score.
+type Tag struct {
+	Name        string `json:"name"`
+	AnswerScore int    `json:"answer_score"`
+	Answers     int    `json:"answers"`
+}
+
+// Profile summarizes the user's standing on the site.
+type Profile struct {
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	Avatar     string `json:"avatar"`
+	Reputation int    `json:"reputation"`
+	Gold       int    `json:"gold"`
+	Silver     int    `json:"silver"`
+	Bronze     int    `json:"bronze"`
+	Tags       []Tag  `json:"top_tags"`
+}
+
+// Client reads one user's profile.
+type Client struct {
+	user   string
+	site   string
+	key    string
+	api    string
+	client *http.Client
+}
+
+// NewClient returns a Client for the user ID on site (e.g.
+// "stackoverflow"). The key is optional and raises the daily quota.
+func NewClient(user, site, key string) *Client {
+	return &Client{user: user, site: site, key: key, api: API, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *Client) get(ctx context.Context, path string, q url.Values, v any) error {
+	q.Set("site", c.site)
+	if c.key != "" {
+		q.Set("key", c.key)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.api+path+"?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var out struct {
+		Items        json.RawMessage `json:"items"`
+		ErrorMessage string          `json:"error_message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("%s: %s", path, resp.Status)
+	}
+	if out.ErrorMessage != "" {
+		return fmt.Errorf("%s: %s", path, out.ErrorMessage)
+	}
+	return json.Unmarshal(out.Items, v)
+}
+
+// Profile fetches the user's reputation, badge counts and n top tags.
+func (c *Client) Profile(ctx context.Context, n int) (*Profile, error) {
+	var users []struct {
+		DisplayName  string `json:"display_name"`
+		Link         string `json:"link"`
+		ProfileImage string `json:"profile_image"`
+		Reputation   int    `json:"reputation"`
+		BadgeCounts  struct {
+			Gold   int `json:"gold"`
+			Silver int `json:"silver"`
+			Bronze int `json:"bronze"`
+		} `json:"badge_counts"`
+	}
+	if err := c.get(ctx, "/users/"+url.PathEscape(c.user), url.Values{}, &users); err != nil {
+		return nil, fmt.Errorf("stackexchange: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("stackexchange: user %s not found on %s", c.user, c.site)
+	}
+	var tags []struct {
+		TagName     string `json:"tag_name"`
+		AnswerScore int    `json:"answer_score"`
+		AnswerCount int    `json:"answer_count"`
+	}
+	q := url.Values{"pagesize": {fmt.Sprint(n)}}
+	if err := c.get(ctx, "/users/"+url.PathEscape(c.user)+"/top-answer-tags", q, &tags); err != nil {
+		return nil, fmt.Errorf("stackexchange: %w", err)
+	}
+
+	u := users[0]
+	p := &Profile{
+		// The API returns display names HTML-escaped.
+		Name:       html.UnescapeString(u.DisplayName),
+		URL:        u.Link,
+		Avatar:     u.ProfileImage,
+		Reputation: u.Reputation,
+		Gold:       u.BadgeCounts.Gold,
+		Silver:     u.BadgeCounts.Silver,
+		Bronze:     u.BadgeCounts.Bronze,
+	}
+	for _, t := range tags {
+		p.Tags = append(p.Tags, Tag{Name: t.TagName, AnswerScore: t.AnswerScore, Answers: t.AnswerCount})
+	}
+	return p, nil
+}
+
+// ShortReputation formats the reputation the way the site's flair does:
+// 987, 12.3k, 1.2m.
+func (p Profile) ShortReputation() string {
+	switch r := p.Reputation; {
+	case r >= 1_000_000:
+		return fmt.Sprintf("%.1fm", float64(r)/1_000_000)
+	case r >= 10_000:
+		return fmt.Sprintf("%.1fk", float64(r)/1_000)
+	default:
+		return fmt.Sprint(r)
+	}
+}
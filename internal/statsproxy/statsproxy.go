@@ -0,0 +1,113 @@
+// Package statsproxy serves a self-hosted Plausible or Umami instance's
+// tracking script and event endpoint from the site's own domain, so content
+// blockers don't strip them and no third-party origin is involved.
+package statsproxy
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+)
+
+// Routes the proxy is mounted on.
+const (
+	ScriptPath = "/stats/script.js"
+	EventPath  = "/stats/event"
+	// UmamiEventPath is where the Umami script posts when its host URL is
+	// set to /stats.
+	UmamiEventPath = "/stats/api/send"
+)
+
+type provider struct {
+	script string
+	event  string
+	tag    string
+}
+
+var providers = map[string]provider{
+	"plausible": {
+		script: "/js/script.js",
+		event:  "/api/event",
+		tag:    `<script defer data-domain="{{.SiteID}}" data-api="` + EventPath + `" src="` + ScriptPath + `"></script>`,
+	},
+	"umami": {
+		script: "/script.js",
+		event:  "/api/send",
+		tag:    `<script defer data-website-id="{{.SiteID}}" data-host-url="/stats" src="` + ScriptPath + `"></script>`,
+	},
+}
+
+// Config selects the analytics backend.
+type Config struct {
+	// Provider is "plausible" or "umami".
+	Provider string
+	// Upstream is the base URL of the self-hosted instance.
+	Upstream string
+	// SiteID is the Plausible data-domain or the Umami website ID.
+	SiteID string
+}
+
+// Proxy forwards the script and event routes to the configured instance.
+type Proxy struct {
+	cfg      Config
+	provider provider
+	rp       *httputil.ReverseProxy
+}
+
+// New validates cfg and builds the proxy.
+func New(cfg Config) (*Proxy, error) {
+	p, ok := providers[cfg.Provider]
+	if !ok {
+		return nil, fmt.Errorf("statsproxy: unknown provider %q", cfg.Provider)
+	}
+	upstream, err := url.Parse(cfg.Upstream)
+	if err != nil || upstream.Scheme == "" || upstream.Host == "" {
+		return nil, fmt.Errorf("statsproxy: invalid upstream %q", cfg.Upstream)
+	}
+
+	rp := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			target := p.event
+			if pr.In.URL.Path == ScriptPath {
+				target = p.script
+			}
+			pr.SetURL(upstream)
+			pr.Out.URL.Path = upstream.JoinPath(target).Path
+			pr.Out.URL.RawPath = ""
+			// Both backends derive unique visitors from the client address.
+			pr.SetXForwarded()
+			pr.Out.Header.Del("Cookie")
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Printf("statsproxy: %s: %v", r.URL.Path, err)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+	return &Proxy{cfg: cfg, provider: p, rp: rp}, nil
+}
+
+// Register mounts the proxy routes on mux.
+func (p *Proxy) Register(mux *http.ServeMux) {
+	mux.Handle("GET "+ScriptPath, p.rp)
+	mux.Handle("POST "+EventPath, p.rp)
+	if p.cfg.Provider == "umami" {
+		mux.Handle("POST "+UmamiEventPath, p.rp)
+	}
+}
+
+// Snippet returns the <script> tag to include in the page head.
+func (p *Proxy) Snippet() (template.HTML, error) {
+	t, err := template.New("tag").Parse(p.provider.tag)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, p.cfg); err != nil {
+		return "", err
+	}
+	return template.HTML(sb.String()), nil
+}
@@ -0,0 +1,220 @@
+// Package strava fetches the owner's latest public Strava activity.
+package strava
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/db"
+)
+
+const (
+	API      = "https://www.strava.com/api/v3"
+	TokenURL = "https://www.strava.com/oauth/token"
+)
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS strava_tokens (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		seed TEXT NOT NULL,
+		refresh_token TEXT NOT NULL
+	)`,
+}
+
+// Activity is a summary of a Strava activity.
+type Activity struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`        // sport type, e.g. "Ride" or "TrailRun"
+	Distance   float64   `json:"distance"`    // meters
+	MovingTime int       `json:"moving_time"` // seconds
+	Elevation  float64   `json:"elevation_gain"`
+	StartDate  time.Time `json:"start_date"`
+	URL        string    `json:"url"`
+}
+
+// Kilometers returns the distance in km.
+func (a Activity) Kilometers() float64 { return a.Distance / 1000 }
+
+// Label returns the sport type as words, e.g. "Trail Run".
+func (a Activity) Label() string {
+	var sb strings.Builder
+	for i, r := range a.Type {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			sb.WriteByte(' ')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Duration formats the moving time as "1h 32m" or "45m".
+func (a Activity) Duration() string {
+	d := (time.Duration(a.MovingTime) * time.Second).Round(time.Minute)
+	if h := int(d.Hours()); h > 0 {
+		return fmt.Sprintf("%dh %02dm", h, int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
+
+// Client reads the athlete's activities. Strava rotates refresh tokens and
+// invalidates the old one, so with a database the latest token is kept
+// there and survives restarts.
+type Client struct {
+	ClientID     string
+	ClientSecret string
+	API          string
+	TokenURL     string
+	HTTP         *http.Client
+
+	db   *sql.DB
+	seed string // the configured refresh token
+
+	mu      sync.Mutex
+	refresh string
+	token   string
+	expires time.Time
+}
+
+// NewClient returns a client authorized by refreshToken. database may be
+// nil, in which case the rotated token only lives in memory.
+func NewClient(ctx context.Context, database *sql.DB, clientID, clientSecret, refreshToken string) (*Client, error) {
+	c := &Client{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		API:          API,
+		TokenURL:     TokenURL,
+		HTTP:         &http.Client{Timeout: 10 * time.Second},
+		db:           database,
+		seed:         refreshToken,
+		refresh:      refreshToken,
+	}
+	if database == nil {
+		return c, nil
+	}
+	if err := db.Migrate(ctx, database, schema...); err != nil {
+		return nil, fmt.Errorf("strava: %w", err)
+	}
+	// A stored token is only used while the configured one is unchanged.
+	var seed, stored string
+	err := database.QueryRowContext(ctx, `SELECT seed, refresh_token FROM strava_tokens WHERE id = 1`).Scan(&seed, &stored)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return nil, fmt.Errorf("strava: %w", err)
+	case seed == refreshToken:
+		c.refresh = stored
+	}
+	return c, nil
+}
+
+// accessToken returns a valid access token, refreshing it when it is about
+// to expire.
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+	form := url.Values{
+		"client_id":     {c.ClientID},
+		"client_secret": {c.ClientSecret},
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {c.refresh},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var body struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresAt    int64  `json:"expires_at"`
+	}
+	if err := c.do(req, &body); err != nil {
+		return "", fmt.Errorf("refresh strava token: %w", err)
+	}
+	c.token = body.AccessToken
+	c.expires = time.Unix(body.ExpiresAt, 0).Add(-time.Minute)
+	if body.RefreshToken != "" && body.RefreshToken != c.refresh {
+		c.refresh = body.RefreshToken
+		if c.db != nil {
+			if _, err := c.db.ExecContext(ctx, `INSERT OR REPLACE INTO strava_tokens (id, seed, refresh_token) VALUES (1, ?, ?)`,
+				c.seed, c.refresh); err != nil {
+				return "", fmt.Errorf("save strava token: %w", err)
+			}
+		}
+	}
+	return c.token, nil
+}
+
+// Latest returns the most recent activity visible to everyone, or nil when
+// none of the recent ones are.
+func (c *Client) Latest(ctx context.Context) (*Activity, error) {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.API+"/athlete/activities?per_page=10", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var list []struct {
+		ID         int64     `json:"id"`
+		Name       string    `json:"name"`
+		SportType  string    `json:"sport_type"`
+		Type       string    `json:"type"`
+		Distance   float64   `json:"distance"` // meters
+		MovingTime int       `json:"moving_time"`
+		Elevation  float64   `json:"total_elevation_gain"`
+		StartDate  time.Time `json:"start_date"`
+		Private    bool      `json:"private"`
+		Visibility string    `json:"visibility"`
+	}
+	if err := c.do(req, &list); err != nil {
+		return nil, err
+	}
+	for _, a := range list {
+		if a.Private || (a.Visibility != "" && a.Visibility != "everyone") {
+			continue
+		}
+		typ := a.SportType
+		if typ == "" {
+			typ = a.Type
+		}
+		return &Activity{
+			ID:         a.ID,
+			Name:       a.Name,
+			Type:       typ,
+			Distance:   a.Distance,
+			MovingTime: a.MovingTime,
+			Elevation:  a.Elevation,
+			StartDate:  a.StartDate,
+			URL:        fmt.Sprintf("https://www.strava.com/activities/%d", a.ID),
+		}, nil
+	}
+	return nil, nil
+}
+
+func (c *Client) do(req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s", req.Method, req.URL.Path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,127 @@
+// Package talks reads a list of conference talks from a spreadsheet, so
+// they can be kept in a shared sheet and written to data/talks.json.
+package talks
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Talk is a talk given at an event.
+type Talk struct {
+	Title    string    `json:"title"`
+	Event    string    `json:"event"`
+	Date     time.Time `json:"date,omitzero"`
+	Location string    `json:"location,omitempty"`
+	URL      string    `json:"url,omitempty"` // the event's page for the talk
+	Slides   string    `json:"slides,omitempty"`
+	Video    string    `json:"video,omitempty"`
+}
+
+// dateLayouts are the date formats accepted in the date column: ISO dates
+// and the US format Google Sheets exports by default.
+var dateLayouts = []string{"2006-01-02", "2006-01", "1/2/2006", "January 2, 2006", "Jan 2, 2006"}
+
+// ParseCSV reads talks from a CSV whose header names the columns title,
+// event, date, location, url, slides and video, in any order and case.
+// Rows without a title are skipped. Talks are returned most recent first.
+func ParseCSV(r io.Reader) ([]Talk, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read talks: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, name := range []string{"title", "event"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("read talks: missing column %q", name)
+		}
+	}
+
+	var list []Talk
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read talks: %w", err)
+		}
+		get := func(name string) string {
+			if i, ok := col[name]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		t := Talk{
+			Title:    get("title"),
+			Event:    get("event"),
+			Location: get("location"),
+			URL:      get("url"),
+			Slides:   get("slides"),
+			Video:    get("video"),
+		}
+		if t.Title == "" {
+			continue
+		}
+		if d := get("date"); d != "" {
+			if t.Date, err = parseDate(d); err != nil {
+				return nil, fmt.Errorf("read talks: line %d: %w", line, err)
+			}
+		}
+		list = append(list, t)
+	}
+	slices.SortStableFunc(list, func(a, b Talk) int { return b.Date.Compare(a.Date) })
+	return list, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", s)
+}
+
+// sheetURL matches a Google Sheets document URL, capturing its ID.
+var sheetURL = regexp.MustCompile(`^https://docs\.google\.com/spreadsheets/d/([\w-]+)`)
+
+// Fetch downloads the CSV at url and parses it. A Google Sheets link is
+// turned into its CSV export, which works for sheets shared with anyone
+// with the link; a gid parameter selects the tab.
+func Fetch(ctx context.Context, client *http.Client, url string) ([]Talk, error) {
+	if m := sheetURL.FindStringSubmatch(url); m != nil && !strings.Contains(url, "/pub?") {
+		gid := "0"
+		if _, after, ok := strings.Cut(url, "gid="); ok {
+			gid, _, _ = strings.Cut(after, "&")
+		}
+		url = "https://docs.google.com/spreadsheets/d/" + m[1] + "/export?format=csv&gid=" + cmp.Or(gid, "0")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch talks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch talks: %s", resp.Status)
+	}
+	return ParseCSV(resp.Body)
+}
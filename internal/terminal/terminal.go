@@ -0,0 +1,209 @@
+// Package terminal renders the portfolio as text for curl and other
+// terminal clients: the about section, experience, skills, projects, the
+// latest blog posts and contact details, from the same data as the home page.
+package terminal
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/content"
+)
+
+// width is the column text is wrapped at.
+const width = 78
+
+// clients are the User-Agent prefixes of terminal HTTP clients.
+var clients = []string{"curl/", "Wget/", "HTTPie/", "xh/"}
+
+// Wants reports whether r should get text rather than HTML: it comes from
+// a terminal client that did not ask for HTML or JSON, or its Accept header
+// prefers text/plain. Color is set for terminal clients unless the query
+// has color=false.
+func Wants(r *http.Request) (text, color bool) {
+	var plainQ, htmlQ, jsonQ float64
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		switch mediaType {
+		case "text/plain":
+			plainQ = max(plainQ, q)
+		case "text/html":
+			htmlQ = max(htmlQ, q)
+		case "application/json":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+	ua := r.UserAgent()
+	terminal := false
+	for _, c := range clients {
+		if strings.HasPrefix(ua, c) {
+			terminal = true
+		}
+	}
+	switch {
+	case plainQ > 0 && plainQ > htmlQ && plainQ > jsonQ:
+		text = true
+	case terminal && htmlQ == 0 && jsonQ == 0:
+		text = true
+	}
+	return text, text && terminal && r.URL.Query().Get("color") != "false"
+}
+
+// ANSI escape sequences.
+const (
+	bold  = "\x1b[1m"
+	dim   = "\x1b[2m"
+	cyan  = "\x1b[36m"
+	reset = "\x1b[0m"
+)
+
+type writer struct {
+	strings.Builder
+	color bool
+}
+
+// style wraps s in the escape sequence when writing in color.
+func (w *writer) style(code, s string) string {
+	if !w.color || s == "" {
+		return s
+	}
+	return code + s + reset
+}
+
+func (w *writer) heading(title string) {
+	w.WriteString("\n  " + w.style(bold+cyan, strings.ToUpper(title)) + "\n")
+	w.WriteString("  " + w.style(dim, strings.Repeat("─", len(title))) + "\n")
+}
+
+// para writes s wrapped at width, starting after first, which takes up
+// indent columns, and indenting the lines that follow by as much.
+func (w *writer) para(first string, indent int, s string) {
+	line, n := first, indent
+	for i, word := range strings.Fields(s) {
+		if i > 0 && n+1+len([]rune(word)) > width {
+			w.WriteString(line + "\n")
+			line, n = strings.Repeat(" ", indent), indent
+		} else if i > 0 {
+			line += " "
+			n++
+		}
+		line += word
+		n += len([]rune(word))
+	}
+	w.WriteString(line + "\n")
+}
+
+// Render returns data as text. Links are made absolute with data.BaseURL.
+func Render(data content.PageData, color bool) string {
+	w := &writer{color: color}
+	a := data.About
+	w.WriteString("\n  " + w.style(bold, a.Name) + "\n")
+	var meta []string
+	for _, s := range []string{a.Tagline, a.Location} {
+		if s != "" {
+			meta = append(meta, s)
+		}
+	}
+	if a.Availability {
+		meta = append(meta, "Open to opportunities")
+	}
+	w.WriteString("  " + w.style(dim, strings.Join(meta, " · ")) + "\n\n")
+	if a.Bio != "" {
+		w.para("  ", 2, a.Bio)
+	}
+
+	if data.HasSection("about") {
+		for _, s := range []struct{ title, typ string }{{"Experience", "work"}, {"Education", "education"}} {
+			var entries []content.Experience
+			for _, e := range data.Experience {
+				if e.Type == s.typ {
+					entries = append(entries, e)
+				}
+			}
+			if len(entries) == 0 {
+				continue
+			}
+			w.heading(s.title)
+			for i, e := range entries {
+				if i > 0 {
+					w.WriteString("\n")
+				}
+				dates := e.DateRange()
+				title := e.Role + " — " + e.Company
+				pad := max(width-2-len([]rune(title))-len([]rune(dates)), 1)
+				w.WriteString("  " + w.style(bold, title) + strings.Repeat(" ", pad) + w.style(dim, dates) + "\n")
+				for _, d := range e.Description {
+					w.para("  - ", 4, d)
+				}
+			}
+		}
+		if len(data.Skills) > 0 {
+			w.heading("Skills")
+			pad := 0
+			for _, c := range data.Skills {
+				pad = max(pad, len([]rune(c.Category)))
+			}
+			for _, c := range data.Skills {
+				label := c.Category + strings.Repeat(" ", pad-len([]rune(c.Category)))
+				w.para("  "+w.style(bold, label)+"  ", pad+4, strings.Join(c.Skills, ", "))
+			}
+		}
+	}
+
+	if data.HasSection("projects") && len(data.Projects) > 0 {
+		w.heading("Projects")
+		for i, p := range data.Projects {
+			if i > 0 {
+				w.WriteString("\n")
+			}
+			w.WriteString("  " + w.style(bold, p.Title))
+			if len(p.Tags) > 0 {
+				w.WriteString("  " + w.style(dim, strings.Join(p.Tags, ", ")))
+			}
+			w.WriteString("\n")
+			w.para("    ", 4, p.Description)
+			w.WriteString("    " + w.style(cyan, data.BaseURL+"/projects/"+p.Slug) + "\n")
+		}
+	}
+
+	if data.HasSection("blog") && len(data.Posts) > 0 {
+		w.heading("Blog")
+		for _, p := range data.Posts[:min(len(data.Posts), 5)] {
+			date := p.Date.Format("Jan 2006")
+			pad := max(width-2-len([]rune(p.Title))-len(date), 1)
+			w.WriteString("  " + w.style(bold, p.Title) + strings.Repeat(" ", pad) + w.style(dim, date) + "\n")
+			w.WriteString("    " + w.style(cyan, data.BaseURL+"/blog/"+p.Slug) + "\n")
+		}
+	}
+
+	if data.HasSection("contact") {
+		w.heading("Contact")
+		for _, l := range []struct{ label, value string }{
+			{"Email", a.Email},
+			{"GitHub", a.GitHub},
+			{"LinkedIn", a.LinkedIn},
+			{"X", a.X},
+		} {
+			if l.value != "" {
+				w.WriteString("  " + w.style(bold, l.label+strings.Repeat(" ", 10-len(l.label))) + w.style(cyan, l.value) + "\n")
+			}
+		}
+		if data.HasSection("about") {
+			w.WriteString("  " + w.style(bold, "Resume    ") + w.style(cyan, data.BaseURL+"/resume.pdf") + "\n")
+		}
+	}
+
+	w.WriteString("\n  " + w.style(dim, "JSON: curl -H 'Accept: application/json' "+data.BaseURL+"/") + "\n\n")
+	return w.String()
+}
@@ -0,0 +1,91 @@
+package uptime
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Healthchecks reads a check through the healthchecks.io management API.
+type Healthchecks struct {
+	key     string
+	check   string
+	baseURL string
+}
+
+// NewHealthchecks returns a Monitor for the check with the given UUID. A
+// read-only API key is enough. baseURL points at a self-hosted instance and
+// defaults to https://healthchecks.io.
+func NewHealthchecks(key, check, baseURL string) *Healthchecks {
+	if baseURL == "" {
+		baseURL = "https://healthchecks.io"
+	}
+	return &Healthchecks{key: key, check: check, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (hc *Healthchecks) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hc.baseURL+"/api/v3/checks/"+url.PathEscape(hc.check)+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Api-Key", hc.key)
+	return do(req, v)
+}
+
+// Status returns the check's state, and its uptime over Window computed
+// from the check's status flips.
+func (hc *Healthchecks) Status(ctx context.Context) (*Status, error) {
+	var check struct {
+		Status string `json:"status"`
+	}
+	if err := hc.get(ctx, "", &check); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	start := now.Add(-Window)
+	var flips []struct {
+		Timestamp time.Time `json:"timestamp"`
+		Up        int       `json:"up"`
+	}
+	if err := hc.get(ctx, "/flips/?start="+strconv.FormatInt(start.Unix(), 10), &flips); err != nil {
+		return nil, err
+	}
+
+	st := &Status{Source: "healthchecks", CheckedAt: now}
+	switch check.Status {
+	case "up", "grace":
+		st.State = StateUp
+	case "down":
+		st.State = StateDown
+	case "paused":
+		st.State = StatePaused
+	default:
+		st.State = StateUnknown
+	}
+
+	// The state before the first flip is the opposite of it; without
+	// flips the current state held for the whole window.
+	up := st.State != StateDown
+	if len(flips) > 0 {
+		up = flips[0].Up == 0
+	}
+	var downtime time.Duration
+	last := start
+	for _, f := range flips {
+		if f.Timestamp.Before(start) {
+			continue
+		}
+		if !up {
+			downtime += f.Timestamp.Sub(last)
+		}
+		up, last = f.Up == 1, f.Timestamp
+	}
+	if !up {
+		downtime += now.Sub(last)
+	}
+	st.Uptime = 100 * (1 - downtime.Seconds()/Window.Seconds())
+	return st, nil
+}
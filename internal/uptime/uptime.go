@@ -0,0 +1,52 @@
+// Package uptime reads the site's current status and 30-day uptime from an
+// external monitor: UptimeRobot or healthchecks.io.
+package uptime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Window is the period the uptime ratio covers.
+const Window = 30 * 24 * time.Hour
+
+// States reported by Status.
+const (
+	StateUp      = "up"
+	StateDown    = "down"
+	StatePaused  = "paused"
+	StateUnknown = "unknown"
+)
+
+// Status is the monitor's view of the site.
+type Status struct {
+	State string `json:"state"`
+	// Uptime is the percentage of Window the site was up.
+	Uptime    float64   `json:"uptime_30d"`
+	Source    string    `json:"source"` // "uptimerobot" or "healthchecks"
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Monitor reports the site's status.
+type Monitor interface {
+	Status(ctx context.Context) (*Status, error)
+}
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// do sends req and decodes a 200 JSON response into v.
+func do(req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s", req.Method, req.URL.Path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,78 @@
+package uptime
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UptimeRobot reads a monitor through the UptimeRobot API.
+type UptimeRobot struct {
+	key     string
+	monitor string
+	apiURL  string
+}
+
+// NewUptimeRobot returns a Monitor for the UptimeRobot monitor with the
+// given ID. With an empty ID the first monitor visible to the key is used,
+// which suits monitor-specific read-only keys.
+func NewUptimeRobot(key, monitor string) *UptimeRobot {
+	return &UptimeRobot{key: key, monitor: monitor, apiURL: "https://api.uptimerobot.com"}
+}
+
+// Status returns the monitor's state and its uptime ratio over Window.
+func (u *UptimeRobot) Status(ctx context.Context) (*Status, error) {
+	form := url.Values{
+		"api_key":              {u.key},
+		"format":               {"json"},
+		"custom_uptime_ratios": {strconv.Itoa(int(Window.Hours() / 24))},
+	}
+	if u.monitor != "" {
+		form.Set("monitors", u.monitor)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.apiURL+"/v2/getMonitors", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var resp struct {
+		Stat  string `json:"stat"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		Monitors []struct {
+			Status int    `json:"status"`
+			Ratio  string `json:"custom_uptime_ratio"`
+		} `json:"monitors"`
+	}
+	if err := do(req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Stat != "ok" {
+		return nil, fmt.Errorf("uptimerobot: %s", resp.Error.Message)
+	}
+	if len(resp.Monitors) == 0 {
+		return nil, fmt.Errorf("uptimerobot: monitor not found")
+	}
+	m := resp.Monitors[0]
+	ratio, err := strconv.ParseFloat(m.Ratio, 64)
+	if err != nil {
+		return nil, fmt.Errorf("uptimerobot: uptime ratio %q: %w", m.Ratio, err)
+	}
+	st := &Status{Uptime: ratio, Source: "uptimerobot", CheckedAt: time.Now()}
+	switch m.Status {
+	case 2:
+		st.State = StateUp
+	case 8, 9: // seems down, down
+		st.State = StateDown
+	case 0:
+		st.State = StatePaused
+	default:
+		st.State = StateUnknown
+	}
+	return st, nil
+}
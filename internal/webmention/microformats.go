@@ -0,0 +1,243 @@
+package webmention
+
+import (
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const maxContent = 500
+
+// parse reads a source document and reports whether it links to target.
+// When it does, the returned mention is filled from the first h-entry:
+// author (h-card), content, published date and, from u-in-reply-to,
+// u-like-of, u-repost-of or u-bookmark-of pointing at target, its type.
+// Sources without microformats still count as plain mentions.
+func parse(r io.Reader, base *url.URL, target string) (Mention, bool, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return Mention{}, false, err
+	}
+	if !linksTo(doc, base, target) {
+		return Mention{}, false, nil
+	}
+
+	m := Mention{Type: TypeMention, URL: base.String(), AuthorURL: base.Scheme + "://" + base.Host}
+	m.AuthorName = base.Host
+	entry := find(doc, func(n *html.Node) bool { return hasClass(n, "h-entry") })
+	if entry == nil {
+		if t := find(doc, func(n *html.Node) bool { return n.DataAtom == atom.Title }); t != nil {
+			m.Content = truncate(text(t))
+		}
+		return m, true, nil
+	}
+
+	var name, summary string
+	properties(entry, func(n *html.Node) {
+		for _, c := range classes(n) {
+			switch c {
+			case "p-author":
+				if hasClass(n, "h-card") {
+					card(n, base, &m)
+				} else if s := text(n); s != "" {
+					m.AuthorName = s
+				}
+			case "e-content":
+				m.Content = truncate(text(n))
+			case "p-summary":
+				summary = truncate(text(n))
+			case "p-name":
+				name = truncate(text(n))
+			case "dt-published":
+				m.Published = parseTime(attr(n, "datetime"), text(n))
+			case "u-url":
+				if u := urlValue(n, base); u != "" {
+					m.URL = u
+				}
+			case "u-in-reply-to", "u-like-of", "u-repost-of", "u-bookmark-of":
+				if sameURL(urlValue(n, base), target) {
+					m.Type = map[string]string{
+						"u-in-reply-to": TypeReply, "u-like-of": TypeLike,
+						"u-repost-of": TypeRepost, "u-bookmark-of": TypeBookmark,
+					}[c]
+				}
+			}
+		}
+	})
+	if m.Content == "" {
+		m.Content = summary
+	}
+	if m.Content == "" {
+		m.Content = name
+	}
+	return m, true, nil
+}
+
+// card fills the author fields from an h-card.
+func card(n *html.Node, base *url.URL, m *Mention) {
+	name := ""
+	properties(n, func(p *html.Node) {
+		switch {
+		case hasClass(p, "p-name"):
+			name = text(p)
+		case hasClass(p, "u-url"):
+			m.AuthorURL = urlValue(p, base)
+		case hasClass(p, "u-photo"):
+			m.AuthorPhoto = urlValue(p, base)
+		}
+	})
+	if name == "" {
+		// Implied name: the card's own text, as in <a class="h-card">.
+		name = text(n)
+	}
+	if name != "" {
+		m.AuthorName = truncate(name)
+	}
+	if n.DataAtom == atom.A && m.AuthorURL == base.Scheme+"://"+base.Host {
+		if u := urlValue(n, base); u != "" {
+			m.AuthorURL = u
+		}
+	}
+}
+
+// properties calls fn for every descendant of root that may carry a
+// property of root: nested microformat roots are visited themselves but
+// not descended into, since their properties belong to them.
+func properties(root *html.Node, fn func(*html.Node)) {
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		fn(c)
+		if !isRoot(c) {
+			properties(c, fn)
+		}
+	}
+}
+
+func isRoot(n *html.Node) bool {
+	return slices.ContainsFunc(classes(n), func(c string) bool { return strings.HasPrefix(c, "h-") })
+}
+
+func linksTo(doc *html.Node, base *url.URL, target string) bool {
+	return find(doc, func(n *html.Node) bool {
+		for _, key := range []string{"href", "src"} {
+			if v := attr(n, key); v != "" && sameURL(resolve(base, v), target) {
+				return true
+			}
+		}
+		return false
+	}) != nil
+}
+
+func find(n *html.Node, match func(*html.Node) bool) *html.Node {
+	if n.Type == html.ElementNode && match(n) {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if f := find(c, match); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func classes(n *html.Node) []string {
+	return strings.Fields(attr(n, "class"))
+}
+
+func hasClass(n *html.Node, class string) bool {
+	return slices.Contains(classes(n), class)
+}
+
+// urlValue returns a u-* property's value: href, src or value, falling
+// back to the element's text.
+func urlValue(n *html.Node, base *url.URL) string {
+	for _, key := range []string{"href", "src", "value"} {
+		if v := attr(n, key); v != "" {
+			return resolve(base, v)
+		}
+	}
+	if u := find(n, func(c *html.Node) bool { return c != n && hasClass(c, "u-url") }); u != nil {
+		return urlValue(u, base)
+	}
+	return resolve(base, text(n))
+}
+
+func resolve(base *url.URL, ref string) string {
+	u, err := base.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return ""
+	}
+	return u.String()
+}
+
+// sameURL compares URLs ignoring fragments and a trailing slash.
+func sameURL(a, b string) bool {
+	norm := func(s string) string {
+		s, _, _ = strings.Cut(s, "#")
+		return strings.TrimSuffix(s, "/")
+	}
+	return a != "" && norm(a) == norm(b)
+}
+
+// blocks separate words in text even without surrounding whitespace.
+var blocks = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Li: true, atom.Blockquote: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.Td: true, atom.Img: true,
+}
+
+// text returns the whitespace-collapsed text of n, skipping scripts and
+// styles.
+func text(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			sb.WriteString(n.Data)
+		case n.DataAtom == atom.Script || n.DataAtom == atom.Style:
+			return
+		case blocks[n.DataAtom]:
+			sb.WriteByte(' ')
+			defer sb.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+func truncate(s string) string {
+	if r := []rune(s); len(r) > maxContent {
+		return strings.TrimSpace(string(r[:maxContent])) + "…"
+	}
+	return s
+}
+
+func parseTime(values ...string) time.Time {
+	for _, v := range values {
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05-0700", "2006-01-02 15:04:05", "2006-01-02"} {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
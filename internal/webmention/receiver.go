@@ -0,0 +1,161 @@
+package webmention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/form"
+	"github.com/fpatron/portfolio/internal/worker"
+)
+
+const maxSource = 1 << 20
+
+// Receiver is the webmention endpoint. It validates the request, answers
+// 202 Accepted and verifies the source in the background.
+type Receiver struct {
+	Store *Store
+	// Accept reports whether target is a page of the site that takes
+	// mentions, and returns the canonical URL they are stored under.
+	Accept func(target *url.URL) (string, bool)
+	// HTTP fetches sources. The default client refuses to connect to
+	// loopback and private addresses.
+	HTTP *http.Client
+
+	pool *worker.Pool
+}
+
+// NewReceiver returns a Receiver storing mentions in store. Sources are
+// verified on pool's workers.
+func NewReceiver(store *Store, accept func(*url.URL) (string, bool), pool *worker.Pool) *Receiver {
+	return &Receiver{Store: store, Accept: accept, HTTP: PublicClient(), pool: pool}
+}
+
+// PublicClient returns an HTTP client that only connects to public
+// addresses, for fetching URLs that come from requests.
+func PublicClient() *http.Client {
+	return &http.Client{
+		Timeout: 15 * time.Second,
+		Transport: &http.Transport{
+			DialContext: (&net.Dialer{Timeout: 5 * time.Second, Control: publicOnly}).DialContext,
+		},
+	}
+}
+
+// publicOnly keeps fetches of untrusted URLs from reaching internal
+// services.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	ip := ap.Addr().Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return fmt.Errorf("refusing to connect to %s", ip)
+	}
+	return nil
+}
+
+func (rc *Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	vals, err := form.Parse(r, form.MaxBytes)
+	if err != nil {
+		form.Error(w, err)
+		return
+	}
+	source, target := vals.Get("source"), vals.Get("target")
+	su, err := parseHTTPURL(source)
+	if err != nil {
+		http.Error(w, "invalid source: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	tu, err := parseHTTPURL(target)
+	if err != nil {
+		http.Error(w, "invalid target: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if su.String() == tu.String() {
+		http.Error(w, "source and target are the same", http.StatusBadRequest)
+		return
+	}
+	canonical, ok := rc.Accept(tu)
+	if !ok {
+		http.Error(w, "target does not accept webmentions", http.StatusBadRequest)
+		return
+	}
+
+	err = rc.pool.Go("webmention verification", func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+		if err := rc.verify(ctx, su.String(), tu.String(), canonical); err != nil {
+			return fmt.Errorf("webmention: %s -> %s: %w", source, target, err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("webmention: %s -> %s: %v", source, target, err)
+		http.Error(w, "too many pending webmentions, try again later", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func parseHTTPURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("must be an absolute http(s) URL")
+	}
+	u.Fragment = ""
+	return u, nil
+}
+
+var errGone = errors.New("source is gone")
+
+// verify fetches source and stores the mention under canonical if it links
+// to target, or removes a stored one if it no longer does.
+func (rc *Receiver) verify(ctx context.Context, source, target, canonical string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/html")
+	resp, err := rc.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusGone || resp.StatusCode == http.StatusNotFound {
+		return errors.Join(errGone, rc.Store.Remove(ctx, source, canonical))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET source: %s", resp.Status)
+	}
+	base := resp.Request.URL
+	m, ok, err := parse(io.LimitReader(resp.Body, maxSource), base, target)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Join(errors.New("source does not link to target"), rc.Store.Remove(ctx, source, canonical))
+	}
+	m.Source, m.Target = source, canonical
+	if m.Published.IsZero() {
+		m.Published = time.Now()
+	}
+	if err := rc.Store.Save(ctx, m); err != nil {
+		return err
+	}
+	log.Printf("webmention: %s %s -> %s", m.Type, source, target)
+	return nil
+}
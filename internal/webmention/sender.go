@@ -0,0 +1,300 @@
+package webmention
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/fpatron/portfolio/internal/worker"
+)
+
+// Delivery statuses of sent mentions.
+const (
+	SentOK         = "sent"
+	SentNoEndpoint = "no endpoint"
+	SentFailed     = "failed"
+)
+
+var senderSchema = []string{
+	`CREATE TABLE IF NOT EXISTS webmention_sources (
+		source TEXT PRIMARY KEY,
+		hash TEXT NOT NULL,
+		links TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS webmentions_sent (
+		source TEXT NOT NULL,
+		target TEXT NOT NULL,
+		endpoint TEXT NOT NULL,
+		status TEXT NOT NULL,
+		detail TEXT NOT NULL,
+		ts INTEGER NOT NULL,
+		PRIMARY KEY (source, target)
+	)`,
+}
+
+// Page is a published page of the site whose outbound links get mentions.
+type Page struct {
+	URL string
+	// Content is anything that, when changed, counts as an update worth
+	// re-sending mentions for.
+	Content string
+	Links   []string
+}
+
+// Links returns the outbound links of an HTML fragment, such as the body of
+// a post at page: the absolute http(s) targets of its <a> elements, resolved
+// against page, leaving out those to page's own host.
+func Links(page, fragment string) []string {
+	base, err := url.Parse(page)
+	if err != nil {
+		return nil
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return nil
+	}
+	var links []string
+	for _, n := range nodes {
+		find(n, func(n *html.Node) bool {
+			if n.DataAtom != atom.A {
+				return false
+			}
+			u, err := url.Parse(resolve(base, attr(n, "href")))
+			if err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && !strings.EqualFold(u.Host, base.Host) {
+				u.Fragment = ""
+				links = append(links, u.String())
+			}
+			return false
+		})
+	}
+	return links
+}
+
+// Delivery is the outcome of the last mention sent from a source to a
+// target.
+type Delivery struct {
+	Source   string    `json:"source"`
+	Target   string    `json:"target"`
+	Endpoint string    `json:"endpoint"`
+	Status   string    `json:"status"`
+	Detail   string    `json:"detail"`
+	At       time.Time `json:"at"`
+}
+
+// Sender sends webmentions for the outbound links of published pages.
+type Sender struct {
+	Store *Store
+	HTTP  *http.Client
+
+	pool *worker.Pool
+}
+
+// NewSender returns a Sender recording deliveries in store and sending on
+// pool's workers. Like the receiver, it doesn't connect to loopback or
+// private addresses.
+func NewSender(store *Store, pool *worker.Pool) *Sender {
+	return &Sender{Store: store, HTTP: PublicClient(), pool: pool}
+}
+
+// Publish sends mentions, in the background, for every page that is new or
+// changed since the last call: to each of its links, and to links it no
+// longer has so their sites can drop the mention. Unchanged pages are
+// skipped.
+func (s *Sender) Publish(ctx context.Context, pages []Page) error {
+	type job struct {
+		source  string
+		targets []string
+	}
+	var jobs []job
+	for _, p := range pages {
+		links := slices.Compact(slices.Sorted(slices.Values(p.Links)))
+		links = slices.DeleteFunc(links, func(l string) bool { return l == "" })
+		sum := sha256.Sum256([]byte(p.Content + "\n" + strings.Join(links, "\n")))
+		hash := hex.EncodeToString(sum[:])
+
+		var oldHash, oldLinks string
+		err := s.Store.db.QueryRowContext(ctx, `SELECT hash, links FROM webmention_sources WHERE source = ?`, p.URL).Scan(&oldHash, &oldLinks)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("webmention: publish: %w", err)
+		}
+		if hash == oldHash {
+			continue
+		}
+		targets := links
+		for _, l := range strings.Split(oldLinks, "\n") {
+			if l != "" && !slices.Contains(targets, l) {
+				targets = append(targets, l)
+			}
+		}
+		if _, err := s.Store.db.ExecContext(ctx, `INSERT OR REPLACE INTO webmention_sources (source, hash, links) VALUES (?, ?, ?)`,
+			p.URL, hash, strings.Join(links, "\n")); err != nil {
+			return fmt.Errorf("webmention: publish: %w", err)
+		}
+		jobs = append(jobs, job{p.URL, targets})
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+	err := s.pool.Go("webmention delivery", func(ctx context.Context) error {
+		for _, j := range jobs {
+			for _, target := range j.targets {
+				ctx, cancel := context.WithTimeout(ctx, time.Minute)
+				s.send(ctx, j.source, target)
+				cancel()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("webmention: publish: %w", err)
+	}
+	return nil
+}
+
+// send discovers target's endpoint, sends the mention and records the
+// outcome.
+func (s *Sender) send(ctx context.Context, source, target string) {
+	d := Delivery{Source: source, Target: target, Status: SentFailed}
+	endpoint, err := s.discover(ctx, target)
+	switch {
+	case err != nil:
+		d.Detail = err.Error()
+	case endpoint == "":
+		d.Status = SentNoEndpoint
+	default:
+		d.Endpoint = endpoint
+		code, err := s.post(ctx, endpoint, source, target)
+		if err != nil {
+			d.Detail = err.Error()
+		} else {
+			d.Status, d.Detail = SentOK, http.StatusText(code)
+		}
+	}
+	if d.Status == SentFailed {
+		log.Printf("webmention: send %s -> %s: %s", source, target, d.Detail)
+	}
+	if err := s.Store.saveDelivery(ctx, d); err != nil {
+		log.Printf("webmention: %v", err)
+	}
+}
+
+func (s *Sender) post(ctx context.Context, endpoint, source, target string) (int, error) {
+	form := url.Values{"source": {source}, "target": {target}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.HTTP.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxSource))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return 0, fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// discover finds target's webmention endpoint from its Link header or, for
+// HTML, the first <link> or <a> with rel="webmention". It returns "" when
+// the target has none.
+func (s *Sender) discover(ctx context.Context, target string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET target: %s", resp.Status)
+	}
+	base := resp.Request.URL
+	for _, v := range resp.Header.Values("Link") {
+		if href, ok := linkHeader(v); ok {
+			return resolve(base, href), nil
+		}
+	}
+	if !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return "", nil
+	}
+	doc, err := html.Parse(io.LimitReader(resp.Body, maxSource))
+	if err != nil {
+		return "", err
+	}
+	n := find(doc, func(n *html.Node) bool {
+		hasHref := slices.ContainsFunc(n.Attr, func(a html.Attribute) bool { return a.Key == "href" })
+		return (n.DataAtom == atom.Link || n.DataAtom == atom.A) && hasHref &&
+			slices.Contains(strings.Fields(strings.ToLower(attr(n, "rel"))), "webmention")
+	})
+	if n == nil {
+		return "", nil
+	}
+	// An empty href is valid and means the target itself.
+	return resolve(base, attr(n, "href")), nil
+}
+
+// linkHeader returns the URL of the rel="webmention" entry of a Link header
+// value, which may list several comma-separated links.
+func linkHeader(v string) (string, bool) {
+	for _, link := range strings.Split(v, ",") {
+		ref, params, _ := strings.Cut(link, ";")
+		ref = strings.TrimSpace(ref)
+		if !strings.HasPrefix(ref, "<") || !strings.HasSuffix(ref, ">") {
+			continue
+		}
+		for _, p := range strings.Split(params, ";") {
+			k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+			if strings.EqualFold(k, "rel") && slices.Contains(strings.Fields(strings.ToLower(strings.Trim(v, `"`))), "webmention") {
+				return ref[1 : len(ref)-1], true
+			}
+		}
+	}
+	return "", false
+}
+
+func (s *Store) saveDelivery(ctx context.Context, d Delivery) error {
+	_, err := s.db.ExecContext(ctx, `INSERT OR REPLACE INTO webmentions_sent (source, target, endpoint, status, detail, ts) VALUES (?, ?, ?, ?, ?, ?)`,
+		d.Source, d.Target, d.Endpoint, d.Status, d.Detail, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("save delivery: %w", err)
+	}
+	return nil
+}
+
+// Deliveries returns the most recent outcomes of sent mentions.
+func (s *Store) Deliveries(ctx context.Context, limit int) ([]Delivery, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT source, target, endpoint, status, detail, ts FROM webmentions_sent ORDER BY ts DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query deliveries: %w", err)
+	}
+	defer rows.Close()
+	var list []Delivery
+	for rows.Next() {
+		var d Delivery
+		var ts int64
+		if err := rows.Scan(&d.Source, &d.Target, &d.Endpoint, &d.Status, &d.Detail, &ts); err != nil {
+			return nil, fmt.Errorf("query deliveries: %w", err)
+		}
+		d.At = time.Unix(ts, 0)
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,148 @@
+// Package webmention receives Webmentions (https://www.w3.org/TR/webmention/)
+// for pages of the site. Incoming mentions are verified in the background,
+// parsed for microformats and stored as pending until approved.
+package webmention
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/db"
+)
+
+// Moderation statuses.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// Mention types, derived from the source's h-entry properties.
+const (
+	TypeMention  = "mention"
+	TypeReply    = "reply"
+	TypeLike     = "like"
+	TypeRepost   = "repost"
+	TypeBookmark = "bookmark"
+)
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS webmentions (
+		id INTEGER PRIMARY KEY,
+		source TEXT NOT NULL,
+		target TEXT NOT NULL,
+		type TEXT NOT NULL,
+		url TEXT NOT NULL,
+		author_name TEXT NOT NULL,
+		author_url TEXT NOT NULL,
+		author_photo TEXT NOT NULL,
+		content TEXT NOT NULL,
+		published INTEGER NOT NULL,
+		received INTEGER NOT NULL,
+		status TEXT NOT NULL,
+		UNIQUE (source, target)
+	)`,
+	`CREATE INDEX IF NOT EXISTS webmentions_target ON webmentions (target, status)`,
+}
+
+// Mention is a verified webmention.
+type Mention struct {
+	ID          int64     `json:"id"`
+	Source      string    `json:"source"`
+	Target      string    `json:"target"`
+	Type        string    `json:"type"`
+	URL         string    `json:"url"`
+	AuthorName  string    `json:"author_name"`
+	AuthorURL   string    `json:"author_url"`
+	AuthorPhoto string    `json:"author_photo"`
+	Content     string    `json:"content"`
+	Published   time.Time `json:"published"`
+	Received    time.Time `json:"received"`
+	Status      string    `json:"status"`
+}
+
+// Store persists mentions in SQLite.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates the tables if needed.
+func NewStore(ctx context.Context, database *sql.DB) (*Store, error) {
+	if err := db.Migrate(ctx, database, append(schema, senderSchema...)...); err != nil {
+		return nil, fmt.Errorf("webmention: %w", err)
+	}
+	return &Store{db: database}, nil
+}
+
+// Save inserts m as pending, or updates the stored mention from the same
+// source to the same target. An update keeps the moderation status, so an
+// approved mention stays approved when its source is edited.
+func (s *Store) Save(ctx context.Context, m Mention) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO webmentions (source, target, type, url, author_name, author_url, author_photo, content, published, received, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (source, target) DO UPDATE SET
+			type = excluded.type, url = excluded.url,
+			author_name = excluded.author_name, author_url = excluded.author_url, author_photo = excluded.author_photo,
+			content = excluded.content, published = excluded.published, received = excluded.received`,
+		m.Source, m.Target, m.Type, m.URL, m.AuthorName, m.AuthorURL, m.AuthorPhoto, m.Content,
+		m.Published.Unix(), time.Now().Unix(), StatusPending)
+	if err != nil {
+		return fmt.Errorf("save webmention: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the mention of target by source, for sources that no
+// longer link to it.
+func (s *Store) Remove(ctx context.Context, source, target string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM webmentions WHERE source = ? AND target = ?`, source, target); err != nil {
+		return fmt.Errorf("remove webmention: %w", err)
+	}
+	return nil
+}
+
+// Approved returns the approved mentions of target, oldest first.
+func (s *Store) Approved(ctx context.Context, target string) ([]Mention, error) {
+	return s.query(ctx, `WHERE target = ? AND status = ? ORDER BY published, id`, target, StatusApproved)
+}
+
+// Pending returns the mentions awaiting moderation, newest first.
+func (s *Store) Pending(ctx context.Context) ([]Mention, error) {
+	return s.query(ctx, `WHERE status = ? ORDER BY received DESC, id DESC`, StatusPending)
+}
+
+// SetStatus moderates the mention with the given ID. It reports whether the
+// mention exists.
+func (s *Store) SetStatus(ctx context.Context, id int64, status string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `UPDATE webmentions SET status = ? WHERE id = ?`, status, id)
+	if err != nil {
+		return false, fmt.Errorf("moderate webmention: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (s *Store) query(ctx context.Context, where string, args ...any) ([]Mention, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, source, target, type, url, author_name, author_url, author_photo, content, published, received, status
+		FROM webmentions `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query webmentions: %w", err)
+	}
+	defer rows.Close()
+	var list []Mention
+	for rows.Next() {
+		var m Mention
+		var published, received int64
+		if err := rows.Scan(&m.ID, &m.Source, &m.Target, &m.Type, &m.URL, &m.AuthorName, &m.AuthorURL,
+			&m.AuthorPhoto, &m.Content, &published, &received, &m.Status); err != nil {
+			return nil, fmt.Errorf("query webmentions: %w", err)
+		}
+		m.Published, m.Received = time.Unix(published, 0), time.Unix(received, 0)
+		list = append(list, m)
+	}
+	return list, rows.Err()
+}
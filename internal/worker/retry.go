@@ -0,0 +1,28 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Retry calls f until it succeeds, at most attempts times, waiting backoff
+// before the first retry and doubling the wait after each failure. It
+// returns f's last error, or gives up early when ctx is canceled.
+func Retry(ctx context.Context, attempts int, backoff time.Duration, f func(context.Context) error) error {
+	var err error
+	for i := range max(attempts, 1) {
+		if i > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return fmt.Errorf("%w (gave up: %w)", err, ctx.Err())
+			}
+			backoff *= 2
+		}
+		if err = f(ctx); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w (after %d attempts)", err, max(attempts, 1))
+}
@@ -0,0 +1,167 @@
+// Package worker runs the site's background tasks, such as federation and
+// webmention deliveries, alerts and scheduled jobs, on a bounded pool of
+// goroutines shared by every feature.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/fpatron/portfolio/internal/metrics"
+)
+
+// ErrFull is returned by Go when the queue has no room for the task.
+var ErrFull = errors.New("worker pool: queue is full")
+
+// ErrClosed is returned for tasks submitted after Close.
+var ErrClosed = errors.New("worker pool: closed")
+
+// Pool runs tasks on a fixed number of workers. A task that panics is
+// recovered and counted as failed; the pool and the other tasks carry on.
+type Pool struct {
+	tasks  chan task
+	ctx    context.Context // canceled when Close gives up waiting
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// task is a unit of work. done, when set, receives its result.
+type task struct {
+	name string
+	ctx  context.Context
+	run  func(context.Context) error
+	done chan error
+}
+
+// New starts a Pool of size workers, with room for queue tasks waiting
+// for one.
+func New(size, queue int) *Pool {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{tasks: make(chan task, max(queue, 0)), ctx: ctx, cancel: cancel}
+	for range max(size, 1) {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+// Go queues f to run in the background under name, which labels its
+// metrics. It returns ErrFull rather than wait when the queue is full. An
+// error f returns is logged as is, so it should say what failed.
+func (p *Pool) Go(name string, f func(context.Context) error) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrClosed
+	}
+	select {
+	case p.tasks <- task{name: name, ctx: p.ctx, run: f}:
+		metrics.WorkerQueued.Inc()
+		return nil
+	default:
+		metrics.WorkerTasks.WithLabelValues(name, metrics.TaskDropped).Inc()
+		return ErrFull
+	}
+}
+
+// Do runs f on a worker, waiting for room in the queue if needed, and
+// returns its error. f's context is canceled with ctx, or when Close gives
+// up waiting for running tasks.
+func (p *Pool) Do(ctx context.Context, name string, f func(context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(p.ctx, cancel)()
+
+	t := task{name: name, ctx: ctx, run: f, done: make(chan error, 1)}
+	if err := p.enqueue(ctx, t); err != nil {
+		return err
+	}
+	return <-t.done
+}
+
+func (p *Pool) enqueue(ctx context.Context, t task) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrClosed
+	}
+	select {
+	case p.tasks <- t:
+		metrics.WorkerQueued.Inc()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting tasks and waits for the queued and running ones
+// until ctx is done, then cancels their context and waits for them to
+// return.
+func (p *Pool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.tasks)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	defer p.cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		<-done
+		return fmt.Errorf("worker pool: %w", ctx.Err())
+	}
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for t := range p.tasks {
+		metrics.WorkerQueued.Dec()
+		err := p.run(t)
+		if t.done != nil {
+			t.done <- err
+		} else if err != nil && !errors.Is(err, context.Canceled) {
+			log.Print(err)
+		}
+	}
+}
+
+// run runs t, recovering from a panic, and records the outcome.
+func (p *Pool) run(t task) (err error) {
+	if t.ctx.Err() != nil {
+		metrics.WorkerTasks.WithLabelValues(t.name, metrics.TaskCanceled).Inc()
+		return t.ctx.Err()
+	}
+	metrics.WorkerBusy.Inc()
+	start := time.Now()
+	defer func() {
+		metrics.WorkerBusy.Dec()
+		metrics.WorkerTaskDuration.WithLabelValues(t.name).Observe(time.Since(start).Seconds())
+		result := metrics.TaskOK
+		if v := recover(); v != nil {
+			log.Printf("worker: %s: panic: %v\n%s", t.name, v, debug.Stack())
+			err = fmt.Errorf("panic: %v", v)
+			result = metrics.TaskPanic
+		} else if err != nil {
+			result = metrics.TaskError
+		}
+		metrics.WorkerTasks.WithLabelValues(t.name, result).Inc()
+	}()
+	return t.run(t.ctx)
+}
@@ -0,0 +1,146 @@
+// Package workhours tells visitors what time it is where the site's owner
+// is, whether they are at work, and so how soon to expect a reply.
+package workhours
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Hours are the working hours: from Start to End after midnight on Days,
+// in Location.
+type Hours struct {
+	Location   *time.Location
+	Start, End time.Duration
+	Days       [7]bool // by time.Weekday
+	// Response is how soon messages are answered during working hours,
+	// e.g. "a few hours".
+	Response string
+}
+
+// Status is where the working hours stand at a point in time.
+type Status struct {
+	Time    time.Time `json:"time"` // in the working hours' location
+	Zone    string    `json:"zone"`
+	Working bool      `json:"working"`
+	// Next is when Working changes, or zero when it never does.
+	Next time.Time `json:"next,omitzero"`
+	// Response is the reply to expect, as a sentence.
+	Response string `json:"response"`
+}
+
+// Parse reads the time zone, hours such as "09:00-17:30" and days such as
+// "mon-fri" or "mon,wed,fri".
+func Parse(zone, hours, days, response string) (*Hours, error) {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, fmt.Errorf("time zone: %w", err)
+	}
+	h := &Hours{Location: loc, Response: response}
+	from, to, ok := strings.Cut(hours, "-")
+	if !ok {
+		return nil, fmt.Errorf("hours %q: want start-end, e.g. 09:00-17:00", hours)
+	}
+	if h.Start, err = clock(from); err != nil {
+		return nil, fmt.Errorf("hours %q: %w", hours, err)
+	}
+	if h.End, err = clock(to); err != nil {
+		return nil, fmt.Errorf("hours %q: %w", hours, err)
+	}
+	if h.End <= h.Start {
+		return nil, fmt.Errorf("hours %q: the end is not after the start", hours)
+	}
+	for _, part := range strings.Split(days, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		first, last := weekday(from), weekday(to)
+		if !isRange {
+			last = first
+		}
+		if first < 0 || last < 0 {
+			return nil, fmt.Errorf("days %q: use mon, tue, wed, thu, fri, sat and sun", days)
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			h.Days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return h, nil
+}
+
+func clock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time such as 09:00", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+var weekdays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// weekday returns the time.Weekday of a day's name or its first three
+// letters or more, or -1.
+func weekday(s string) int {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for i, name := range weekdays {
+		if len(s) >= 3 && strings.HasPrefix(name, s) {
+			return i
+		}
+	}
+	return -1
+}
+
+// At returns the status at t.
+func (h *Hours) At(t time.Time) Status {
+	t = t.In(h.Location)
+	s := Status{Time: t, Zone: t.Format("MST")}
+	// Working days are a week apart at most, so the next start or end of
+	// work is within eight days.
+	y, m, d := t.Date()
+	for i := 0; i <= 7 && s.Next.IsZero(); i++ {
+		day := time.Date(y, m, d+i, 0, 0, 0, 0, h.Location)
+		if !h.Days[day.Weekday()] {
+			continue
+		}
+		// Adding minutes to the wall clock keeps the hours right on the
+		// days clocks change.
+		start := time.Date(y, m, d+i, 0, int(h.Start/time.Minute), 0, 0, h.Location)
+		end := time.Date(y, m, d+i, 0, int(h.End/time.Minute), 0, 0, h.Location)
+		if t.Before(start) {
+			s.Next = start
+		} else if t.Before(end) {
+			s.Working, s.Next = true, end
+		}
+	}
+	switch {
+	case s.Working:
+		s.Response = "Usually replies within " + h.Response + "."
+	case s.Next.IsZero():
+		s.Response = "Replies may take a while."
+	case s.Next.YearDay() == t.YearDay():
+		s.Response = fmt.Sprintf("Off for now, back at %s (in %s).", s.Next.Format("15:04"), until(s.Next.Sub(t)))
+	case s.Next.Sub(t) < 24*time.Hour:
+		s.Response = fmt.Sprintf("Off for now, back tomorrow at %s (in %s).", s.Next.Format("15:04"), until(s.Next.Sub(t)))
+	default:
+		s.Response = fmt.Sprintf("Off for now, back %s at %s.", s.Next.Format("Monday"), s.Next.Format("15:04"))
+	}
+	return s
+}
+
+// until rounds d to minutes under an hour and to hours above.
+func until(d time.Duration) string {
+	if d < time.Hour {
+		n := max(int(d.Round(time.Minute)/time.Minute), 1)
+		if n == 1 {
+			return "1 minute"
+		}
+		return fmt.Sprintf("%d minutes", n)
+	}
+	n := int(d.Round(time.Hour) / time.Hour)
+	if n == 1 {
+		return "about an hour"
+	}
+	return fmt.Sprintf("about %d hours", n)
+}
@@ -0,0 +1,95 @@
+// Package youtube reads a channel's recent uploads from its public RSS feed,
+// which needs no API key.
+package youtube
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// FeedURL is the channel feed endpoint.
+const FeedURL = "https://www.youtube.com/feeds/videos.xml"
+
+// Video is an upload listed in the feed.
+type Video struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Thumbnail   string    `json:"thumbnail"`
+	Description string    `json:"description,omitempty"`
+	Views       int       `json:"views"`
+	Published   time.Time `json:"published"`
+}
+
+// Channel reads one channel's feed.
+type Channel struct {
+	id     string
+	feed   string
+	client *http.Client
+}
+
+// NewChannel returns a Channel for the channel ID, e.g. "UC...".
+func NewChannel(id string) *Channel {
+	return &Channel{id: id, feed: FeedURL, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+type feed struct {
+	Entries []struct {
+		VideoID   string    `xml:"http://www.youtube.com/xml/schemas/2015 videoId"`
+		Title     string    `xml:"title"`
+		Published time.Time `xml:"published"`
+		Link      struct {
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+		Group struct {
+			Thumbnail struct {
+				URL string `xml:"url,attr"`
+			} `xml:"thumbnail"`
+			Description string `xml:"description"`
+			Community   struct {
+				Statistics struct {
+					Views int `xml:"views,attr"`
+				} `xml:"statistics"`
+			} `xml:"community"`
+		} `xml:"http://search.yahoo.com/mrss/ group"`
+	} `xml:"entry"`
+}
+
+// Latest returns up to n of the channel's most recent uploads, newest
+// first. The feed lists the last 15.
+func (c *Channel) Latest(ctx context.Context, n int) ([]Video, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.feed+"?channel_id="+url.QueryEscape(c.id), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("youtube: fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("youtube: fetch feed: %s", resp.Status)
+	}
+	var f feed
+	if err := xml.NewDecoder(resp.Body).Decode(&f); err != nil {
+		return nil, fmt.Errorf("youtube: decode feed: %w", err)
+	}
+
+	var list []Video
+	for _, e := range f.Entries[:min(n, len(f.Entries))] {
+		list = append(list, Video{
+			ID:          e.VideoID,
+			Title:       e.Title,
+			URL:         e.Link.Href,
+			Thumbnail:   e.Group.Thumbnail.URL,
+			Description: e.Group.Description,
+			Views:       e.Group.Community.Statistics.Views,
+			Published:   e.Published,
+		})
+	}
+	return list, nil
+}
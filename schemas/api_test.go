@@ -0,0 +1,126 @@
+package schemas_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	portfolio "github.com/fpatron/portfolio"
+	"github.com/fpatron/portfolio/golden"
+	"github.com/fpatron/portfolio/internal/github"
+	"github.com/fpatron/portfolio/internal/handler"
+	"github.com/fpatron/portfolio/internal/jsonschema"
+	"github.com/fpatron/portfolio/internal/uptime"
+	"github.com/fpatron/portfolio/schemas"
+)
+
+// TestAPISchemas checks that the schemas in api/ match the response types.
+// A difference means a struct change altered the API: run the test with
+// -update to publish the new schemas, after making sure clients can take
+// it.
+func TestAPISchemas(t *testing.T) {
+	generated := map[string]*jsonschema.Schema{schemas.ErrorName: generate(schemas.ErrorName, "Error response", handler.APIError{})}
+	for path, v := range handler.APIResponses {
+		name := schemas.APIName(path)
+		generated[name] = generate(name, "GET "+path, v)
+	}
+	for name, s := range generated {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetIndent("", "  ")
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(s); err != nil {
+				t.Fatal(err)
+			}
+			golden.Compare(t, name, buf.Bytes())
+		})
+	}
+}
+
+// generate returns the schema of the type of v, published as name.
+func generate(name, title string, v any) *jsonschema.Schema {
+	s := jsonschema.Generate(reflect.TypeOf(v))
+	s.Schema = "https://json-schema.org/draft/2020-12/schema"
+	s.ID = schemas.Path + name
+	s.Title = title
+	return s
+}
+
+// TestAPIResponses requests every API route, with the data files and with
+// the integrations' data, in successful and failing cases, and checks each
+// response against the published schema.
+func TestAPIResponses(t *testing.T) {
+	h, err := handler.New(portfolio.FS, handler.Options{BaseURL: "http://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	h.PublicRoutes(mux)
+
+	unavailable := []string{"/api/github/stats", "/api/status"}
+	requests := append([]string{
+		"/api/projects",
+		"/api/projects?tag=go&sort=title&limit=1",
+		"/api/projects?offset=1000",
+		"/api/projects?limit=0",
+		"/api/projects?sort=date",
+		"/api/projects?sort=stars",
+		"/api/experience",
+		"/api/experience?type=work&sort=date&limit=1&offset=1",
+		"/api/experience?type=education",
+		"/api/experience?offset=-1",
+		"/api/posts",
+		"/api/posts?tag=go&sort=title&limit=1",
+		"/api/posts?sort=stars",
+		"/api/posts?limit=x",
+		"/api/search?q=go",
+		"/api/search?q=c%2B%2B&type=skill&type=project&limit=2",
+		"/api/search",
+		"/api/commands",
+		"/api/commands?group=project&limit=1",
+		"/api/commands?limit=x",
+	}, unavailable...)
+	for _, target := range requests {
+		check(t, mux, target)
+	}
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	h.SetGitHubStats(github.Stats{Contributions: -1, Repos: 3, Stars: 10, Languages: []github.Language{{Name: "Go", Percent: 75}}, UpdatedAt: now})
+	h.SetUptime(&uptime.Status{State: "up", Uptime: 99.9, Source: "healthchecks", CheckedAt: now})
+	for _, target := range unavailable {
+		check(t, mux, target)
+	}
+
+	for path := range handler.APIResponses {
+		if !slices.ContainsFunc(requests, func(r string) bool { return strings.HasPrefix(r, path) }) {
+			t.Errorf("%s is not requested", path)
+		}
+	}
+}
+
+func check(t *testing.T, mux *http.ServeMux, target string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	mediaType, _, _ := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	if mediaType != "application/json" {
+		t.Errorf("GET %s: %d with Content-Type %q, want JSON", target, rec.Code, mediaType)
+		return
+	}
+	path, _, _ := strings.Cut(target, "?")
+	s, err := schemas.Response(path, rec.Code)
+	if s == nil || err != nil {
+		t.Fatalf("GET %s: no schema: %v", target, err)
+	}
+	if err := s.ValidateJSON(rec.Body.Bytes()); err != nil {
+		t.Errorf("GET %s: %d response does not conform to its schema:\n%v\n%s", target, rec.Code, err, rec.Body)
+	}
+}
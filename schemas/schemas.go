@@ -0,0 +1,169 @@
+// Package schemas holds the JSON Schemas of the data files, which
+// "portfolio validate" checks the files against, and of the JSON API
+// responses, which the contract tests check the handlers against. The
+// server publishes them under /schemas/ for editors and API clients.
+package schemas
+
+//go:generate go test -run TestAPISchemas -update
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/fpatron/portfolio/internal/jsonschema"
+)
+
+// FS holds a schema per data file, named after it: projects.schema.json
+// describes data/projects.json. The schemas of the API responses are in
+// api/, generated from handler.APIResponses.
+//
+//go:embed *.schema.json api/*.schema.json
+var FS embed.FS
+
+// Path is the URL prefix the schemas are served under.
+const Path = "/schemas/"
+
+// ErrorName is the name of the schema of the API's error responses.
+const ErrorName = "api/error.schema.json"
+
+// Name returns the name of the schema of a data file such as
+// data/projects.json.
+func Name(dataFile string) string {
+	return strings.TrimSuffix(path.Base(dataFile), ".json") + ".schema.json"
+}
+
+// APIName returns the name of the schema of the responses of an API path:
+// api/github-stats.schema.json for /api/github/stats.
+func APIName(apiPath string) string {
+	return "api/" + strings.ReplaceAll(strings.TrimPrefix(apiPath, "/api/"), "/", "-") + ".schema.json"
+}
+
+// compiled holds every schema of FS by name.
+var compiled = sync.OnceValues(func() (map[string]*jsonschema.Schema, error) {
+	names, err := fs.Glob(FS, "*.schema.json")
+	if err != nil {
+		return nil, err
+	}
+	api, err := fs.Glob(FS, "api/*.schema.json")
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]*jsonschema.Schema)
+	for _, name := range append(names, api...) {
+		b, err := fs.ReadFile(FS, name)
+		if err != nil {
+			return nil, err
+		}
+		if m[name], err = jsonschema.Compile(b); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return m, nil
+})
+
+// Get returns the compiled schema name, or nil if there is none.
+func Get(name string) (*jsonschema.Schema, error) {
+	m, err := compiled()
+	if err != nil {
+		return nil, err
+	}
+	return m[name], nil
+}
+
+// Validate checks the data file name in fsys against its schema.
+func Validate(fsys fs.FS, name string) error {
+	s, err := Get(Name(name))
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return fmt.Errorf("no schema for %s", name)
+	}
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	return s.ValidateJSON(data)
+}
+
+// Response returns the schema a response of the API path with the given
+// status conforms to, or nil when the path is not part of the API.
+func Response(apiPath string, status int) (*jsonschema.Schema, error) {
+	s, err := Get(APIName(apiPath))
+	if s == nil || err != nil {
+		return nil, err
+	}
+	if status >= 400 {
+		return Get(ErrorName)
+	}
+	return s, nil
+}
+
+// CheckResponses logs the problems of the API responses of next that do
+// not conform to their schemas. The responses themselves pass through
+// unchanged.
+func CheckResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s, _ := Get(APIName(r.URL.Path)); s == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &copyWriter{ResponseWriter: w}
+		next.ServeHTTP(cw, r)
+		if !cw.json {
+			return
+		}
+		s, err := Response(r.URL.Path, cw.status)
+		if err == nil {
+			err = s.ValidateJSON(cw.buf.Bytes())
+		}
+		var joined interface{ Unwrap() []error }
+		if errors.As(err, &joined) {
+			for _, e := range joined.Unwrap() {
+				log.Printf("api: %s: %v", r.URL.Path, e)
+			}
+		} else if err != nil {
+			log.Printf("api: %s: %v", r.URL.Path, err)
+		}
+	})
+}
+
+// copyWriter keeps a copy of a JSON response body.
+type copyWriter struct {
+	http.ResponseWriter
+	status int
+	json   bool
+	buf    bytes.Buffer
+}
+
+func (w *copyWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+		mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+		w.json = mediaType == "application/json" && w.Header().Get("Content-Encoding") == ""
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *copyWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.json {
+		w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *copyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
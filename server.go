@@ -0,0 +1,1036 @@
+package portfolio
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/fpatron/portfolio/internal/abtest"
+	"github.com/fpatron/portfolio/internal/activitypub"
+	"github.com/fpatron/portfolio/internal/analytics"
+	"github.com/fpatron/portfolio/internal/auth"
+	"github.com/fpatron/portfolio/internal/booking"
+	"github.com/fpatron/portfolio/internal/books"
+	"github.com/fpatron/portfolio/internal/captcha"
+	"github.com/fpatron/portfolio/internal/content"
+	"github.com/fpatron/portfolio/internal/db"
+	"github.com/fpatron/portfolio/internal/digest"
+	"github.com/fpatron/portfolio/internal/geoip"
+	"github.com/fpatron/portfolio/internal/github"
+	"github.com/fpatron/portfolio/internal/grpcserver"
+	"github.com/fpatron/portfolio/internal/guestbook"
+	"github.com/fpatron/portfolio/internal/handler"
+	"github.com/fpatron/portfolio/internal/handler/contact"
+	"github.com/fpatron/portfolio/internal/htmlcheck"
+	"github.com/fpatron/portfolio/internal/i18n"
+	"github.com/fpatron/portfolio/internal/images"
+	"github.com/fpatron/portfolio/internal/inbox"
+	"github.com/fpatron/portfolio/internal/indieauth"
+	"github.com/fpatron/portfolio/internal/likes"
+	"github.com/fpatron/portfolio/internal/livereload"
+	"github.com/fpatron/portfolio/internal/mailer"
+	"github.com/fpatron/portfolio/internal/mastodon"
+	"github.com/fpatron/portfolio/internal/metrics"
+	"github.com/fpatron/portfolio/internal/notify"
+	pb "github.com/fpatron/portfolio/internal/pb/portfoliov1"
+	"github.com/fpatron/portfolio/internal/pkgstats"
+	"github.com/fpatron/portfolio/internal/preview"
+	"github.com/fpatron/portfolio/internal/ratelimit"
+	"github.com/fpatron/portfolio/internal/repos"
+	"github.com/fpatron/portfolio/internal/scheduler"
+	"github.com/fpatron/portfolio/internal/shortlinks"
+	"github.com/fpatron/portfolio/internal/sitefs"
+	"github.com/fpatron/portfolio/internal/sse"
+	"github.com/fpatron/portfolio/internal/stackexchange"
+	"github.com/fpatron/portfolio/internal/statsproxy"
+	"github.com/fpatron/portfolio/internal/strava"
+	"github.com/fpatron/portfolio/internal/webmention"
+	"github.com/fpatron/portfolio/internal/worker"
+	"github.com/fpatron/portfolio/internal/workhours"
+	"github.com/fpatron/portfolio/internal/youtube"
+	"github.com/fpatron/portfolio/schemas"
+)
+
+// Server is the portfolio site with its integrations and background jobs.
+// Run it with Start, or mount Handler on another server's mux.
+type Server struct {
+	cfg    Config
+	fsys   fs.FS
+	dev    string
+	log    *log.Logger
+	routes []func(*http.ServeMux)
+	now    func() time.Time
+
+	h        *handler.Handler
+	hooks    *handler.Hooks
+	events   *sse.Broker
+	jobs     *scheduler.Scheduler
+	pool     *worker.Pool
+	rpc      *grpcserver.Server
+	tenants  *tenantRouter
+	recorder *analytics.Recorder
+	root     http.Handler
+	closers  []func() error
+
+	reloadMu  sync.Mutex
+	closeOnce sync.Once
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	httpSrv *http.Server
+	grpcSrv *grpc.Server
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithFS serves the templates, static files and data files of fsys instead
+// of the built-in ones. fsys has the layout of FS.
+func WithFS(fsys fs.FS) Option {
+	return func(s *Server) { s.fsys = fsys }
+}
+
+// WithConfig sets the server's settings. Without it, they are read from
+// the environment.
+func WithConfig(cfg Config) Option {
+	return func(s *Server) { s.cfg = cfg }
+}
+
+// WithLogger sets the logger for the server's messages and request log.
+func WithLogger(l *log.Logger) Option {
+	return func(s *Server) { s.log = l }
+}
+
+// WithRoutes registers f to add routes to the site's mux, next to the
+// built-in ones.
+func WithRoutes(f func(*http.ServeMux)) Option {
+	return func(s *Server) { s.routes = append(s.routes, f) }
+}
+
+// WithClock makes now the clock of the analytics, IndieAuth, the local
+// time and the contact form's fill time and rate limit checks, for tests
+// that need recorded times or expiries to be predictable.
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) { s.now = now }
+}
+
+// WithDev serves the site from the directory dir and reloads open pages
+// when its templates, static files or data files change.
+func WithDev(dir string) Option {
+	return func(s *Server) {
+		s.dev = dir
+		s.fsys = os.DirFS(dir)
+	}
+}
+
+// New creates a Server and its integrations. It opens the database, but
+// starts nothing until Start is called.
+func New(opts ...Option) (*Server, error) {
+	s := &Server{cfg: ConfigFromEnv(), fsys: FS, log: log.Default()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if err := s.init(); err != nil {
+		s.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// init wires the handler, the integrations configured in s.cfg and the
+// routes.
+func (s *Server) init() error {
+	ctx := context.Background()
+	c := s.cfg
+
+	// hooks carries the optional integrations: their routes, middleware,
+	// contact delivery and publishing.
+	hooks := &handler.Hooks{}
+	s.hooks = hooks
+
+	var database *sql.DB
+	if c.DatabasePath != "" {
+		var err error
+		database, err = db.Open(c.DatabasePath)
+		if err != nil {
+			return fmt.Errorf("open database: %w", err)
+		}
+		s.closers = append(s.closers, database.Close)
+
+		s.recorder, err = analytics.New(ctx, database)
+		if err != nil {
+			return fmt.Errorf("initialize analytics: %w", err)
+		}
+		s.recorder.Now = s.now
+		if geoPath := c.getenv("GEOIP_DATABASE"); geoPath != "" {
+			geo, err := geoip.Open(geoPath)
+			if err != nil {
+				return fmt.Errorf("open geoip database: %w", err)
+			}
+			s.closers = append(s.closers, geo.Close)
+			s.recorder.Countries = geo
+		}
+		hooks.ExtraRoutes(func(mux *http.ServeMux) { mux.HandleFunc("GET /trap", s.recorder.Honeypot) })
+		hooks.OnRequest(s.recorder.Middleware)
+	}
+
+	var stats *statsproxy.Proxy
+	if upstream := c.getenv("STATS_UPSTREAM"); upstream != "" {
+		var err error
+		stats, err = statsproxy.New(statsproxy.Config{
+			Provider: c.getenv("STATS_PROVIDER"),
+			Upstream: upstream,
+			SiteID:   c.getenv("STATS_SITE_ID"),
+		})
+		if err != nil {
+			return fmt.Errorf("initialize stats proxy: %w", err)
+		}
+		hooks.ExtraRoutes(stats.Register)
+	}
+
+	// The pool runs the background work of every integration. It is
+	// closed before the database, which its tasks use.
+	pool := worker.New(c.envInt("WORKER_POOL_SIZE", 4), c.envInt("WORKER_QUEUE_SIZE", 100))
+	s.pool = pool
+	s.closers = append(s.closers, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return pool.Close(ctx)
+	})
+	jobs := scheduler.New(pool)
+	s.jobs = jobs
+	opts := handler.Options{
+		Hooks:     hooks,
+		BaseURL:   c.BaseURL,
+		UTMSource: c.getenv("OUTBOUND_UTM_SOURCE"),
+		Jobs:      jobs,
+	}
+	opts.Strict, _ = strconv.ParseBool(c.getenv("STRICT_TEMPLATES"))
+	opts.Feed = handler.FeedOptions{
+		Title:    c.getenv("FEED_TITLE"),
+		Posts:    c.envInt("FEED_POSTS", 20),
+		Projects: c.envInt("FEED_PROJECTS", 10),
+	}
+	opts.Drafts, _ = strconv.ParseBool(c.getenv("BLOG_DRAFTS"))
+	opts.ContactGuard = contact.NewGuard(
+		c.envDuration("CONTACT_MIN_FILL_TIME", 3*time.Second),
+		c.envInt("CONTACT_RATE_LIMIT", 5),
+		c.envDuration("CONTACT_RATE_WINDOW", time.Hour),
+	)
+	opts.ContactGuard.Now = s.now
+	if n := c.envInt("CONTACT_REQUESTS_PER_MINUTE", 2); n > 0 {
+		opts.ContactLimiter = ratelimit.PerMinute(n, c.envInt("CONTACT_BURST", 3))
+		opts.ContactLimiter.Now = s.now
+	}
+	if name := c.getenv("CAPTCHA_PROVIDER"); name != "" {
+		v, err := captcha.New(name, c.getenv("CAPTCHA_SITE_KEY"), c.getenv("CAPTCHA_SECRET_KEY"))
+		if err != nil {
+			return fmt.Errorf("invalid CAPTCHA_PROVIDER: %w", err)
+		}
+		opts.Captcha = v
+	}
+	for _, lang := range strings.Split(c.getenv("LANGUAGES"), ",") {
+		if lang = strings.TrimSpace(lang); lang == "" {
+			continue
+		}
+		if len(opts.Languages) > 0 {
+			if info, err := fs.Stat(s.fsys, "data/"+lang); err != nil || !info.IsDir() {
+				return fmt.Errorf("invalid LANGUAGES: no data/%s directory for %q", lang, lang)
+			}
+		}
+		opts.Languages = append(opts.Languages, lang)
+	}
+	if len(opts.Languages) > 0 {
+		opts.Lang = opts.Languages[0]
+	}
+	opts.Drafts = opts.Drafts || s.dev != ""
+	for _, name := range strings.Split(c.getenv("DISABLED_SECTIONS"), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if !slices.Contains(handler.SectionNames(), name) {
+			return fmt.Errorf("invalid DISABLED_SECTIONS: unknown section %q, want one of %s", name, strings.Join(handler.SectionNames(), ", "))
+		}
+		opts.DisabledSections = append(opts.DisabledSections, name)
+	}
+	for _, path := range strings.Split(c.getenv("DISABLED_ROUTES"), ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("invalid DISABLED_ROUTES: %q is not a path", path)
+		}
+		if path != "/" {
+			path = strings.TrimSuffix(path, "/")
+		}
+		opts.DisabledRoutes = append(opts.DisabledRoutes, path)
+	}
+	if zone := c.getenv("LOCAL_TIMEZONE"); zone != "" {
+		hours, err := workhours.Parse(zone, cmp.Or(c.getenv("WORKING_HOURS"), "09:00-17:00"), cmp.Or(c.getenv("WORKING_DAYS"), "mon-fri"), cmp.Or(c.getenv("RESPONSE_TIME"), "a few hours"))
+		if err != nil {
+			return fmt.Errorf("invalid LOCAL_TIMEZONE, WORKING_HOURS or WORKING_DAYS: %w", err)
+		}
+		opts.Hours = hours
+	}
+	opts.Now = s.now
+	if stats != nil {
+		snippet, err := stats.Snippet()
+		if err != nil {
+			return fmt.Errorf("render stats snippet: %w", err)
+		}
+		opts.AnalyticsScript = snippet
+	}
+	opts.Analytics = s.recorder
+	if s.recorder != nil {
+		jobs.Add(scheduler.Job{
+			Name:      "analytics aggregation",
+			Schedule:  scheduler.Every(time.Hour),
+			Immediate: true,
+			Run:       s.recorder.Aggregate,
+		})
+	}
+	events := sse.NewBroker()
+	s.events = events
+	opts.Events = events
+	var mail *mailer.Mailer
+	if host := c.getenv("SMTP_HOST"); host != "" {
+		port := c.envInt("SMTP_PORT", 587)
+		startTLS, err := strconv.ParseBool(cmp.Or(c.getenv("SMTP_STARTTLS"), strconv.FormatBool(port != 465)))
+		if err != nil {
+			return fmt.Errorf("invalid SMTP_STARTTLS: %w", err)
+		}
+		mail = mailer.New(mailer.Config{
+			Host:     host,
+			Port:     port,
+			Username: c.getenv("SMTP_USERNAME"),
+			Password: c.getenv("SMTP_PASSWORD"),
+			From:     c.getenv("SMTP_FROM"),
+			StartTLS: startTLS,
+		})
+	} else if user, pass := c.getenv("GMAIL_USER"), c.getenv("GMAIL_APP_PASSWORD"); user != "" && pass != "" {
+		mail = mailer.Gmail(user, pass)
+	}
+	var emails *template.Template
+	if mail != nil {
+		if mail.From() == "" {
+			return errors.New("SMTP_FROM or SMTP_USERNAME is required to send mail")
+		}
+		var err error
+		if emails, err = mailer.ParseTemplates(s.fsys); err != nil {
+			return fmt.Errorf("load email templates: %w", err)
+		}
+		queue := &mailer.Queue{
+			Mailer:  mail,
+			Pool:    pool,
+			Backoff: c.envDuration("SMTP_RETRY_BACKOFF", 30*time.Second),
+		}
+		autoReply, _ := strconv.ParseBool(c.getenv("CONTACT_AUTOREPLY"))
+		var emailTexts *texttemplate.Template
+		if autoReply {
+			if emailTexts, err = mailer.ParseTextTemplates(s.fsys); err != nil {
+				return fmt.Errorf("load email templates: %w", err)
+			}
+		}
+		hooks.OnContactSubmission(handler.MailContact(queue, emails, emailTexts, autoReply))
+	}
+	// Contact notifications are queued and retried like contact email.
+	var texts *texttemplate.Template
+	notifyContact := func(name string, n notify.Notifier) error {
+		if texts == nil {
+			var err error
+			if texts, err = notify.ParseTemplates(s.fsys); err != nil {
+				return fmt.Errorf("load notification templates: %w", err)
+			}
+		}
+		queue := &notify.Queue{
+			Notifier: n,
+			Pool:     pool,
+			Name:     name,
+			Backoff:  c.envDuration("NOTIFY_RETRY_BACKOFF", 30*time.Second),
+		}
+		hooks.OnContactSubmission(handler.NotifyContact(queue, texts))
+		return nil
+	}
+	if u := c.getenv("CONTACT_WEBHOOK_URL"); u != "" {
+		webhook, err := notify.NewWebhook(u, c.getenv("CONTACT_WEBHOOK_PAYLOAD"))
+		if err != nil {
+			return fmt.Errorf("configure contact webhook: %w", err)
+		}
+		if err := notifyContact("webhook", webhook); err != nil {
+			return err
+		}
+	}
+	var alerts *notify.Alerts
+	if token, chat := c.getenv("TELEGRAM_BOT_TOKEN"), c.getenv("TELEGRAM_CHAT_ID"); token != "" && chat != "" {
+		telegram := notify.NewTelegram(token, chat)
+		if err := notifyContact("telegram", telegram); err != nil {
+			return err
+		}
+		alerts = notify.NewAlerts(telegram, c.envDuration("ALERT_COOLDOWN", time.Hour), pool)
+		hooks.OnRequest(func(next http.Handler) http.Handler { return alertMiddleware(alerts, next) })
+	}
+
+	if repo, token := c.getenv("GITHUB_DISCUSSIONS_REPO"), c.getenv("GITHUB_TOKEN"); repo != "" && token != "" {
+		gc := github.NewClient(c.getenv("GITHUB_USER"), token)
+		if api := c.getenv("GITHUB_API_URL"); api != "" {
+			gc.API = strings.TrimSuffix(api, "/")
+		}
+		opts.Discussions = github.NewDiscussions(gc, repo, c.getenv("GITHUB_DISCUSSIONS_CATEGORY"), c.envDuration("COMMENTS_CACHE_TTL", 10*time.Minute))
+	}
+
+	imageDir := cmp.Or(c.getenv("IMAGE_CACHE_DIR"), filepath.Join(os.TempDir(), "portfolio-images"))
+	imageCache, err := images.New(imageDir, c.envInt("IMAGE_MAX_WIDTH", 1600))
+	if err != nil {
+		return fmt.Errorf("initialize image cache: %w", err)
+	}
+	opts.Images = imageCache
+	hooks.OnDataLoad(handler.LocalImages(imageCache))
+	hooks.ExtraRoutes(func(mux *http.ServeMux) { mux.Handle("GET "+images.Path+"{key}", imageCache) })
+	pruneSchedule, err := scheduler.Cron(cmp.Or(c.getenv("IMAGE_PRUNE_SCHEDULE"), "0 4 * * *"))
+	if err != nil {
+		return fmt.Errorf("invalid IMAGE_PRUNE_SCHEDULE: %w", err)
+	}
+	jobs.Add(scheduler.Job{
+		Name:     "image cache pruning",
+		Schedule: pruneSchedule,
+		Run: func(context.Context) error {
+			n, err := imageCache.Prune(c.envDuration("IMAGE_CACHE_MAX_AGE", 30*24*time.Hour))
+			if n > 0 {
+				s.log.Printf("images: pruned %d unused images", n)
+			}
+			return err
+		},
+	})
+
+	subscriptions, err := newsletterProvider(c)
+	if err != nil {
+		return fmt.Errorf("initialize newsletter: %w", err)
+	}
+	opts.Newsletter = subscriptions
+
+	if database != nil && opts.BaseURL != "" {
+		store, err := webmention.NewStore(ctx, database)
+		if err != nil {
+			return fmt.Errorf("initialize webmentions: %w", err)
+		}
+		opts.Webmentions = store
+		sender := webmention.NewSender(store, pool)
+		hooks.OnPublish(func(ctx context.Context, data content.PageData) error {
+			if err := sender.Publish(ctx, mentionPages(data, opts.BaseURL)); err != nil {
+				return fmt.Errorf("webmention: %w", err)
+			}
+			return nil
+		})
+	}
+
+	if enabled, _ := strconv.ParseBool(c.getenv("INDIEAUTH")); enabled {
+		if database == nil || opts.BaseURL == "" || c.getenv("ADMIN_USER") == "" {
+			return errors.New("INDIEAUTH requires DATABASE_PATH, BASE_URL and ADMIN_USER")
+		}
+		ia, err := indieauth.New(ctx, database, opts.BaseURL)
+		if err != nil {
+			return fmt.Errorf("initialize indieauth: %w", err)
+		}
+		ia.Now = s.now
+		opts.IndieAuth = ia
+	}
+
+	if database != nil {
+		shelf, err := books.NewStore(ctx, database)
+		if err != nil {
+			return fmt.Errorf("initialize books: %w", err)
+		}
+		opts.Books = shelf
+		if opts.Guestbook, err = guestbook.NewStore(ctx, database); err != nil {
+			return fmt.Errorf("initialize guestbook: %w", err)
+		}
+		if opts.Inbox, err = inbox.NewStore(ctx, database); err != nil {
+			return fmt.Errorf("initialize inbox: %w", err)
+		}
+		hooks.OnContactSubmission(handler.StoreContact(opts.Inbox))
+		if opts.ShortLinks, err = shortlinks.NewStore(ctx, database); err != nil {
+			return fmt.Errorf("initialize short links: %w", err)
+		}
+		if opts.Likes, err = likes.NewStore(ctx, database, c.envDuration("LIKES_WINDOW", 24*time.Hour)); err != nil {
+			return fmt.Errorf("initialize likes: %w", err)
+		}
+	}
+
+	h, err := handler.New(s.fsys, opts)
+	if err != nil {
+		return fmt.Errorf("initialize handler: %w", err)
+	}
+	s.h = h
+	limits := images.DefaultLimits
+	limits.Width = c.envInt("IMAGE_MAX_WIDTH", limits.Width)
+	if problems, err := h.LintImages(limits); err != nil {
+		s.log.Printf("images: %v", err)
+	} else {
+		for _, p := range problems {
+			s.log.Printf("images: %s", p)
+		}
+	}
+	if opts.IndieAuth != nil {
+		opts.IndieAuth.Profile = h.IndieAuthProfile
+	}
+
+	if alerts != nil {
+		jobs.OnError = func(job string, err error) {
+			alerts.Alert("job "+job, fmt.Sprintf("Background job %q failed: %v", job, err))
+		}
+	}
+	if to := c.getenv("DIGEST_EMAIL"); to != "" && s.recorder != nil && mail != nil {
+		weekly := &digest.Digest{
+			Analytics: s.recorder,
+			Mailer:    mail,
+			Templates: emails,
+			To:        to,
+			SiteName:  h.Data().About.Name,
+			BaseURL:   opts.BaseURL,
+		}
+		schedule, err := scheduler.Cron(cmp.Or(c.getenv("DIGEST_SCHEDULE"), "0 8 * * 1"))
+		if err != nil {
+			return fmt.Errorf("invalid DIGEST_SCHEDULE: %w", err)
+		}
+		jobs.Add(scheduler.Job{Name: "weekly digest", Schedule: schedule, Run: weekly.Send})
+	}
+	var gh *github.Client
+	if user := c.getenv("GITHUB_USER"); user != "" {
+		gh = github.NewClient(user, c.getenv("GITHUB_TOKEN"))
+		if api := c.getenv("GITHUB_API_URL"); api != "" {
+			gh.API = strings.TrimSuffix(api, "/")
+		}
+		jobs.Add(scheduler.Job{
+			Name:      "github stats",
+			Schedule:  scheduler.Every(time.Hour),
+			Immediate: true,
+			Run: func(ctx context.Context) error {
+				st, err := gh.Stats(ctx)
+				if err != nil {
+					return err
+				}
+				h.SetGitHubStats(st)
+				return nil
+			},
+		})
+	}
+	providers, err := repoProviders(c, c.getenv("REPO_ACCOUNTS"), gh)
+	if err != nil {
+		return fmt.Errorf("invalid REPO_ACCOUNTS: %w", err)
+	}
+	if len(providers) > 0 {
+		syncer := repos.NewSyncer(c.envInt("REPO_MIN_STARS", 1), providers...)
+		jobs.Add(scheduler.Job{
+			Name:      "repo sync",
+			Schedule:  scheduler.Every(c.envDuration("REPO_SYNC_INTERVAL", time.Hour)),
+			Immediate: true,
+			Run: func(ctx context.Context) error {
+				list, err := syncer.Sync(ctx)
+				h.SetRepos(list)
+				return err
+			},
+		})
+	}
+	pkgClient := pkgstats.NewClient()
+	jobs.Add(scheduler.Job{
+		Name:      "package stats",
+		Schedule:  scheduler.Every(c.envDuration("PACKAGE_STATS_INTERVAL", 12*time.Hour)),
+		Immediate: true,
+		Run: func(ctx context.Context) error {
+			refs := h.PackageRefs()
+			if len(refs) == 0 {
+				return nil
+			}
+			stats, err := pkgClient.Fetch(ctx, refs)
+			h.SetPackages(stats)
+			return err
+		},
+	})
+	if account := c.getenv("MASTODON_ACCOUNT"); account != "" {
+		masto, err := mastodon.NewClient(account)
+		if err != nil {
+			return fmt.Errorf("invalid MASTODON_ACCOUNT: %w", err)
+		}
+		jobs.Add(scheduler.Job{
+			Name:      "mastodon posts",
+			Schedule:  scheduler.Every(c.envDuration("MASTODON_REFRESH_INTERVAL", 15*time.Minute)),
+			Immediate: true,
+			Run: func(ctx context.Context) error {
+				posts, err := masto.Posts(ctx, 5)
+				if err != nil {
+					return err
+				}
+				h.SetSocial(masto.Profile(), posts)
+				return nil
+			},
+		})
+	}
+	if id, secret, refresh := c.getenv("STRAVA_CLIENT_ID"), c.getenv("STRAVA_CLIENT_SECRET"), c.getenv("STRAVA_REFRESH_TOKEN"); id != "" && secret != "" && refresh != "" {
+		athlete, err := strava.NewClient(ctx, database, id, secret, refresh)
+		if err != nil {
+			return fmt.Errorf("initialize strava: %w", err)
+		}
+		jobs.Add(scheduler.Job{
+			Name:      "strava activity",
+			Schedule:  scheduler.Every(c.envDuration("STRAVA_REFRESH_INTERVAL", time.Hour)),
+			Immediate: true,
+			Run: func(ctx context.Context) error {
+				a, err := athlete.Latest(ctx)
+				if err != nil {
+					return err
+				}
+				if a != nil {
+					h.SetStrava(a)
+				}
+				return nil
+			},
+		})
+	}
+	if calendar := bookingProvider(c); calendar != nil {
+		loc, err := time.LoadLocation(cmp.Or(c.getenv("BOOKING_TIMEZONE"), "UTC"))
+		if err != nil {
+			return fmt.Errorf("invalid BOOKING_TIMEZONE: %w", err)
+		}
+		days := c.envInt("BOOKING_DAYS", 7)
+		jobs.Add(scheduler.Job{
+			Name:      "booking slots",
+			Schedule:  scheduler.Every(c.envDuration("BOOKING_REFRESH_INTERVAL", 15*time.Minute)),
+			Immediate: true,
+			Run: func(ctx context.Context) error {
+				// Start an hour out: nobody books a call for right now, and
+				// Calendly rejects ranges that start in the past.
+				from := time.Now().Add(time.Hour)
+				slots, err := calendar.Slots(ctx, from, from.AddDate(0, 0, days))
+				if err != nil {
+					return err
+				}
+				h.SetBooking(handler.BookingData{Days: booking.ByDay(slots, loc, 6), Zone: loc.String()})
+				return nil
+			},
+		})
+	}
+	if id := c.getenv("YOUTUBE_CHANNEL_ID"); id != "" {
+		channel := youtube.NewChannel(id)
+		count := c.envInt("YOUTUBE_VIDEOS", 6)
+		jobs.Add(scheduler.Job{
+			Name:      "youtube uploads",
+			Schedule:  scheduler.Every(c.envDuration("YOUTUBE_REFRESH_INTERVAL", time.Hour)),
+			Immediate: true,
+			Run: func(ctx context.Context) error {
+				list, err := channel.Latest(ctx, count)
+				if err != nil {
+					return err
+				}
+				h.SetVideos(list)
+				return nil
+			},
+		})
+	}
+	if id := c.getenv("STACKEXCHANGE_USER_ID"); id != "" {
+		client := stackexchange.NewClient(id, cmp.Or(c.getenv("STACKEXCHANGE_SITE"), "stackoverflow"), c.getenv("STACKEXCHANGE_KEY"))
+		jobs.Add(scheduler.Job{
+			Name:      "stack exchange profile",
+			Schedule:  scheduler.Every(c.envDuration("STACKEXCHANGE_REFRESH_INTERVAL", 6*time.Hour)),
+			Immediate: true,
+			Run: func(ctx context.Context) error {
+				p, err := client.Profile(ctx, 5)
+				if err != nil {
+					return err
+				}
+				h.SetStackExchange(p)
+				return nil
+			},
+		})
+	}
+	if monitor := uptimeMonitor(c); monitor != nil {
+		jobs.Add(scheduler.Job{
+			Name:      "uptime status",
+			Schedule:  scheduler.Every(c.envDuration("UPTIME_REFRESH_INTERVAL", 5*time.Minute)),
+			Immediate: true,
+			Run: func(ctx context.Context) error {
+				st, err := monitor.Status(ctx)
+				if err != nil {
+					return err
+				}
+				h.SetUptime(st)
+				return nil
+			},
+		})
+	}
+	if subscriptions != nil {
+		jobs.Add(scheduler.Job{
+			Name:      "newsletter subscribers",
+			Schedule:  scheduler.Every(c.envDuration("NEWSLETTER_COUNT_INTERVAL", time.Hour)),
+			Immediate: true,
+			Run: func(ctx context.Context) error {
+				n, err := subscriptions.Subscribers(ctx)
+				if err != nil {
+					return err
+				}
+				h.SetSubscribers(n)
+				return nil
+			},
+		})
+	}
+	if user := c.getenv("OPENLIBRARY_USER"); user != "" && opts.Books != nil {
+		library := books.NewOpenLibrary(user)
+		jobs.Add(scheduler.Job{
+			Name:      "open library shelves",
+			Schedule:  scheduler.Every(c.envDuration("BOOKS_SYNC_INTERVAL", 6*time.Hour)),
+			Immediate: true,
+			Run: func(ctx context.Context) error {
+				list, err := library.Books(ctx)
+				if err != nil {
+					return err
+				}
+				return opts.Books.Replace(ctx, "openlibrary", list)
+			},
+		})
+	}
+	if player := nowPlayingProvider(c); player != nil {
+		jobs.Add(scheduler.Job{
+			Name:      "now playing",
+			Schedule:  scheduler.Every(c.envDuration("NOWPLAYING_INTERVAL", 30*time.Second)),
+			Immediate: true,
+			Run: func(ctx context.Context) error {
+				track, err := player.NowPlaying(ctx)
+				if err != nil {
+					return err
+				}
+				if !h.SetNowPlaying(track) {
+					return nil
+				}
+				frag, err := h.Fragment("nowplaying", track)
+				if err != nil {
+					return err
+				}
+				events.Publish(handler.NowPlayingTopic, frag)
+				return nil
+			},
+		})
+	}
+
+	if username := c.getenv("ACTIVITYPUB_USERNAME"); username != "" {
+		if database == nil || opts.BaseURL == "" {
+			return errors.New("ACTIVITYPUB_USERNAME requires DATABASE_PATH and BASE_URL")
+		}
+		about := h.Data().About
+		fedi, err := activitypub.New(ctx, database, activitypub.Config{
+			BaseURL:  opts.BaseURL,
+			Username: username,
+			Name:     about.Name,
+			Summary:  about.Tagline,
+			Icon:     absoluteURL(opts.BaseURL, about.ProfilePhoto),
+		}, pool)
+		if err != nil {
+			return fmt.Errorf("initialize activitypub: %w", err)
+		}
+		hooks.ExtraRoutes(fedi.Register)
+		hooks.OnPublish(func(ctx context.Context, data content.PageData) error {
+			if err := fedi.Publish(ctx, articles(data, opts.BaseURL)); err != nil {
+				return fmt.Errorf("activitypub: %w", err)
+			}
+			return nil
+		})
+	}
+
+	staticFS, err := fs.Sub(s.fsys, "static")
+	if err != nil {
+		return fmt.Errorf("create static sub-FS: %w", err)
+	}
+
+	publishViewers(h, events)
+
+	s.rpc = grpcserver.New(h)
+	gateway, err := grpcserver.Gateway(ctx, s.rpc)
+	if err != nil {
+		return fmt.Errorf("initialize grpc gateway: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	h.PublicRoutes(mux)
+	h.FormRoutes(mux)
+	mux.HandleFunc("GET /health", h.Health)
+	mux.Handle("GET /events", events)
+
+	adminUser, adminPass := c.getenv("ADMIN_USER"), c.getenv("ADMIN_PASSWORD")
+	// Browsers resend Basic credentials on cross-site requests, so the
+	// admin routes also refuse unsafe requests from other origins.
+	csrf := http.NewCrossOriginProtection()
+	admin := func(next http.HandlerFunc) http.Handler {
+		return csrf.Handler(auth.Basic(adminUser, adminPass, next))
+	}
+	mux.Handle("GET /api/export", admin(h.Export))
+	mux.Handle("GET /admin/stats", admin(h.AdminStats))
+	mux.Handle("GET /metrics", admin(metrics.Handler().ServeHTTP))
+	mux.Handle("GET /admin/webmentions", admin(h.AdminWebmentions))
+	mux.Handle("GET /admin/jobs", admin(h.AdminJobs))
+	mux.Handle("POST /admin/jobs/run", admin(h.RunJob))
+	if opts.IndieAuth != nil {
+		mux.Handle("GET "+indieauth.AuthorizationPath, admin(h.IndieAuthorize))
+		mux.Handle("POST /auth/decide", admin(h.IndieAuthDecide))
+		opts.IndieAuth.Register(mux, admin)
+	}
+	mux.Handle("POST /admin/webmentions/{id}", admin(h.ModerateWebmention))
+	mux.Handle("GET /admin/guestbook", admin(h.AdminGuestbook))
+	mux.Handle("POST /admin/guestbook/{id}", admin(h.ModerateGuestbook))
+	mux.Handle("GET /admin/messages", admin(h.AdminMessages))
+	mux.Handle("POST /admin/messages/{id}", admin(h.MarkMessage))
+	mux.Handle("GET /admin/links", admin(h.AdminLinks))
+	mux.Handle("POST /admin/links", admin(h.AddShortLink))
+	mux.Handle("DELETE /admin/links/{code}", admin(h.DeleteShortLink))
+	if repo := c.getenv("PREVIEW_REPO"); repo != "" {
+		previews := preview.New(repo, cmp.Or(c.getenv("PREVIEW_DATA_DIR"), "data"), s.fsys, c.BaseURL)
+		previews.Fetch, _ = strconv.ParseBool(c.getenv("PREVIEW_FETCH"))
+		s.closers = append(s.closers, func() error { previews.Close(); return nil })
+		mux.Handle("GET "+preview.Prefix+"{ref}/{path...}", admin(previews.ServeHTTP))
+	}
+
+	mux.Handle("GET /v1/", gateway)
+	if opts.Webmentions != nil {
+		mux.Handle("POST /webmention", webmention.NewReceiver(opts.Webmentions, h.WebmentionTarget, s.pool))
+	}
+	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(staticFS)))
+	hooks.OnRequest(h.DisableRoutes)
+	if s.dev != "" {
+		hooks.OnRequest(htmlcheck.Middleware)
+		if check, _ := strconv.ParseBool(cmp.Or(c.getenv("VALIDATE_API_RESPONSES"), "true")); check {
+			hooks.OnRequest(schemas.CheckResponses)
+		}
+		hooks.OnRequest(livereload.Inject)
+	}
+	for _, f := range s.routes {
+		hooks.ExtraRoutes(f)
+	}
+	hooks.Routes(mux)
+	var site http.Handler = mux
+	if dir := c.getenv("AB_VARIANT_DIR"); dir != "" {
+		hB, err := h.Variant(sitefs.FS{Base: s.fsys, Theme: os.DirFS(dir)})
+		if err != nil {
+			return fmt.Errorf("load AB_VARIANT_DIR: %w", err)
+		}
+		muxB := http.NewServeMux()
+		hB.PublicRoutes(muxB)
+		percent := c.envInt("AB_PERCENT", 50)
+		split := abtest.New(muxB, percent)
+		hooks.OnRequest(split.Assign)
+		site = split.Route(mux)
+		s.log.Printf("A/B test: %d%% of visitors see %s", percent, dir)
+	}
+	if len(opts.Languages) > 1 {
+		langs := i18n.New(opts.Lang)
+		for _, lang := range opts.Languages[1:] {
+			hl, err := h.Locale(i18n.FS(s.fsys, lang), lang)
+			if err != nil {
+				return fmt.Errorf("load language %s: %w", lang, err)
+			}
+			muxL := http.NewServeMux()
+			hl.PublicRoutes(muxL)
+			langs.Add(lang, muxL)
+		}
+		hooks.OnRequest(langs.Select)
+		site = langs.Route(site)
+		s.log.Printf("serving languages %s", strings.Join(opts.Languages, ", "))
+	}
+	root := hooks.Middleware(site)
+
+	if path := c.getenv("TENANTS_FILE"); path != "" {
+		list, err := loadTenants(path)
+		if err != nil {
+			return fmt.Errorf("invalid TENANTS_FILE:\n%w", err)
+		}
+		if s.tenants, err = newTenantRouter(list, s.fsys, root); err != nil {
+			return fmt.Errorf("initialize tenants: %w", err)
+		}
+		root = s.tenants
+		s.log.Printf("serving %d tenants", len(list))
+	}
+	s.root = loggingMiddleware(s.log, root)
+	return nil
+}
+
+// Handler returns the site's handler, for mounting at the root of another
+// server's mux. Call Start as well to run the background jobs.
+func (s *Server) Handler() http.Handler {
+	return s.root
+}
+
+// Start runs the background jobs and, for the ports set in the config, the
+// HTTP and gRPC servers. It blocks until ctx is done, Shutdown is called or
+// a server fails, and returns the failure if any. Call Shutdown afterwards
+// to stop the servers.
+func (s *Server) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.mu.Unlock()
+		return errors.New("server already started")
+	}
+	s.cancel = cancel
+	if s.cfg.Port != "" {
+		s.httpSrv = &http.Server{
+			Addr:         ":" + s.cfg.Port,
+			Handler:      s.root,
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		}
+		s.httpSrv.RegisterOnShutdown(s.events.Close)
+	}
+	if s.cfg.GRPCPort != "" {
+		s.grpcSrv = grpc.NewServer()
+		pb.RegisterPortfolioServiceServer(s.grpcSrv, s.rpc)
+	}
+	httpSrv, grpcSrv := s.httpSrv, s.grpcSrv
+	s.mu.Unlock()
+
+	go s.jobs.Run(ctx)
+	s.publish(ctx)
+	publishAvailability(s.h, s.events)
+	if s.dev != "" {
+		s.watch(ctx)
+	}
+
+	errc := make(chan error, 2)
+	if httpSrv != nil {
+		ln, err := net.Listen("tcp", httpSrv.Addr)
+		if err != nil {
+			return fmt.Errorf("listen: %w", err)
+		}
+		s.log.Printf("server listening on %s", httpSrv.Addr)
+		go func() {
+			if err := httpSrv.Serve(ln); err != nil && err != http.ErrServerClosed {
+				errc <- fmt.Errorf("server error: %w", err)
+			}
+		}()
+	}
+	if grpcSrv != nil {
+		ln, err := net.Listen("tcp", ":"+s.cfg.GRPCPort)
+		if err != nil {
+			return fmt.Errorf("grpc listen: %w", err)
+		}
+		s.log.Printf("grpc listening on :%s", s.cfg.GRPCPort)
+		go func() {
+			if err := grpcSrv.Serve(ln); err != nil {
+				errc <- fmt.Errorf("grpc server error: %w", err)
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errc:
+		return err
+	}
+}
+
+// Shutdown gracefully stops the servers and background jobs, then closes
+// the database. The server cannot be started again.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	cancel, httpSrv, grpcSrv := s.cancel, s.httpSrv, s.grpcSrv
+	s.mu.Unlock()
+
+	if grpcSrv != nil {
+		grpcSrv.GracefulStop()
+	}
+	var err error
+	if httpSrv != nil {
+		err = httpSrv.Shutdown(ctx)
+	} else {
+		s.events.Close()
+	}
+	if cancel != nil {
+		cancel()
+	}
+	if perr := s.pool.Close(ctx); err == nil {
+		err = perr
+	}
+	s.close()
+	return err
+}
+
+// close releases the database and the other resources opened by New.
+func (s *Server) close() {
+	s.closeOnce.Do(func() {
+		if s.recorder != nil {
+			s.recorder.Close()
+		}
+		for _, c := range slices.Backward(s.closers) {
+			if err := c(); err != nil {
+				s.log.Printf("close: %v", err)
+			}
+		}
+	})
+}
+
+// Reload re-reads the templates and data files of the site and its
+// tenants, and announces the changes.
+func (s *Server) Reload() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	before := s.h.Data().About.Availability
+	if err := s.h.Reload(); err != nil {
+		return err
+	}
+	s.log.Println("data reloaded")
+	if s.tenants != nil {
+		if err := s.tenants.Reload(); err != nil {
+			s.log.Printf("reload failed: %v", err)
+		}
+	}
+	s.publish(context.Background())
+	if s.h.Data().About.Availability != before {
+		publishAvailability(s.h, s.events)
+	}
+	return nil
+}
+
+// publish announces new and changed projects to followers and to the
+// sites they link to.
+func (s *Server) publish(ctx context.Context) {
+	if err := s.hooks.Publish(ctx, s.h.Data()); err != nil {
+		s.log.Printf("publish: %v", err)
+	}
+}
+
+// watch reloads the data and open pages when the files of the site
+// directory change.
+func (s *Server) watch(ctx context.Context) {
+	versions := livereload.NewVersioner(s.events.Publish)
+	dirs := []string{filepath.Join(s.dev, "templates"), filepath.Join(s.dev, "static"), filepath.Join(s.dev, "data"), filepath.Join(s.dev, "content")}
+	go livereload.Watch(ctx, dirs, 300*time.Millisecond, func(changed []string) {
+		for _, p := range changed {
+			if !strings.HasPrefix(p, dirs[1]+string(filepath.Separator)) {
+				if err := s.Reload(); err != nil {
+					s.log.Printf("reload failed: %v", err)
+					return
+				}
+				break
+			}
+		}
+		s.log.Printf("dev: %d files changed, reloading pages", len(changed))
+		versions.Bump()
+	})
+	s.log.Printf("dev mode: serving %s", s.dev)
+}
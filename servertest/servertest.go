@@ -0,0 +1,116 @@
+// Package servertest runs the whole site, with its middleware, admin routes
+// and integrations, on an httptest server, for end-to-end tests against the
+// real routes:
+//
+//	srv := servertest.New(t, servertest.Options{
+//		Env: map[string]string{"ADMIN_USER": "admin", "ADMIN_PASSWORD": "secret"},
+//	})
+//	resp, err := srv.Client().Get(srv.URL + "/")
+package servertest
+
+import (
+	"context"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	portfolio "github.com/fpatron/portfolio"
+)
+
+// Options configures the site under test. The zero value serves the
+// built-in files with no integrations.
+type Options struct {
+	// FS holds the templates, static files and data files. It defaults to
+	// portfolio.FS.
+	FS fs.FS
+	// Env holds the settings of the integrations by environment variable
+	// name, such as ADMIN_USER. The real environment is not read.
+	Env map[string]string
+	// Database opens a database in the test's temporary directory, which
+	// enables analytics, webmentions and the other stored features.
+	Database bool
+	// Now, when set, is the clock of the analytics, IndieAuth, the local
+	// time and the contact form's checks.
+	Now func() time.Time
+	// Routes add routes next to the built-in ones.
+	Routes []func(*http.ServeMux)
+}
+
+// Server is a site running for a test.
+type Server struct {
+	*httptest.Server
+	// Site is the site, for reloading it or reaching its handler.
+	Site *portfolio.Server
+}
+
+// New starts the site and shuts it down when the test ends. Its BASE_URL is
+// the server's URL. The background jobs do not run, and log messages go to
+// the test log.
+func New(t testing.TB, opts Options) *Server {
+	t.Helper()
+	ts := httptest.NewUnstartedServer(nil)
+	cfg := portfolio.Config{
+		BaseURL: "http://" + ts.Listener.Addr().String(),
+		Getenv:  func(key string) string { return opts.Env[key] },
+	}
+	if opts.Database {
+		cfg.DatabasePath = filepath.Join(t.TempDir(), "portfolio.db")
+	}
+	logs := &testLog{t: t}
+	options := []portfolio.Option{portfolio.WithConfig(cfg), portfolio.WithLogger(log.New(logs, "", 0))}
+	if opts.FS != nil {
+		options = append(options, portfolio.WithFS(opts.FS))
+	}
+	if opts.Now != nil {
+		options = append(options, portfolio.WithClock(opts.Now))
+	}
+	for _, f := range opts.Routes {
+		options = append(options, portfolio.WithRoutes(f))
+	}
+	site, err := portfolio.New(options...)
+	if err != nil {
+		ts.Close()
+		t.Fatalf("servertest: %v", err)
+	}
+	ts.Config.Handler = site.Handler()
+	ts.Start()
+	t.Cleanup(func() {
+		ts.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := site.Shutdown(ctx); err != nil {
+			t.Errorf("servertest: shutdown: %v", err)
+		}
+		logs.stop()
+	})
+	return &Server{Server: ts, Site: site}
+}
+
+// testLog writes log messages to the test log until the test ends, after
+// which the testing package no longer accepts them.
+type testLog struct {
+	mu   sync.Mutex
+	t    testing.TB
+	done bool
+}
+
+func (l *testLog) Write(b []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.done {
+		l.t.Log(strings.TrimSuffix(string(b), "\n"))
+	}
+	return len(b), nil
+}
+
+func (l *testLog) stop() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.done = true
+}
@@ -0,0 +1,153 @@
+package servertest_test
+
+import (
+	"encoding/json"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fpatron/portfolio/servertest"
+)
+
+// clock is a test clock that only moves when told to.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+var tokenInput = regexp.MustCompile(`name="token" value="([^"]+)"`)
+
+// TestContactRateLimit sends the contact form through the whole site: a
+// post with a token from the form's view is delivered, and the next one
+// past the burst is answered 429 with a Retry-After header.
+func TestContactRateLimit(t *testing.T) {
+	c := &clock{now: time.Date(2026, time.January, 14, 9, 30, 0, 0, time.UTC)}
+	srv := servertest.New(t, servertest.Options{
+		Env: map[string]string{
+			"CONTACT_BURST":               "1",
+			"CONTACT_REQUESTS_PER_MINUTE": "1",
+		},
+		Now: c.Now,
+	})
+
+	body := post(t, srv, "/contact/viewed", nil, http.StatusOK)
+	m := tokenInput.FindStringSubmatch(body)
+	if m == nil {
+		t.Fatalf("POST /contact/viewed: no token in %q", body)
+	}
+	c.Add(5 * time.Second)
+
+	form := url.Values{
+		"name":    {"Ada"},
+		"email":   {"ada@example.com"},
+		"message": {"Hello, this is a message about a project."},
+		"token":   {m[1]},
+	}
+	if body := post(t, srv, "/contact", form, http.StatusOK); !strings.Contains(body, "contact-success") {
+		t.Errorf("first POST /contact: want the success message, got %q", body)
+	}
+	resp := send(t, srv, "/contact", form)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("second POST /contact: got %d, want 429", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want 60", got)
+	}
+}
+
+// send posts form to path.
+func send(t *testing.T, srv *servertest.Server, path string, form url.Values) *http.Response {
+	t.Helper()
+	resp, err := srv.Client().PostForm(srv.URL+path, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// post posts form to path, checks the status and returns the body.
+func post(t *testing.T, srv *servertest.Server, path string, form url.Values, status int) string {
+	t.Helper()
+	resp := send(t, srv, path, form)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != status {
+		t.Fatalf("POST %s: got %d, want %d: %s", path, resp.StatusCode, status, b)
+	}
+	return string(b)
+}
+
+var oembedLink = regexp.MustCompile(`<link rel="alternate" type="application/json\+oembed" href="([^"]+)"`)
+
+// TestPostOEmbed follows the oEmbed discovery link of a blog post and
+// checks the embed it describes.
+func TestPostOEmbed(t *testing.T) {
+	srv := servertest.New(t, servertest.Options{})
+	page := get(t, srv, "/blog/htmx-and-go", http.StatusOK)
+	m := oembedLink.FindStringSubmatch(page)
+	if m == nil {
+		t.Fatal("post page has no oEmbed discovery link")
+	}
+	link, err := url.Parse(html.UnescapeString(m[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Path != "/oembed" || link.Query().Get("url") != srv.URL+"/blog/htmx-and-go" {
+		t.Fatalf("discovery link = %s, want the oEmbed of the post", link)
+	}
+
+	var resp struct {
+		Type, Title, HTML string
+	}
+	if err := json.Unmarshal([]byte(get(t, srv, link.RequestURI(), http.StatusOK)), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Type != "rich" || resp.Title != "Building this site with Go and HTMX" {
+		t.Errorf("oEmbed = %+v, want the rich embed of the post", resp)
+	}
+	if !strings.Contains(resp.HTML, `href="`+srv.URL+`/blog/htmx-and-go"`) {
+		t.Errorf("embed doesn't link to the post: %s", resp.HTML)
+	}
+
+	get(t, srv, "/oembed?url="+url.QueryEscape(srv.URL+"/blog/missing"), http.StatusNotFound)
+}
+
+// get fetches path, checks the status and returns the body.
+func get(t *testing.T, srv *servertest.Server, path string, status int) string {
+	t.Helper()
+	resp, err := srv.Client().Get(srv.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != status {
+		t.Fatalf("GET %s: got %d, want %d: %s", path, resp.StatusCode, status, b)
+	}
+	return string(b)
+}
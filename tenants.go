@@ -0,0 +1,154 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fpatron/portfolio/internal/handler"
+	"github.com/fpatron/portfolio/internal/metrics"
+	"github.com/fpatron/portfolio/internal/sitefs"
+)
+
+// tenant is an additional site served by the same process, listed in the
+// file named by TENANTS_FILE.
+type tenant struct {
+	Name    string   `json:"name"`
+	Hosts   []string `json:"hosts"`
+	BaseURL string   `json:"base_url"`
+	// Data is the directory holding the tenant's data files.
+	Data string `json:"data"`
+	// Theme, when set, is a directory whose templates/ and static/ files
+	// replace the built-in ones of the same name.
+	Theme string `json:"theme"`
+}
+
+// loadTenants reads and checks the tenants file.
+func loadTenants(path string) ([]tenant, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var list []tenant
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	var errs []error
+	names := make(map[string]bool)
+	hosts := make(map[string]bool)
+	for i, t := range list {
+		switch {
+		case t.Name == "" || t.Name == "default":
+			errs = append(errs, fmt.Errorf("tenants[%d]: name must be set and not %q", i, "default"))
+		case names[t.Name]:
+			errs = append(errs, fmt.Errorf("tenants[%d]: duplicate name %q", i, t.Name))
+		}
+		names[t.Name] = true
+		if t.Data == "" {
+			errs = append(errs, fmt.Errorf("tenants[%d] (%s): missing data directory", i, t.Name))
+		}
+		if len(t.Hosts) == 0 {
+			errs = append(errs, fmt.Errorf("tenants[%d] (%s): no hosts", i, t.Name))
+		}
+		for _, h := range t.Hosts {
+			h = strings.ToLower(h)
+			if hosts[h] {
+				errs = append(errs, fmt.Errorf("tenants[%d] (%s): host %q is already used", i, t.Name, h))
+			}
+			hosts[h] = true
+		}
+	}
+	return list, errors.Join(errs...)
+}
+
+// files returns the tenant's site content over base, the primary site's
+// files.
+func (t tenant) files(base fs.FS) fs.FS {
+	site := sitefs.FS{Base: base, Data: os.DirFS(t.Data)}
+	if t.Theme != "" {
+		site.Theme = os.DirFS(t.Theme)
+	}
+	return site
+}
+
+// tenantSite is a tenant's own handler and routes. Tenants get the
+// content pages, partials and API; the integrations configured through the
+// environment belong to the primary site. Contact messages are only logged.
+type tenantSite struct {
+	name string
+	h    *handler.Handler
+	mux  *http.ServeMux
+}
+
+func newTenantSite(t tenant, base fs.FS) (*tenantSite, error) {
+	site := t.files(base)
+	h, err := handler.New(site, handler.Options{BaseURL: t.BaseURL})
+	if err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", t.Name, err)
+	}
+	static, err := fs.Sub(site, "static")
+	if err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", t.Name, err)
+	}
+	mux := http.NewServeMux()
+	h.PublicRoutes(mux)
+	h.FormRoutes(mux)
+	mux.HandleFunc("GET /health", h.Health)
+	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(static)))
+	return &tenantSite{name: t.Name, h: h, mux: mux}, nil
+}
+
+// tenantRouter sends each request to the tenant owning its host, and
+// requests for any other host to the primary site. It counts requests per
+// tenant.
+type tenantRouter struct {
+	sites   []*tenantSite
+	byHost  map[string]*tenantSite
+	primary http.Handler
+}
+
+func newTenantRouter(list []tenant, base fs.FS, primary http.Handler) (*tenantRouter, error) {
+	r := &tenantRouter{byHost: make(map[string]*tenantSite), primary: primary}
+	for _, t := range list {
+		site, err := newTenantSite(t, base)
+		if err != nil {
+			return nil, err
+		}
+		r.sites = append(r.sites, site)
+		for _, host := range t.Hosts {
+			r.byHost[strings.ToLower(host)] = site
+		}
+	}
+	return r, nil
+}
+
+func (tr *tenantRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	name, next := "default", tr.primary
+	if site, ok := tr.byHost[strings.ToLower(host)]; ok {
+		name, next = site.name, site.mux
+	}
+	rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+	next.ServeHTTP(rw, r)
+	metrics.TenantRequests.WithLabelValues(name, strconv.Itoa(rw.status)).Inc()
+}
+
+// Reload re-reads every tenant's templates and data files.
+func (tr *tenantRouter) Reload() error {
+	var errs []error
+	for _, site := range tr.sites {
+		if err := site.h.Reload(); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", site.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}